@@ -0,0 +1,157 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+
+	"github.com/oklog/ulid/v2"
+)
+
+// verifyBalances 立て替えの金額と支払いの合計、残高テーブルと支払いが一致しているかを検証する
+// 不整合が見つかった場合はエラーを返す
+func verifyBalances(ctx context.Context, a *admin, args []string) error {
+	fs := flag.NewFlagSet("balances verify", flag.ContinueOnError)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	r := a.repositories()
+
+	events, err := r.queries.ListUnbalancedEvents(ctx)
+	if err != nil {
+		return err
+	}
+	for _, e := range events {
+		// 個人間の立て替えはグループに属さない
+		group := "なし"
+		if e.GroupID != nil {
+			group = *e.GroupID
+		}
+		a.printf("不一致\t%s\t%s\tグループ=%s\t金額=%d\t支払い合計=%d\n", e.ID, e.Name, group, e.Amount, e.Paid)
+	}
+
+	mismatches, err := r.queries.ListBalanceMismatches(ctx)
+	if err != nil {
+		return err
+	}
+	for _, m := range mismatches {
+		a.printf("残高不一致\t%s\t%s\t支払いからの集計=%d\t残高テーブル=%d\n", m.UserID, m.OtherUserID, m.Expected, m.Actual)
+	}
+
+	total, err := r.credits.TotalOutstanding(ctx)
+	if err != nil {
+		return err
+	}
+	a.printf("未精算の金額の合計: %d\n", total)
+
+	var errs []error
+	if len(events) > 0 {
+		errs = append(errs, fmt.Errorf("%d件の立て替えで金額と支払いの合計が一致しません", len(events)))
+	}
+	if len(mismatches) > 0 {
+		errs = append(errs, fmt.Errorf("%d件の残高が支払いと一致しません (balances rebuildで作り直してください)", len(mismatches)))
+	}
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+	a.printf("不整合は見つかりませんでした\n")
+
+	return nil
+}
+
+// recomputeBalances 支払いが不足している立て替えの支払いマトリクスを作り直す
+// 不足分はリポジトリからの復元時と同様に作成者の立て替え額と負担額とみなす
+func recomputeBalances(ctx context.Context, a *admin, args []string) error {
+	fs := flag.NewFlagSet("balances recompute", flag.ContinueOnError)
+	dryRun := fs.Bool("dry-run", false, "変更を反映せずに実行内容を表示する")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	var recomputed, skipped int
+	err := a.transaction(ctx, *dryRun, func(r repositories) error {
+		events, err := r.queries.ListUnbalancedEvents(ctx)
+		if err != nil {
+			return err
+		}
+
+		for _, e := range events {
+			// 支払いが金額を超えている場合はどの支払いが誤っているか判断できない
+			if e.Paid > int64(e.Amount) {
+				a.printf("スキップ\t%s\t%s\t金額=%d\t支払い合計=%d (支払いが金額を超えているため手動で修正してください)\n", e.ID, e.Name, e.Amount, e.Paid)
+				skipped++
+				continue
+			}
+
+			id, err := ulid.Parse(e.ID)
+			if err != nil {
+				return err
+			}
+
+			lending, err := r.lendings.FindByID(ctx, id)
+			if err != nil {
+				return fmt.Errorf("立て替え%sの取得に失敗しました: %w", e.ID, err)
+			}
+
+			updated, err := lending.Update(ctx, lending.Name(), lending.Amount(), lending.EventDate(), lending.DueDate())
+			if err != nil {
+				return err
+			}
+			if err := r.lendings.Update(ctx, updated); err != nil {
+				return fmt.Errorf("立て替え%sの更新に失敗しました: %w", e.ID, err)
+			}
+
+			a.printf("再計算\t%s\t%s\t金額=%d\t支払い合計=%d\n", e.ID, e.Name, e.Amount, e.Paid)
+			recomputed++
+		}
+
+		a.printf("%d件の立て替えを再計算しました\n", recomputed)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if skipped > 0 {
+		return fmt.Errorf("%d件の立て替えは再計算できませんでした", skipped)
+	}
+
+	return nil
+}
+
+// rebuildBalances 残高テーブルを支払いから作り直す
+// 作り直している間は支払いの変更を待たせる
+func rebuildBalances(ctx context.Context, a *admin, args []string) error {
+	fs := flag.NewFlagSet("balances rebuild", flag.ContinueOnError)
+	dryRun := fs.Bool("dry-run", false, "変更を反映せずに実行内容を表示する")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	return a.transaction(ctx, *dryRun, func(r repositories) error {
+		if err := r.queries.LockPaymentsForBalanceRebuild(ctx); err != nil {
+			return err
+		}
+
+		mismatches, err := r.queries.ListBalanceMismatches(ctx)
+		if err != nil {
+			return err
+		}
+		for _, m := range mismatches {
+			a.printf("修正\t%s\t%s\t%d -> %d\n", m.UserID, m.OtherUserID, m.Actual, m.Expected)
+		}
+
+		if err := r.queries.DeleteAllBalances(ctx); err != nil {
+			return err
+		}
+		rows, err := r.queries.RebuildBalances(ctx)
+		if err != nil {
+			return err
+		}
+
+		a.printf("%d件の残高を作り直しました (不一致%d件)\n", rows, len(mismatches))
+		return nil
+	})
+}
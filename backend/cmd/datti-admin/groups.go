@@ -0,0 +1,61 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"slices"
+
+	"github.com/haebeal/datti/internal/domain"
+	"github.com/oklog/ulid/v2"
+)
+
+// transferGroup グループの作成者を変更する
+// 作成者が退会した場合などに、残ったメンバーへ管理権限を引き継ぐために使用する
+func transferGroup(ctx context.Context, a *admin, args []string) error {
+	fs := flag.NewFlagSet("groups transfer", flag.ContinueOnError)
+	groupID := fs.String("group", "", "対象のグループID")
+	to := fs.String("to", "", "新しい作成者のユーザーID (グループのメンバーである必要がある)")
+	dryRun := fs.Bool("dry-run", false, "変更を反映せずに実行内容を表示する")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *groupID == "" || *to == "" {
+		return errors.New("-groupと-toは必須です")
+	}
+	id, err := ulid.Parse(*groupID)
+	if err != nil {
+		return fmt.Errorf("グループIDの形式が正しくありません: %w", err)
+	}
+
+	return a.transaction(ctx, *dryRun, func(r repositories) error {
+		group, err := r.groups.FindByID(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		members, err := r.groups.FindMembersByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if !slices.ContainsFunc(members, func(m *domain.User) bool {
+			return m.ID() == *to
+		}) {
+			return fmt.Errorf("%sはグループのメンバーではありません", *to)
+		}
+
+		updated, err := group.TransferOwnership(ctx, *to)
+		if err != nil {
+			return err
+		}
+		if err := r.groups.Update(ctx, updated); err != nil {
+			return err
+		}
+
+		a.printf("グループ: %s (%s)\n", group.ID(), group.Name())
+		a.printf("作成者を%sから%sに変更しました\n", group.CreatedBy(), updated.CreatedBy())
+		return nil
+	})
+}
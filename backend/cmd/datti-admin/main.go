@@ -0,0 +1,145 @@
+// datti-admin データの修正などの運用作業を行う管理コマンド
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/haebeal/datti/internal/config"
+	"github.com/haebeal/datti/internal/gateway/postgres"
+	"github.com/haebeal/datti/internal/gateway/repository"
+	"github.com/haebeal/datti/internal/logger"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const usage = `使い方: datti-admin <コマンド> <サブコマンド> [オプション]
+
+コマンド:
+  balances verify
+        立て替えの金額と支払いの合計、残高テーブルと支払いが一致しているかを検証する
+  balances recompute [-dry-run]
+        支払いが不足している立て替えの支払いマトリクスを作り直す
+  balances rebuild [-dry-run]
+        残高テーブルを支払いから作り直す
+  users merge -from ID -into ID [-force] [-dry-run]
+        重複したユーザーを統合する
+  users export -user ID [-o FILE] [-dry-run]
+        ユーザーのデータをZIPアーカイブで出力する
+  groups transfer -group ID -to ID [-dry-run]
+        グループの作成者を変更する
+
+-dry-runを指定した場合は実行内容を表示するだけで、変更は反映しない`
+
+// command 管理コマンドのサブコマンド
+type command func(ctx context.Context, a *admin, args []string) error
+
+var commands = map[string]command{
+	"balances verify":    verifyBalances,
+	"balances recompute": recomputeBalances,
+	"balances rebuild":   rebuildBalances,
+	"users merge":        mergeUsers,
+	"users export":       exportUser,
+	"groups transfer":    transferGroup,
+}
+
+func main() {
+	if err := run(os.Args[1:]); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string) error {
+	if len(args) < 2 {
+		return errors.New(usage)
+	}
+	cmd, ok := commands[args[0]+" "+args[1]]
+	if !ok {
+		return errors.New(usage)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	cfg, err := config.LoadDatabase()
+	if err != nil {
+		return fmt.Errorf("設定が正しくありません: %w", err)
+	}
+
+	slog.SetDefault(logger.New(os.Stderr, slog.LevelInfo))
+
+	pool, err := repository.NewPool(ctx, *cfg)
+	if err != nil {
+		return err
+	}
+	defer pool.Close()
+
+	return cmd(ctx, &admin{pool: pool, out: os.Stdout}, args[2:])
+}
+
+// admin 管理コマンドの実行環境
+type admin struct {
+	pool *pgxpool.Pool
+	out  io.Writer
+}
+
+// repositories 管理コマンドから使用するリポジトリ
+type repositories struct {
+	queries    *postgres.Queries
+	users      *repository.UserRepositoryImpl
+	groups     *repository.GroupRepositoryImpl
+	lendings   *repository.LendingRepositoryImpl
+	repayments *repository.RepaymentRepositoryImpl
+	credits    *repository.CreditRepositoryImpl
+	contacts   *repository.ContactRepositoryImpl
+}
+
+func newRepositories(db repository.DB) repositories {
+	queries := postgres.New(db)
+	return repositories{
+		queries:    queries,
+		users:      repository.NewUserRepository(db),
+		groups:     repository.NewGroupRepository(queries),
+		lendings:   repository.NewLendingRepository(queries),
+		repayments: repository.NewRepaymentRepository(queries),
+		credits:    repository.NewCreditRepository(queries),
+		contacts:   repository.NewContactRepository(queries),
+	}
+}
+
+// repositories トランザクションを使用しない読み取り専用のリポジトリを返す
+func (a *admin) repositories() repositories {
+	return newRepositories(a.pool)
+}
+
+// transaction fnをトランザクション内で実行する
+// dryRunの場合は変更を確定せずにロールバックする
+func (a *admin) transaction(ctx context.Context, dryRun bool, fn func(r repositories) error) error {
+	tx, err := a.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	// コミット後のロールバックは何もしない
+	defer tx.Rollback(context.WithoutCancel(ctx))
+
+	if err := fn(newRepositories(tx)); err != nil {
+		return err
+	}
+
+	if dryRun {
+		a.printf("ドライランのため変更は反映していません\n")
+		return nil
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (a *admin) printf(format string, args ...any) {
+	fmt.Fprintf(a.out, format, args...)
+}
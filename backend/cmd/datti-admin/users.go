@@ -0,0 +1,113 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"os"
+
+	"github.com/haebeal/datti/internal/presentation/api/handler"
+	"github.com/haebeal/datti/internal/usecase"
+)
+
+// mergeUsers 重複したユーザーを統合する
+// 認証プロバイダの移行時に別のユーザーとして登録されてしまった場合に使用する
+func mergeUsers(ctx context.Context, a *admin, args []string) error {
+	fs := flag.NewFlagSet("users merge", flag.ContinueOnError)
+	fromID := fs.String("from", "", "統合元のユーザーID (統合後に削除される)")
+	intoID := fs.String("into", "", "統合先のユーザーID")
+	force := fs.Bool("force", false, "メールアドレスが一致しない場合も統合する")
+	dryRun := fs.Bool("dry-run", false, "変更を反映せずに実行内容を表示する")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *fromID == "" || *intoID == "" {
+		return errors.New("-fromと-intoは必須です")
+	}
+	if *fromID == *intoID {
+		return errors.New("-fromと-intoには異なるユーザーを指定してください")
+	}
+
+	return a.transaction(ctx, *dryRun, func(r repositories) error {
+		from, err := r.users.FindByID(ctx, *fromID)
+		if err != nil {
+			return err
+		}
+		into, err := r.users.FindByID(ctx, *intoID)
+		if err != nil {
+			return err
+		}
+
+		a.printf("統合元: %s (%s, %s)\n", from.ID(), from.Name(), from.Email())
+		a.printf("統合先: %s (%s, %s)\n", into.ID(), into.Name(), into.Email())
+		if from.Email() != into.Email() && !*force {
+			return errors.New("メールアドレスが一致しません。同一人物であることを確認した上で-forceを指定してください")
+		}
+
+		groups, err := r.groups.FindByMemberUserID(ctx, from.ID())
+		if err != nil {
+			return err
+		}
+		for _, g := range groups {
+			a.printf("グループ\t%s\t%s\n", g.ID(), g.Name())
+		}
+
+		if err := r.users.Merge(ctx, from, into); err != nil {
+			return err
+		}
+
+		credits, err := r.credits.FindByUserID(ctx, into.ID())
+		if err != nil {
+			return err
+		}
+		for _, c := range credits {
+			a.printf("統合後の貸し借り\t%s\t%d\n", c.UserID(), c.Amount())
+		}
+
+		a.printf("%sを%sに統合しました\n", from.ID(), into.ID())
+		return nil
+	})
+}
+
+// exportUser ユーザーのデータをAPIの GET /users/me/export と同じ形式のZIPアーカイブで出力する
+func exportUser(ctx context.Context, a *admin, args []string) error {
+	fs := flag.NewFlagSet("users export", flag.ContinueOnError)
+	userID := fs.String("user", "", "出力するユーザーID")
+	output := fs.String("o", "", "出力先のファイル (省略した場合は標準出力)")
+	dryRun := fs.Bool("dry-run", false, "出力せずに件数のみを表示する")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *userID == "" {
+		return errors.New("-userは必須です")
+	}
+
+	r := a.repositories()
+	uu := usecase.NewUserUseCase(r.users, r.groups, r.lendings, r.repayments, r.credits, r.contacts)
+	export, err := uu.ExportMe(ctx, handler.UserExportMeInput{UID: *userID})
+	if err != nil {
+		return err
+	}
+
+	if *dryRun {
+		a.printf("ユーザー: %s (%s, %s)\n", export.User.ID(), export.User.Name(), export.User.Email())
+		a.printf("グループ: %d件\n立て替え: %d件\n返済: %d件\n貸し借り: %d件\n", len(export.Groups), len(export.Lendings), len(export.Repayments), len(export.Credits))
+		a.printf("ドライランのため出力していません\n")
+		return nil
+	}
+
+	var buf bytes.Buffer
+	if err := handler.WriteUserExportArchive(&buf, export); err != nil {
+		return err
+	}
+
+	if *output == "" {
+		_, err = a.out.Write(buf.Bytes())
+		return err
+	}
+	// 個人情報を含むため所有者のみ読み書きできるようにする
+	return os.WriteFile(*output, buf.Bytes(), 0o600)
+}
@@ -4,31 +4,42 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"log"
+	"log/slog"
+	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
-	"github.com/aws/aws-sdk-go-v2/config"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/service/cognitoidentityprovider"
-	"github.com/haebeal/datti/internal/gateway/postgres"
-	"github.com/haebeal/datti/internal/gateway/repository"
+	"github.com/haebeal/datti/internal/config"
+	"github.com/haebeal/datti/internal/gateway/broker"
+	"github.com/haebeal/datti/internal/gateway/cache"
+	"github.com/haebeal/datti/internal/logger"
 	"github.com/haebeal/datti/internal/presentation/api"
 	"github.com/haebeal/datti/internal/presentation/api/handler"
 	"github.com/haebeal/datti/internal/presentation/api/middleware"
 	"github.com/haebeal/datti/internal/presentation/api/server"
 	"github.com/haebeal/datti/internal/usecase"
-	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/labstack/echo/v4"
+	echomiddleware "github.com/labstack/echo/v4/middleware"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 
 	"go.opentelemetry.io/contrib/instrumentation/github.com/labstack/echo/otelecho"
 	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
 	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	otelprometheus "go.opentelemetry.io/otel/exporters/prometheus"
 	"go.opentelemetry.io/otel/propagation"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
 	"go.opentelemetry.io/otel/sdk/resource"
 	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
 )
 
-func setupOpenTelemetry(ctx context.Context) (shutdown func(context.Context) error, err error) {
+func setupOpenTelemetry(ctx context.Context, cfg config.TelemetryConfig) (shutdown func(context.Context) error, metricsHandler http.Handler, err error) {
 	var shutdownFuncs []func(context.Context) error
 
 	shutdown = func(ctx context.Context) error {
@@ -40,12 +51,6 @@ func setupOpenTelemetry(ctx context.Context) (shutdown func(context.Context) err
 		return err
 	}
 
-	texporter, err := otlptracehttp.New(ctx)
-	if err != nil {
-		err = errors.Join(err, shutdown(ctx))
-		return
-	}
-
 	r, err := resource.Merge(
 		resource.Default(),
 		resource.NewWithAttributes(
@@ -57,91 +62,254 @@ func setupOpenTelemetry(ctx context.Context) (shutdown func(context.Context) err
 		return
 	}
 
-	tp := sdktrace.NewTracerProvider(
-		sdktrace.WithBatcher(texporter),
-		sdktrace.WithResource(r),
-	)
+	if cfg.TracesExporter == config.TracesExporterOTLP {
+		texporter, terr := otlptracehttp.New(ctx)
+		if terr != nil {
+			err = errors.Join(terr, shutdown(ctx))
+			return
+		}
 
-	otel.SetTracerProvider(tp)
-	shutdownFuncs = append(shutdownFuncs, tp.Shutdown)
+		tp := sdktrace.NewTracerProvider(
+			sdktrace.WithBatcher(texporter),
+			sdktrace.WithResource(r),
+		)
+
+		otel.SetTracerProvider(tp)
+		shutdownFuncs = append(shutdownFuncs, tp.Shutdown)
+	}
 
 	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
 		propagation.TraceContext{}, propagation.Baggage{}),
 	)
 
-	return shutdown, nil
+	mpOpts := []sdkmetric.Option{sdkmetric.WithResource(r)}
+	for _, name := range cfg.MetricsExporters {
+		switch name {
+		case config.MetricsExporterPrometheus:
+			registry := prometheus.NewRegistry()
+			pexporter, perr := otelprometheus.New(otelprometheus.WithRegisterer(registry))
+			if perr != nil {
+				err = errors.Join(perr, shutdown(ctx))
+				return
+			}
+			mpOpts = append(mpOpts, sdkmetric.WithReader(pexporter))
+			metricsHandler = promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
+		case config.MetricsExporterOTLP:
+			mexporter, merr := otlpmetrichttp.New(ctx)
+			if merr != nil {
+				err = errors.Join(merr, shutdown(ctx))
+				return
+			}
+			mpOpts = append(mpOpts, sdkmetric.WithReader(sdkmetric.NewPeriodicReader(mexporter)))
+		}
+	}
+
+	mp := sdkmetric.NewMeterProvider(mpOpts...)
+	otel.SetMeterProvider(mp)
+	shutdownFuncs = append(shutdownFuncs, mp.Shutdown)
+
+	return shutdown, metricsHandler, nil
 }
 
-func main() {
-	ctx := context.Background()
+// groupUpdateBufferSize 購読者ごとに保持する未送信のグループの変更の数
+const groupUpdateBufferSize = 32
 
-	port, ok := os.LookupEnv("PORT")
-	if !ok {
-		log.Fatal("環境変数PORTが設定してありません")
-		os.Exit(1)
+func main() {
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := runMigrate(os.Args[2:]); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
 	}
 
-	dsn, ok := os.LookupEnv("POSTGRES_DSN")
-	if !ok {
-		log.Fatal("環境変数POSTGRES_DSNが設定してありません")
+	// SIGTERM (ECSのタスク停止) とSIGINTで終了処理を開始する
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	cfg, err := config.Load()
+	if err != nil {
+		slog.Error("設定が正しくありません", slog.Any("error", err))
 		os.Exit(1)
 	}
 
-	shutdown, err := setupOpenTelemetry(ctx)
+	appLogger := logger.New(os.Stdout, cfg.SlogLevel())
+	slog.SetDefault(appLogger)
+
+	shutdown, metricsHandler, err := setupOpenTelemetry(ctx, cfg.Telemetry)
 	if err != nil {
-		log.Fatal("OpenTelemetryのセットアップでエラーが発生しました")
+		slog.Error("OpenTelemetryのセットアップでエラーが発生しました", slog.Any("error", err))
 		os.Exit(1)
 	}
 
-	pool, err := pgxpool.New(ctx, dsn)
+	st, err := openStorage(ctx, cfg)
 	if err != nil {
-		log.Fatal(err)
+		slog.Error("データの保存先の準備に失敗しました", slog.Any("error", err))
+		os.Exit(1)
 	}
-	defer pool.Close()
 
-	queries := postgres.New(pool)
+	gub := broker.NewMemoryBroker(groupUpdateBufferSize)
 
-	ur := repository.NewUserRepository(queries)
-	lr := repository.NewLendingRepository(queries)
-	cr := repository.NewCreditRepository(queries)
-	rr := repository.NewRepaymentRepository(queries)
-	gr := repository.NewGroupRepository(queries)
+	// 立て替えなどの認可で毎回行うメンバーの確認をキャッシュする
+	gr := cache.NewGroupRepository(st.gr, cfg.Cache.MembershipTTL)
 
-	lu := usecase.NewLendingUseCase(ur, gr, lr)
-	cu := usecase.NewCreditUseCase(cr)
-	ru := usecase.NewRepaymentUseCase(rr, cr)
-	gu := usecase.NewGroupUseCase(ur, gr)
-	uu := usecase.NewUserUseCase(ur)
-	au := usecase.NewAuthUseCase(ur)
+	if err := usecase.RegisterCreditMetrics(st.cr); err != nil {
+		slog.Error("メトリクスの登録に失敗しました", slog.Any("error", err))
+		os.Exit(1)
+	}
+
+	lu := usecase.NewLendingUseCase(st.ur, gr, st.lr, st.ctr, st.cmr, gub)
+	cmu := usecase.NewCommentUseCase(st.ur, gr, st.lr, st.cmr)
+	cu := usecase.NewCreditUseCase(st.cr)
+	ru := usecase.NewRepaymentUseCase(st.rr, st.cr, gr, gub)
+	gu := usecase.NewGroupUseCase(st.ur, gr, gub)
+	uu := usecase.NewUserUseCase(st.ur, gr, st.lr, st.rr, st.cr, st.ctr)
+	gsu := usecase.NewGuestUseCase(st.ur, gr, st.gsr, gub)
+	ctu := usecase.NewContactUseCase(st.ur, st.ctr)
+	au := usecase.NewAuthUseCase(st.ur, st.gsr, gub)
+
+	awsCfg, err := awsconfig.LoadDefaultConfig(ctx, awsconfig.WithRegion(cfg.Auth.AWSRegion))
+	if err != nil {
+		slog.Error("AWSへの認証に失敗しました", slog.Any("error", err))
+		os.Exit(1)
+	}
+	cognitoClient := cognitoidentityprovider.NewFromConfig(awsCfg)
 
-	hh := handler.NewHealthHandler()
+	checkers := st.checkers
+	checkers["auth"] = middleware.CognitoReadinessChecker{Client: cognitoClient}
+	hh := handler.NewHealthHandler(checkers)
 	lh := handler.NewLendingHandler(lu)
+	cmh := handler.NewCommentHandler(cmu)
 	ch := handler.NewCreditHandler(cu)
 	rh := handler.NewRepaymentHandler(ru)
 	gh := handler.NewGroupHandler(gu)
+	gsh := handler.NewGuestHandler(gsu)
 	uh := handler.NewUserHandler(uu)
+	cth := handler.NewContactHandler(ctu)
 	ah := handler.NewAuthHandler(au)
-	server := server.NewServer(lh, ch, hh, rh, gh, uh, ah)
+	server := server.NewServer(lh, cmh, ch, hh, rh, gh, gsh, uh, cth, ah)
 
 	e := echo.New()
+	e.HTTPErrorHandler = handler.ErrorHandler
+
+	// X-Forwarded-Forは信頼するプロキシを経由した場合のみ使用する
+	ipExtractor, err := middleware.NewIPExtractor(cfg.HTTP.TrustedProxies)
+	if err != nil {
+		slog.Error("信頼するプロキシの設定が正しくありません", slog.Any("error", err))
+		os.Exit(1)
+	}
+	e.IPExtractor = ipExtractor
+
+	e.HideBanner = true
+	e.HidePort = true
+	e.Server.ReadTimeout = cfg.HTTP.ReadTimeout
+	e.Server.WriteTimeout = cfg.HTTP.WriteTimeout
+	e.Server.IdleTimeout = cfg.HTTP.IdleTimeout
 
 	e.Use(otelecho.Middleware("github.com/haebeal/datti"))
+	e.Use(middleware.RequestLoggerMiddleware(middleware.RequestLoggerMiddlewareConfig{
+		Logger: appLogger,
+	}))
 
-	awsCfg, err := config.LoadDefaultConfig(ctx, config.WithRegion("ap-northeast-1"))
-	if err != nil {
-		log.Fatal("AWSへの認証に失敗しました")
+	if len(cfg.HTTP.CORSAllowOrigins) > 0 {
+		e.Use(echomiddleware.CORSWithConfig(echomiddleware.CORSConfig{
+			AllowOrigins:  cfg.HTTP.CORSAllowOrigins,
+			AllowHeaders:  []string{echo.HeaderAuthorization, echo.HeaderContentType, "If-Match", middleware.IdempotencyKeyHeader},
+			ExposeHeaders: []string{"ETag", echo.HeaderRetryAfter, middleware.IdempotentReplayedHeader},
+		}))
 	}
-	cognitoClient := cognitoidentityprovider.NewFromConfig(awsCfg)
+
+	// 認証前のルートとサインアップ・ログインはIPアドレス単位で制限する
+	rateLimitStore := middleware.NewMemoryRateLimitStore()
+	e.Use(middleware.RateLimitMiddleware(middleware.RateLimitMiddlewareConfig{
+		Store:   rateLimitStore,
+		Rate:    cfg.RateLimit.IPRate,
+		Burst:   cfg.RateLimit.IPBurst,
+		KeyFunc: middleware.RateLimitKeyByIPForPaths([]string{"/health", "/ready", "/auth/"}),
+	}))
 
 	e.Use(middleware.AuthMiddleware(middleware.AuthMiddlewareConfig{
-		SkipPaths:     []string{"/health"},
+		SkipPaths:     []string{"/health", "/ready"},
 		CognitoClient: cognitoClient,
 	}))
 
+	// 認証済みのリクエストはユーザー単位で制限する
+	e.Use(middleware.RateLimitMiddleware(middleware.RateLimitMiddlewareConfig{
+		Store:   rateLimitStore,
+		Rate:    cfg.RateLimit.UserRate,
+		Burst:   cfg.RateLimit.UserBurst,
+		KeyFunc: middleware.RateLimitKeyByUserID,
+	}))
+
+	e.Use(middleware.RequestCacheMiddleware(middleware.RequestCacheMiddlewareConfig{
+		WithCache: cache.WithRequestScope,
+	}))
+
+	e.Use(middleware.IdempotencyMiddleware(middleware.IdempotencyMiddlewareConfig{
+		Repository: st.ir,
+		TTL:        cfg.Idempotency.TTL,
+	}))
+
 	api.RegisterHandlers(e, server)
 
-	if err = errors.Join(e.Start(fmt.Sprintf(":%s", port)), shutdown(ctx)); err != nil {
-		e.Logger.Fatal(err)
+	// /metricsは認証なしで公開するため、APIとは別の内部向けのポートで提供する
+	var metricsServer *http.Server
+	metricsErr := make(chan error, 1)
+	if metricsHandler != nil {
+		mux := http.NewServeMux()
+		mux.Handle("GET /metrics", metricsHandler)
+		metricsServer = &http.Server{
+			Addr:        fmt.Sprintf(":%s", cfg.Telemetry.MetricsPort),
+			Handler:     mux,
+			ReadTimeout: cfg.HTTP.ReadTimeout,
+		}
+		go func() {
+			slog.Info("メトリクスを公開します", slog.String("port", cfg.Telemetry.MetricsPort))
+			if err := metricsServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				metricsErr <- err
+			}
+		}()
+	}
+
+	serverErr := make(chan error, 1)
+	go func() {
+		slog.Info("サーバーを起動します", slog.String("port", cfg.Port))
+		if err := e.Start(fmt.Sprintf(":%s", cfg.Port)); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			serverErr <- err
+		}
+		close(serverErr)
+	}()
+
+	select {
+	case <-ctx.Done():
+	case err = <-serverErr:
+	case err = <-metricsErr:
+	}
+	stop()
+
+	slog.Info("終了処理を開始します")
+	hh.Drain()
+
+	// ロードバランサーが振り分け対象から外すまで新規リクエストを受け付ける
+	time.Sleep(cfg.HTTP.ShutdownDelay)
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.HTTP.ShutdownTimeout)
+	defer cancel()
+
+	// 配信中のストリームを終了させないとShutdownが完了しない
+	gub.Close()
+
+	// 処理中のリクエストの完了を待ってから、データの保存先とテレメトリーを閉じる
+	err = errors.Join(err, e.Shutdown(shutdownCtx))
+	if metricsServer != nil {
+		err = errors.Join(err, metricsServer.Shutdown(shutdownCtx))
+	}
+	st.close()
+	err = errors.Join(err, shutdown(shutdownCtx))
+
+	if err != nil {
+		slog.Error("サーバーが停止しました", slog.Any("error", err))
 		os.Exit(1)
 	}
+	slog.Info("サーバーを停止しました")
 }
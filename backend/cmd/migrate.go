@@ -0,0 +1,92 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+
+	"github.com/haebeal/datti/internal/config"
+	"github.com/haebeal/datti/internal/gateway/migration"
+	"github.com/haebeal/datti/internal/gateway/repository"
+	"github.com/haebeal/datti/internal/logger"
+	"github.com/haebeal/datti/sql/migrations"
+)
+
+const migrateUsage = `使い方: datti migrate <コマンド>
+
+コマンド:
+  up            未適用のマイグレーションを全て適用する
+  down [N|all]  適用済みのマイグレーションを新しい順にN件 (既定は1件) 取り消す
+  status        マイグレーションの適用状況を表示する`
+
+// runMigrate migrateサブコマンドを実行する
+func runMigrate(args []string) error {
+	if len(args) == 0 {
+		return errors.New(migrateUsage)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	cfg, err := config.LoadDatabase()
+	if err != nil {
+		return fmt.Errorf("設定が正しくありません: %w", err)
+	}
+
+	slog.SetDefault(logger.New(os.Stderr, slog.LevelInfo))
+
+	pool, err := repository.NewPool(ctx, *cfg)
+	if err != nil {
+		return err
+	}
+	defer pool.Close()
+
+	m, err := migration.NewMigrator(pool, migrations.FS)
+	if err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "up":
+		applied, err := m.Up(ctx)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("%d件のマイグレーションを適用しました\n", len(applied))
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			if args[1] == "all" {
+				steps = 0
+			} else if steps, err = strconv.Atoi(args[1]); err != nil || steps < 1 {
+				return fmt.Errorf("取り消す件数は1以上の整数またはallで指定してください: %s", args[1])
+			}
+		}
+		reverted, err := m.Down(ctx, steps)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("%d件のマイグレーションを取り消しました\n", len(reverted))
+	case "status":
+		statuses, err := m.Status(ctx)
+		if err != nil {
+			return err
+		}
+		for _, s := range statuses {
+			appliedAt := "未適用"
+			if s.AppliedAt != nil {
+				appliedAt = s.AppliedAt.Format("2006-01-02 15:04:05 MST")
+			}
+			fmt.Printf("%04d_%s\t%s\n", s.Version, s.Name, appliedAt)
+		}
+	default:
+		return errors.New(migrateUsage)
+	}
+
+	return nil
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/haebeal/datti/internal/config"
+	"github.com/haebeal/datti/internal/domain"
+	"github.com/haebeal/datti/internal/gateway/memory"
+	"github.com/haebeal/datti/internal/gateway/migration"
+	"github.com/haebeal/datti/internal/gateway/postgres"
+	"github.com/haebeal/datti/internal/gateway/repository"
+	"github.com/haebeal/datti/internal/presentation/api/handler"
+	"github.com/haebeal/datti/sql/migrations"
+)
+
+// storage データの保存先ごとのリポジトリの実装
+type storage struct {
+	ur  domain.UserRepository
+	lr  domain.LendingRepository
+	cr  domain.CreditRepository
+	rr  domain.RepaymentRepository
+	gr  domain.GroupRepository
+	gsr domain.GuestRepository
+	ctr domain.ContactRepository
+	cmr domain.CommentRepository
+	ir  domain.IdempotencyKeyRepository
+
+	// checkers 保存先の疎通確認 (/readyで使用する)
+	checkers map[string]handler.ReadinessChecker
+	// close 処理中のリクエストが完了した後に保存先との接続を閉じる
+	close func()
+}
+
+// openStorage 設定に従ってデータの保存先を準備する
+func openStorage(ctx context.Context, cfg *config.Config) (*storage, error) {
+	if cfg.Storage == config.StorageMemory {
+		slog.Warn("データをメモリに保存します。サーバーを停止するとデータは失われます")
+
+		s := memory.NewStore()
+		return &storage{
+			ur:       memory.NewUserRepository(s),
+			lr:       memory.NewLendingRepository(s),
+			cr:       memory.NewCreditRepository(s),
+			rr:       memory.NewRepaymentRepository(s),
+			gr:       memory.NewGroupRepository(s),
+			gsr:      memory.NewGuestRepository(s),
+			ctr:      memory.NewContactRepository(s),
+			cmr:      memory.NewCommentRepository(s),
+			ir:       memory.NewIdempotencyKeyRepository(s),
+			checkers: map[string]handler.ReadinessChecker{},
+			close:    func() {},
+		}, nil
+	}
+
+	pool, err := repository.NewPool(ctx, cfg.Database)
+	if err != nil {
+		return nil, fmt.Errorf("データベースへの接続に失敗しました: %w", err)
+	}
+
+	if cfg.Database.MigrateOnStart {
+		m, err := migration.NewMigrator(pool, migrations.FS)
+		if err == nil {
+			_, err = m.Up(ctx)
+		}
+		if err != nil {
+			pool.Close()
+			return nil, fmt.Errorf("マイグレーションの適用に失敗しました: %w", err)
+		}
+	}
+
+	if err := repository.RegisterPoolMetrics(pool); err != nil {
+		pool.Close()
+		return nil, fmt.Errorf("メトリクスの登録に失敗しました: %w", err)
+	}
+
+	queries := postgres.New(pool)
+
+	return &storage{
+		ur:       repository.NewUserRepository(pool),
+		lr:       repository.NewLendingRepository(queries),
+		cr:       repository.NewCreditRepository(queries),
+		rr:       repository.NewRepaymentRepository(queries),
+		gr:       repository.NewGroupRepository(queries),
+		gsr:      repository.NewGuestRepository(pool),
+		ctr:      repository.NewContactRepository(queries),
+		cmr:      repository.NewCommentRepository(queries),
+		ir:       repository.NewIdempotencyKeyRepository(queries),
+		checkers: map[string]handler.ReadinessChecker{"postgres": pool},
+		close:    pool.Close,
+	}, nil
+}
@@ -0,0 +1,477 @@
+// Package config アプリケーション設定のパッケージ
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/haebeal/datti/internal/logger"
+	"gopkg.in/yaml.v3"
+)
+
+// データの保存先
+const (
+	StoragePostgres = "postgres"
+	// StorageMemory プロセスのメモリに保存する (デモやフロントエンドの開発用で、再起動するとデータは失われる)
+	StorageMemory = "memory"
+)
+
+// 認証プロバイダー
+const (
+	AuthProviderCognito = "cognito"
+)
+
+// メトリクスのエクスポーター
+const (
+	MetricsExporterPrometheus = "prometheus"
+	MetricsExporterOTLP       = "otlp"
+	MetricsExporterNone       = "none"
+)
+
+// トレースのエクスポーター
+const (
+	TracesExporterOTLP = "otlp"
+	TracesExporterNone = "none"
+)
+
+// Config アプリケーション設定
+// 既定値、設定ファイル、環境変数の順に読み込み、後に読み込んだ値で上書きする
+type Config struct {
+	Port        string            `yaml:"port"`
+	LogLevel    string            `yaml:"logLevel"`
+	Storage     string            `yaml:"storage"`
+	Database    DatabaseConfig    `yaml:"database"`
+	Auth        AuthConfig        `yaml:"auth"`
+	Telemetry   TelemetryConfig   `yaml:"telemetry"`
+	HTTP        HTTPConfig        `yaml:"http"`
+	RateLimit   RateLimitConfig   `yaml:"rateLimit"`
+	Idempotency IdempotencyConfig `yaml:"idempotency"`
+	Cache       CacheConfig       `yaml:"cache"`
+}
+
+// DatabaseConfig データベースの接続設定
+type DatabaseConfig struct {
+	DSN             string        `yaml:"dsn"`
+	MaxConns        int32         `yaml:"maxConns"`
+	MinConns        int32         `yaml:"minConns"`
+	MaxConnLifetime time.Duration `yaml:"maxConnLifetime"`
+	MaxConnIdleTime time.Duration `yaml:"maxConnIdleTime"`
+	// MigrateOnStart サーバーの起動時に未適用のマイグレーションを適用するか
+	MigrateOnStart bool `yaml:"migrateOnStart"`
+}
+
+// AuthConfig 認証プロバイダーの設定
+type AuthConfig struct {
+	Provider  string `yaml:"provider"`
+	AWSRegion string `yaml:"awsRegion"`
+}
+
+// TelemetryConfig OpenTelemetryのエクスポーターの設定
+// エクスポート先のエンドポイントはOTEL_EXPORTER_OTLP_*環境変数で指定する
+type TelemetryConfig struct {
+	TracesExporter   string   `yaml:"tracesExporter"`
+	MetricsExporters []string `yaml:"metricsExporters"`
+	// MetricsPort Prometheusの/metricsを公開するポート (認証がないため、APIとは別の外部に公開しないポートを使用する)
+	MetricsPort string `yaml:"metricsPort"`
+}
+
+// HTTPConfig HTTPサーバーの設定
+type HTTPConfig struct {
+	CORSAllowOrigins []string      `yaml:"corsAllowOrigins"`
+	ReadTimeout      time.Duration `yaml:"readTimeout"`
+	WriteTimeout     time.Duration `yaml:"writeTimeout"`
+	IdleTimeout      time.Duration `yaml:"idleTimeout"`
+	// TrustedProxies X-Forwarded-Forを信頼するプロキシ (CIDR形式、未設定の場合は接続元のIPアドレスを使用する)
+	TrustedProxies []string `yaml:"trustedProxies"`
+	// ShutdownDelay SIGTERM受信後も新規リクエストを受け付ける時間
+	ShutdownDelay time.Duration `yaml:"shutdownDelay"`
+	// ShutdownTimeout 処理中のリクエストの完了を待つ時間
+	ShutdownTimeout time.Duration `yaml:"shutdownTimeout"`
+}
+
+// RateLimitConfig レート制限の設定 (1秒あたりのリクエスト数とバースト数)
+// IP単位の制限は認証前のルートとサインアップ・ログインにのみ適用する
+type RateLimitConfig struct {
+	IPRate    float64 `yaml:"ipRate"`
+	IPBurst   int     `yaml:"ipBurst"`
+	UserRate  float64 `yaml:"userRate"`
+	UserBurst int     `yaml:"userBurst"`
+}
+
+// IdempotencyConfig 冪等性キーの設定
+type IdempotencyConfig struct {
+	TTL time.Duration `yaml:"ttl"`
+}
+
+// CacheConfig キャッシュの設定
+type CacheConfig struct {
+	// MembershipTTL グループのメンバーであることを確認した結果をリクエストをまたいで保持する時間 (0の場合はリクエスト内のみ)
+	MembershipTTL time.Duration `yaml:"membershipTTL"`
+}
+
+// Default 既定値の設定
+func Default() *Config {
+	return &Config{
+		LogLevel: "info",
+		Storage:  StoragePostgres,
+		Database: DatabaseConfig{
+			MaxConns:        10,
+			MinConns:        0,
+			MaxConnLifetime: time.Hour,
+			MaxConnIdleTime: 30 * time.Minute,
+		},
+		Auth: AuthConfig{
+			Provider: AuthProviderCognito,
+		},
+		Telemetry: TelemetryConfig{
+			TracesExporter:   TracesExporterOTLP,
+			MetricsExporters: []string{MetricsExporterPrometheus},
+			MetricsPort:      "9090",
+		},
+		HTTP: HTTPConfig{
+			ReadTimeout:     10 * time.Second,
+			WriteTimeout:    30 * time.Second,
+			IdleTimeout:     120 * time.Second,
+			ShutdownDelay:   0,
+			ShutdownTimeout: 25 * time.Second,
+		},
+		RateLimit: RateLimitConfig{
+			IPRate:    5,
+			IPBurst:   20,
+			UserRate:  10,
+			UserBurst: 30,
+		},
+		Idempotency: IdempotencyConfig{
+			TTL: 24 * time.Hour,
+		},
+		Cache: CacheConfig{
+			MembershipTTL: 5 * time.Second,
+		},
+	}
+}
+
+// Load 設定を読み込んで検証する
+// CONFIG_FILE環境変数でYAML形式の設定ファイルを指定できる
+func Load() (*Config, error) {
+	cfg, err := load()
+	if err != nil {
+		return nil, err
+	}
+
+	// 環境変数の形式の誤りと設定値の誤りをまとめて報告する
+	if err := errors.Join(cfg.loadEnv(os.LookupEnv), cfg.Validate()); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+// LoadDatabase データベースの接続設定のみを読み込んで検証する
+// マイグレーションなどサーバーを起動しないコマンドで使用する
+func LoadDatabase() (*DatabaseConfig, error) {
+	cfg, err := load()
+	if err != nil {
+		return nil, err
+	}
+
+	// 環境変数を反映してから検証する
+	envErr := cfg.loadEnv(os.LookupEnv)
+
+	var v validator
+	cfg.Database.validate(&v)
+	if err := errors.Join(envErr, v.err()); err != nil {
+		return nil, err
+	}
+
+	return &cfg.Database, nil
+}
+
+// load 既定値に設定ファイルの値を反映する
+func load() (*Config, error) {
+	cfg := Default()
+
+	if path, ok := os.LookupEnv("CONFIG_FILE"); ok && path != "" {
+		if err := cfg.loadFile(path); err != nil {
+			return nil, err
+		}
+	}
+
+	return cfg, nil
+}
+
+// loadFile 設定ファイルを読み込む (未知の項目はエラー)
+func (c *Config) loadFile(path string) error {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("設定ファイル%sを読み込めません: %w", path, err)
+	}
+
+	dec := yaml.NewDecoder(bytes.NewReader(b))
+	dec.KnownFields(true)
+	if err := dec.Decode(c); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("設定ファイル%sの形式が正しくありません: %w", path, err)
+	}
+
+	return nil
+}
+
+// loadEnv 環境変数を読み込む
+// 形式が正しくない環境変数は全てまとめてエラーにする
+func (c *Config) loadEnv(lookup func(string) (string, bool)) error {
+	var errs []error
+
+	str := func(key string, dst *string) {
+		if v, ok := lookup(key); ok {
+			*dst = v
+		}
+	}
+	list := func(key string, dst *[]string) {
+		if v, ok := lookup(key); ok {
+			*dst = splitList(v)
+		}
+	}
+	integer := func(key string, dst *int) {
+		if v, ok := lookup(key); ok {
+			n, err := strconv.Atoi(v)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: 整数で指定してください (%q)", key, v))
+				return
+			}
+			*dst = n
+		}
+	}
+	int32v := func(key string, dst *int32) {
+		if v, ok := lookup(key); ok {
+			n, err := strconv.ParseInt(v, 10, 32)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: 整数で指定してください (%q)", key, v))
+				return
+			}
+			*dst = int32(n)
+		}
+	}
+	boolean := func(key string, dst *bool) {
+		if v, ok := lookup(key); ok {
+			b, err := strconv.ParseBool(v)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: trueまたはfalseで指定してください (%q)", key, v))
+				return
+			}
+			*dst = b
+		}
+	}
+	float := func(key string, dst *float64) {
+		if v, ok := lookup(key); ok {
+			f, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: 数値で指定してください (%q)", key, v))
+				return
+			}
+			*dst = f
+		}
+	}
+	duration := func(key string, dst *time.Duration) {
+		if v, ok := lookup(key); ok {
+			d, err := time.ParseDuration(v)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: 10s, 5mのような時間の形式で指定してください (%q)", key, v))
+				return
+			}
+			*dst = d
+		}
+	}
+
+	str("PORT", &c.Port)
+	str("LOG_LEVEL", &c.LogLevel)
+	str("STORAGE", &c.Storage)
+
+	str("POSTGRES_DSN", &c.Database.DSN)
+	int32v("DB_MAX_CONNS", &c.Database.MaxConns)
+	int32v("DB_MIN_CONNS", &c.Database.MinConns)
+	duration("DB_MAX_CONN_LIFETIME", &c.Database.MaxConnLifetime)
+	duration("DB_MAX_CONN_IDLE_TIME", &c.Database.MaxConnIdleTime)
+	boolean("DB_MIGRATE_ON_START", &c.Database.MigrateOnStart)
+
+	str("AUTH_PROVIDER", &c.Auth.Provider)
+	str("AWS_REGION", &c.Auth.AWSRegion)
+
+	str("OTEL_TRACES_EXPORTER", &c.Telemetry.TracesExporter)
+	list("OTEL_METRICS_EXPORTER", &c.Telemetry.MetricsExporters)
+	str("METRICS_PORT", &c.Telemetry.MetricsPort)
+
+	list("CORS_ALLOW_ORIGINS", &c.HTTP.CORSAllowOrigins)
+	list("TRUSTED_PROXIES", &c.HTTP.TrustedProxies)
+	duration("HTTP_READ_TIMEOUT", &c.HTTP.ReadTimeout)
+	duration("HTTP_WRITE_TIMEOUT", &c.HTTP.WriteTimeout)
+	duration("HTTP_IDLE_TIMEOUT", &c.HTTP.IdleTimeout)
+	duration("SHUTDOWN_DELAY", &c.HTTP.ShutdownDelay)
+	duration("SHUTDOWN_TIMEOUT", &c.HTTP.ShutdownTimeout)
+
+	float("RATE_LIMIT_IP_RPS", &c.RateLimit.IPRate)
+	integer("RATE_LIMIT_IP_BURST", &c.RateLimit.IPBurst)
+	float("RATE_LIMIT_USER_RPS", &c.RateLimit.UserRate)
+	integer("RATE_LIMIT_USER_BURST", &c.RateLimit.UserBurst)
+
+	duration("IDEMPOTENCY_KEY_TTL", &c.Idempotency.TTL)
+
+	duration("MEMBERSHIP_CACHE_TTL", &c.Cache.MembershipTTL)
+
+	return errors.Join(errs...)
+}
+
+// Validate 設定値を検証する
+// 全ての問題をまとめてエラーにする
+func (c *Config) Validate() error {
+	var v validator
+	invalid := v.invalid
+
+	if c.Port == "" {
+		invalid("PORT", "必須です")
+	} else if port, err := strconv.Atoi(c.Port); err != nil || port < 1 || port > 65535 {
+		invalid("PORT", "1から65535のポート番号を指定してください (%q)", c.Port)
+	}
+
+	if _, err := logger.ParseLevel(c.LogLevel); err != nil {
+		invalid("LOG_LEVEL", "debug, info, warn, errorのいずれかを指定してください (%q)", c.LogLevel)
+	}
+
+	switch c.Storage {
+	case StoragePostgres:
+		c.Database.validate(&v)
+	case StorageMemory:
+	default:
+		invalid("STORAGE", "postgres, memoryのいずれかを指定してください (%q)", c.Storage)
+	}
+
+	switch c.Auth.Provider {
+	case AuthProviderCognito:
+		if c.Auth.AWSRegion == "" {
+			invalid("AWS_REGION", "認証プロバイダーがcognitoの場合は必須です")
+		}
+	default:
+		invalid("AUTH_PROVIDER", "cognitoを指定してください (%q)", c.Auth.Provider)
+	}
+
+	if !slices.Contains([]string{TracesExporterOTLP, TracesExporterNone}, c.Telemetry.TracesExporter) {
+		invalid("OTEL_TRACES_EXPORTER", "otlp, noneのいずれかを指定してください (%q)", c.Telemetry.TracesExporter)
+	}
+	for _, e := range c.Telemetry.MetricsExporters {
+		if !slices.Contains([]string{MetricsExporterPrometheus, MetricsExporterOTLP, MetricsExporterNone}, e) {
+			invalid("OTEL_METRICS_EXPORTER", "prometheus, otlp, noneのいずれかを指定してください (%q)", e)
+		}
+	}
+	if slices.Contains(c.Telemetry.MetricsExporters, MetricsExporterPrometheus) {
+		if port, err := strconv.Atoi(c.Telemetry.MetricsPort); err != nil || port < 1 || port > 65535 {
+			invalid("METRICS_PORT", "1から65535のポート番号を指定してください (%q)", c.Telemetry.MetricsPort)
+		} else if c.Telemetry.MetricsPort == c.Port {
+			invalid("METRICS_PORT", "PORTとは別のポートを指定してください (%q)", c.Telemetry.MetricsPort)
+		}
+	}
+
+	for _, origin := range c.HTTP.CORSAllowOrigins {
+		if origin != "*" && !strings.HasPrefix(origin, "http://") && !strings.HasPrefix(origin, "https://") {
+			invalid("CORS_ALLOW_ORIGINS", "http://またはhttps://から始まるオリジンを指定してください (%q)", origin)
+		}
+	}
+	for _, proxy := range c.HTTP.TrustedProxies {
+		if _, _, err := net.ParseCIDR(proxy); err != nil {
+			invalid("TRUSTED_PROXIES", "CIDR形式で指定してください (%q)", proxy)
+		}
+	}
+	if c.HTTP.ReadTimeout < 0 {
+		invalid("HTTP_READ_TIMEOUT", "0以上の時間を指定してください (%s)", c.HTTP.ReadTimeout)
+	}
+	if c.HTTP.WriteTimeout < 0 {
+		invalid("HTTP_WRITE_TIMEOUT", "0以上の時間を指定してください (%s)", c.HTTP.WriteTimeout)
+	}
+	if c.HTTP.IdleTimeout < 0 {
+		invalid("HTTP_IDLE_TIMEOUT", "0以上の時間を指定してください (%s)", c.HTTP.IdleTimeout)
+	}
+	if c.HTTP.ShutdownDelay < 0 {
+		invalid("SHUTDOWN_DELAY", "0以上の時間を指定してください (%s)", c.HTTP.ShutdownDelay)
+	}
+	if c.HTTP.ShutdownTimeout <= 0 {
+		invalid("SHUTDOWN_TIMEOUT", "正の時間を指定してください (%s)", c.HTTP.ShutdownTimeout)
+	}
+
+	if c.RateLimit.IPRate <= 0 {
+		invalid("RATE_LIMIT_IP_RPS", "正の数を指定してください (%g)", c.RateLimit.IPRate)
+	}
+	if c.RateLimit.IPBurst < 1 {
+		invalid("RATE_LIMIT_IP_BURST", "1以上を指定してください (%d)", c.RateLimit.IPBurst)
+	}
+	if c.RateLimit.UserRate <= 0 {
+		invalid("RATE_LIMIT_USER_RPS", "正の数を指定してください (%g)", c.RateLimit.UserRate)
+	}
+	if c.RateLimit.UserBurst < 1 {
+		invalid("RATE_LIMIT_USER_BURST", "1以上を指定してください (%d)", c.RateLimit.UserBurst)
+	}
+
+	if c.Idempotency.TTL <= 0 {
+		invalid("IDEMPOTENCY_KEY_TTL", "正の時間を指定してください (%s)", c.Idempotency.TTL)
+	}
+
+	if c.Cache.MembershipTTL < 0 {
+		invalid("MEMBERSHIP_CACHE_TTL", "0以上の時間を指定してください (%s)", c.Cache.MembershipTTL)
+	}
+
+	return v.err()
+}
+
+// validate データベースの接続設定を検証する
+func (c DatabaseConfig) validate(v *validator) {
+	if c.DSN == "" {
+		v.invalid("POSTGRES_DSN", "必須です")
+	}
+	if c.MaxConns < 1 {
+		v.invalid("DB_MAX_CONNS", "1以上を指定してください (%d)", c.MaxConns)
+	}
+	if c.MinConns < 0 || c.MinConns > c.MaxConns {
+		v.invalid("DB_MIN_CONNS", "0以上DB_MAX_CONNS以下を指定してください (%d)", c.MinConns)
+	}
+	if c.MaxConnLifetime <= 0 {
+		v.invalid("DB_MAX_CONN_LIFETIME", "正の時間を指定してください (%s)", c.MaxConnLifetime)
+	}
+	if c.MaxConnIdleTime <= 0 {
+		v.invalid("DB_MAX_CONN_IDLE_TIME", "正の時間を指定してください (%s)", c.MaxConnIdleTime)
+	}
+}
+
+// validator 検証で見つかった問題を集める
+type validator struct {
+	errs []error
+}
+
+func (v *validator) invalid(key string, format string, args ...any) {
+	v.errs = append(v.errs, fmt.Errorf("%s: %s", key, fmt.Sprintf(format, args...)))
+}
+
+func (v *validator) err() error {
+	return errors.Join(v.errs...)
+}
+
+// SlogLevel ログレベル
+func (c *Config) SlogLevel() slog.Level {
+	level, _ := logger.ParseLevel(c.LogLevel)
+	return level
+}
+
+// splitList カンマ区切りの文字列を分割する (空の要素は除く)
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
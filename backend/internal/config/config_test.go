@@ -0,0 +1,29 @@
+package config_test
+
+import (
+	"testing"
+
+	"github.com/haebeal/datti/internal/config"
+)
+
+func TestLoadDatabase(t *testing.T) {
+	t.Setenv("CONFIG_FILE", "")
+	t.Setenv("POSTGRES_DSN", "postgres://datti@localhost:5432/datti")
+
+	cfg, err := config.LoadDatabase()
+	if err != nil {
+		t.Fatalf("LoadDatabase() error = %v", err)
+	}
+	if cfg.DSN != "postgres://datti@localhost:5432/datti" {
+		t.Errorf("DSN = %q, want the value of POSTGRES_DSN", cfg.DSN)
+	}
+}
+
+func TestLoadDatabaseRequiresDSN(t *testing.T) {
+	t.Setenv("CONFIG_FILE", "")
+	t.Setenv("POSTGRES_DSN", "")
+
+	if _, err := config.LoadDatabase(); err == nil {
+		t.Error("LoadDatabase() error = nil, want an error for an empty POSTGRES_DSN")
+	}
+}
@@ -0,0 +1,122 @@
+package domain
+
+import (
+	"context"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/oklog/ulid/v2"
+	"go.opentelemetry.io/otel/codes"
+)
+
+// commentBodyMaxLength コメント本文の最大文字数
+const commentBodyMaxLength = 1000
+
+// Comment 立て替えに対するコメントエンティティ
+type Comment struct {
+	id        ulid.ULID
+	lendingID ulid.ULID
+	author    *User
+	body      string
+	createdAt time.Time
+	updatedAt time.Time
+}
+
+// NewComment Commentエンティティのファクトリ関数 (リポジトリからの復元用)
+func NewComment(ctx context.Context, id ulid.ULID, lendingID ulid.ULID, author *User, body string, createdAt time.Time, updatedAt time.Time) (c *Comment, err error) {
+	_, span := tracer.Start(ctx, "domain.Comment.New")
+	defer func() {
+		if err != nil {
+			span.SetStatus(codes.Error, err.Error())
+			span.RecordError(err)
+		}
+		span.End()
+	}()
+
+	if author == nil {
+		return nil, NewValidationError("author", "投稿者は必須です")
+	}
+
+	if strings.TrimSpace(body) == "" {
+		return nil, NewValidationError("body", "コメントは1文字以上である必要があります")
+	}
+
+	if utf8.RuneCountInString(body) > commentBodyMaxLength {
+		return nil, NewValidationError("body", "コメントは1000文字以下である必要があります")
+	}
+
+	if createdAt.After(updatedAt) {
+		return nil, NewValidationError("updatedAt", "更新日は作成日より後である必要があります")
+	}
+
+	return &Comment{
+		id:        id,
+		lendingID: lendingID,
+		author:    author,
+		body:      body,
+		createdAt: createdAt,
+		updatedAt: updatedAt,
+	}, nil
+}
+
+// CreateComment 新規Commentを作成するファクトリ関数
+func CreateComment(ctx context.Context, lendingID ulid.ULID, author *User, body string) (*Comment, error) {
+	id := ulid.Make()
+	now := time.Now()
+
+	return NewComment(ctx, id, lendingID, author, body, now, now)
+}
+
+// Edit コメント本文を編集する
+func (c *Comment) Edit(ctx context.Context, body string) (*Comment, error) {
+	now := time.Now()
+
+	return NewComment(ctx, c.id, c.lendingID, c.author, body, c.createdAt, now)
+}
+
+// ID コメントID
+func (c *Comment) ID() ulid.ULID {
+	return c.id
+}
+
+// LendingID コメント先の立て替えID
+func (c *Comment) LendingID() ulid.ULID {
+	return c.lendingID
+}
+
+// Author 投稿者
+func (c *Comment) Author() *User {
+	return c.author
+}
+
+// Body 本文
+func (c *Comment) Body() string {
+	return c.body
+}
+
+// CreatedAt 作成日時
+func (c *Comment) CreatedAt() time.Time {
+	return c.createdAt
+}
+
+// UpdatedAt 更新日時
+func (c *Comment) UpdatedAt() time.Time {
+	return c.updatedAt
+}
+
+// CommentRepository コメントリポジトリのインターフェース
+type CommentRepository interface {
+	// Create コメントを作成する
+	Create(ctx context.Context, c *Comment) error
+	// FindByID IDでコメントを取得する
+	FindByID(ctx context.Context, id ulid.ULID) (*Comment, error)
+	// FindByLendingID 立て替えのコメント一覧を古い順に取得する
+	FindByLendingID(ctx context.Context, lendingID ulid.ULID, cursor *string, limit int32) ([]*Comment, error)
+	// CountByLendingIDs 立て替えごとのコメント数を取得する (コメントがない立て替えは含まない)
+	CountByLendingIDs(ctx context.Context, lendingIDs []ulid.ULID) (map[ulid.ULID]int32, error)
+	// Update コメントを更新する
+	Update(ctx context.Context, c *Comment) error
+	// Delete コメントを削除する
+	Delete(ctx context.Context, id ulid.ULID) error
+}
@@ -0,0 +1,47 @@
+package domain
+
+import "context"
+
+// Contact ユーザーの連絡先
+// 明示的に追加したユーザーに加えて、同じグループのメンバーも連絡先として扱う
+type Contact struct {
+	user  *User
+	added bool
+}
+
+// NewContact Contactのファクトリ関数
+func NewContact(user *User, added bool) (*Contact, error) {
+	if user == nil {
+		return nil, NewValidationError("user", "ユーザーは必須です")
+	}
+
+	if user.IsGuest() {
+		return nil, NewValidationError("user", "ゲストは連絡先に追加できません")
+	}
+
+	return &Contact{
+		user:  user,
+		added: added,
+	}, nil
+}
+
+// User 連絡先のユーザー
+func (c *Contact) User() *User {
+	return c.user
+}
+
+// Added 明示的に追加した連絡先かどうか
+// falseの場合は同じグループのメンバーであるため連絡先になっている
+func (c *Contact) Added() bool {
+	return c.added
+}
+
+// ContactRepository 連絡先リポジトリのインターフェース
+type ContactRepository interface {
+	// Add ユーザーの連絡先にcontactを追加する
+	Add(ctx context.Context, userID string, contact *User) error
+	// Remove 明示的に追加した連絡先を削除する
+	Remove(ctx context.Context, userID string, contactID string) error
+	// FindByUserID ユーザーの連絡先一覧を取得する
+	FindByUserID(ctx context.Context, userID string) ([]*Contact, error)
+}
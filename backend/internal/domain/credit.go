@@ -8,13 +8,15 @@ import (
 
 // Credit ユーザー間の債権/債務を表すエンティティ
 // Amount が正なら貸している、負なら借りている
+// Overdue はそのうち返済期限を過ぎた金額で、Amount と同じ符号になる
 type Credit struct {
-	userID string
-	amount int64
+	userID  string
+	amount  int64
+	overdue int64
 }
 
 // NewCredit Creditエンティティのファクトリ関数
-func NewCredit(ctx context.Context, userID string, amount int64) (c *Credit, err error) {
+func NewCredit(ctx context.Context, userID string, amount int64, overdue int64) (c *Credit, err error) {
 	_, span := tracer.Start(ctx, "domain.Credit.NewCredit")
 	defer func() {
 		if err != nil {
@@ -28,12 +30,41 @@ func NewCredit(ctx context.Context, userID string, amount int64) (c *Credit, err
 		return nil, NewValidationError("userID", "ユーザーIDは必須です")
 	}
 
+	if (amount >= 0 && (overdue < 0 || overdue > amount)) || (amount < 0 && (overdue > 0 || overdue < amount)) {
+		return nil, NewValidationError("overdue", "延滞額は残高を超えない同じ符号の金額である必要があります")
+	}
+
 	return &Credit{
-		userID: userID,
-		amount: amount,
+		userID:  userID,
+		amount:  amount,
+		overdue: overdue,
 	}, nil
 }
 
+// DatedDebts 返済期限のある立て替えによる相手ユーザーとの貸し/借りの合計
+type DatedDebts struct {
+	Lent     int64
+	Borrowed int64
+	// LentNotDue, BorrowedNotDue そのうち返済期限前のもの
+	LentNotDue     int64
+	BorrowedNotDue int64
+}
+
+// OverdueAmount 残高のうち返済期限を過ぎた金額を求める
+// 返済や相殺は返済期限のない債務から充当し、その後は返済期限の早い債務から充当するものとする
+// そのため残高のうち返済期限のある債務の合計を超えない分から、返済期限前の債務を差し引いた分を延滞とする
+// (返済期限のない立て替えを集計せずに求められる)
+func OverdueAmount(amount int64, d DatedDebts) int64 {
+	switch {
+	case amount > 0:
+		return max(min(amount, d.Lent)-d.LentNotDue, 0)
+	case amount < 0:
+		return min(max(amount, -d.Borrowed)+d.BorrowedNotDue, 0)
+	default:
+		return 0
+	}
+}
+
 // UserID 相手ユーザーID
 func (c *Credit) UserID() string {
 	return c.userID
@@ -44,6 +75,16 @@ func (c *Credit) Amount() int64 {
 	return c.amount
 }
 
+// Overdue 返済期限を過ぎた金額(正=相手が延滞している、負=自分が延滞している)
+func (c *Credit) Overdue() int64 {
+	return c.overdue
+}
+
+// IsOverdue 返済期限を過ぎた金額があるかどうか
+func (c *Credit) IsOverdue() bool {
+	return c.overdue != 0
+}
+
 // IsLending 貸しているかどうか
 func (c *Credit) IsLending() bool {
 	return c.amount > 0
@@ -81,4 +122,6 @@ type CreditRepository interface {
 	FindByUserID(ctx context.Context, userID string) ([]*Credit, error)
 	// FindByUserIDAndOtherUserID 特定のユーザーとの債権/債務を取得
 	FindByUserIDAndOtherUserID(ctx context.Context, userID string, otherUserID string) (*Credit, error)
+	// TotalOutstanding 全ユーザー間の未精算の金額の合計を取得
+	TotalOutstanding(ctx context.Context) (int64, error)
 }
@@ -11,6 +11,11 @@ type Debtor struct {
 }
 
 func NewDebtor(id string, name string, avatar string, email string, amount int64) (*Debtor, error) {
+	// 債務額が0の債務者は支払いとして記録されず復元できないため指定できない
+	if amount < 1 {
+		return nil, NewValidationError("debts", "債務額は1以上である必要があります")
+	}
+
 	return &Debtor{
 		id:     id,
 		name:   name,
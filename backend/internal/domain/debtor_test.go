@@ -0,0 +1,32 @@
+package domain_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/haebeal/datti/internal/domain"
+)
+
+func TestNewDebtor(t *testing.T) {
+	tests := []struct {
+		name    string
+		amount  int64
+		wantErr bool
+	}{
+		{"債務額が1以上", 1, false},
+		{"債務額が0", 0, true},
+		{"債務額が負", -100, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := domain.NewDebtor("bob", "Bob", "", "bob@example.com", tt.amount)
+			if tt.wantErr != errors.Is(err, &domain.ValidationError{}) {
+				t.Errorf("NewDebtor(%d) error = %v, wantErr %t", tt.amount, err, tt.wantErr)
+			}
+			if !tt.wantErr && err != nil {
+				t.Errorf("NewDebtor(%d) error = %v", tt.amount, err)
+			}
+		})
+	}
+}
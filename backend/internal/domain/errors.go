@@ -20,6 +20,16 @@ func (e *ValidationError) Error() string {
 	return fmt.Sprintf("%s: %s", e.field, e.message)
 }
 
+// Field 不正な値が指定された項目名を返す
+func (e *ValidationError) Field() string {
+	return e.field
+}
+
+// Message エラーの内容を返す
+func (e *ValidationError) Message() string {
+	return e.message
+}
+
 // Is errors.Isで型判定できるようにする
 func (e *ValidationError) Is(target error) bool {
 	_, ok := target.(*ValidationError)
@@ -80,10 +90,12 @@ func (e *ForbiddenError) Is(target error) bool {
 	return ok
 }
 
-// ConflictError 競合エラー(リソースが既に存在する場合)
+// ConflictError 競合エラー(リソースが既に存在する場合、または更新が競合した場合)
 type ConflictError struct {
 	resource string
 	message  string
+	// stale 更新対象のバージョンが古いことによる競合か
+	stale bool
 }
 
 // NewConflictError ConflictErrorのファクトリ関数
@@ -98,11 +110,32 @@ func (e *ConflictError) Error() string {
 	return fmt.Sprintf("%s: %s", e.resource, e.message)
 }
 
+// NewStaleVersionError 更新対象のバージョンが古い場合のConflictErrorを生成する
+func NewStaleVersionError(resource string) *ConflictError {
+	err := NewConflictError(resource, "他のユーザーによって更新されています。最新の情報を取得してやり直してください")
+	err.stale = true
+	return err
+}
+
+// VerifyVersion 指定されたバージョンが現在のバージョンと一致することを確認する
+// expectedがnilの場合は確認しない
+func VerifyVersion(resource string, current int32, expected *int32) error {
+	if expected != nil && *expected != current {
+		return NewStaleVersionError(resource)
+	}
+	return nil
+}
+
 // Resource 競合したリソース名を返す
 func (e *ConflictError) Resource() string {
 	return e.resource
 }
 
+// IsStaleVersion 更新対象のバージョンが古いことによる競合かを返す
+func (e *ConflictError) IsStaleVersion() bool {
+	return e.stale
+}
+
 // Is errors.Isで型判定できるようにする
 func (e *ConflictError) Is(target error) bool {
 	_, ok := target.(*ConflictError)
@@ -2,6 +2,7 @@ package domain
 
 import (
 	"context"
+	"fmt"
 	"time"
 	"unicode/utf8"
 
@@ -11,15 +12,21 @@ import (
 
 // Group グループを表すドメインエンティティ
 type Group struct {
-	id        ulid.ULID
-	name      string
-	createdBy string
-	createdAt time.Time
-	updatedAt time.Time
+	id               ulid.ULID
+	name             string
+	createdBy        string
+	paymentTermsDays *int32
+	createdAt        time.Time
+	updatedAt        time.Time
+	version          int32
 }
 
+// maxPaymentTermsDays 既定の支払い期限として指定できる最大の日数
+const maxPaymentTermsDays = 365
+
 // NewGroup グループドメインエンティティのファクトリ関数
-func NewGroup(ctx context.Context, id ulid.ULID, name string, createdBy string, createdAt time.Time, updatedAt time.Time) (g *Group, err error) {
+// paymentTermsDaysがnilの場合は既定の支払い期限を設けない
+func NewGroup(ctx context.Context, id ulid.ULID, name string, createdBy string, paymentTermsDays *int32, createdAt time.Time, updatedAt time.Time, version int32) (g *Group, err error) {
 	_, span := tracer.Start(ctx, "domain.Group.New")
 	defer func() {
 		if err != nil {
@@ -37,21 +44,27 @@ func NewGroup(ctx context.Context, id ulid.ULID, name string, createdBy string,
 		return nil, NewValidationError("createdBy", "作成者IDは必須です")
 	}
 
+	if paymentTermsDays != nil && (*paymentTermsDays < 0 || *paymentTermsDays > maxPaymentTermsDays) {
+		return nil, NewValidationError("paymentTermsDays", fmt.Sprintf("支払い期限は0日以上%d日以下である必要があります", maxPaymentTermsDays))
+	}
+
 	if createdAt.After(updatedAt) {
 		return nil, NewValidationError("createdAt", "作成日は更新日より前である必要があります")
 	}
 
 	return &Group{
-		id:        id,
-		name:      name,
-		createdBy: createdBy,
-		createdAt: createdAt,
-		updatedAt: updatedAt,
+		id:               id,
+		name:             name,
+		createdBy:        createdBy,
+		paymentTermsDays: paymentTermsDays,
+		createdAt:        createdAt,
+		updatedAt:        updatedAt,
+		version:          version,
 	}, nil
 }
 
 // CreateGroup グループの作成を行うファクトリ関数
-func CreateGroup(ctx context.Context, name string, createdBy string) (g *Group, err error) {
+func CreateGroup(ctx context.Context, name string, createdBy string, paymentTermsDays *int32) (g *Group, err error) {
 	ctx, span := tracer.Start(ctx, "domain.Group.Create")
 	defer func() {
 		if err != nil {
@@ -64,17 +77,43 @@ func CreateGroup(ctx context.Context, name string, createdBy string) (g *Group,
 	id := ulid.Make()
 	now := time.Now()
 
-	return NewGroup(ctx, id, name, createdBy, now, now)
+	return NewGroup(ctx, id, name, createdBy, paymentTermsDays, now, now, 1)
 }
 
 // Update グループの更新を行う
-func (g *Group) Update(ctx context.Context, name string) (*Group, error) {
+// paymentTermsDaysがnilの場合は既定の支払い期限を変更せず、clearPaymentTermsDaysがtrueの場合は期限をなくす
+func (g *Group) Update(ctx context.Context, name string, paymentTermsDays *int32, clearPaymentTermsDays bool) (*Group, error) {
 	ctx, span := tracer.Start(ctx, "domain.Group.Update")
 	defer span.End()
 
+	terms := g.paymentTermsDays
+	switch {
+	case clearPaymentTermsDays && paymentTermsDays != nil:
+		return nil, NewValidationError("paymentTermsDays", "支払い期限とclearPaymentTermsDaysは同時に指定できません")
+	case clearPaymentTermsDays:
+		terms = nil
+	case paymentTermsDays != nil:
+		terms = paymentTermsDays
+	}
+
 	now := time.Now()
 
-	return NewGroup(ctx, g.id, name, g.createdBy, g.createdAt, now)
+	return NewGroup(ctx, g.id, name, g.createdBy, terms, g.createdAt, now, g.version+1)
+}
+
+// TransferOwnership グループの作成者を変更する
+// 新しい作成者がグループのメンバーであることは呼び出し側で確認する
+func (g *Group) TransferOwnership(ctx context.Context, userID string) (*Group, error) {
+	ctx, span := tracer.Start(ctx, "domain.Group.TransferOwnership")
+	defer span.End()
+
+	if userID == g.createdBy {
+		return nil, NewValidationError("createdBy", "既にグループの作成者です")
+	}
+
+	now := time.Now()
+
+	return NewGroup(ctx, g.id, g.name, userID, g.paymentTermsDays, g.createdAt, now, g.version+1)
 }
 
 // ID グループID (ULID形式)
@@ -91,6 +130,22 @@ func (g *Group) CreatedBy() string {
 	return g.createdBy
 }
 
+// PaymentTermsDays 立て替えの既定の支払い期限 (立て替えの日付からの日数、設けない場合はnil)
+func (g *Group) PaymentTermsDays() *int32 {
+	return g.paymentTermsDays
+}
+
+// DefaultDueDate 返済期限を指定しない立て替えに適用する返済期限を求める
+// 既定の支払い期限を設けていない場合はnilを返す
+func (g *Group) DefaultDueDate(eventDate time.Time) *time.Time {
+	if g.paymentTermsDays == nil {
+		return nil
+	}
+
+	dueDate := eventDate.AddDate(0, 0, int(*g.paymentTermsDays))
+	return &dueDate
+}
+
 // CreatedAt 作成日時
 func (g *Group) CreatedAt() time.Time {
 	return g.createdAt
@@ -101,6 +156,11 @@ func (g *Group) UpdatedAt() time.Time {
 	return g.updatedAt
 }
 
+// Version 楽観的排他制御のためのバージョン (更新のたびに1ずつ増える)
+func (g *Group) Version() int32 {
+	return g.version
+}
+
 // GroupRepository グループリポジトリのインターフェース
 type GroupRepository interface {
 	// Create グループを作成する
@@ -117,6 +177,11 @@ type GroupRepository interface {
 	AddMember(ctx context.Context, g *Group, u *User) error
 	// FindMembersByID グループのメンバー一覧を取得する
 	FindMembersByID(ctx context.Context, id ulid.ULID) ([]*User, error)
+	// IsMember ユーザーがグループのメンバーであるかを確認する
+	IsMember(ctx context.Context, id ulid.ULID, userID string) (bool, error)
+	// FindMemberIDs userIDsのうちグループのメンバーであるユーザーのIDを取得する
+	FindMemberIDs(ctx context.Context, id ulid.ULID, userIDs []string) ([]string, error)
 	// RemoveMember グループからメンバーを削除する
+	// メンバーが関与する立て替えの支払いは残し、脱退後も貸し借りを保つ
 	RemoveMember(ctx context.Context, g *Group, u *User) error
 }
@@ -0,0 +1,53 @@
+package domain_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/haebeal/datti/internal/domain"
+)
+
+func TestGroupUpdatePaymentTermsDays(t *testing.T) {
+	thirty := int32(30)
+	sixty := int32(60)
+
+	tests := []struct {
+		name    string
+		days    *int32
+		clear   bool
+		want    *int32
+		wantErr bool
+	}{
+		{"省略した場合は変更しない", nil, false, &thirty, false},
+		{"指定した場合は変更する", &sixty, false, &sixty, false},
+		{"clearPaymentTermsDaysで期限をなくす", nil, true, nil, false},
+		{"同時に指定できない", &sixty, true, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			g, err := domain.CreateGroup(ctx, "group", "alice", &thirty)
+			if err != nil {
+				t.Fatalf("CreateGroup() error = %v", err)
+			}
+
+			got, err := g.Update(ctx, "renamed", tt.days, tt.clear)
+			if tt.wantErr {
+				if !errors.Is(err, &domain.ValidationError{}) {
+					t.Errorf("Update() error = %v, want ValidationError", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Update() error = %v", err)
+			}
+
+			terms := got.PaymentTermsDays()
+			if (terms == nil) != (tt.want == nil) || (terms != nil && *terms != *tt.want) {
+				t.Errorf("PaymentTermsDays() = %v, want %v", terms, tt.want)
+			}
+		})
+	}
+}
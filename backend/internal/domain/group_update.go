@@ -0,0 +1,91 @@
+package domain
+
+import (
+	"context"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+)
+
+// GroupUpdateType グループで発生した変更の種類
+type GroupUpdateType string
+
+const (
+	GroupUpdateLendingCreated   GroupUpdateType = "lending.created"
+	GroupUpdateLendingUpdated   GroupUpdateType = "lending.updated"
+	GroupUpdateLendingDeleted   GroupUpdateType = "lending.deleted"
+	GroupUpdateRepaymentCreated GroupUpdateType = "repayment.created"
+	GroupUpdateRepaymentUpdated GroupUpdateType = "repayment.updated"
+	GroupUpdateRepaymentDeleted GroupUpdateType = "repayment.deleted"
+	GroupUpdateMemberAdded      GroupUpdateType = "member.added"
+	GroupUpdateMemberRemoved    GroupUpdateType = "member.removed"
+)
+
+// GroupUpdate グループのメンバーに通知する変更
+// 変更後の内容は含めず、受け取ったクライアントが必要に応じて再取得する
+type GroupUpdate struct {
+	id         ulid.ULID
+	groupID    ulid.ULID
+	updateType GroupUpdateType
+	resourceID string
+	occurredAt time.Time
+}
+
+// NewGroupUpdate GroupUpdateのファクトリ関数 (ブローカーからの復元用)
+func NewGroupUpdate(id ulid.ULID, groupID ulid.ULID, updateType GroupUpdateType, resourceID string, occurredAt time.Time) (*GroupUpdate, error) {
+	if updateType == "" {
+		return nil, NewValidationError("type", "変更の種類は必須です")
+	}
+
+	if resourceID == "" {
+		return nil, NewValidationError("resourceID", "変更されたリソースのIDは必須です")
+	}
+
+	return &GroupUpdate{
+		id:         id,
+		groupID:    groupID,
+		updateType: updateType,
+		resourceID: resourceID,
+		occurredAt: occurredAt,
+	}, nil
+}
+
+// CreateGroupUpdate 新規GroupUpdateを作成するファクトリ関数
+func CreateGroupUpdate(groupID ulid.ULID, updateType GroupUpdateType, resourceID string) (*GroupUpdate, error) {
+	return NewGroupUpdate(ulid.Make(), groupID, updateType, resourceID, time.Now())
+}
+
+// ID 変更ID (SSEのイベントIDとして使用する)
+func (u *GroupUpdate) ID() ulid.ULID {
+	return u.id
+}
+
+// GroupID 変更が発生したグループのID
+func (u *GroupUpdate) GroupID() ulid.ULID {
+	return u.groupID
+}
+
+// Type 変更の種類
+func (u *GroupUpdate) Type() GroupUpdateType {
+	return u.updateType
+}
+
+// ResourceID 変更された立て替え・返済・メンバーのID
+func (u *GroupUpdate) ResourceID() string {
+	return u.resourceID
+}
+
+// OccurredAt 変更日時
+func (u *GroupUpdate) OccurredAt() time.Time {
+	return u.occurredAt
+}
+
+// GroupUpdateBroker グループの変更を購読者に配信するブローカーのインターフェース
+// 複数のタスク間で配信する場合はPostgresのLISTEN/NOTIFYなどで実装する
+type GroupUpdateBroker interface {
+	// Publish 変更をグループの購読者に配信する
+	Publish(ctx context.Context, u *GroupUpdate) error
+	// Subscribe グループの変更を購読する
+	// 返されたチャネルはcancelの呼び出し、配信の遅延、ブローカーの停止のいずれかで閉じられる
+	Subscribe(ctx context.Context, groupID ulid.ULID) (updates <-chan *GroupUpdate, cancel func(), err error)
+}
@@ -0,0 +1,119 @@
+package domain
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"strings"
+
+	"github.com/oklog/ulid/v2"
+	"go.opentelemetry.io/otel/codes"
+)
+
+// guestIDPrefix ゲストに採番するIDの接頭辞
+const guestIDPrefix = "guest-"
+
+// Guest アカウントを持たずにグループに参加するゲストメンバー
+// 立て替えではUserとして支払い者や債務者になり、
+// 登録ユーザーがメールアドレスまたは招待トークンで引き継ぐとそのユーザーに統合される
+type Guest struct {
+	user        *User
+	groupID     ulid.ULID
+	inviteToken string
+}
+
+// NewGuest Guestエンティティのファクトリ関数 (リポジトリからの復元用)
+func NewGuest(ctx context.Context, user *User, groupID ulid.ULID, inviteToken string) (g *Guest, err error) {
+	_, span := tracer.Start(ctx, "domain.Guest.New")
+	defer func() {
+		if err != nil {
+			span.SetStatus(codes.Error, err.Error())
+			span.RecordError(err)
+		}
+		span.End()
+	}()
+
+	if user == nil || !user.IsGuest() {
+		return nil, NewValidationError("user", "ゲストのユーザーである必要があります")
+	}
+
+	if inviteToken == "" {
+		return nil, NewValidationError("inviteToken", "招待トークンは必須です")
+	}
+
+	return &Guest{
+		user:        user,
+		groupID:     groupID,
+		inviteToken: inviteToken,
+	}, nil
+}
+
+// CreateGuest グループにゲストを作成するファクトリ関数
+func CreateGuest(ctx context.Context, groupID ulid.ULID, name string, email string) (g *Guest, err error) {
+	ctx, span := tracer.Start(ctx, "domain.Guest.Create")
+	defer func() {
+		if err != nil {
+			span.SetStatus(codes.Error, err.Error())
+			span.RecordError(err)
+		}
+		span.End()
+	}()
+
+	id := guestIDPrefix + strings.ToLower(ulid.Make().String())
+	user, err := NewGuestUser(ctx, id, name, email)
+	if err != nil {
+		return nil, err
+	}
+
+	// 招待トークンは推測されないように十分な長さの乱数とする
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return nil, err
+	}
+
+	return NewGuest(ctx, user, groupID, hex.EncodeToString(b))
+}
+
+// User 立て替えで使用するゲストのユーザー
+func (g *Guest) User() *User {
+	return g.user
+}
+
+// ID ゲストのユーザーID
+func (g *Guest) ID() string {
+	return g.user.ID()
+}
+
+// Name ゲストの表示名
+func (g *Guest) Name() string {
+	return g.user.Name()
+}
+
+// Email ゲストのメールアドレス (未設定の場合は空文字)
+func (g *Guest) Email() string {
+	return g.user.Email()
+}
+
+// GroupID ゲストが所属するグループのID
+func (g *Guest) GroupID() ulid.ULID {
+	return g.groupID
+}
+
+// InviteToken 登録ユーザーがゲストを引き継ぐための招待トークン
+func (g *Guest) InviteToken() string {
+	return g.inviteToken
+}
+
+// GuestRepository ゲストリポジトリのインターフェース
+type GuestRepository interface {
+	// Create ゲストを作成し、グループのメンバーに追加する
+	Create(ctx context.Context, g *Guest) error
+	// FindByGroupID グループのゲスト一覧を取得する
+	FindByGroupID(ctx context.Context, groupID ulid.ULID) ([]*Guest, error)
+	// FindByInviteToken 招待トークンでゲストを取得する
+	FindByInviteToken(ctx context.Context, token string) (*Guest, error)
+	// FindByEmail メールアドレスが一致するゲスト一覧を取得する
+	FindByEmail(ctx context.Context, email string) ([]*Guest, error)
+	// Claim ゲストのグループ所属や支払いをuに付け替えてゲストを削除する
+	Claim(ctx context.Context, g *Guest, u *User) error
+}
@@ -0,0 +1,121 @@
+package domain
+
+import (
+	"context"
+	"time"
+	"unicode/utf8"
+)
+
+// IdempotencyKey 作成系リクエストの冪等性キー
+// 同じキーで再送されたリクエストには最初のレスポンスを返す
+type IdempotencyKey struct {
+	userID       string
+	key          string
+	method       string
+	path         string
+	requestHash  string
+	statusCode   int
+	contentType  string
+	responseBody []byte
+	createdAt    time.Time
+}
+
+// NewIdempotencyKey IdempotencyKeyのファクトリ関数 (リポジトリからの復元用)
+// statusCodeが0の場合は処理中を表す
+func NewIdempotencyKey(userID string, key string, method string, path string, requestHash string, statusCode int, contentType string, responseBody []byte, createdAt time.Time) (*IdempotencyKey, error) {
+	if userID == "" {
+		return nil, NewValidationError("userID", "ユーザーIDは必須です")
+	}
+
+	if utf8.RuneCountInString(key) < 1 || utf8.RuneCountInString(key) > 255 {
+		return nil, NewValidationError("Idempotency-Key", "Idempotency-Keyは1文字以上255文字以下である必要があります")
+	}
+
+	return &IdempotencyKey{
+		userID:       userID,
+		key:          key,
+		method:       method,
+		path:         path,
+		requestHash:  requestHash,
+		statusCode:   statusCode,
+		contentType:  contentType,
+		responseBody: responseBody,
+		createdAt:    createdAt,
+	}, nil
+}
+
+// CreateIdempotencyKey 処理中の冪等性キーを作成する
+func CreateIdempotencyKey(userID string, key string, method string, path string, requestHash string) (*IdempotencyKey, error) {
+	return NewIdempotencyKey(userID, key, method, path, requestHash, 0, "", nil, time.Now())
+}
+
+// Complete 処理結果のレスポンスを記録する
+func (k *IdempotencyKey) Complete(statusCode int, contentType string, responseBody []byte) (*IdempotencyKey, error) {
+	return NewIdempotencyKey(k.userID, k.key, k.method, k.path, k.requestHash, statusCode, contentType, responseBody, k.createdAt)
+}
+
+// Matches 同じリクエスト内容で使用されたキーかどうか
+func (k *IdempotencyKey) Matches(method string, path string, requestHash string) bool {
+	return k.method == method && k.path == path && k.requestHash == requestHash
+}
+
+// Completed レスポンスが記録済みかどうか
+func (k *IdempotencyKey) Completed() bool {
+	return k.statusCode != 0
+}
+
+// UserID キーを発行したユーザーID
+func (k *IdempotencyKey) UserID() string {
+	return k.userID
+}
+
+// Key クライアントが指定したキー
+func (k *IdempotencyKey) Key() string {
+	return k.key
+}
+
+// Method HTTPメソッド
+func (k *IdempotencyKey) Method() string {
+	return k.method
+}
+
+// Path リクエストパス
+func (k *IdempotencyKey) Path() string {
+	return k.path
+}
+
+// RequestHash リクエスト内容のハッシュ
+func (k *IdempotencyKey) RequestHash() string {
+	return k.requestHash
+}
+
+// StatusCode 記録されたレスポンスのステータスコード
+func (k *IdempotencyKey) StatusCode() int {
+	return k.statusCode
+}
+
+// ContentType 記録されたレスポンスのContent-Type
+func (k *IdempotencyKey) ContentType() string {
+	return k.contentType
+}
+
+// ResponseBody 記録されたレスポンスボディ
+func (k *IdempotencyKey) ResponseBody() []byte {
+	return k.responseBody
+}
+
+// CreatedAt 作成日時
+func (k *IdempotencyKey) CreatedAt() time.Time {
+	return k.createdAt
+}
+
+// IdempotencyKeyRepository 冪等性キーリポジトリのインターフェース
+type IdempotencyKeyRepository interface {
+	// Reserve キーを処理中として登録する
+	// 有効期限内の同じキーが既に存在する場合は登録せずにそのキーを返す
+	Reserve(ctx context.Context, k *IdempotencyKey, expiresBefore time.Time) (*IdempotencyKey, error)
+	// Complete 処理結果のレスポンスを保存する
+	Complete(ctx context.Context, k *IdempotencyKey) error
+	// Delete キーを削除する (処理が失敗して再試行を許可する場合)
+	Delete(ctx context.Context, k *IdempotencyKey) error
+}
@@ -2,6 +2,8 @@ package domain
 
 import (
 	"context"
+	"maps"
+	"slices"
 	"time"
 	"unicode/utf8"
 
@@ -10,19 +12,26 @@ import (
 )
 
 // Lending 立て替えイベント集約
+// 金額は支払い者が立て替えた金額の合計と一致し、
+// また支払い者自身の負担額と債務者の負担額の合計とも一致する
 type Lending struct {
 	id        ulid.ULID
 	name      string
 	amount    int64
 	eventDate time.Time
+	dueDate   *time.Time
 	payer     *Payer
+	payers    map[string]*Payer
 	debtors   map[string]*Debtor
 	createdAt time.Time
 	updatedAt time.Time
+	version   int32
 }
 
 // NewLending Lendingエンティティのファクトリ関数 (リポジトリからの復元用)
-func NewLending(ctx context.Context, id ulid.ULID, name string, amount int64, eventDate time.Time, payer *Payer, debtors map[string]*Debtor, createdAt time.Time, updatedAt time.Time) (l *Lending, err error) {
+// payerは立て替えの作成者で、payersに含まれている必要がある
+// dueDateがnilの場合は返済期限を設けない
+func NewLending(ctx context.Context, id ulid.ULID, name string, amount int64, eventDate time.Time, dueDate *time.Time, payer *Payer, payers map[string]*Payer, debtors map[string]*Debtor, createdAt time.Time, updatedAt time.Time, version int32) (l *Lending, err error) {
 	_, span := tracer.Start(ctx, "domain.Lending.New")
 	defer func() {
 		if err != nil {
@@ -36,14 +45,32 @@ func NewLending(ctx context.Context, id ulid.ULID, name string, amount int64, ev
 		return nil, NewValidationError("name", "イベント名は1文字以上である必要があります")
 	}
 
+	if err := validateDueDate(eventDate, dueDate); err != nil {
+		return nil, err
+	}
+
 	if payer == nil {
 		return nil, NewValidationError("payer", "支払い者は必須です")
 	}
 
+	if _, exists := payers[payer.ID()]; !exists {
+		return nil, NewValidationError("payers", "作成者は支払い者に含まれる必要があります")
+	}
+
 	if len(debtors) == 0 {
 		return nil, NewValidationError("debtors", "債務者は1人以上必要です")
 	}
 
+	for debtorID := range debtors {
+		if _, exists := payers[debtorID]; exists {
+			return nil, NewValidationError("debtor", "支払い者自身を債務者にすることはできません")
+		}
+	}
+
+	if err := validateBreakdown(amount, payers, debtors); err != nil {
+		return nil, err
+	}
+
 	if createdAt.After(updatedAt) {
 		return nil, NewValidationError("updatedAt", "更新日は作成日より後である必要があります")
 	}
@@ -53,16 +80,50 @@ func NewLending(ctx context.Context, id ulid.ULID, name string, amount int64, ev
 		name:      name,
 		amount:    amount,
 		eventDate: eventDate,
-		payer:     payer,
+		dueDate:   dueDate,
+		payer:     payers[payer.ID()],
+		payers:    payers,
 		debtors:   debtors,
 		createdAt: createdAt,
 		updatedAt: updatedAt,
+		version:   version,
 	}, nil
 }
 
+// validateDueDate 返済期限が立て替えの日付より前でないことを検証する
+func validateDueDate(eventDate time.Time, dueDate *time.Time) error {
+	if dueDate != nil && dueDate.Before(eventDate) {
+		return NewValidationError("dueDate", "返済期限は立て替えの日付以降である必要があります")
+	}
+	return nil
+}
+
+// validateBreakdown 立て替えた金額の合計、および負担額の合計が金額と一致することを検証する
+func validateBreakdown(amount int64, payers map[string]*Payer, debtors map[string]*Debtor) error {
+	var paid int64
+	var shares int64
+	for _, p := range payers {
+		paid += p.Amount()
+		shares += p.Share()
+	}
+	if paid != amount {
+		return NewValidationError("payers", "支払い額の合計が金額と一致しません")
+	}
+
+	var debts int64
+	for _, d := range debtors {
+		debts += d.Amount()
+	}
+	if shares+debts != amount {
+		return NewValidationError("amount", "支払い者の負担額と債務の合計が金額と一致しません")
+	}
+
+	return nil
+}
+
 // CreateLending 新規Lendingを作成するファクトリ関数
-// debtorsは空で作成し、AddDebtorメソッドで追加する
-func CreateLending(ctx context.Context, name string, amount int64, eventDate time.Time, payer *Payer) (*Lending, error) {
+// payerは作成者となる支払い者で、他の支払い者はAddPayer、債務者はAddDebtorメソッドで追加する
+func CreateLending(ctx context.Context, name string, amount int64, eventDate time.Time, dueDate *time.Time, payer *Payer) (*Lending, error) {
 	_, span := tracer.Start(ctx, "domain.Lending.Create")
 	defer span.End()
 
@@ -70,6 +131,10 @@ func CreateLending(ctx context.Context, name string, amount int64, eventDate tim
 		return nil, NewValidationError("name", "イベント名は1文字以上である必要があります")
 	}
 
+	if err := validateDueDate(eventDate, dueDate); err != nil {
+		return nil, err
+	}
+
 	if payer == nil {
 		return nil, NewValidationError("payer", "支払い者は必須です")
 	}
@@ -82,24 +147,59 @@ func CreateLending(ctx context.Context, name string, amount int64, eventDate tim
 		name:      name,
 		amount:    amount,
 		eventDate: eventDate,
+		dueDate:   dueDate,
 		payer:     payer,
+		payers:    map[string]*Payer{payer.ID(): payer},
 		debtors:   make(map[string]*Debtor),
 		createdAt: now,
 		updatedAt: now,
+		version:   1,
 	}, nil
 }
 
 // Update Lendingの基本情報を更新する
-func (l *Lending) Update(ctx context.Context, name string, amount int64, eventDate time.Time) (*Lending, error) {
+func (l *Lending) Update(ctx context.Context, name string, amount int64, eventDate time.Time, dueDate *time.Time) (*Lending, error) {
 	now := time.Now()
 
-	return NewLending(ctx, l.id, name, amount, eventDate, l.payer, l.debtors, l.createdAt, now)
+	return NewLending(ctx, l.id, name, amount, eventDate, dueDate, l.payer, l.payers, l.debtors, l.createdAt, now, l.version+1)
+}
+
+// ValidateBreakdown 支払い者と債務者を追加した後に金額の内訳が一致することを検証する
+func (l *Lending) ValidateBreakdown() error {
+	return validateBreakdown(l.amount, l.payers, l.debtors)
+}
+
+// AddPayer 作成者以外の支払い者を追加する
+func (l *Lending) AddPayer(payer *Payer) error {
+	// 重複チェック
+	if _, exists := l.payers[payer.ID()]; exists {
+		return NewValidationError("payer", "既に追加されている支払い者です")
+	}
+
+	if _, exists := l.debtors[payer.ID()]; exists {
+		return NewValidationError("payer", "債務者を支払い者にすることはできません")
+	}
+
+	l.payers[payer.ID()] = payer
+	return nil
+}
+
+// SetPayers 支払い者一覧を置き換える (作成者を含む必要がある)
+func (l *Lending) SetPayers(payers map[string]*Payer) error {
+	payer, exists := payers[l.payer.ID()]
+	if !exists {
+		return NewValidationError("payers", "作成者は支払い者に含まれる必要があります")
+	}
+
+	l.payer = payer
+	l.payers = payers
+	return nil
 }
 
 // AddDebtor 債務者を追加する
 func (l *Lending) AddDebtor(debtor *Debtor) error {
 	// 自分自身への立て替えはできない
-	if debtor.ID() == l.payer.ID() {
+	if _, exists := l.payers[debtor.ID()]; exists {
 		return NewValidationError("debtor", "支払い者自身を債務者にすることはできません")
 	}
 
@@ -151,6 +251,11 @@ func (l *Lending) EventDate() time.Time {
 	return l.eventDate
 }
 
+// DueDate 返済期限 (設けていない場合はnil)
+func (l *Lending) DueDate() *time.Time {
+	return l.dueDate
+}
+
 // CreatedAt 作成日時
 func (l *Lending) CreatedAt() time.Time {
 	return l.createdAt
@@ -161,16 +266,91 @@ func (l *Lending) UpdatedAt() time.Time {
 	return l.updatedAt
 }
 
-// Payer 支払い者
+// Version 楽観的排他制御のためのバージョン (更新のたびに1ずつ増える)
+func (l *Lending) Version() int32 {
+	return l.version
+}
+
+// Payer 作成者である支払い者
 func (l *Lending) Payer() *Payer {
 	return l.payer
 }
 
+// Payers 支払い者一覧 (作成者を含む)
+func (l *Lending) Payers() map[string]*Payer {
+	return l.payers
+}
+
+// PayerShare 支払い者自身の負担額の合計
+func (l *Lending) PayerShare() int64 {
+	var total int64
+	for _, p := range l.payers {
+		total += p.Share()
+	}
+	return total
+}
+
 // Debtors 債務者一覧
 func (l *Lending) Debtors() map[string]*Debtor {
 	return l.debtors
 }
 
+// DebtTotal 債務者の負担額の合計
+func (l *Lending) DebtTotal() int64 {
+	var total int64
+	for _, d := range l.debtors {
+		total += d.Amount()
+	}
+	return total
+}
+
+// LendingAllocation 支払いマトリクスの要素
+// PayerIDの支払い者がDebtorIDのユーザーの負担分のうちAmountを立て替えたことを表す
+// PayerIDとDebtorIDが同じ場合は支払い者自身の負担分
+type LendingAllocation struct {
+	PayerID  string
+	DebtorID string
+	Amount   int64
+}
+
+// Allocations 支払い額と負担額から支払いマトリクスを算出する
+// 各支払い者は自身の負担分を優先して支払い、残りをユーザーID順に割り当てる
+func (l *Lending) Allocations() []LendingAllocation {
+	payerIDs := slices.Sorted(maps.Keys(l.payers))
+
+	remainingPaid := make(map[string]int64, len(l.payers))
+	remainingOwed := make(map[string]int64, len(l.payers)+len(l.debtors))
+	allocations := make([]LendingAllocation, 0, len(l.payers)+len(l.debtors))
+
+	for _, id := range payerIDs {
+		p := l.payers[id]
+		self := min(p.Amount(), p.Share())
+		if self > 0 {
+			allocations = append(allocations, LendingAllocation{PayerID: id, DebtorID: id, Amount: self})
+		}
+		remainingPaid[id] = p.Amount() - self
+		remainingOwed[id] = p.Share() - self
+	}
+	for id, d := range l.debtors {
+		remainingOwed[id] = d.Amount()
+	}
+
+	consumerIDs := slices.Sorted(maps.Keys(remainingOwed))
+	for _, payerID := range payerIDs {
+		for _, consumerID := range consumerIDs {
+			amount := min(remainingPaid[payerID], remainingOwed[consumerID])
+			if amount <= 0 {
+				continue
+			}
+			allocations = append(allocations, LendingAllocation{PayerID: payerID, DebtorID: consumerID, Amount: amount})
+			remainingPaid[payerID] -= amount
+			remainingOwed[consumerID] -= amount
+		}
+	}
+
+	return allocations
+}
+
 // LendingPaginationParams ページネーションパラメータ
 type LendingPaginationParams struct {
 	Limit  int32
@@ -184,14 +364,27 @@ type PaginatedLendings struct {
 	HasMore    bool
 }
 
+// LendingSearchResult 立て替え検索結果
+// 個人間の立て替えの場合、Groupはnil
+type LendingSearchResult struct {
+	Group   *Group
+	Lending *Lending
+}
+
 // LendingRepository 立て替えイベントリポジトリのインターフェース
 type LendingRepository interface {
-	// Create 立て替えを作成する
+	// Create 立て替えを作成する (gがnilの場合はグループに属さない個人間の立て替えとして作成する)
 	Create(ctx context.Context, g *Group, l *Lending) error
 	// FindByID IDで立て替えを取得する
 	FindByID(ctx context.Context, id ulid.ULID) (*Lending, error)
+	// FindPersonalByID IDで個人間の立て替えを取得する (グループの立て替えの場合はNotFoundError)
+	FindPersonalByID(ctx context.Context, id ulid.ULID) (*Lending, error)
+	// FindByUserID グループと個人間の立て替えのうち、ユーザーが関与するものを新しい順に取得する
+	FindByUserID(ctx context.Context, userID string, cursor *string, limit int32) ([]*LendingSearchResult, error)
 	// FindByGroupAndUserID グループとユーザーIDで立て替え一覧を取得する
 	FindByGroupAndUserID(ctx context.Context, g *Group, userID string, cursor *string, limit *int32) ([]*Lending, error)
+	// Search 指定グループ内でユーザーが関与する立て替えを名前で検索する (関連度順)
+	Search(ctx context.Context, groups []*Group, userID string, query string, limit int32) ([]*LendingSearchResult, error)
 	// Update 立て替えを更新する
 	Update(ctx context.Context, l *Lending) error
 	// Delete 立て替えを削除する
@@ -0,0 +1,109 @@
+package domain_test
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/haebeal/datti/internal/domain"
+)
+
+func TestLendingAllocations(t *testing.T) {
+	type payer struct {
+		id     string
+		amount int64
+		share  int64
+	}
+	tests := []struct {
+		name   string
+		amount int64
+		payers []payer
+		debts  map[string]int64
+		want   []domain.LendingAllocation
+	}{
+		{
+			name:   "支払い者の負担分は自身への支払いとする",
+			amount: 3000,
+			payers: []payer{{"alice", 3000, 1000}},
+			debts:  map[string]int64{"bob": 1000, "carol": 1000},
+			want: []domain.LendingAllocation{
+				{PayerID: "alice", DebtorID: "alice", Amount: 1000},
+				{PayerID: "alice", DebtorID: "bob", Amount: 1000},
+				{PayerID: "alice", DebtorID: "carol", Amount: 1000},
+			},
+		},
+		{
+			name:   "支払い者の負担額が0の場合は自身への支払いを作らない",
+			amount: 1000,
+			payers: []payer{{"alice", 1000, 0}},
+			debts:  map[string]int64{"bob": 1000},
+			want: []domain.LendingAllocation{
+				{PayerID: "alice", DebtorID: "bob", Amount: 1000},
+			},
+		},
+		{
+			name:   "複数の支払い者はユーザーID順に債務を割り当てる",
+			amount: 3000,
+			payers: []payer{{"alice", 2000, 500}, {"bob", 1000, 500}},
+			debts:  map[string]int64{"carol": 2000},
+			want: []domain.LendingAllocation{
+				{PayerID: "alice", DebtorID: "alice", Amount: 500},
+				{PayerID: "bob", DebtorID: "bob", Amount: 500},
+				{PayerID: "alice", DebtorID: "carol", Amount: 1500},
+				{PayerID: "bob", DebtorID: "carol", Amount: 500},
+			},
+		},
+		{
+			name:   "負担額より少なく支払った支払い者は他の支払い者に債務を負う",
+			amount: 2000,
+			payers: []payer{{"alice", 500, 1000}, {"bob", 1500, 0}},
+			debts:  map[string]int64{"carol": 1000},
+			want: []domain.LendingAllocation{
+				{PayerID: "alice", DebtorID: "alice", Amount: 500},
+				{PayerID: "bob", DebtorID: "alice", Amount: 500},
+				{PayerID: "bob", DebtorID: "carol", Amount: 1000},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+
+			var l *domain.Lending
+			for _, p := range tt.payers {
+				payer, err := domain.NewPayer(p.id, p.id, "", p.id+"@example.com", p.amount, p.share)
+				if err != nil {
+					t.Fatalf("NewPayer() error = %v", err)
+				}
+				if l == nil {
+					l, err = domain.CreateLending(ctx, "lending", tt.amount, time.Now(), nil, payer)
+					if err != nil {
+						t.Fatalf("CreateLending() error = %v", err)
+					}
+					continue
+				}
+				if err := l.AddPayer(payer); err != nil {
+					t.Fatalf("AddPayer() error = %v", err)
+				}
+			}
+			for id, amount := range tt.debts {
+				debtor, err := domain.NewDebtor(id, id, "", id+"@example.com", amount)
+				if err != nil {
+					t.Fatalf("NewDebtor() error = %v", err)
+				}
+				if err := l.AddDebtor(debtor); err != nil {
+					t.Fatalf("AddDebtor() error = %v", err)
+				}
+			}
+			if err := l.ValidateBreakdown(); err != nil {
+				t.Fatalf("ValidateBreakdown() error = %v", err)
+			}
+
+			if got := l.Allocations(); !slices.Equal(got, tt.want) {
+				t.Errorf("Allocations() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
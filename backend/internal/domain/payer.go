@@ -1,23 +1,46 @@
 package domain
 
-
 // 支払い者
+// amount は立て替えた金額、share は支払い者自身の負担額
 type Payer struct {
 	id     string
 	name   string
 	avatar string
 	email  string
+	amount int64
+	share  int64
 }
 
-func NewPayer(id string, name string, avatar string, email string) (*Payer, error) {
+func NewPayer(id string, name string, avatar string, email string, amount int64, share int64) (*Payer, error) {
+	if amount < 0 {
+		return nil, NewValidationError("payers", "支払い額は0以上である必要があります")
+	}
+
+	if share < 0 {
+		return nil, NewValidationError("payerShare", "支払い者の負担額は0以上である必要があります")
+	}
+
 	return &Payer{
 		id:     id,
 		name:   name,
 		avatar: avatar,
 		email:  email,
+		amount: amount,
+		share:  share,
 	}, nil
 }
 
+func (p *Payer) Update(amount int64, share int64) (*Payer, error) {
+	return NewPayer(
+		p.id,
+		p.name,
+		p.avatar,
+		p.email,
+		amount,
+		share,
+	)
+}
+
 func (p *Payer) Equal(c *Payer) bool {
 	return p.id == c.id
 }
@@ -37,3 +60,11 @@ func (p *Payer) Avatar() string {
 func (p *Payer) Email() string {
 	return p.email
 }
+
+func (p *Payer) Amount() int64 {
+	return p.amount
+}
+
+func (p *Payer) Share() int64 {
+	return p.share
+}
@@ -16,10 +16,11 @@ type Repayment struct {
 	amount    int64
 	createdAt time.Time
 	updatedAt time.Time
+	version   int32
 }
 
 // NewRepayment Repaymentエンティティのファクトリ関数 (リポジトリからの復元用)
-func NewRepayment(ctx context.Context, id ulid.ULID, payerID string, debtorID string, amount int64, createdAt time.Time, updatedAt time.Time) (r *Repayment, err error) {
+func NewRepayment(ctx context.Context, id ulid.ULID, payerID string, debtorID string, amount int64, createdAt time.Time, updatedAt time.Time, version int32) (r *Repayment, err error) {
 	_, span := tracer.Start(ctx, "domain.Repayment.New")
 	defer func() {
 		if err != nil {
@@ -56,6 +57,7 @@ func NewRepayment(ctx context.Context, id ulid.ULID, payerID string, debtorID st
 		amount:    amount,
 		createdAt: createdAt,
 		updatedAt: updatedAt,
+		version:   version,
 	}, nil
 }
 
@@ -64,14 +66,14 @@ func CreateRepayment(ctx context.Context, payerID string, debtorID string, amoun
 	id := ulid.Make()
 	now := time.Now()
 
-	return NewRepayment(ctx, id, payerID, debtorID, amount, now, now)
+	return NewRepayment(ctx, id, payerID, debtorID, amount, now, now, 1)
 }
 
 // Update 返済金額を更新する
 func (r *Repayment) Update(ctx context.Context, amount int64) (*Repayment, error) {
 	now := time.Now()
 
-	return NewRepayment(ctx, r.id, r.payerID, r.debtorID, amount, r.createdAt, now)
+	return NewRepayment(ctx, r.id, r.payerID, r.debtorID, amount, r.createdAt, now, r.version+1)
 }
 
 // ID 返済ID
@@ -104,6 +106,11 @@ func (r *Repayment) UpdatedAt() time.Time {
 	return r.updatedAt
 }
 
+// Version 楽観的排他制御のためのバージョン (更新のたびに1ずつ増える)
+func (r *Repayment) Version() int32 {
+	return r.version
+}
+
 // RepaymentRepository 返済リポジトリのインターフェース
 type RepaymentRepository interface {
 	// Create 返済を作成する
@@ -112,6 +119,8 @@ type RepaymentRepository interface {
 	FindByID(ctx context.Context, id ulid.ULID) (*Repayment, error)
 	// FindByPayerID 支払い者IDで返済一覧を取得する
 	FindByPayerID(ctx context.Context, payerID string, cursor *string, limit *int32) ([]*Repayment, error)
+	// FindByDebtorID 受取人IDで返済一覧を取得する
+	FindByDebtorID(ctx context.Context, debtorID string, cursor *string, limit *int32) ([]*Repayment, error)
 	// Update 返済を更新する
 	Update(ctx context.Context, r *Repayment) error
 	// Delete 返済を削除する
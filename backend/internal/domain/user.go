@@ -4,17 +4,27 @@ import (
 	"context"
 	"net/mail"
 	"net/url"
+	"strings"
 	"unicode/utf8"
 
+	"github.com/oklog/ulid/v2"
 	"go.opentelemetry.io/otel/codes"
 )
 
+const (
+	// deletedUserIDPrefix 退会済みのユーザーに採番するIDの接頭辞
+	deletedUserIDPrefix = "deleted-"
+	// deletedUserName 退会済みのユーザーの表示名
+	deletedUserName = "退会済みユーザー"
+)
+
 // User ユーザーを表すドメインエンティティ
 type User struct {
 	id     string
 	name   string
 	avatar string
 	email  string
+	guest  bool
 }
 
 // NewUser ユーザードメインエンティティのファクトリ関数
@@ -36,12 +46,15 @@ func NewUser(ctx context.Context, id string, name string, avatar string, email s
 		return nil, NewValidationError("name", "ユーザー名は1文字以上である必要があります")
 	}
 
-	parsedURL, err := url.Parse(avatar)
-	if err != nil {
-		return nil, NewValidationError("avatar", "アバターURLが不正です")
-	}
-	if parsedURL.Scheme == "" || parsedURL.Host == "" {
-		return nil, NewValidationError("avatar", "アバターURLが不正です")
+	// アバターは未設定(空文字)を許可する
+	if avatar != "" {
+		parsedURL, err := url.Parse(avatar)
+		if err != nil {
+			return nil, NewValidationError("avatar", "アバターURLが不正です")
+		}
+		if parsedURL.Scheme == "" || parsedURL.Host == "" {
+			return nil, NewValidationError("avatar", "アバターURLが不正です")
+		}
 	}
 
 	if _, err := mail.ParseAddress(email); err != nil {
@@ -56,6 +69,40 @@ func NewUser(ctx context.Context, id string, name string, avatar string, email s
 	}, nil
 }
 
+// NewGuestUser アカウントを持たないゲストのユーザードメインエンティティのファクトリ関数
+// ゲストはアバターを持たず、メールアドレスは任意
+func NewGuestUser(ctx context.Context, id string, name string, email string) (u *User, err error) {
+	_, span := tracer.Start(ctx, "domain.User.NewGuest")
+	defer func() {
+		if err != nil {
+			span.SetStatus(codes.Error, err.Error())
+			span.RecordError(err)
+		}
+		span.End()
+	}()
+
+	if id == "" {
+		return nil, NewValidationError("id", "ユーザーIDは必須です")
+	}
+
+	if utf8.RuneCountInString(name) < 1 {
+		return nil, NewValidationError("name", "ユーザー名は1文字以上である必要があります")
+	}
+
+	if email != "" {
+		if _, err := mail.ParseAddress(email); err != nil {
+			return nil, NewValidationError("email", "メールアドレスの形式が不正です")
+		}
+	}
+
+	return &User{
+		id:    id,
+		name:  name,
+		email: email,
+		guest: true,
+	}, nil
+}
+
 // UpdateProfile プロフィールの更新を行う
 func (u *User) UpdateProfile(ctx context.Context, name string, avatar string) (*User, error) {
 	ctx, span := tracer.Start(ctx, "domain.User.UpdateProfile")
@@ -64,6 +111,17 @@ func (u *User) UpdateProfile(ctx context.Context, name string, avatar string) (*
 	return NewUser(ctx, u.id, name, avatar, u.email)
 }
 
+// Anonymize 退会に伴い個人情報を取り除いたユーザーを作成する
+// 認証プロバイダのIDとの紐付けを解除するため、IDも新しく採番する
+func (u *User) Anonymize(ctx context.Context) (*User, error) {
+	ctx, span := tracer.Start(ctx, "domain.User.Anonymize")
+	defer span.End()
+
+	id := deletedUserIDPrefix + strings.ToLower(ulid.Make().String())
+
+	return NewUser(ctx, id, deletedUserName, "", id+"@deleted.invalid")
+}
+
 // ID ユーザーID
 func (u *User) ID() string {
 	return u.id
@@ -79,16 +137,40 @@ func (u *User) Avatar() string {
 	return u.avatar
 }
 
-// Email メールアドレス
+// Email メールアドレス (ゲストの場合は空文字の場合がある)
 func (u *User) Email() string {
 	return u.email
 }
 
+// IsGuest アカウントを持たないゲストかどうか
+func (u *User) IsGuest() bool {
+	return u.guest
+}
+
 // UserSearchQuery ユーザー検索クエリ
+// 検索対象はUserIDのユーザーの連絡先に限る
 type UserSearchQuery struct {
-	Name  *string
-	Email *string
-	Limit int32
+	UserID string
+	Name   *string
+	Email  *string
+	Limit  int32
+}
+
+// UserPrivacy ユーザーのプライバシー設定
+type UserPrivacy struct {
+	discoverableByEmail bool
+}
+
+// NewUserPrivacy UserPrivacyのファクトリ関数
+func NewUserPrivacy(discoverableByEmail bool) *UserPrivacy {
+	return &UserPrivacy{
+		discoverableByEmail: discoverableByEmail,
+	}
+}
+
+// DiscoverableByEmail 連絡先ではないユーザーからメールアドレスの完全一致で検索できるかどうか
+func (p *UserPrivacy) DiscoverableByEmail() bool {
+	return p.discoverableByEmail
 }
 
 // UserRepository ユーザーリポジトリのインターフェース
@@ -97,12 +179,27 @@ type UserRepository interface {
 	Create(ctx context.Context, u *User) error
 	// FindByID IDでユーザーを取得する
 	FindByID(ctx context.Context, id string) (*User, error)
+	// FindByIDs IDで複数のユーザーをまとめて取得する (存在しないIDのユーザーは含まない)
+	FindByIDs(ctx context.Context, ids []string) ([]*User, error)
 	// FindByEmail メールアドレスでユーザーを取得する
 	FindByEmail(ctx context.Context, email string) (*User, error)
-	// FindByQuery 検索条件でユーザー一覧を取得する
+	// FindByQuery 検索条件で連絡先のユーザー一覧を取得する
 	FindByQuery(ctx context.Context, query UserSearchQuery) ([]*User, error)
+	// FindDiscoverableByEmail メールアドレスでの検索を許可しているユーザーをメールアドレスの完全一致で取得する
+	FindDiscoverableByEmail(ctx context.Context, email string) (*User, error)
+	// FindPrivacy ユーザーのプライバシー設定を取得する
+	FindPrivacy(ctx context.Context, id string) (*UserPrivacy, error)
+	// UpdatePrivacy ユーザーのプライバシー設定を更新する
+	UpdatePrivacy(ctx context.Context, id string, p *UserPrivacy) error
 	// Update ユーザーを更新する
 	Update(ctx context.Context, u *User) error
 	// UpdateID ユーザーIDを更新する(認証プロバイダ移行用)
 	UpdateID(ctx context.Context, oldID, newID string) error
+	// Anonymize ユーザーをanonymizedの内容で匿名化し、グループから脱退させる
+	// 立て替えや返済の記録は匿名化したユーザーのものとして残す
+	// 作成したグループは最も古くから所属しているメンバーに引き継ぎ、他にメンバーがいない場合は削除する
+	// 未精算の貸し借りがある場合はConflictErrorを返し、何も変更しない
+	Anonymize(ctx context.Context, u *User, anonymized *User) error
+	// Merge fromのグループ所属や支払いをintoに付け替えてfromを削除する(重複ユーザーの統合用)
+	Merge(ctx context.Context, from, into *User) error
 }
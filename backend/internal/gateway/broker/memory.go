@@ -0,0 +1,114 @@
+// Package broker グループの変更を購読者に配信するブローカーの実装
+package broker
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+
+	"github.com/haebeal/datti/internal/domain"
+	"github.com/haebeal/datti/internal/logger"
+	"github.com/oklog/ulid/v2"
+)
+
+// ErrClosed 停止したブローカーを購読しようとした場合のエラー
+var ErrClosed = errors.New("broker: closed")
+
+// MemoryBroker プロセス内で変更を配信するブローカー
+// 別のタスクで発生した変更は配信されないため、複数タスクで動作させる場合は共有ブローカーの実装に差し替える
+type MemoryBroker struct {
+	bufferSize int
+
+	mu          sync.Mutex
+	subscribers map[ulid.ULID]map[*subscriber]struct{}
+	closed      bool
+}
+
+type subscriber struct {
+	ch chan *domain.GroupUpdate
+}
+
+// NewMemoryBroker MemoryBrokerのファクトリ関数
+// bufferSizeは購読者ごとに保持できる未送信の変更の数
+func NewMemoryBroker(bufferSize int) *MemoryBroker {
+	return &MemoryBroker{
+		bufferSize:  bufferSize,
+		subscribers: make(map[ulid.ULID]map[*subscriber]struct{}),
+	}
+}
+
+// Publish 変更をグループの購読者に配信する
+// 配信が追いつかない購読者は切断し、再接続時に最新の状態を取得させる
+func (b *MemoryBroker) Publish(ctx context.Context, u *domain.GroupUpdate) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for s := range b.subscribers[u.GroupID()] {
+		select {
+		case s.ch <- u:
+		default:
+			logger.FromContext(ctx).WarnContext(ctx, "配信が遅延している購読者を切断しました",
+				slog.String("groupId", u.GroupID().String()),
+			)
+			b.remove(u.GroupID(), s)
+		}
+	}
+
+	return nil
+}
+
+// Subscribe グループの変更を購読する
+func (b *MemoryBroker) Subscribe(ctx context.Context, groupID ulid.ULID) (<-chan *domain.GroupUpdate, func(), error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return nil, nil, ErrClosed
+	}
+
+	s := &subscriber{ch: make(chan *domain.GroupUpdate, b.bufferSize)}
+	if b.subscribers[groupID] == nil {
+		b.subscribers[groupID] = make(map[*subscriber]struct{})
+	}
+	b.subscribers[groupID][s] = struct{}{}
+
+	cancel := func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		b.remove(groupID, s)
+	}
+
+	return s.ch, cancel, nil
+}
+
+// Close 全ての購読を終了し、以降の購読を受け付けない
+// 終了処理で配信中のリクエストを完了させるために使用する
+func (b *MemoryBroker) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for groupID, subscribers := range b.subscribers {
+		for s := range subscribers {
+			b.remove(groupID, s)
+		}
+	}
+	b.closed = true
+}
+
+// remove 購読者を削除してチャネルを閉じる (ロックを取得した状態で呼び出す)
+func (b *MemoryBroker) remove(groupID ulid.ULID, s *subscriber) {
+	subscribers, ok := b.subscribers[groupID]
+	if !ok {
+		return
+	}
+	if _, ok := subscribers[s]; !ok {
+		return
+	}
+
+	delete(subscribers, s)
+	close(s.ch)
+	if len(subscribers) == 0 {
+		delete(b.subscribers, groupID)
+	}
+}
@@ -0,0 +1,39 @@
+// Package cache リポジトリの読み込み結果をキャッシュするデコレーター
+//
+// キャッシュはリクエスト単位のものと、リクエストをまたいで短時間保持するものの2段階で、
+// 書き込みは同じデコレーターを経由したものだけがキャッシュに反映される。
+package cache
+
+import (
+	"context"
+	"sync"
+
+	"github.com/oklog/ulid/v2"
+)
+
+type requestScopeKey struct{}
+
+// requestScope リクエスト単位のキャッシュ
+type requestScope struct {
+	mu      sync.Mutex
+	members map[membershipKey]struct{}
+}
+
+// WithRequestScope リクエスト単位のキャッシュをcontextに格納する
+// 格納されていない場合はリクエストをまたぐキャッシュのみを使用する
+func WithRequestScope(ctx context.Context) context.Context {
+	return context.WithValue(ctx, requestScopeKey{}, &requestScope{
+		members: make(map[membershipKey]struct{}),
+	})
+}
+
+func requestScopeFromContext(ctx context.Context) (*requestScope, bool) {
+	rs, ok := ctx.Value(requestScopeKey{}).(*requestScope)
+	return rs, ok
+}
+
+// membershipKey グループとユーザーの組み合わせ
+type membershipKey struct {
+	groupID ulid.ULID
+	userID  string
+}
@@ -0,0 +1,28 @@
+package cache_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/haebeal/datti/internal/gateway/cache"
+	"github.com/haebeal/datti/internal/gateway/memory"
+	"github.com/haebeal/datti/internal/gateway/repositorytest"
+)
+
+// TestRepositories キャッシュを経由しても結果が変わらないことを確認する
+func TestRepositories(t *testing.T) {
+	repositorytest.Run(t, func(t *testing.T) repositorytest.Repositories {
+		s := memory.NewStore()
+		return repositorytest.Repositories{
+			User:           memory.NewUserRepository(s),
+			Guest:          memory.NewGuestRepository(s),
+			Contact:        memory.NewContactRepository(s),
+			Credit:         memory.NewCreditRepository(s),
+			Lending:        memory.NewLendingRepository(s),
+			Group:          cache.NewGroupRepository(memory.NewGroupRepository(s), time.Minute),
+			Repayment:      memory.NewRepaymentRepository(s),
+			Comment:        memory.NewCommentRepository(s),
+			IdempotencyKey: memory.NewIdempotencyKeyRepository(s),
+		}
+	})
+}
@@ -0,0 +1,170 @@
+package cache
+
+import (
+	"context"
+	"maps"
+	"sync"
+	"time"
+
+	"github.com/haebeal/datti/internal/domain"
+	"github.com/oklog/ulid/v2"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+)
+
+// GroupRepositoryImpl グループのメンバーシップの確認結果をキャッシュするグループリポジトリ
+//
+// メンバーであるという結果のみをキャッシュし、メンバーでない場合は毎回確認する。
+// AddMemberで追加したメンバーはキャッシュに登録し、RemoveMemberとDeleteで削除する。
+// ユーザーの統合や退会など他のリポジトリを経由した脱退は、ttlが経過するまで反映されない。
+type GroupRepositoryImpl struct {
+	domain.GroupRepository
+
+	ttl time.Duration
+
+	mu        sync.Mutex
+	members   map[membershipKey]time.Time
+	nextSweep time.Time
+}
+
+// NewGroupRepository GroupRepositoryImplのファクトリ関数
+// ttlが0の場合はリクエスト単位のキャッシュのみを使用する
+func NewGroupRepository(gr domain.GroupRepository, ttl time.Duration) *GroupRepositoryImpl {
+	return &GroupRepositoryImpl{
+		GroupRepository: gr,
+		ttl:             ttl,
+		members:         make(map[membershipKey]time.Time),
+	}
+}
+
+// IsMember ユーザーがグループのメンバーであるかを確認する
+func (gr *GroupRepositoryImpl) IsMember(ctx context.Context, id ulid.ULID, userID string) (isMember bool, err error) {
+	ctx, span := tracer.Start(ctx, "cache.Group.IsMember")
+	hit := false
+	defer func() {
+		span.SetAttributes(attribute.Bool("cache.hit", hit))
+		if err != nil {
+			span.SetStatus(codes.Error, err.Error())
+			span.RecordError(err)
+		}
+		span.End()
+	}()
+
+	key := membershipKey{groupID: id, userID: userID}
+	if gr.cached(ctx, key) {
+		hit = true
+		return true, nil
+	}
+
+	isMember, err = gr.GroupRepository.IsMember(ctx, id, userID)
+	if err != nil {
+		return false, err
+	}
+	if isMember {
+		gr.store(ctx, key)
+	}
+
+	return isMember, nil
+}
+
+// AddMember グループにメンバーを追加する
+func (gr *GroupRepositoryImpl) AddMember(ctx context.Context, g *domain.Group, u *domain.User) error {
+	if err := gr.GroupRepository.AddMember(ctx, g, u); err != nil {
+		return err
+	}
+
+	gr.store(ctx, membershipKey{groupID: g.ID(), userID: u.ID()})
+
+	return nil
+}
+
+// RemoveMember グループからメンバーを削除する
+func (gr *GroupRepositoryImpl) RemoveMember(ctx context.Context, g *domain.Group, u *domain.User) error {
+	// 削除に失敗した場合もメンバーのままか分からないため、結果によらず無効化する
+	key := membershipKey{groupID: g.ID(), userID: u.ID()}
+	defer gr.invalidate(ctx, func(k membershipKey) bool {
+		return k == key
+	})
+
+	return gr.GroupRepository.RemoveMember(ctx, g, u)
+}
+
+// Delete グループを削除する
+func (gr *GroupRepositoryImpl) Delete(ctx context.Context, g *domain.Group) error {
+	defer gr.invalidate(ctx, func(k membershipKey) bool {
+		return k.groupID == g.ID()
+	})
+
+	return gr.GroupRepository.Delete(ctx, g)
+}
+
+// cached リクエスト単位のキャッシュ、リクエストをまたぐキャッシュの順に確認する
+func (gr *GroupRepositoryImpl) cached(ctx context.Context, key membershipKey) bool {
+	rs, ok := requestScopeFromContext(ctx)
+	if ok {
+		rs.mu.Lock()
+		_, hit := rs.members[key]
+		rs.mu.Unlock()
+		if hit {
+			return true
+		}
+	}
+
+	gr.mu.Lock()
+	expiresAt, hit := gr.members[key]
+	gr.mu.Unlock()
+	if !hit || time.Now().After(expiresAt) {
+		return false
+	}
+
+	if ok {
+		rs.mu.Lock()
+		rs.members[key] = struct{}{}
+		rs.mu.Unlock()
+	}
+	return true
+}
+
+// store メンバーであることを両方のキャッシュに登録する
+func (gr *GroupRepositoryImpl) store(ctx context.Context, key membershipKey) {
+	if rs, ok := requestScopeFromContext(ctx); ok {
+		rs.mu.Lock()
+		rs.members[key] = struct{}{}
+		rs.mu.Unlock()
+	}
+
+	if gr.ttl <= 0 {
+		return
+	}
+
+	now := time.Now()
+
+	gr.mu.Lock()
+	defer gr.mu.Unlock()
+
+	// 有効期限切れのエントリーはttlごとにまとめて削除する
+	if now.After(gr.nextSweep) {
+		maps.DeleteFunc(gr.members, func(_ membershipKey, expiresAt time.Time) bool {
+			return now.After(expiresAt)
+		})
+		gr.nextSweep = now.Add(gr.ttl)
+	}
+	gr.members[key] = now.Add(gr.ttl)
+}
+
+// invalidate 条件に一致するエントリーを両方のキャッシュから削除する
+func (gr *GroupRepositoryImpl) invalidate(ctx context.Context, match func(k membershipKey) bool) {
+	if rs, ok := requestScopeFromContext(ctx); ok {
+		rs.mu.Lock()
+		maps.DeleteFunc(rs.members, func(k membershipKey, _ struct{}) bool {
+			return match(k)
+		})
+		rs.mu.Unlock()
+	}
+
+	gr.mu.Lock()
+	maps.DeleteFunc(gr.members, func(k membershipKey, _ time.Time) bool {
+		return match(k)
+	})
+	gr.mu.Unlock()
+}
@@ -0,0 +1,8 @@
+package cache
+
+import (
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var tracer trace.Tracer = otel.Tracer("github.com/haebeal/datti/internal/gateway/cache")
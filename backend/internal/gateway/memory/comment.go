@@ -0,0 +1,143 @@
+package memory
+
+import (
+	"context"
+	"slices"
+
+	"github.com/haebeal/datti/internal/domain"
+	"github.com/oklog/ulid/v2"
+)
+
+// CommentRepositoryImpl コメントリポジトリのメモリ上の実装
+type CommentRepositoryImpl struct {
+	s *Store
+}
+
+// NewCommentRepository CommentRepositoryImplのファクトリ関数
+func NewCommentRepository(s *Store) *CommentRepositoryImpl {
+	return &CommentRepositoryImpl{
+		s: s,
+	}
+}
+
+// Create コメントを作成する
+func (cr *CommentRepositoryImpl) Create(ctx context.Context, c *domain.Comment) error {
+	cr.s.mu.Lock()
+	defer cr.s.mu.Unlock()
+
+	if _, exists := cr.s.comments[c.ID()]; exists {
+		return errDuplicate("lending_comments", c.ID().String())
+	}
+
+	cr.s.comments[c.ID()] = &commentRow{
+		id:        c.ID(),
+		eventID:   c.LendingID(),
+		userID:    c.Author().ID(),
+		body:      c.Body(),
+		createdAt: c.CreatedAt(),
+		updatedAt: c.UpdatedAt(),
+	}
+
+	return nil
+}
+
+// FindByID IDでコメントを取得する
+func (cr *CommentRepositoryImpl) FindByID(ctx context.Context, id ulid.ULID) (*domain.Comment, error) {
+	cr.s.mu.Lock()
+	defer cr.s.mu.Unlock()
+
+	row, ok := cr.s.comments[id]
+	if !ok {
+		return nil, domain.NewNotFoundError("comment", id.String())
+	}
+
+	return cr.toComment(ctx, row)
+}
+
+// FindByLendingID 立て替えのコメント一覧を古い順に取得する
+func (cr *CommentRepositoryImpl) FindByLendingID(ctx context.Context, lendingID ulid.ULID, cursor *string, limit int32) ([]*domain.Comment, error) {
+	cr.s.mu.Lock()
+	defer cr.s.mu.Unlock()
+
+	var rows []*commentRow
+	for _, row := range cr.s.comments {
+		if row.eventID == lendingID && (cursor == nil || row.id.String() > *cursor) {
+			rows = append(rows, row)
+		}
+	}
+	slices.SortFunc(rows, func(a, b *commentRow) int {
+		return a.id.Compare(b.id)
+	})
+	rows = limitRows(rows, limit)
+
+	comments := make([]*domain.Comment, 0, len(rows))
+	for _, row := range rows {
+		comment, err := cr.toComment(ctx, row)
+		if err != nil {
+			return nil, err
+		}
+		comments = append(comments, comment)
+	}
+
+	return comments, nil
+}
+
+// CountByLendingIDs 立て替えごとのコメント数を取得する (コメントがない立て替えは含まない)
+func (cr *CommentRepositoryImpl) CountByLendingIDs(ctx context.Context, lendingIDs []ulid.ULID) (map[ulid.ULID]int32, error) {
+	cr.s.mu.Lock()
+	defer cr.s.mu.Unlock()
+
+	counts := make(map[ulid.ULID]int32, len(lendingIDs))
+	for _, row := range cr.s.comments {
+		if slices.Contains(lendingIDs, row.eventID) {
+			counts[row.eventID]++
+		}
+	}
+
+	return counts, nil
+}
+
+// Update コメントを更新する
+func (cr *CommentRepositoryImpl) Update(ctx context.Context, c *domain.Comment) error {
+	cr.s.mu.Lock()
+	defer cr.s.mu.Unlock()
+
+	row, ok := cr.s.comments[c.ID()]
+	if !ok {
+		return domain.NewNotFoundError("comment", c.ID().String())
+	}
+
+	row.body = c.Body()
+	row.updatedAt = c.UpdatedAt()
+
+	return nil
+}
+
+// Delete コメントを削除する
+func (cr *CommentRepositoryImpl) Delete(ctx context.Context, id ulid.ULID) error {
+	cr.s.mu.Lock()
+	defer cr.s.mu.Unlock()
+
+	if _, ok := cr.s.comments[id]; !ok {
+		return domain.NewNotFoundError("comment", id.String())
+	}
+
+	delete(cr.s.comments, id)
+
+	return nil
+}
+
+// toComment コメントの行を投稿者と合わせてドメインエンティティに変換する (ロックを取得した状態で呼び出す)
+func (cr *CommentRepositoryImpl) toComment(ctx context.Context, row *commentRow) (*domain.Comment, error) {
+	user, ok := cr.s.users[row.userID]
+	if !ok {
+		return nil, domain.NewNotFoundError("user", row.userID)
+	}
+
+	author, err := domain.NewUser(ctx, user.id, user.name, user.avatar, user.email)
+	if err != nil {
+		return nil, err
+	}
+
+	return domain.NewComment(ctx, row.id, row.eventID, author, row.body, row.createdAt, row.updatedAt)
+}
@@ -0,0 +1,77 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/haebeal/datti/internal/domain"
+)
+
+// ContactRepositoryImpl 連絡先リポジトリのメモリ上の実装
+type ContactRepositoryImpl struct {
+	s *Store
+}
+
+// NewContactRepository ContactRepositoryImplのファクトリ関数
+func NewContactRepository(s *Store) *ContactRepositoryImpl {
+	return &ContactRepositoryImpl{
+		s: s,
+	}
+}
+
+// Add ユーザーの連絡先にcontactを追加する
+func (cr *ContactRepositoryImpl) Add(ctx context.Context, userID string, contact *domain.User) error {
+	cr.s.mu.Lock()
+	defer cr.s.mu.Unlock()
+
+	if cr.s.hasContact(userID, contact.ID()) {
+		return errDuplicate("contacts", userID+"/"+contact.ID())
+	}
+
+	cr.s.contacts = append(cr.s.contacts, &contactRow{
+		userID:    userID,
+		contactID: contact.ID(),
+		createdAt: time.Now(),
+	})
+
+	return nil
+}
+
+// Remove 明示的に追加した連絡先を削除する
+func (cr *ContactRepositoryImpl) Remove(ctx context.Context, userID string, contactID string) error {
+	cr.s.mu.Lock()
+	defer cr.s.mu.Unlock()
+
+	if !cr.s.hasContact(userID, contactID) {
+		return domain.NewNotFoundError("contact", contactID)
+	}
+
+	cr.s.contacts = slices.DeleteFunc(cr.s.contacts, func(c *contactRow) bool {
+		return c.userID == userID && c.contactID == contactID
+	})
+
+	return nil
+}
+
+// FindByUserID ユーザーの連絡先一覧を名前順に取得する
+func (cr *ContactRepositoryImpl) FindByUserID(ctx context.Context, userID string) ([]*domain.Contact, error) {
+	cr.s.mu.Lock()
+	defer cr.s.mu.Unlock()
+
+	rows := cr.s.contactCandidates(userID)
+	contacts := make([]*domain.Contact, 0, len(rows))
+	for _, row := range rows {
+		user, err := domain.NewUser(ctx, row.id, row.name, row.avatar, row.email)
+		if err != nil {
+			return nil, err
+		}
+		contact, err := domain.NewContact(user, cr.s.hasContact(userID, row.id))
+		if err != nil {
+			return nil, err
+		}
+		contacts = append(contacts, contact)
+	}
+
+	return contacts, nil
+}
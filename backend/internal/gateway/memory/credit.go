@@ -0,0 +1,142 @@
+package memory
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/haebeal/datti/internal/domain"
+)
+
+// CreditRepositoryImpl 債権/債務リポジトリのメモリ上の実装
+// Postgres実装は支払いの変更時に更新する残高テーブルから取得するが、
+// メモリ上では件数が少ないため、取得のたびに立て替えと返済の全ての支払いから残高と延滞額を集計する
+type CreditRepositoryImpl struct {
+	s *Store
+}
+
+// NewCreditRepository CreditRepositoryImplのファクトリ関数
+func NewCreditRepository(s *Store) *CreditRepositoryImpl {
+	return &CreditRepositoryImpl{
+		s: s,
+	}
+}
+
+// FindByUserID 自分と他ユーザーとの債権/債務一覧を取得
+// 正=貸している、負=借りている
+func (r *CreditRepositoryImpl) FindByUserID(ctx context.Context, userID string) ([]*domain.Credit, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	balances := r.s.balances(userID)
+	dated := r.s.datedDebts(userID, time.Now())
+
+	// 0以外のCreditを作成
+	credits := make([]*domain.Credit, 0, len(balances))
+	for _, otherUserID := range slices.Sorted(maps.Keys(balances)) {
+		amount := balances[otherUserID]
+		if amount == 0 {
+			continue
+		}
+		credit, err := domain.NewCredit(ctx, otherUserID, amount, domain.OverdueAmount(amount, dated[otherUserID]))
+		if err != nil {
+			return nil, err
+		}
+		credits = append(credits, credit)
+	}
+
+	return credits, nil
+}
+
+// FindByUserIDAndOtherUserID 特定のユーザーとの債権/債務を取得
+// 正=貸している、負=借りている
+func (r *CreditRepositoryImpl) FindByUserIDAndOtherUserID(ctx context.Context, userID string, otherUserID string) (*domain.Credit, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	amount := r.s.balances(userID)[otherUserID]
+	if amount == 0 {
+		return nil, domain.NewNotFoundError("credit", otherUserID)
+	}
+
+	debts := r.s.datedDebts(userID, time.Now())[otherUserID]
+
+	return domain.NewCredit(ctx, otherUserID, amount, domain.OverdueAmount(amount, debts))
+}
+
+// TotalOutstanding 全ユーザー間の未精算の金額の合計を取得
+// ユーザーの組ごとに相殺した残高の絶対値を合計する
+func (r *CreditRepositoryImpl) TotalOutstanding(ctx context.Context) (int64, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	type pair struct{ low, high string }
+	balances := make(map[pair]int64)
+	for _, p := range r.s.payments {
+		switch {
+		case p.payerID < p.debtorID:
+			balances[pair{p.payerID, p.debtorID}] += p.amount
+		case p.payerID > p.debtorID:
+			balances[pair{p.debtorID, p.payerID}] -= p.amount
+		}
+	}
+
+	var total int64
+	for _, balance := range balances {
+		total += max(balance, -balance)
+	}
+
+	return total, nil
+}
+
+// balances ユーザーごとの残高を集計する (ロックを取得した状態で呼び出す)
+// 支払い者自身の負担分 (payerID = debtorID) は除外する
+func (s *Store) balances(userID string) map[string]int64 {
+	balances := make(map[string]int64)
+	for _, p := range s.payments {
+		if p.payerID == p.debtorID {
+			continue
+		}
+		if p.payerID == userID {
+			balances[p.debtorID] += p.amount // 貸し = 正
+		}
+		if p.debtorID == userID {
+			balances[p.payerID] -= p.amount // 借り = 負
+		}
+	}
+	return balances
+}
+
+// datedDebts 返済期限のある立て替えによる相手ユーザーごとの貸し/借りの合計を集計する
+// (ロックを取得した状態で呼び出す)
+func (s *Store) datedDebts(userID string, now time.Time) map[string]domain.DatedDebts {
+	debts := make(map[string]domain.DatedDebts)
+	for _, p := range s.payments {
+		if p.eventID == nil || p.payerID == p.debtorID {
+			continue
+		}
+		e, ok := s.events[*p.eventID]
+		if !ok || e.dueDate == nil {
+			continue
+		}
+		notDue := !e.dueDate.Before(now)
+		switch userID {
+		case p.payerID:
+			d := debts[p.debtorID]
+			d.Lent += p.amount
+			if notDue {
+				d.LentNotDue += p.amount
+			}
+			debts[p.debtorID] = d
+		case p.debtorID:
+			d := debts[p.payerID]
+			d.Borrowed += p.amount
+			if notDue {
+				d.BorrowedNotDue += p.amount
+			}
+			debts[p.payerID] = d
+		}
+	}
+	return debts
+}
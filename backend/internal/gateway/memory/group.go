@@ -0,0 +1,268 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"time"
+
+	"github.com/haebeal/datti/internal/domain"
+	"github.com/oklog/ulid/v2"
+)
+
+// GroupRepositoryImpl グループリポジトリのメモリ上の実装
+type GroupRepositoryImpl struct {
+	s *Store
+}
+
+// NewGroupRepository GroupRepositoryImplのファクトリ関数
+func NewGroupRepository(s *Store) *GroupRepositoryImpl {
+	return &GroupRepositoryImpl{
+		s: s,
+	}
+}
+
+// Create グループを作成する
+func (gr *GroupRepositoryImpl) Create(ctx context.Context, g *domain.Group) error {
+	gr.s.mu.Lock()
+	defer gr.s.mu.Unlock()
+
+	if _, exists := gr.s.groups[g.ID()]; exists {
+		return errDuplicate("groups", g.ID().String())
+	}
+
+	gr.s.groups[g.ID()] = &groupRow{
+		id:               g.ID(),
+		name:             g.Name(),
+		createdBy:        g.CreatedBy(),
+		paymentTermsDays: g.PaymentTermsDays(),
+		createdAt:        g.CreatedAt(),
+		updatedAt:        g.UpdatedAt(),
+		version:          1,
+		seq:              gr.s.nextSeq(),
+	}
+
+	return nil
+}
+
+// FindByMemberUserID ユーザーが所属するグループ一覧を新しい順に取得する
+func (gr *GroupRepositoryImpl) FindByMemberUserID(ctx context.Context, userID string) ([]*domain.Group, error) {
+	gr.s.mu.Lock()
+	defer gr.s.mu.Unlock()
+
+	var rows []*groupRow
+	for _, m := range gr.s.members {
+		if m.userID == userID {
+			if row, ok := gr.s.groups[m.groupID]; ok {
+				rows = append(rows, row)
+			}
+		}
+	}
+	slices.SortFunc(rows, func(a, b *groupRow) int {
+		return cmp.Or(b.createdAt.Compare(a.createdAt), cmp.Compare(b.seq, a.seq))
+	})
+
+	groups := make([]*domain.Group, 0, len(rows))
+	for _, row := range rows {
+		group, err := toGroup(ctx, row)
+		if err != nil {
+			return nil, err
+		}
+		groups = append(groups, group)
+	}
+
+	return groups, nil
+}
+
+// FindByID グループをIDで取得する
+func (gr *GroupRepositoryImpl) FindByID(ctx context.Context, id ulid.ULID) (*domain.Group, error) {
+	gr.s.mu.Lock()
+	defer gr.s.mu.Unlock()
+
+	row, ok := gr.s.groups[id]
+	if !ok {
+		return nil, domain.NewNotFoundError("group", id.String())
+	}
+
+	return toGroup(ctx, row)
+}
+
+// Update グループを更新する
+func (gr *GroupRepositoryImpl) Update(ctx context.Context, g *domain.Group) error {
+	gr.s.mu.Lock()
+	defer gr.s.mu.Unlock()
+
+	// 読み込み時のバージョンから変わっていない場合のみ更新する
+	row, ok := gr.s.groups[g.ID()]
+	if !ok || row.version != g.Version()-1 {
+		return domain.NewStaleVersionError("group")
+	}
+
+	row.name = g.Name()
+	row.createdBy = g.CreatedBy()
+	row.paymentTermsDays = g.PaymentTermsDays()
+	row.updatedAt = g.UpdatedAt()
+	row.version = g.Version()
+
+	return nil
+}
+
+// Delete グループを削除する
+// グループの立て替えとその支払い、メンバー、ゲストも削除する
+func (gr *GroupRepositoryImpl) Delete(ctx context.Context, g *domain.Group) error {
+	gr.s.mu.Lock()
+	defer gr.s.mu.Unlock()
+
+	gr.s.deleteGroup(g.ID())
+
+	return nil
+}
+
+// AddMember グループにメンバーを追加する
+func (gr *GroupRepositoryImpl) AddMember(ctx context.Context, g *domain.Group, u *domain.User) error {
+	gr.s.mu.Lock()
+	defer gr.s.mu.Unlock()
+
+	return gr.s.addMember(g.ID(), u.ID())
+}
+
+// FindMembersByID グループのメンバー一覧を追加された順に取得する
+func (gr *GroupRepositoryImpl) FindMembersByID(ctx context.Context, id ulid.ULID) ([]*domain.User, error) {
+	gr.s.mu.Lock()
+	defer gr.s.mu.Unlock()
+
+	var rows []*memberRow
+	for _, m := range gr.s.members {
+		if m.groupID == id {
+			rows = append(rows, m)
+		}
+	}
+	slices.SortFunc(rows, func(a, b *memberRow) int {
+		return cmp.Or(a.createdAt.Compare(b.createdAt), cmp.Compare(a.seq, b.seq))
+	})
+
+	members := make([]*domain.User, 0, len(rows))
+	for _, m := range rows {
+		row, ok := gr.s.users[m.userID]
+		if !ok {
+			continue
+		}
+		user, err := toUser(ctx, row)
+		if err != nil {
+			return nil, err
+		}
+		members = append(members, user)
+	}
+
+	return members, nil
+}
+
+// IsMember ユーザーがグループのメンバーであるかを確認する
+func (gr *GroupRepositoryImpl) IsMember(ctx context.Context, id ulid.ULID, userID string) (bool, error) {
+	gr.s.mu.Lock()
+	defer gr.s.mu.Unlock()
+
+	return gr.s.isMember(id, userID), nil
+}
+
+// FindMemberIDs userIDsのうちグループのメンバーであるユーザーのIDをID順に取得する
+func (gr *GroupRepositoryImpl) FindMemberIDs(ctx context.Context, id ulid.ULID, userIDs []string) ([]string, error) {
+	gr.s.mu.Lock()
+	defer gr.s.mu.Unlock()
+
+	var memberIDs []string
+	for _, m := range gr.s.members {
+		if m.groupID == id && slices.Contains(userIDs, m.userID) {
+			memberIDs = append(memberIDs, m.userID)
+		}
+	}
+	slices.Sort(memberIDs)
+
+	return memberIDs, nil
+}
+
+// RemoveMember グループからメンバーを削除する
+// 脱退しても貸し借りが消えないよう、メンバーが関与する立て替えの支払いは残す
+func (gr *GroupRepositoryImpl) RemoveMember(ctx context.Context, g *domain.Group, u *domain.User) error {
+	gr.s.mu.Lock()
+	defer gr.s.mu.Unlock()
+
+	groupID := g.ID()
+	gr.s.members = slices.DeleteFunc(gr.s.members, func(m *memberRow) bool {
+		return m.groupID == groupID && m.userID == u.ID()
+	})
+
+	// ゲストは他のグループに所属できないため、グループから外れたら削除する
+	// 立て替えや返済の記録が残っている場合は履歴を表示できるように残す
+	if u.IsGuest() {
+		referenced := slices.ContainsFunc(gr.s.payments, func(p *paymentRow) bool {
+			return p.payerID == u.ID() || p.debtorID == u.ID()
+		})
+		if row, ok := gr.s.users[u.ID()]; ok && row.guestGroupID != nil && !referenced {
+			gr.s.deleteUser(u.ID())
+		}
+	}
+
+	return nil
+}
+
+// deleteGroup グループの立て替えとその支払い、メンバー、ゲストとともにグループを削除する
+// (ロックを取得した状態で呼び出す)
+func (s *Store) deleteGroup(groupID ulid.ULID) {
+	s.deletePayments(func(p *paymentRow) bool {
+		if p.eventID == nil {
+			return false
+		}
+		e, ok := s.events[*p.eventID]
+		return ok && e.groupID != nil && *e.groupID == groupID
+	})
+	for id, e := range s.events {
+		if e.groupID != nil && *e.groupID == groupID {
+			s.deleteEvent(id)
+		}
+	}
+
+	s.members = slices.DeleteFunc(s.members, func(m *memberRow) bool {
+		return m.groupID == groupID
+	})
+	for id, u := range s.users {
+		if u.guestGroupID != nil && *u.guestGroupID == groupID {
+			s.deleteUser(id)
+		}
+	}
+	delete(s.groups, groupID)
+}
+
+// successor グループの作成者userIDの次に古くから所属しているメンバーを取得する
+// 他にメンバーがいない場合は空文字を返す (ロックを取得した状態で呼び出す)
+func (s *Store) successor(groupID ulid.ULID, userID string) string {
+	var next *memberRow
+	for _, m := range s.members {
+		if m.groupID != groupID || m.userID == userID {
+			continue
+		}
+		if next == nil || cmp.Or(m.createdAt.Compare(next.createdAt), cmp.Compare(m.seq, next.seq)) < 0 {
+			next = m
+		}
+	}
+	if next == nil {
+		return ""
+	}
+	return next.userID
+}
+
+// addMember グループにメンバーを追加する (ロックを取得した状態で呼び出す)
+func (s *Store) addMember(groupID ulid.ULID, userID string) error {
+	if s.isMember(groupID, userID) {
+		return errDuplicate("group_members", groupID.String()+"/"+userID)
+	}
+
+	s.members = append(s.members, &memberRow{
+		groupID:   groupID,
+		userID:    userID,
+		createdAt: time.Now(),
+		seq:       s.nextSeq(),
+	})
+
+	return nil
+}
@@ -0,0 +1,124 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"time"
+
+	"github.com/haebeal/datti/internal/domain"
+	"github.com/oklog/ulid/v2"
+)
+
+// GuestRepositoryImpl ゲストリポジトリのメモリ上の実装
+type GuestRepositoryImpl struct {
+	s *Store
+}
+
+// NewGuestRepository GuestRepositoryImplのファクトリ関数
+func NewGuestRepository(s *Store) *GuestRepositoryImpl {
+	return &GuestRepositoryImpl{
+		s: s,
+	}
+}
+
+// Create ゲストを作成し、グループのメンバーに追加する
+func (gr *GuestRepositoryImpl) Create(ctx context.Context, g *domain.Guest) error {
+	gr.s.mu.Lock()
+	defer gr.s.mu.Unlock()
+
+	if _, exists := gr.s.users[g.ID()]; exists {
+		return errDuplicate("users", g.ID())
+	}
+
+	groupID := g.GroupID()
+	gr.s.users[g.ID()] = &userRow{
+		id:                  g.ID(),
+		name:                g.Name(),
+		email:               g.Email(),
+		guestGroupID:        &groupID,
+		inviteToken:         g.InviteToken(),
+		discoverableByEmail: true,
+		createdAt:           time.Now(),
+		seq:                 gr.s.nextSeq(),
+	}
+
+	return gr.s.addMember(groupID, g.ID())
+}
+
+// FindByGroupID グループのゲスト一覧を作成順に取得する
+func (gr *GuestRepositoryImpl) FindByGroupID(ctx context.Context, groupID ulid.ULID) ([]*domain.Guest, error) {
+	gr.s.mu.Lock()
+	defer gr.s.mu.Unlock()
+
+	return gr.findGuests(ctx, func(u *userRow) bool {
+		return *u.guestGroupID == groupID
+	})
+}
+
+// FindByInviteToken 招待トークンでゲストを取得する
+func (gr *GuestRepositoryImpl) FindByInviteToken(ctx context.Context, token string) (*domain.Guest, error) {
+	gr.s.mu.Lock()
+	defer gr.s.mu.Unlock()
+
+	guests, err := gr.findGuests(ctx, func(u *userRow) bool {
+		return u.inviteToken == token
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(guests) == 0 {
+		// 招待トークンはエラーの詳細に含めない
+		return nil, domain.NewNotFoundError("guest", "")
+	}
+
+	return guests[0], nil
+}
+
+// FindByEmail メールアドレスが一致するゲスト一覧を作成順に取得する
+func (gr *GuestRepositoryImpl) FindByEmail(ctx context.Context, email string) ([]*domain.Guest, error) {
+	gr.s.mu.Lock()
+	defer gr.s.mu.Unlock()
+
+	return gr.findGuests(ctx, func(u *userRow) bool {
+		return u.email == email
+	})
+}
+
+// Claim ゲストのグループ所属や支払いをuに付け替えてゲストを削除する
+func (gr *GuestRepositoryImpl) Claim(ctx context.Context, g *domain.Guest, u *domain.User) error {
+	gr.s.mu.Lock()
+	defer gr.s.mu.Unlock()
+
+	gr.s.mergeUser(g.ID(), u.ID())
+
+	return nil
+}
+
+// findGuests 条件に一致するゲストを作成順に取得する (ロックを取得した状態で呼び出す)
+func (gr *GuestRepositoryImpl) findGuests(ctx context.Context, match func(u *userRow) bool) ([]*domain.Guest, error) {
+	var rows []*userRow
+	for _, u := range gr.s.users {
+		if u.guestGroupID != nil && match(u) {
+			rows = append(rows, u)
+		}
+	}
+	slices.SortFunc(rows, func(a, b *userRow) int {
+		return cmp.Or(a.createdAt.Compare(b.createdAt), cmp.Compare(a.seq, b.seq))
+	})
+
+	guests := make([]*domain.Guest, 0, len(rows))
+	for _, row := range rows {
+		user, err := toUser(ctx, row)
+		if err != nil {
+			return nil, err
+		}
+		guest, err := domain.NewGuest(ctx, user, *row.guestGroupID, row.inviteToken)
+		if err != nil {
+			return nil, err
+		}
+		guests = append(guests, guest)
+	}
+
+	return guests, nil
+}
@@ -0,0 +1,75 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/haebeal/datti/internal/domain"
+)
+
+// IdempotencyKeyRepositoryImpl 冪等性キーリポジトリのメモリ上の実装
+type IdempotencyKeyRepositoryImpl struct {
+	s *Store
+}
+
+// NewIdempotencyKeyRepository IdempotencyKeyRepositoryImplのファクトリ関数
+func NewIdempotencyKeyRepository(s *Store) *IdempotencyKeyRepositoryImpl {
+	return &IdempotencyKeyRepositoryImpl{
+		s: s,
+	}
+}
+
+// Reserve キーを処理中として登録する
+// 有効期限内の同じキーが既に存在する場合は登録せずにそのキーを返す
+func (ir *IdempotencyKeyRepositoryImpl) Reserve(ctx context.Context, k *domain.IdempotencyKey, expiresBefore time.Time) (*domain.IdempotencyKey, error) {
+	ir.s.mu.Lock()
+	defer ir.s.mu.Unlock()
+
+	id := idempotencyKeyID{userID: k.UserID(), key: k.Key()}
+
+	// 有効期限切れのキーは再利用できるように削除する
+	if row, ok := ir.s.idempotencyKeys[id]; ok && row.createdAt.Before(expiresBefore) {
+		delete(ir.s.idempotencyKeys, id)
+	}
+
+	row, ok := ir.s.idempotencyKeys[id]
+	if !ok {
+		ir.s.idempotencyKeys[id] = &idempotencyKeyRow{
+			userID:      k.UserID(),
+			key:         k.Key(),
+			method:      k.Method(),
+			path:        k.Path(),
+			requestHash: k.RequestHash(),
+			createdAt:   k.CreatedAt(),
+		}
+		return nil, nil
+	}
+
+	// 既に登録されているキーを返す
+	return domain.NewIdempotencyKey(row.userID, row.key, row.method, row.path, row.requestHash, row.statusCode, row.contentType, slices.Clone(row.responseBody), row.createdAt)
+}
+
+// Complete 処理結果のレスポンスを保存する
+func (ir *IdempotencyKeyRepositoryImpl) Complete(ctx context.Context, k *domain.IdempotencyKey) error {
+	ir.s.mu.Lock()
+	defer ir.s.mu.Unlock()
+
+	if row, ok := ir.s.idempotencyKeys[idempotencyKeyID{userID: k.UserID(), key: k.Key()}]; ok {
+		row.statusCode = k.StatusCode()
+		row.contentType = k.ContentType()
+		row.responseBody = slices.Clone(k.ResponseBody())
+	}
+
+	return nil
+}
+
+// Delete キーを削除する
+func (ir *IdempotencyKeyRepositoryImpl) Delete(ctx context.Context, k *domain.IdempotencyKey) error {
+	ir.s.mu.Lock()
+	defer ir.s.mu.Unlock()
+
+	delete(ir.s.idempotencyKeys, idempotencyKeyID{userID: k.UserID(), key: k.Key()})
+
+	return nil
+}
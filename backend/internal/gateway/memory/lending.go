@@ -0,0 +1,326 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"time"
+
+	"github.com/haebeal/datti/internal/domain"
+	"github.com/oklog/ulid/v2"
+)
+
+// LendingRepositoryImpl 立て替えリポジトリのメモリ上の実装
+type LendingRepositoryImpl struct {
+	s *Store
+}
+
+// NewLendingRepository LendingRepositoryImplのファクトリ関数
+func NewLendingRepository(s *Store) *LendingRepositoryImpl {
+	return &LendingRepositoryImpl{
+		s: s,
+	}
+}
+
+// Create 立て替えを作成する (gがnilの場合はグループに属さない個人間の立て替えとして作成する)
+func (lr *LendingRepositoryImpl) Create(ctx context.Context, g *domain.Group, l *domain.Lending) error {
+	lr.s.mu.Lock()
+	defer lr.s.mu.Unlock()
+
+	if _, exists := lr.s.events[l.ID()]; exists {
+		return errDuplicate("events", l.ID().String())
+	}
+
+	var groupID *ulid.ULID
+	if g != nil {
+		id := g.ID()
+		groupID = &id
+	}
+	lr.s.events[l.ID()] = &eventRow{
+		id:        l.ID(),
+		groupID:   groupID,
+		name:      l.Name(),
+		amount:    l.Amount(),
+		eventDate: l.EventDate(),
+		dueDate:   l.DueDate(),
+		createdAt: l.CreatedAt(),
+		updatedAt: l.UpdatedAt(),
+		createdBy: l.Payer().ID(),
+		version:   1,
+	}
+
+	lr.s.createAllocationPayments(l)
+
+	return nil
+}
+
+// createAllocationPayments 支払いマトリクスの各要素を支払いとして作成する (ロックを取得した状態で呼び出す)
+func (s *Store) createAllocationPayments(l *domain.Lending) {
+	eventID := l.ID()
+	now := time.Now()
+	for _, allocation := range l.Allocations() {
+		s.payments = append(s.payments, &paymentRow{
+			id:        ulid.Make(),
+			eventID:   &eventID,
+			payerID:   allocation.PayerID,
+			debtorID:  allocation.DebtorID,
+			amount:    allocation.Amount,
+			createdAt: now,
+			updatedAt: now,
+			version:   1,
+		})
+	}
+}
+
+// FindByID 立て替えをIDで取得する
+func (lr *LendingRepositoryImpl) FindByID(ctx context.Context, id ulid.ULID) (*domain.Lending, error) {
+	lr.s.mu.Lock()
+	defer lr.s.mu.Unlock()
+
+	event, ok := lr.s.events[id]
+	if !ok {
+		return nil, domain.NewNotFoundError("lending", id.String())
+	}
+
+	return lr.restore(ctx, event)
+}
+
+// FindPersonalByID 個人間の立て替えをIDで取得する
+func (lr *LendingRepositoryImpl) FindPersonalByID(ctx context.Context, id ulid.ULID) (*domain.Lending, error) {
+	lr.s.mu.Lock()
+	defer lr.s.mu.Unlock()
+
+	event, ok := lr.s.events[id]
+	if !ok || event.groupID != nil {
+		return nil, domain.NewNotFoundError("lending", id.String())
+	}
+
+	return lr.restore(ctx, event)
+}
+
+// restore イベントと支払いマトリクスからLending集約を復元する (ロックを取得した状態で呼び出す)
+func (lr *LendingRepositoryImpl) restore(ctx context.Context, event *eventRow) (*domain.Lending, error) {
+	payments := lr.s.eventPayments(event.id)
+	if len(payments) == 0 {
+		return nil, domain.NewNotFoundError("lending payments", event.id.String())
+	}
+
+	// 支払いマトリクスから各ユーザーの立て替え額と負担額を集計
+	paid := make(map[string]int64)
+	owed := make(map[string]int64)
+	userIDs := make(map[string]struct{}, len(payments))
+	for _, p := range payments {
+		paid[p.payerID] += p.amount
+		owed[p.debtorID] += p.amount
+		userIDs[p.payerID] = struct{}{}
+		userIDs[p.debtorID] = struct{}{}
+	}
+
+	// 作成者を特定 (作成者が支払い者でなくなっている場合は最初の支払い者とみなす)
+	createdBy := payments[0].payerID
+	if _, exists := paid[event.createdBy]; exists {
+		createdBy = event.createdBy
+	}
+
+	payers := make(map[string]*domain.Payer, len(paid))
+	debtors := make(map[string]*domain.Debtor, len(owed))
+	for userID := range userIDs {
+		user, ok := lr.s.users[userID]
+		if !ok {
+			return nil, domain.NewNotFoundError("user", userID)
+		}
+
+		if amount, isPayer := paid[userID]; isPayer {
+			payer, err := domain.NewPayer(user.id, user.name, user.avatar, user.email, amount, owed[userID])
+			if err != nil {
+				return nil, err
+			}
+			payers[payer.ID()] = payer
+			continue
+		}
+
+		debtor, err := domain.NewDebtor(user.id, user.name, user.avatar, user.email, owed[userID])
+		if err != nil {
+			return nil, err
+		}
+		debtors[debtor.ID()] = debtor
+	}
+
+	return domain.NewLending(ctx, event.id, event.name, event.amount, event.eventDate, event.dueDate, payers[createdBy], payers, debtors, event.createdAt, event.updatedAt, event.version)
+}
+
+// FindByGroupAndUserID グループとユーザーIDで立て替え一覧を新しい順に取得する
+func (lr *LendingRepositoryImpl) FindByGroupAndUserID(ctx context.Context, g *domain.Group, userID string, cursor *string, limit *int32) ([]*domain.Lending, error) {
+	lr.s.mu.Lock()
+	defer lr.s.mu.Unlock()
+
+	events := lr.s.findEvents(userID, cursor, *limit, func(e *eventRow) bool {
+		return e.groupID != nil && *e.groupID == g.ID()
+	})
+
+	lendings := make([]*domain.Lending, 0, len(events))
+	for _, event := range events {
+		lending, err := lr.restore(ctx, event)
+		if err != nil {
+			return nil, err
+		}
+		lendings = append(lendings, lending)
+	}
+
+	return lendings, nil
+}
+
+// FindByUserID グループと個人間の立て替えのうち、ユーザーが関与するものを新しい順に取得する
+func (lr *LendingRepositoryImpl) FindByUserID(ctx context.Context, userID string, cursor *string, limit int32) ([]*domain.LendingSearchResult, error) {
+	lr.s.mu.Lock()
+	defer lr.s.mu.Unlock()
+
+	events := lr.s.findEvents(userID, cursor, limit, func(e *eventRow) bool {
+		return true
+	})
+
+	results := make([]*domain.LendingSearchResult, 0, len(events))
+	for _, event := range events {
+		lending, err := lr.restore(ctx, event)
+		if err != nil {
+			return nil, err
+		}
+
+		// 個人間の立て替えはグループを持たない
+		var group *domain.Group
+		if event.groupID != nil {
+			row, ok := lr.s.groups[*event.groupID]
+			if !ok {
+				return nil, domain.NewNotFoundError("group", event.groupID.String())
+			}
+			group, err = toGroup(ctx, row)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		results = append(results, &domain.LendingSearchResult{
+			Group:   group,
+			Lending: lending,
+		})
+	}
+
+	return results, nil
+}
+
+// findEvents ユーザーが関与するイベントをIDの降順にカーソル以降からlimit件取得する (ロックを取得した状態で呼び出す)
+func (s *Store) findEvents(userID string, cursor *string, limit int32, match func(e *eventRow) bool) []*eventRow {
+	var events []*eventRow
+	for _, e := range s.events {
+		if match(e) && afterCursor(e.id, cursor) && s.involves(e.id, userID) {
+			events = append(events, e)
+		}
+	}
+	slices.SortFunc(events, func(a, b *eventRow) int {
+		return b.id.Compare(a.id)
+	})
+
+	return limitRows(events, limit)
+}
+
+// Search 指定グループ内でユーザーが関与する立て替えを名前で検索する (関連度順)
+// 名前の部分一致に加えて、pg_trgmの<%演算子を近似した表記揺れの検索を行う
+func (lr *LendingRepositoryImpl) Search(ctx context.Context, groups []*domain.Group, userID string, query string, limit int32) ([]*domain.LendingSearchResult, error) {
+	lr.s.mu.Lock()
+	defer lr.s.mu.Unlock()
+
+	if len(groups) == 0 {
+		return []*domain.LendingSearchResult{}, nil
+	}
+
+	groupByID := make(map[ulid.ULID]*domain.Group, len(groups))
+	for _, g := range groups {
+		groupByID[g.ID()] = g
+	}
+
+	type hit struct {
+		event *eventRow
+		rank  float64
+	}
+	var hits []hit
+	for _, e := range lr.s.events {
+		if e.groupID == nil {
+			continue
+		}
+		if _, ok := groupByID[*e.groupID]; !ok || !lr.s.involves(e.id, userID) {
+			continue
+		}
+
+		wordSimilarity := trigramWordSimilarity(query, e.name)
+		if !containsFold(e.name, query) && wordSimilarity < wordSimilarityThreshold {
+			continue
+		}
+		hits = append(hits, hit{
+			event: e,
+			rank:  max(trigramSimilarity(e.name, query), wordSimilarity),
+		})
+	}
+	slices.SortFunc(hits, func(a, b hit) int {
+		return cmp.Or(
+			cmp.Compare(b.rank, a.rank),
+			b.event.eventDate.Compare(a.event.eventDate),
+			b.event.id.Compare(a.event.id),
+		)
+	})
+	hits = limitRows(hits, limit)
+
+	results := make([]*domain.LendingSearchResult, 0, len(hits))
+	for _, h := range hits {
+		lending, err := lr.restore(ctx, h.event)
+		if err != nil {
+			return nil, err
+		}
+
+		results = append(results, &domain.LendingSearchResult{
+			Group:   groupByID[*h.event.groupID],
+			Lending: lending,
+		})
+	}
+
+	return results, nil
+}
+
+// Update 立て替えを更新する
+func (lr *LendingRepositoryImpl) Update(ctx context.Context, l *domain.Lending) error {
+	lr.s.mu.Lock()
+	defer lr.s.mu.Unlock()
+
+	// イベント情報を更新 (読み込み時のバージョンから変わっていない場合のみ)
+	event, ok := lr.s.events[l.ID()]
+	if !ok || event.version != l.Version()-1 {
+		return domain.NewStaleVersionError("lending")
+	}
+
+	event.name = l.Name()
+	event.amount = l.Amount()
+	event.eventDate = l.EventDate()
+	event.dueDate = l.DueDate()
+	event.updatedAt = l.UpdatedAt()
+	event.version = l.Version()
+
+	// 既存の支払いを削除して支払いマトリクスを作り直す
+	lr.s.deletePayments(func(p *paymentRow) bool {
+		return p.eventID != nil && *p.eventID == l.ID()
+	})
+	lr.s.createAllocationPayments(l)
+
+	return nil
+}
+
+// Delete 立て替えを削除する
+func (lr *LendingRepositoryImpl) Delete(ctx context.Context, id ulid.ULID) error {
+	lr.s.mu.Lock()
+	defer lr.s.mu.Unlock()
+
+	lr.s.deletePayments(func(p *paymentRow) bool {
+		return p.eventID != nil && *p.eventID == id
+	})
+	lr.s.deleteEvent(id)
+
+	return nil
+}
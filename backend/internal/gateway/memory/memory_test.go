@@ -0,0 +1,25 @@
+package memory_test
+
+import (
+	"testing"
+
+	"github.com/haebeal/datti/internal/gateway/memory"
+	"github.com/haebeal/datti/internal/gateway/repositorytest"
+)
+
+func TestRepositories(t *testing.T) {
+	repositorytest.Run(t, func(t *testing.T) repositorytest.Repositories {
+		s := memory.NewStore()
+		return repositorytest.Repositories{
+			User:           memory.NewUserRepository(s),
+			Guest:          memory.NewGuestRepository(s),
+			Contact:        memory.NewContactRepository(s),
+			Credit:         memory.NewCreditRepository(s),
+			Lending:        memory.NewLendingRepository(s),
+			Group:          memory.NewGroupRepository(s),
+			Repayment:      memory.NewRepaymentRepository(s),
+			Comment:        memory.NewCommentRepository(s),
+			IdempotencyKey: memory.NewIdempotencyKeyRepository(s),
+		}
+	})
+}
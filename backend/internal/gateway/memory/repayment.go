@@ -0,0 +1,146 @@
+package memory
+
+import (
+	"context"
+	"slices"
+
+	"github.com/haebeal/datti/internal/domain"
+	"github.com/oklog/ulid/v2"
+)
+
+// RepaymentRepositoryImpl 返済リポジトリのメモリ上の実装
+// 返済は立て替えに紐づかない支払いとして保存する
+type RepaymentRepositoryImpl struct {
+	s *Store
+}
+
+// NewRepaymentRepository RepaymentRepositoryImplのファクトリ関数
+func NewRepaymentRepository(s *Store) *RepaymentRepositoryImpl {
+	return &RepaymentRepositoryImpl{
+		s: s,
+	}
+}
+
+// Create 返済を作成する
+func (rr *RepaymentRepositoryImpl) Create(ctx context.Context, r *domain.Repayment) error {
+	rr.s.mu.Lock()
+	defer rr.s.mu.Unlock()
+
+	if rr.s.findPayment(r.ID()) != nil {
+		return errDuplicate("payments", r.ID().String())
+	}
+
+	rr.s.payments = append(rr.s.payments, &paymentRow{
+		id:        r.ID(),
+		payerID:   r.PayerID(),
+		debtorID:  r.DebtorID(),
+		amount:    r.Amount(),
+		createdAt: r.CreatedAt(),
+		updatedAt: r.UpdatedAt(),
+		version:   1,
+	})
+
+	return nil
+}
+
+// FindByID 返済をIDで取得する
+func (rr *RepaymentRepositoryImpl) FindByID(ctx context.Context, id ulid.ULID) (*domain.Repayment, error) {
+	rr.s.mu.Lock()
+	defer rr.s.mu.Unlock()
+
+	p := rr.s.findPayment(id)
+	if p == nil || p.eventID != nil {
+		return nil, domain.NewNotFoundError("repayment", id.String())
+	}
+
+	return toRepayment(ctx, p)
+}
+
+// FindByPayerID 支払い者IDで返済一覧を新しい順に取得する
+func (rr *RepaymentRepositoryImpl) FindByPayerID(ctx context.Context, payerID string, cursor *string, limit *int32) ([]*domain.Repayment, error) {
+	rr.s.mu.Lock()
+	defer rr.s.mu.Unlock()
+
+	return rr.findRepayments(ctx, cursor, *limit, func(p *paymentRow) bool {
+		return p.payerID == payerID
+	})
+}
+
+// FindByDebtorID 受取人IDで返済一覧を新しい順に取得する
+func (rr *RepaymentRepositoryImpl) FindByDebtorID(ctx context.Context, debtorID string, cursor *string, limit *int32) ([]*domain.Repayment, error) {
+	rr.s.mu.Lock()
+	defer rr.s.mu.Unlock()
+
+	return rr.findRepayments(ctx, cursor, *limit, func(p *paymentRow) bool {
+		return p.debtorID == debtorID
+	})
+}
+
+// Update 返済を更新する
+func (rr *RepaymentRepositoryImpl) Update(ctx context.Context, r *domain.Repayment) error {
+	rr.s.mu.Lock()
+	defer rr.s.mu.Unlock()
+
+	// 読み込み時のバージョンから変わっていない場合のみ更新する
+	p := rr.s.findPayment(r.ID())
+	if p == nil || p.version != r.Version()-1 {
+		return domain.NewStaleVersionError("repayment")
+	}
+
+	p.amount = r.Amount()
+	p.updatedAt = r.UpdatedAt()
+	p.version = r.Version()
+
+	return nil
+}
+
+// Delete 返済を削除する
+func (rr *RepaymentRepositoryImpl) Delete(ctx context.Context, id ulid.ULID) error {
+	rr.s.mu.Lock()
+	defer rr.s.mu.Unlock()
+
+	rr.s.deletePayments(func(p *paymentRow) bool {
+		return p.id == id
+	})
+
+	return nil
+}
+
+// findRepayments 条件に一致する返済をIDの降順にカーソル以降からlimit件取得する (ロックを取得した状態で呼び出す)
+func (rr *RepaymentRepositoryImpl) findRepayments(ctx context.Context, cursor *string, limit int32, match func(p *paymentRow) bool) ([]*domain.Repayment, error) {
+	var rows []*paymentRow
+	for _, p := range rr.s.payments {
+		if p.eventID == nil && match(p) && afterCursor(p.id, cursor) {
+			rows = append(rows, p)
+		}
+	}
+	slices.SortFunc(rows, func(a, b *paymentRow) int {
+		return b.id.Compare(a.id)
+	})
+	rows = limitRows(rows, limit)
+
+	repayments := make([]*domain.Repayment, 0, len(rows))
+	for _, p := range rows {
+		repayment, err := toRepayment(ctx, p)
+		if err != nil {
+			return nil, err
+		}
+		repayments = append(repayments, repayment)
+	}
+
+	return repayments, nil
+}
+
+// findPayment IDで支払いを取得する (ロックを取得した状態で呼び出す)
+func (s *Store) findPayment(id ulid.ULID) *paymentRow {
+	for _, p := range s.payments {
+		if p.id == id {
+			return p
+		}
+	}
+	return nil
+}
+
+func toRepayment(ctx context.Context, p *paymentRow) (*domain.Repayment, error) {
+	return domain.NewRepayment(ctx, p.id, p.payerID, p.debtorID, p.amount, p.createdAt, p.updatedAt, p.version)
+}
@@ -0,0 +1,394 @@
+// Package memory データベースを使わずにプロセスのメモリにデータを保存するリポジトリの実装
+//
+// デモやフロントエンドの開発でPostgresを用意せずにサーバーを起動するためのもので、再起動するとデータは失われる。
+// 各メソッドはStoreのロックを取得した状態で実行するため、Postgres実装でトランザクション内で実行する処理と同じく
+// 途中の状態が他のリクエストから見えることはない。
+package memory
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/haebeal/datti/internal/domain"
+	"github.com/oklog/ulid/v2"
+)
+
+// Store 全てのリポジトリが共有するデータ
+// Postgresのテーブルと同じ単位で保持し、外部キーのON DELETE/ON UPDATE CASCADEも同じように反映する
+type Store struct {
+	mu sync.Mutex
+	// seq 作成順の連番 (作成日時が同じ行の順序を決めるために使用する)
+	seq int64
+
+	users           map[string]*userRow
+	groups          map[ulid.ULID]*groupRow
+	members         []*memberRow
+	events          map[ulid.ULID]*eventRow
+	payments        []*paymentRow
+	contacts        []*contactRow
+	comments        map[ulid.ULID]*commentRow
+	idempotencyKeys map[idempotencyKeyID]*idempotencyKeyRow
+}
+
+// NewStore 空のStoreを作成する
+func NewStore() *Store {
+	return &Store{
+		users:           make(map[string]*userRow),
+		groups:          make(map[ulid.ULID]*groupRow),
+		events:          make(map[ulid.ULID]*eventRow),
+		comments:        make(map[ulid.ULID]*commentRow),
+		idempotencyKeys: make(map[idempotencyKeyID]*idempotencyKeyRow),
+	}
+}
+
+// userRow usersテーブルの行 (ゲストと退会済みのユーザーを含む)
+type userRow struct {
+	id                  string
+	name                string
+	avatar              string
+	email               string
+	guestGroupID        *ulid.ULID
+	inviteToken         string
+	discoverableByEmail bool
+	deleted             bool
+	createdAt           time.Time
+	seq                 int64
+}
+
+// groupRow groupsテーブルの行
+type groupRow struct {
+	id               ulid.ULID
+	name             string
+	createdBy        string
+	paymentTermsDays *int32
+	createdAt        time.Time
+	updatedAt        time.Time
+	version          int32
+	seq              int64
+}
+
+// memberRow group_membersテーブルの行
+type memberRow struct {
+	groupID   ulid.ULID
+	userID    string
+	createdAt time.Time
+	seq       int64
+}
+
+// eventRow eventsテーブルの行 (個人間の立て替えはgroupIDがnil)
+type eventRow struct {
+	id        ulid.ULID
+	groupID   *ulid.ULID
+	name      string
+	amount    int64
+	eventDate time.Time
+	dueDate   *time.Time
+	createdAt time.Time
+	updatedAt time.Time
+	createdBy string
+	version   int32
+}
+
+// paymentRow paymentsテーブルの行
+// event_paymentsで立て替えに紐づく支払いはeventIDを持ち、紐づかない支払いは返済を表す
+type paymentRow struct {
+	id        ulid.ULID
+	eventID   *ulid.ULID
+	payerID   string
+	debtorID  string
+	amount    int64
+	createdAt time.Time
+	updatedAt time.Time
+	version   int32
+}
+
+// contactRow contactsテーブルの行
+type contactRow struct {
+	userID    string
+	contactID string
+	createdAt time.Time
+}
+
+// commentRow lending_commentsテーブルの行
+type commentRow struct {
+	id        ulid.ULID
+	eventID   ulid.ULID
+	userID    string
+	body      string
+	createdAt time.Time
+	updatedAt time.Time
+}
+
+// idempotencyKeyID idempotency_keysテーブルの主キー
+type idempotencyKeyID struct {
+	userID string
+	key    string
+}
+
+// idempotencyKeyRow idempotency_keysテーブルの行 (statusCodeが0の行は処理中を表す)
+type idempotencyKeyRow struct {
+	userID       string
+	key          string
+	method       string
+	path         string
+	requestHash  string
+	statusCode   int
+	contentType  string
+	responseBody []byte
+	createdAt    time.Time
+}
+
+// nextSeq 作成順の連番を採番する (ロックを取得した状態で呼び出す)
+func (s *Store) nextSeq() int64 {
+	s.seq++
+	return s.seq
+}
+
+// errDuplicate 主キーが重複する行を作成しようとした場合のエラー
+func errDuplicate(table string, key string) error {
+	return fmt.Errorf("memory: %sに%sは既に存在します", table, key)
+}
+
+// toUser ユーザーの行をドメインエンティティに変換する
+func toUser(ctx context.Context, row *userRow) (*domain.User, error) {
+	if row.guestGroupID != nil {
+		return domain.NewGuestUser(ctx, row.id, row.name, row.email)
+	}
+	return domain.NewUser(ctx, row.id, row.name, row.avatar, row.email)
+}
+
+// toGroup グループの行をドメインエンティティに変換する
+func toGroup(ctx context.Context, row *groupRow) (*domain.Group, error) {
+	return domain.NewGroup(ctx, row.id, row.name, row.createdBy, row.paymentTermsDays, row.createdAt, row.updatedAt, row.version)
+}
+
+// isMember ユーザーがグループのメンバーかどうか (ロックを取得した状態で呼び出す)
+func (s *Store) isMember(groupID ulid.ULID, userID string) bool {
+	return slices.ContainsFunc(s.members, func(m *memberRow) bool {
+		return m.groupID == groupID && m.userID == userID
+	})
+}
+
+// sharesGroup 2人のユーザーが同じグループに所属しているかどうか (ロックを取得した状態で呼び出す)
+func (s *Store) sharesGroup(userID string, otherUserID string) bool {
+	for _, m := range s.members {
+		if m.userID == userID && s.isMember(m.groupID, otherUserID) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasContact userIDのユーザーがcontactIDのユーザーを連絡先に追加しているかどうか (ロックを取得した状態で呼び出す)
+func (s *Store) hasContact(userID string, contactID string) bool {
+	return slices.ContainsFunc(s.contacts, func(c *contactRow) bool {
+		return c.userID == userID && c.contactID == contactID
+	})
+}
+
+// contactCandidates 連絡先として扱う登録ユーザーを名前順に取得する (ロックを取得した状態で呼び出す)
+// 明示的に追加したユーザーと、同じグループに所属するユーザーが対象
+func (s *Store) contactCandidates(userID string) []*userRow {
+	var rows []*userRow
+	for _, u := range s.users {
+		if u.deleted || u.guestGroupID != nil || u.id == userID {
+			continue
+		}
+		if s.hasContact(userID, u.id) || s.sharesGroup(userID, u.id) {
+			rows = append(rows, u)
+		}
+	}
+
+	slices.SortFunc(rows, func(a, b *userRow) int {
+		return cmp.Or(cmp.Compare(a.name, b.name), cmp.Compare(a.id, b.id))
+	})
+	return rows
+}
+
+// deleteUser ユーザーを削除し、グループ所属と連絡先を削除する (ロックを取得した状態で呼び出す)
+func (s *Store) deleteUser(id string) {
+	delete(s.users, id)
+	s.members = slices.DeleteFunc(s.members, func(m *memberRow) bool {
+		return m.userID == id
+	})
+	s.contacts = slices.DeleteFunc(s.contacts, func(c *contactRow) bool {
+		return c.userID == id || c.contactID == id
+	})
+}
+
+// renameUser ユーザーIDを変更し、参照しているグループや支払いなども付け替える (ロックを取得した状態で呼び出す)
+func (s *Store) renameUser(fromID string, toID string) error {
+	u, ok := s.users[fromID]
+	if !ok {
+		return nil
+	}
+	if _, exists := s.users[toID]; exists {
+		return errDuplicate("users", toID)
+	}
+
+	delete(s.users, fromID)
+	u.id = toID
+	s.users[toID] = u
+
+	for _, g := range s.groups {
+		if g.createdBy == fromID {
+			g.createdBy = toID
+		}
+	}
+	for _, m := range s.members {
+		if m.userID == fromID {
+			m.userID = toID
+		}
+	}
+	for _, e := range s.events {
+		if e.createdBy == fromID {
+			e.createdBy = toID
+		}
+	}
+	for _, p := range s.payments {
+		if p.payerID == fromID {
+			p.payerID = toID
+		}
+		if p.debtorID == fromID {
+			p.debtorID = toID
+		}
+	}
+	for _, c := range s.contacts {
+		if c.userID == fromID {
+			c.userID = toID
+		}
+		if c.contactID == fromID {
+			c.contactID = toID
+		}
+	}
+	for _, c := range s.comments {
+		if c.userID == fromID {
+			c.userID = toID
+		}
+	}
+
+	return nil
+}
+
+// mergeUser fromIDのグループ所属や支払いをintoIDに付け替えてfromIDのユーザーを削除する (ロックを取得した状態で呼び出す)
+// ユーザーの統合とゲストの引き継ぎで共通して使用する
+func (s *Store) mergeUser(fromID string, intoID string) {
+	now := time.Now()
+
+	// 両方が所属しているグループは重複させない
+	for _, m := range s.members {
+		if m.userID == fromID && !s.isMember(m.groupID, intoID) {
+			s.members = append(s.members, &memberRow{groupID: m.groupID, userID: intoID, createdAt: m.createdAt, seq: m.seq})
+		}
+	}
+
+	for _, g := range s.groups {
+		if g.createdBy == fromID {
+			g.createdBy = intoID
+		}
+	}
+	for _, e := range s.events {
+		if e.createdBy == fromID {
+			e.createdBy = intoID
+		}
+	}
+
+	// 立て替えの支払いは付け替えると支払い者自身の負担分になる
+	for _, p := range s.payments {
+		if p.payerID == fromID {
+			p.payerID = intoID
+			p.updatedAt = now
+		}
+		if p.debtorID == fromID {
+			p.debtorID = intoID
+			p.updatedAt = now
+		}
+	}
+
+	// 統合した2人の間の返済は意味を持たないため削除する
+	s.payments = slices.DeleteFunc(s.payments, func(p *paymentRow) bool {
+		return p.eventID == nil && p.payerID == intoID && p.debtorID == intoID
+	})
+
+	// 連絡先は両方向とも付け替え、重複するものは統合元と一緒に削除される
+	for _, c := range s.contacts {
+		if c.userID == fromID && c.contactID != intoID && !s.hasContact(intoID, c.contactID) {
+			s.contacts = append(s.contacts, &contactRow{userID: intoID, contactID: c.contactID, createdAt: c.createdAt})
+		}
+		if c.contactID == fromID && c.userID != intoID && !s.hasContact(c.userID, intoID) {
+			s.contacts = append(s.contacts, &contactRow{userID: c.userID, contactID: intoID, createdAt: c.createdAt})
+		}
+	}
+
+	for _, c := range s.comments {
+		if c.userID == fromID {
+			c.userID = intoID
+		}
+	}
+
+	s.deleteIdempotencyKeysByUserID(fromID)
+	s.deleteUser(fromID)
+}
+
+// deleteIdempotencyKeysByUserID ユーザーの冪等性キーを削除する (ロックを取得した状態で呼び出す)
+func (s *Store) deleteIdempotencyKeysByUserID(userID string) {
+	for id := range s.idempotencyKeys {
+		if id.userID == userID {
+			delete(s.idempotencyKeys, id)
+		}
+	}
+}
+
+// deleteEvent イベントとコメントを削除する (ロックを取得した状態で呼び出す)
+// 支払いは削除しないため、先にdeletePaymentsで削除する
+func (s *Store) deleteEvent(id ulid.ULID) {
+	delete(s.events, id)
+	for commentID, c := range s.comments {
+		if c.eventID == id {
+			delete(s.comments, commentID)
+		}
+	}
+}
+
+// deletePayments 条件に一致する支払いを削除する (ロックを取得した状態で呼び出す)
+func (s *Store) deletePayments(del func(p *paymentRow) bool) {
+	s.payments = slices.DeleteFunc(s.payments, del)
+}
+
+// eventPayments イベントに紐づく支払いを作成順に取得する (ロックを取得した状態で呼び出す)
+func (s *Store) eventPayments(eventID ulid.ULID) []*paymentRow {
+	var payments []*paymentRow
+	for _, p := range s.payments {
+		if p.eventID != nil && *p.eventID == eventID {
+			payments = append(payments, p)
+		}
+	}
+	return payments
+}
+
+// involves ユーザーがイベントの支払い者または債務者かどうか (ロックを取得した状態で呼び出す)
+func (s *Store) involves(eventID ulid.ULID, userID string) bool {
+	return slices.ContainsFunc(s.eventPayments(eventID), func(p *paymentRow) bool {
+		return p.payerID == userID || p.debtorID == userID
+	})
+}
+
+// afterCursor IDの降順のページネーションでカーソルより後ろにあるかどうか
+func afterCursor(id ulid.ULID, cursor *string) bool {
+	return cursor == nil || id.String() < *cursor
+}
+
+// limitRows 先頭からlimit件を取得する
+func limitRows[T any](rows []T, limit int32) []T {
+	if limit < 0 {
+		limit = 0
+	}
+	if int(limit) < len(rows) {
+		return rows[:limit]
+	}
+	return rows
+}
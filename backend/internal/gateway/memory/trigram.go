@@ -0,0 +1,56 @@
+package memory
+
+import (
+	"strings"
+	"unicode"
+)
+
+// wordSimilarityThreshold pg_trgmのpg_trgm.word_similarity_thresholdの既定値
+const wordSimilarityThreshold = 0.6
+
+// trigrams pg_trgmと同じ方法で文字列のトライグラムの集合を求める
+// 英数字以外で区切った単語ごとに小文字にして前に2つ、後ろに1つ空白を付け、3文字ずつ取り出す
+func trigrams(s string) map[string]struct{} {
+	set := make(map[string]struct{})
+	words := strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for _, word := range words {
+		runes := []rune("  " + word + " ")
+		for i := 0; i+3 <= len(runes); i++ {
+			set[string(runes[i:i+3])] = struct{}{}
+		}
+	}
+	return set
+}
+
+// trigramSimilarity pg_trgmのsimilarity関数に相当する類似度 (共通するトライグラムの割合)
+func trigramSimilarity(a string, b string) float64 {
+	ta, tb := trigrams(a), trigrams(b)
+	shared := countShared(ta, tb)
+	union := len(ta) + len(tb) - shared
+	if union == 0 {
+		return 0
+	}
+	return float64(shared) / float64(union)
+}
+
+// trigramWordSimilarity pg_trgmのword_similarity関数を近似した類似度
+// queryのトライグラムのうちtextに含まれるものの割合で、textの一部分にqueryが似ているほど1に近づく
+func trigramWordSimilarity(query string, text string) float64 {
+	tq := trigrams(query)
+	if len(tq) == 0 {
+		return 0
+	}
+	return float64(countShared(tq, trigrams(text))) / float64(len(tq))
+}
+
+func countShared(a map[string]struct{}, b map[string]struct{}) int {
+	var n int
+	for t := range a {
+		if _, ok := b[t]; ok {
+			n++
+		}
+	}
+	return n
+}
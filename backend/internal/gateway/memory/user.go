@@ -0,0 +1,245 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/haebeal/datti/internal/domain"
+)
+
+// UserRepositoryImpl ユーザーリポジトリのメモリ上の実装
+type UserRepositoryImpl struct {
+	s *Store
+}
+
+// NewUserRepository UserRepositoryImplのファクトリ関数
+func NewUserRepository(s *Store) *UserRepositoryImpl {
+	return &UserRepositoryImpl{
+		s: s,
+	}
+}
+
+// Create ユーザーを作成する
+func (ur *UserRepositoryImpl) Create(ctx context.Context, u *domain.User) error {
+	ur.s.mu.Lock()
+	defer ur.s.mu.Unlock()
+
+	if _, exists := ur.s.users[u.ID()]; exists {
+		return errDuplicate("users", u.ID())
+	}
+
+	ur.s.users[u.ID()] = &userRow{
+		id:                  u.ID(),
+		name:                u.Name(),
+		avatar:              u.Avatar(),
+		email:               u.Email(),
+		discoverableByEmail: true,
+		createdAt:           time.Now(),
+		seq:                 ur.s.nextSeq(),
+	}
+
+	return nil
+}
+
+// FindByID IDでユーザーを取得する (ゲストと退会済みのユーザーを含む)
+func (ur *UserRepositoryImpl) FindByID(ctx context.Context, id string) (*domain.User, error) {
+	ur.s.mu.Lock()
+	defer ur.s.mu.Unlock()
+
+	row, ok := ur.s.users[id]
+	if !ok {
+		return nil, domain.NewNotFoundError("user", id)
+	}
+
+	return toUser(ctx, row)
+}
+
+// FindByIDs IDで複数のユーザーをまとめてID順に取得する (存在しないIDのユーザーは含まない)
+func (ur *UserRepositoryImpl) FindByIDs(ctx context.Context, ids []string) ([]*domain.User, error) {
+	ur.s.mu.Lock()
+	defer ur.s.mu.Unlock()
+
+	ids = slices.Compact(slices.Sorted(slices.Values(ids)))
+	users := make([]*domain.User, 0, len(ids))
+	for _, id := range ids {
+		row, ok := ur.s.users[id]
+		if !ok {
+			continue
+		}
+		user, err := toUser(ctx, row)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, user)
+	}
+
+	return users, nil
+}
+
+// FindByEmail メールアドレスでユーザーを取得する (ゲストを除く)
+func (ur *UserRepositoryImpl) FindByEmail(ctx context.Context, email string) (*domain.User, error) {
+	ur.s.mu.Lock()
+	defer ur.s.mu.Unlock()
+
+	for _, row := range ur.s.users {
+		if row.email == email && row.guestGroupID == nil {
+			return toUser(ctx, row)
+		}
+	}
+
+	return nil, domain.NewNotFoundError("user", email)
+}
+
+// FindByQuery 検索条件で連絡先のユーザー一覧を名前順に取得する
+// 名前とメールアドレスは大文字と小文字を区別せずに部分一致で検索する
+func (ur *UserRepositoryImpl) FindByQuery(ctx context.Context, query domain.UserSearchQuery) ([]*domain.User, error) {
+	ur.s.mu.Lock()
+	defer ur.s.mu.Unlock()
+
+	var rows []*userRow
+	for _, row := range ur.s.contactCandidates(query.UserID) {
+		if (query.Name != nil && containsFold(row.name, *query.Name)) ||
+			(query.Email != nil && containsFold(row.email, *query.Email)) {
+			rows = append(rows, row)
+		}
+	}
+
+	rows = limitRows(rows, query.Limit)
+	users := make([]*domain.User, 0, len(rows))
+	for _, row := range rows {
+		user, err := domain.NewUser(ctx, row.id, row.name, row.avatar, row.email)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, user)
+	}
+
+	return users, nil
+}
+
+// FindDiscoverableByEmail メールアドレスでの検索を許可しているユーザーをメールアドレスの完全一致で取得する
+func (ur *UserRepositoryImpl) FindDiscoverableByEmail(ctx context.Context, email string) (*domain.User, error) {
+	ur.s.mu.Lock()
+	defer ur.s.mu.Unlock()
+
+	for _, row := range ur.s.users {
+		if strings.EqualFold(row.email, email) && !row.deleted && row.guestGroupID == nil && row.discoverableByEmail {
+			return domain.NewUser(ctx, row.id, row.name, row.avatar, row.email)
+		}
+	}
+
+	return nil, domain.NewNotFoundError("user", email)
+}
+
+// FindPrivacy ユーザーのプライバシー設定を取得する
+func (ur *UserRepositoryImpl) FindPrivacy(ctx context.Context, id string) (*domain.UserPrivacy, error) {
+	ur.s.mu.Lock()
+	defer ur.s.mu.Unlock()
+
+	row, ok := ur.s.users[id]
+	if !ok {
+		return nil, domain.NewNotFoundError("user", id)
+	}
+
+	return domain.NewUserPrivacy(row.discoverableByEmail), nil
+}
+
+// UpdatePrivacy ユーザーのプライバシー設定を更新する
+func (ur *UserRepositoryImpl) UpdatePrivacy(ctx context.Context, id string, p *domain.UserPrivacy) error {
+	ur.s.mu.Lock()
+	defer ur.s.mu.Unlock()
+
+	if row, ok := ur.s.users[id]; ok {
+		row.discoverableByEmail = p.DiscoverableByEmail()
+	}
+
+	return nil
+}
+
+// Update ユーザーを更新する
+func (ur *UserRepositoryImpl) Update(ctx context.Context, u *domain.User) error {
+	ur.s.mu.Lock()
+	defer ur.s.mu.Unlock()
+
+	if row, ok := ur.s.users[u.ID()]; ok {
+		row.name = u.Name()
+		row.avatar = u.Avatar()
+	}
+
+	return nil
+}
+
+// UpdateID ユーザーIDを更新する
+// 支払いやグループの参照も新しいIDに付け替える
+func (ur *UserRepositoryImpl) UpdateID(ctx context.Context, oldID, newID string) error {
+	ur.s.mu.Lock()
+	defer ur.s.mu.Unlock()
+
+	return ur.s.renameUser(oldID, newID)
+}
+
+// Anonymize ユーザーをanonymizedの内容で匿名化し、グループから脱退させる
+func (ur *UserRepositoryImpl) Anonymize(ctx context.Context, u *domain.User, anonymized *domain.User) error {
+	ur.s.mu.Lock()
+	defer ur.s.mu.Unlock()
+
+	for _, amount := range ur.s.balances(u.ID()) {
+		if amount != 0 {
+			return domain.NewConflictError("credit", "未精算の貸し借りがあるため退会できません")
+		}
+	}
+
+	// 作成したグループは最も古くから所属しているメンバーに引き継ぐ
+	for _, g := range ur.s.groups {
+		if g.createdBy != u.ID() {
+			continue
+		}
+		successor := ur.s.successor(g.id, u.ID())
+		if successor == "" {
+			// 他にメンバーがいないグループも脱退したメンバーとの立て替えの記録を残すため、
+			// 匿名化したユーザーを作成者としたまま残す
+			continue
+		}
+		g.createdBy = successor
+		g.updatedAt = time.Now()
+		g.version++
+	}
+
+	if err := ur.s.renameUser(u.ID(), anonymized.ID()); err != nil {
+		return err
+	}
+	if row, ok := ur.s.users[anonymized.ID()]; ok {
+		row.name = anonymized.Name()
+		row.avatar = anonymized.Avatar()
+		row.email = anonymized.Email()
+		row.deleted = true
+	}
+
+	// RemoveMemberと同様に、他のメンバーとの立て替えの記録を残すため支払いは削除しない
+	ur.s.members = slices.DeleteFunc(ur.s.members, func(m *memberRow) bool {
+		return m.userID == anonymized.ID()
+	})
+	ur.s.contacts = slices.DeleteFunc(ur.s.contacts, func(c *contactRow) bool {
+		return c.userID == anonymized.ID() || c.contactID == anonymized.ID()
+	})
+	ur.s.deleteIdempotencyKeysByUserID(u.ID())
+
+	return nil
+}
+
+// Merge fromのグループ所属や支払いをintoに付け替えてfromを削除する
+func (ur *UserRepositoryImpl) Merge(ctx context.Context, from, into *domain.User) error {
+	ur.s.mu.Lock()
+	defer ur.s.mu.Unlock()
+
+	ur.s.mergeUser(from.ID(), into.ID())
+
+	return nil
+}
+
+// containsFold sにsubstrが大文字と小文字を区別せずに含まれるかどうか (ILIKE '%substr%'に相当)
+func containsFold(s string, substr string) bool {
+	return strings.Contains(strings.ToLower(s), strings.ToLower(substr))
+}
@@ -0,0 +1,125 @@
+package migration_test
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/haebeal/datti/internal/gateway/migration"
+	"github.com/haebeal/datti/sql/migrations"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+func TestLoad(t *testing.T) {
+	ms, err := migration.Load(migrations.FS)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(ms) == 0 {
+		t.Fatal("Load() returned no migrations")
+	}
+
+	for i, m := range ms {
+		if want := int64(i + 1); m.Version != want {
+			t.Errorf("migrations[%d].Version = %d, want %d", i, m.Version, want)
+		}
+	}
+}
+
+// TestMigrateUpDown 空のデータベースに最新までマイグレーションを適用し、全て取り消せることを確認する
+// TEST_POSTGRES_DSNに空のデータベースを指定した場合のみ実行する
+func TestMigrateUpDown(t *testing.T) {
+	dsn, ok := os.LookupEnv("TEST_POSTGRES_DSN")
+	if !ok {
+		t.Skip("TEST_POSTGRES_DSN is not set")
+	}
+
+	ctx := context.Background()
+	pool, err := pgxpool.New(ctx, dsn)
+	if err != nil {
+		t.Fatalf("pgxpool.New() error = %v", err)
+	}
+	defer pool.Close()
+
+	if tables := userTables(t, pool); len(tables) > 0 {
+		t.Fatalf("database is not empty: %v", tables)
+	}
+
+	m, err := migration.NewMigrator(pool, migrations.FS)
+	if err != nil {
+		t.Fatalf("NewMigrator() error = %v", err)
+	}
+	all, err := migration.Load(migrations.FS)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	applied, err := m.Up(ctx)
+	if err != nil {
+		t.Fatalf("Up() error = %v", err)
+	}
+	if len(applied) != len(all) {
+		t.Errorf("Up() applied %d migrations, want %d", len(applied), len(all))
+	}
+
+	// 適用済みの場合は何もしない
+	applied, err = m.Up(ctx)
+	if err != nil {
+		t.Fatalf("second Up() error = %v", err)
+	}
+	if len(applied) != 0 {
+		t.Errorf("second Up() applied %d migrations, want 0", len(applied))
+	}
+
+	statuses, err := m.Status(ctx)
+	if err != nil {
+		t.Fatalf("Status() error = %v", err)
+	}
+	for _, s := range statuses {
+		if s.AppliedAt == nil {
+			t.Errorf("migration %d_%s is not applied", s.Version, s.Name)
+		}
+	}
+
+	reverted, err := m.Down(ctx, 0)
+	if err != nil {
+		t.Fatalf("Down() error = %v", err)
+	}
+	if len(reverted) != len(all) {
+		t.Errorf("Down() reverted %d migrations, want %d", len(reverted), len(all))
+	}
+
+	// schema_migrations以外のテーブルが残っていないこと
+	tables := userTables(t, pool)
+	if len(tables) != 1 || tables[0] != "schema_migrations" {
+		t.Errorf("tables after Down() = %v, want [schema_migrations]", tables)
+	}
+
+	if _, err := pool.Exec(ctx, "DROP TABLE schema_migrations"); err != nil {
+		t.Fatalf("failed to drop schema_migrations: %v", err)
+	}
+}
+
+func userTables(t *testing.T, pool *pgxpool.Pool) []string {
+	t.Helper()
+
+	rows, err := pool.Query(context.Background(), "SELECT tablename FROM pg_tables WHERE schemaname = 'public' ORDER BY tablename")
+	if err != nil {
+		t.Fatalf("failed to list tables: %v", err)
+	}
+	defer rows.Close()
+
+	var tables []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			t.Fatalf("failed to scan table name: %v", err)
+		}
+		tables = append(tables, name)
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatalf("failed to list tables: %v", err)
+	}
+
+	return tables
+}
@@ -8,14 +8,29 @@ import (
 	"time"
 )
 
+type Balance struct {
+	UserID      string
+	OtherUserID string
+	Amount      int64
+}
+
+type Contact struct {
+	UserID    string
+	ContactID string
+	CreatedAt time.Time
+}
+
 type Event struct {
 	ID        string
-	GroupID   string
+	GroupID   *string
 	Name      string
 	Amount    int32
 	EventDate time.Time
 	CreatedAt time.Time
 	UpdatedAt time.Time
+	DueDate   *time.Time
+	CreatedBy *string
+	Version   int32
 }
 
 type EventPayment struct {
@@ -24,11 +39,13 @@ type EventPayment struct {
 }
 
 type Group struct {
-	ID        string
-	Name      string
-	CreatedBy string
-	CreatedAt time.Time
-	UpdatedAt time.Time
+	ID               string
+	Name             string
+	CreatedBy        string
+	CreatedAt        time.Time
+	UpdatedAt        time.Time
+	PaymentTermsDays *int32
+	Version          int32
 }
 
 type GroupMember struct {
@@ -37,6 +54,27 @@ type GroupMember struct {
 	CreatedAt time.Time
 }
 
+type IdempotencyKey struct {
+	UserID       string
+	Key          string
+	Method       string
+	Path         string
+	RequestHash  string
+	StatusCode   *int32
+	ContentType  *string
+	ResponseBody []byte
+	CreatedAt    time.Time
+}
+
+type LendingComment struct {
+	ID        string
+	EventID   string
+	UserID    string
+	Body      string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
 type Payment struct {
 	ID        string
 	PayerID   string
@@ -44,13 +82,18 @@ type Payment struct {
 	Amount    int32
 	CreatedAt time.Time
 	UpdatedAt time.Time
+	Version   int32
 }
 
 type User struct {
-	ID        string
-	Name      string
-	Avatar    string
-	Email     string
-	CreatedAt time.Time
-	UpdatedAt time.Time
+	ID                  string
+	Name                string
+	Avatar              string
+	Email               string
+	CreatedAt           time.Time
+	UpdatedAt           time.Time
+	DeletedAt           *time.Time
+	GuestGroupID        *string
+	InviteToken         *string
+	DiscoverableByEmail bool
 }
@@ -10,6 +10,21 @@ import (
 	"time"
 )
 
+const addContact = `-- name: AddContact :exec
+INSERT INTO contacts (user_id, contact_id, created_at)
+VALUES ($1, $2, current_timestamp)
+`
+
+type AddContactParams struct {
+	UserID    string
+	ContactID string
+}
+
+func (q *Queries) AddContact(ctx context.Context, arg AddContactParams) error {
+	_, err := q.db.Exec(ctx, addContact, arg.UserID, arg.ContactID)
+	return err
+}
+
 const addGroupMember = `-- name: AddGroupMember :exec
 INSERT INTO group_members (group_id, user_id, created_at)
 VALUES ($1, $2, current_timestamp)
@@ -25,19 +40,83 @@ func (q *Queries) AddGroupMember(ctx context.Context, arg AddGroupMemberParams)
 	return err
 }
 
+const anonymizeUser = `-- name: AnonymizeUser :exec
+UPDATE users
+SET id = $1,
+    name = $2,
+    avatar = $3,
+    email = $4,
+    deleted_at = current_timestamp,
+    updated_at = current_timestamp
+WHERE id = $5
+`
+
+type AnonymizeUserParams struct {
+	NewID  string
+	Name   string
+	Avatar string
+	Email  string
+	ID     string
+}
+
+func (q *Queries) AnonymizeUser(ctx context.Context, arg AnonymizeUserParams) error {
+	_, err := q.db.Exec(ctx, anonymizeUser,
+		arg.NewID,
+		arg.Name,
+		arg.Avatar,
+		arg.Email,
+		arg.ID,
+	)
+	return err
+}
+
+const countLendingCommentsByEventIDs = `-- name: CountLendingCommentsByEventIDs :many
+SELECT event_id, COUNT(*)::int AS count
+FROM lending_comments
+WHERE event_id = ANY($1::text[])
+GROUP BY event_id
+`
+
+type CountLendingCommentsByEventIDsRow struct {
+	EventID string
+	Count   int32
+}
+
+func (q *Queries) CountLendingCommentsByEventIDs(ctx context.Context, eventIds []string) ([]CountLendingCommentsByEventIDsRow, error) {
+	rows, err := q.db.Query(ctx, countLendingCommentsByEventIDs, eventIds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []CountLendingCommentsByEventIDsRow
+	for rows.Next() {
+		var i CountLendingCommentsByEventIDsRow
+		if err := rows.Scan(&i.EventID, &i.Count); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const createEvent = `-- name: CreateEvent :exec
-INSERT INTO events (id, group_id, name, amount, event_date, created_at, updated_at)
-VALUES ($1, $2, $3, $4, $5, $6, $7)
+INSERT INTO events (id, group_id, name, amount, event_date, created_at, updated_at, created_by, due_date)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
 `
 
 type CreateEventParams struct {
 	ID        string
-	GroupID   string
+	GroupID   *string
 	Name      string
 	Amount    int32
 	EventDate time.Time
 	CreatedAt time.Time
 	UpdatedAt time.Time
+	CreatedBy *string
+	DueDate   *time.Time
 }
 
 func (q *Queries) CreateEvent(ctx context.Context, arg CreateEventParams) error {
@@ -49,36 +128,60 @@ func (q *Queries) CreateEvent(ctx context.Context, arg CreateEventParams) error
 		arg.EventDate,
 		arg.CreatedAt,
 		arg.UpdatedAt,
+		arg.CreatedBy,
+		arg.DueDate,
 	)
 	return err
 }
 
-const createEventPayment = `-- name: CreateEventPayment :exec
+const createEventPayments = `-- name: CreateEventPayments :exec
+WITH inserted AS (
+  INSERT INTO payments (id, payer_id, debtor_id, amount, created_at, updated_at)
+  SELECT
+    unnest($2::text[]),
+    unnest($3::text[]),
+    unnest($4::text[]),
+    unnest($5::int[]),
+    current_timestamp,
+    current_timestamp
+  RETURNING id
+)
 INSERT INTO event_payments (event_id, payment_id)
-VALUES ($1, $2)
+SELECT $1::text, id FROM inserted
 `
 
-type CreateEventPaymentParams struct {
+type CreateEventPaymentsParams struct {
 	EventID   string
-	PaymentID string
-}
-
-func (q *Queries) CreateEventPayment(ctx context.Context, arg CreateEventPaymentParams) error {
-	_, err := q.db.Exec(ctx, createEventPayment, arg.EventID, arg.PaymentID)
+	Ids       []string
+	PayerIds  []string
+	DebtorIds []string
+	Amounts   []int32
+}
+
+// 支払いの作成とイベントへの紐付けを1回のクエリで行う
+func (q *Queries) CreateEventPayments(ctx context.Context, arg CreateEventPaymentsParams) error {
+	_, err := q.db.Exec(ctx, createEventPayments,
+		arg.EventID,
+		arg.Ids,
+		arg.PayerIds,
+		arg.DebtorIds,
+		arg.Amounts,
+	)
 	return err
 }
 
 const createGroup = `-- name: CreateGroup :exec
-INSERT INTO groups (id, name, created_by, created_at, updated_at)
-VALUES ($1, $2, $3, $4, $5)
+INSERT INTO groups (id, name, created_by, created_at, updated_at, payment_terms_days)
+VALUES ($1, $2, $3, $4, $5, $6)
 `
 
 type CreateGroupParams struct {
-	ID        string
-	Name      string
-	CreatedBy string
-	CreatedAt time.Time
-	UpdatedAt time.Time
+	ID               string
+	Name             string
+	CreatedBy        string
+	CreatedAt        time.Time
+	UpdatedAt        time.Time
+	PaymentTermsDays *int32
 }
 
 func (q *Queries) CreateGroup(ctx context.Context, arg CreateGroupParams) error {
@@ -88,28 +191,87 @@ func (q *Queries) CreateGroup(ctx context.Context, arg CreateGroupParams) error
 		arg.CreatedBy,
 		arg.CreatedAt,
 		arg.UpdatedAt,
+		arg.PaymentTermsDays,
 	)
 	return err
 }
 
-const createPayment = `-- name: CreatePayment :exec
-INSERT INTO payments (id, payer_id, debtor_id, amount, created_at, updated_at)
-VALUES ($1, $2, $3, $4, current_timestamp, current_timestamp)
+const createGuest = `-- name: CreateGuest :exec
+INSERT INTO users (id, name, avatar, email, guest_group_id, invite_token, created_at, updated_at)
+VALUES ($1, $2, '', $3, $4, $5, current_timestamp, current_timestamp)
 `
 
-type CreatePaymentParams struct {
-	ID       string
-	PayerID  string
-	DebtorID string
-	Amount   int32
+type CreateGuestParams struct {
+	ID           string
+	Name         string
+	Email        string
+	GuestGroupID *string
+	InviteToken  *string
 }
 
-func (q *Queries) CreatePayment(ctx context.Context, arg CreatePaymentParams) error {
-	_, err := q.db.Exec(ctx, createPayment,
+func (q *Queries) CreateGuest(ctx context.Context, arg CreateGuestParams) error {
+	_, err := q.db.Exec(ctx, createGuest,
 		arg.ID,
-		arg.PayerID,
-		arg.DebtorID,
-		arg.Amount,
+		arg.Name,
+		arg.Email,
+		arg.GuestGroupID,
+		arg.InviteToken,
+	)
+	return err
+}
+
+const createIdempotencyKey = `-- name: CreateIdempotencyKey :execrows
+INSERT INTO idempotency_keys (user_id, key, method, path, request_hash, created_at)
+VALUES ($1, $2, $3, $4, $5, $6)
+ON CONFLICT (user_id, key) DO NOTHING
+`
+
+type CreateIdempotencyKeyParams struct {
+	UserID      string
+	Key         string
+	Method      string
+	Path        string
+	RequestHash string
+	CreatedAt   time.Time
+}
+
+func (q *Queries) CreateIdempotencyKey(ctx context.Context, arg CreateIdempotencyKeyParams) (int64, error) {
+	result, err := q.db.Exec(ctx, createIdempotencyKey,
+		arg.UserID,
+		arg.Key,
+		arg.Method,
+		arg.Path,
+		arg.RequestHash,
+		arg.CreatedAt,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const createLendingComment = `-- name: CreateLendingComment :exec
+INSERT INTO lending_comments (id, event_id, user_id, body, created_at, updated_at)
+VALUES ($1, $2, $3, $4, $5, $6)
+`
+
+type CreateLendingCommentParams struct {
+	ID        string
+	EventID   string
+	UserID    string
+	Body      string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func (q *Queries) CreateLendingComment(ctx context.Context, arg CreateLendingCommentParams) error {
+	_, err := q.db.Exec(ctx, createLendingComment,
+		arg.ID,
+		arg.EventID,
+		arg.UserID,
+		arg.Body,
+		arg.CreatedAt,
+		arg.UpdatedAt,
 	)
 	return err
 }
@@ -162,6 +324,41 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) error {
 	return err
 }
 
+const deleteAllBalances = `-- name: DeleteAllBalances :exec
+DELETE FROM balances
+`
+
+func (q *Queries) DeleteAllBalances(ctx context.Context) error {
+	_, err := q.db.Exec(ctx, deleteAllBalances)
+	return err
+}
+
+const deleteContact = `-- name: DeleteContact :execrows
+DELETE FROM contacts WHERE user_id = $1 AND contact_id = $2
+`
+
+type DeleteContactParams struct {
+	UserID    string
+	ContactID string
+}
+
+func (q *Queries) DeleteContact(ctx context.Context, arg DeleteContactParams) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteContact, arg.UserID, arg.ContactID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteContactsByUserID = `-- name: DeleteContactsByUserID :exec
+DELETE FROM contacts WHERE user_id = $1 OR contact_id = $1
+`
+
+func (q *Queries) DeleteContactsByUserID(ctx context.Context, userID string) error {
+	_, err := q.db.Exec(ctx, deleteContactsByUserID, userID)
+	return err
+}
+
 const deleteEvent = `-- name: DeleteEvent :exec
 DELETE FROM events WHERE id = $1
 `
@@ -185,6 +382,22 @@ func (q *Queries) DeleteEventPayment(ctx context.Context, arg DeleteEventPayment
 	return err
 }
 
+const deleteExpiredIdempotencyKey = `-- name: DeleteExpiredIdempotencyKey :exec
+DELETE FROM idempotency_keys
+WHERE user_id = $1 AND key = $2 AND created_at < $3
+`
+
+type DeleteExpiredIdempotencyKeyParams struct {
+	UserID    string
+	Key       string
+	CreatedAt time.Time
+}
+
+func (q *Queries) DeleteExpiredIdempotencyKey(ctx context.Context, arg DeleteExpiredIdempotencyKeyParams) error {
+	_, err := q.db.Exec(ctx, deleteExpiredIdempotencyKey, arg.UserID, arg.Key, arg.CreatedAt)
+	return err
+}
+
 const deleteGroup = `-- name: DeleteGroup :exec
 DELETE FROM groups
 WHERE id = $1
@@ -210,6 +423,65 @@ func (q *Queries) DeleteGroupMember(ctx context.Context, arg DeleteGroupMemberPa
 	return err
 }
 
+const deleteGroupMembersByUserID = `-- name: DeleteGroupMembersByUserID :exec
+DELETE FROM group_members WHERE user_id = $1
+`
+
+func (q *Queries) DeleteGroupMembersByUserID(ctx context.Context, userID string) error {
+	_, err := q.db.Exec(ctx, deleteGroupMembersByUserID, userID)
+	return err
+}
+
+const deleteGuestIfUnreferenced = `-- name: DeleteGuestIfUnreferenced :exec
+DELETE FROM users u
+WHERE u.id = $1
+  AND u.guest_group_id IS NOT NULL
+  AND NOT EXISTS (
+    SELECT 1 FROM payments p WHERE p.payer_id = u.id OR p.debtor_id = u.id
+  )
+`
+
+func (q *Queries) DeleteGuestIfUnreferenced(ctx context.Context, id string) error {
+	_, err := q.db.Exec(ctx, deleteGuestIfUnreferenced, id)
+	return err
+}
+
+const deleteIdempotencyKey = `-- name: DeleteIdempotencyKey :exec
+DELETE FROM idempotency_keys
+WHERE user_id = $1 AND key = $2
+`
+
+type DeleteIdempotencyKeyParams struct {
+	UserID string
+	Key    string
+}
+
+func (q *Queries) DeleteIdempotencyKey(ctx context.Context, arg DeleteIdempotencyKeyParams) error {
+	_, err := q.db.Exec(ctx, deleteIdempotencyKey, arg.UserID, arg.Key)
+	return err
+}
+
+const deleteIdempotencyKeysByUserID = `-- name: DeleteIdempotencyKeysByUserID :exec
+DELETE FROM idempotency_keys WHERE user_id = $1
+`
+
+func (q *Queries) DeleteIdempotencyKeysByUserID(ctx context.Context, userID string) error {
+	_, err := q.db.Exec(ctx, deleteIdempotencyKeysByUserID, userID)
+	return err
+}
+
+const deleteLendingComment = `-- name: DeleteLendingComment :execrows
+DELETE FROM lending_comments WHERE id = $1
+`
+
+func (q *Queries) DeleteLendingComment(ctx context.Context, id string) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteLendingComment, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
 const deletePayment = `-- name: DeletePayment :exec
 DELETE FROM payments WHERE id = $1
 `
@@ -225,48 +497,80 @@ WHERE id IN (
   SELECT ep.payment_id
   FROM event_payments ep
   INNER JOIN events e ON ep.event_id = e.id
-  WHERE e.group_id = $1
+  WHERE e.group_id = $1::text
 )
 `
 
-func (q *Queries) DeletePaymentsByGroupID(ctx context.Context, groupID string) error {
-	_, err := q.db.Exec(ctx, deletePaymentsByGroupID, groupID)
+func (q *Queries) DeletePaymentsByGroupID(ctx context.Context, dollar_1 string) error {
+	_, err := q.db.Exec(ctx, deletePaymentsByGroupID, dollar_1)
 	return err
 }
 
-const deletePaymentsByGroupIDAndUserID = `-- name: DeletePaymentsByGroupIDAndUserID :exec
-DELETE FROM payments
-WHERE id IN (
-  SELECT ep.payment_id
-  FROM event_payments ep
-  INNER JOIN events e ON ep.event_id = e.id
-  INNER JOIN payments p ON ep.payment_id = p.id
-  WHERE e.group_id = $1
-    AND (p.payer_id = $2 OR p.debtor_id = $2)
-)
+const deleteRepayment = `-- name: DeleteRepayment :exec
+DELETE FROM payments WHERE id = $1
 `
 
-type DeletePaymentsByGroupIDAndUserIDParams struct {
-	GroupID string
-	PayerID string
+func (q *Queries) DeleteRepayment(ctx context.Context, id string) error {
+	_, err := q.db.Exec(ctx, deleteRepayment, id)
+	return err
 }
 
-func (q *Queries) DeletePaymentsByGroupIDAndUserID(ctx context.Context, arg DeletePaymentsByGroupIDAndUserIDParams) error {
-	_, err := q.db.Exec(ctx, deletePaymentsByGroupIDAndUserID, arg.GroupID, arg.PayerID)
+const deleteSelfRepaymentsByUserID = `-- name: DeleteSelfRepaymentsByUserID :exec
+DELETE FROM payments p
+WHERE p.payer_id = $1
+  AND p.debtor_id = $1
+  AND NOT EXISTS (SELECT 1 FROM event_payments ep WHERE ep.payment_id = p.id)
+`
+
+func (q *Queries) DeleteSelfRepaymentsByUserID(ctx context.Context, payerID string) error {
+	_, err := q.db.Exec(ctx, deleteSelfRepaymentsByUserID, payerID)
 	return err
 }
 
-const deleteRepayment = `-- name: DeleteRepayment :exec
-DELETE FROM payments WHERE id = $1
+const deleteUser = `-- name: DeleteUser :exec
+DELETE FROM users WHERE id = $1
 `
 
-func (q *Queries) DeleteRepayment(ctx context.Context, id string) error {
-	_, err := q.db.Exec(ctx, deleteRepayment, id)
+func (q *Queries) DeleteUser(ctx context.Context, id string) error {
+	_, err := q.db.Exec(ctx, deleteUser, id)
 	return err
 }
 
+const existsBalanceByUserID = `-- name: ExistsBalanceByUserID :one
+SELECT EXISTS (
+  SELECT 1 FROM balances
+  WHERE user_id = $1 AND amount != 0
+)
+`
+
+func (q *Queries) ExistsBalanceByUserID(ctx context.Context, userID string) (bool, error) {
+	row := q.db.QueryRow(ctx, existsBalanceByUserID, userID)
+	var exists bool
+	err := row.Scan(&exists)
+	return exists, err
+}
+
+const existsGroupMember = `-- name: ExistsGroupMember :one
+SELECT EXISTS (
+  SELECT 1 FROM group_members
+  WHERE group_id = $1 AND user_id = $2
+)
+`
+
+type ExistsGroupMemberParams struct {
+	GroupID string
+	UserID  string
+}
+
+func (q *Queries) ExistsGroupMember(ctx context.Context, arg ExistsGroupMemberParams) (bool, error) {
+	row := q.db.QueryRow(ctx, existsGroupMember, arg.GroupID, arg.UserID)
+	var exists bool
+	err := row.Scan(&exists)
+	return exists, err
+}
+
 const findAllEvents = `-- name: FindAllEvents :many
-SELECT id, group_id, name, amount, event_date, created_at, updated_at FROM events
+SELECT id, group_id, name, amount, event_date, created_at, updated_at, due_date, created_by, version FROM events
 `
 
 func (q *Queries) FindAllEvents(ctx context.Context) ([]Event, error) {
@@ -286,6 +590,9 @@ func (q *Queries) FindAllEvents(ctx context.Context) ([]Event, error) {
 			&i.EventDate,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.DueDate,
+			&i.CreatedBy,
+			&i.Version,
 		); err != nil {
 			return nil, err
 		}
@@ -310,7 +617,7 @@ SELECT DISTINCT ON (e.id)
 FROM events e
 INNER JOIN event_payments ep ON e.id = ep.event_id
 INNER JOIN payments p ON ep.payment_id = p.id
-WHERE e.group_id = $1
+WHERE e.group_id = $1::text
   AND (p.payer_id = $2 OR p.debtor_id = $2)
   AND ($3::text IS NULL OR e.id < $3)
 ORDER BY e.id DESC
@@ -326,7 +633,7 @@ type FindAllLendingsByGroupIDAndUserIDWithCursorParams struct {
 
 type FindAllLendingsByGroupIDAndUserIDWithCursorRow struct {
 	ID        string
-	GroupID   string
+	GroupID   *string
 	Name      string
 	Amount    int32
 	EventDate time.Time
@@ -373,15 +680,24 @@ const findAllUsers = `-- name: FindAllUsers :many
 SELECT id, name, avatar, email, created_at, updated_at FROM users
 `
 
-func (q *Queries) FindAllUsers(ctx context.Context) ([]User, error) {
+type FindAllUsersRow struct {
+	ID        string
+	Name      string
+	Avatar    string
+	Email     string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func (q *Queries) FindAllUsers(ctx context.Context) ([]FindAllUsersRow, error) {
 	rows, err := q.db.Query(ctx, findAllUsers)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []User
+	var items []FindAllUsersRow
 	for rows.Next() {
-		var i User
+		var i FindAllUsersRow
 		if err := rows.Scan(
 			&i.ID,
 			&i.Name,
@@ -400,39 +716,82 @@ func (q *Queries) FindAllUsers(ctx context.Context) ([]User, error) {
 	return items, nil
 }
 
-const findDebtorsByEventIDs = `-- name: FindDebtorsByEventIDs :many
-SELECT ep.event_id, u.id, u.name, u.avatar, u.email, p.amount
-FROM payments p
-INNER JOIN event_payments ep ON p.id = ep.payment_id
-INNER JOIN users u ON p.debtor_id = u.id
-WHERE ep.event_id = ANY($1::text[])
+const findBalance = `-- name: FindBalance :one
+SELECT amount
+FROM balances
+WHERE user_id = $1 AND other_user_id = $2
 `
 
-type FindDebtorsByEventIDsRow struct {
-	EventID string
-	ID      string
-	Name    string
-	Avatar  string
-	Email   string
-	Amount  int32
+type FindBalanceParams struct {
+	UserID      string
+	OtherUserID string
 }
 
-func (q *Queries) FindDebtorsByEventIDs(ctx context.Context, eventIds []string) ([]FindDebtorsByEventIDsRow, error) {
-	rows, err := q.db.Query(ctx, findDebtorsByEventIDs, eventIds)
+func (q *Queries) FindBalance(ctx context.Context, arg FindBalanceParams) (int64, error) {
+	row := q.db.QueryRow(ctx, findBalance, arg.UserID, arg.OtherUserID)
+	var amount int64
+	err := row.Scan(&amount)
+	return amount, err
+}
+
+const findContactsBySearch = `-- name: FindContactsBySearch :many
+SELECT u.id, u.name, u.avatar, u.email
+FROM users u
+WHERE u.deleted_at IS NULL
+  AND u.guest_group_id IS NULL
+  AND u.id <> $1
+  AND (
+    EXISTS (
+      SELECT 1 FROM contacts c
+      WHERE c.user_id = $1 AND c.contact_id = u.id
+    )
+    OR EXISTS (
+      SELECT 1 FROM group_members gm1
+      INNER JOIN group_members gm2 ON gm1.group_id = gm2.group_id
+      WHERE gm1.user_id = $1 AND gm2.user_id = u.id
+    )
+  )
+  AND (
+    ($2::text IS NOT NULL AND u.name ILIKE '%' || $2 || '%')
+    OR ($3::text IS NOT NULL AND u.email ILIKE '%' || $3 || '%')
+  )
+ORDER BY u.name ASC
+LIMIT $4
+`
+
+type FindContactsBySearchParams struct {
+	UserID string
+	Name   *string
+	Email  *string
+	Limit  int32
+}
+
+type FindContactsBySearchRow struct {
+	ID     string
+	Name   string
+	Avatar string
+	Email  string
+}
+
+func (q *Queries) FindContactsBySearch(ctx context.Context, arg FindContactsBySearchParams) ([]FindContactsBySearchRow, error) {
+	rows, err := q.db.Query(ctx, findContactsBySearch,
+		arg.UserID,
+		arg.Name,
+		arg.Email,
+		arg.Limit,
+	)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []FindDebtorsByEventIDsRow
+	var items []FindContactsBySearchRow
 	for rows.Next() {
-		var i FindDebtorsByEventIDsRow
+		var i FindContactsBySearchRow
 		if err := rows.Scan(
-			&i.EventID,
 			&i.ID,
 			&i.Name,
 			&i.Avatar,
 			&i.Email,
-			&i.Amount,
 		); err != nil {
 			return nil, err
 		}
@@ -444,31 +803,162 @@ func (q *Queries) FindDebtorsByEventIDs(ctx context.Context, eventIds []string)
 	return items, nil
 }
 
-const findEventByGroupIDAndDebtorIDAndEventID = `-- name: FindEventByGroupIDAndDebtorIDAndEventID :one
-SELECT
-  e.id AS event_id,
-  e.group_id,
-  e.name,
-  e.event_date,
-  p.amount,
-  e.created_at,
-  e.updated_at
-FROM events e
-INNER JOIN event_payments ep ON e.id = ep.event_id
-INNER JOIN payments p ON ep.payment_id = p.id
-WHERE e.group_id = $1 AND p.debtor_id = $2 AND e.id = $3
-LIMIT 1
+const findContactsByUserID = `-- name: FindContactsByUserID :many
+SELECT u.id, u.name, u.avatar, u.email,
+  EXISTS (
+    SELECT 1 FROM contacts c
+    WHERE c.user_id = $1 AND c.contact_id = u.id
+  ) AS added
+FROM users u
+WHERE u.deleted_at IS NULL
+  AND u.guest_group_id IS NULL
+  AND u.id <> $1
+  AND (
+    EXISTS (
+      SELECT 1 FROM contacts c
+      WHERE c.user_id = $1 AND c.contact_id = u.id
+    )
+    OR EXISTS (
+      SELECT 1 FROM group_members gm1
+      INNER JOIN group_members gm2 ON gm1.group_id = gm2.group_id
+      WHERE gm1.user_id = $1 AND gm2.user_id = u.id
+    )
+  )
+ORDER BY u.name ASC
 `
 
-type FindEventByGroupIDAndDebtorIDAndEventIDParams struct {
-	GroupID  string
-	DebtorID string
-	ID       string
+type FindContactsByUserIDRow struct {
+	ID     string
+	Name   string
+	Avatar string
+	Email  string
+	Added  bool
+}
+
+func (q *Queries) FindContactsByUserID(ctx context.Context, userID string) ([]FindContactsByUserIDRow, error) {
+	rows, err := q.db.Query(ctx, findContactsByUserID, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []FindContactsByUserIDRow
+	for rows.Next() {
+		var i FindContactsByUserIDRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Avatar,
+			&i.Email,
+			&i.Added,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const findDebtorsByEventIDs = `-- name: FindDebtorsByEventIDs :many
+SELECT ep.event_id, u.id, u.name, u.avatar, u.email, p.amount
+FROM payments p
+INNER JOIN event_payments ep ON p.id = ep.payment_id
+INNER JOIN users u ON p.debtor_id = u.id
+WHERE ep.event_id = ANY($1::text[])
+`
+
+type FindDebtorsByEventIDsRow struct {
+	EventID string
+	ID      string
+	Name    string
+	Avatar  string
+	Email   string
+	Amount  int32
+}
+
+func (q *Queries) FindDebtorsByEventIDs(ctx context.Context, eventIds []string) ([]FindDebtorsByEventIDsRow, error) {
+	rows, err := q.db.Query(ctx, findDebtorsByEventIDs, eventIds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []FindDebtorsByEventIDsRow
+	for rows.Next() {
+		var i FindDebtorsByEventIDsRow
+		if err := rows.Scan(
+			&i.EventID,
+			&i.ID,
+			&i.Name,
+			&i.Avatar,
+			&i.Email,
+			&i.Amount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const findDiscoverableUserByEmail = `-- name: FindDiscoverableUserByEmail :one
+SELECT id, name, avatar, email
+FROM users
+WHERE lower(email) = lower($1)
+  AND deleted_at IS NULL
+  AND guest_group_id IS NULL
+  AND discoverable_by_email
+LIMIT 1
+`
+
+type FindDiscoverableUserByEmailRow struct {
+	ID     string
+	Name   string
+	Avatar string
+	Email  string
+}
+
+func (q *Queries) FindDiscoverableUserByEmail(ctx context.Context, email string) (FindDiscoverableUserByEmailRow, error) {
+	row := q.db.QueryRow(ctx, findDiscoverableUserByEmail, email)
+	var i FindDiscoverableUserByEmailRow
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Avatar,
+		&i.Email,
+	)
+	return i, err
+}
+
+const findEventByGroupIDAndDebtorIDAndEventID = `-- name: FindEventByGroupIDAndDebtorIDAndEventID :one
+SELECT
+  e.id AS event_id,
+  e.group_id,
+  e.name,
+  e.event_date,
+  p.amount,
+  e.created_at,
+  e.updated_at
+FROM events e
+INNER JOIN event_payments ep ON e.id = ep.event_id
+INNER JOIN payments p ON ep.payment_id = p.id
+WHERE e.group_id = $1::text AND p.debtor_id = $2 AND e.id = $3
+LIMIT 1
+`
+
+type FindEventByGroupIDAndDebtorIDAndEventIDParams struct {
+	GroupID  string
+	DebtorID string
+	ID       string
 }
 
 type FindEventByGroupIDAndDebtorIDAndEventIDRow struct {
 	EventID   string
-	GroupID   string
+	GroupID   *string
 	Name      string
 	EventDate time.Time
 	Amount    int32
@@ -492,7 +982,7 @@ func (q *Queries) FindEventByGroupIDAndDebtorIDAndEventID(ctx context.Context, a
 }
 
 const findEventById = `-- name: FindEventById :one
-SELECT id, group_id, name, amount, event_date, created_at, updated_at
+SELECT id, group_id, name, amount, event_date, created_at, updated_at, due_date, created_by, version
 FROM events WHERE id = $1 LIMIT 1
 `
 
@@ -507,12 +997,51 @@ func (q *Queries) FindEventById(ctx context.Context, id string) (Event, error) {
 		&i.EventDate,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.DueDate,
+		&i.CreatedBy,
+		&i.Version,
 	)
 	return i, err
 }
 
+const findEventsByIDs = `-- name: FindEventsByIDs :many
+SELECT id, group_id, name, amount, event_date, created_at, updated_at, due_date, created_by, version
+FROM events WHERE id = ANY($1::text[])
+`
+
+func (q *Queries) FindEventsByIDs(ctx context.Context, ids []string) ([]Event, error) {
+	rows, err := q.db.Query(ctx, findEventsByIDs, ids)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Event
+	for rows.Next() {
+		var i Event
+		if err := rows.Scan(
+			&i.ID,
+			&i.GroupID,
+			&i.Name,
+			&i.Amount,
+			&i.EventDate,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.DueDate,
+			&i.CreatedBy,
+			&i.Version,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const findGroupByID = `-- name: FindGroupByID :one
-SELECT id, name, created_by, created_at, updated_at
+SELECT id, name, created_by, created_at, updated_at, payment_terms_days, version
 FROM groups WHERE id = $1 LIMIT 1
 `
 
@@ -525,12 +1054,45 @@ func (q *Queries) FindGroupByID(ctx context.Context, id string) (Group, error) {
 		&i.CreatedBy,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.PaymentTermsDays,
+		&i.Version,
 	)
 	return i, err
 }
 
+const findGroupMemberIDsIn = `-- name: FindGroupMemberIDsIn :many
+SELECT user_id FROM group_members
+WHERE group_id = $1 AND user_id = ANY($2::text[])
+ORDER BY user_id
+`
+
+type FindGroupMemberIDsInParams struct {
+	GroupID string
+	UserIds []string
+}
+
+func (q *Queries) FindGroupMemberIDsIn(ctx context.Context, arg FindGroupMemberIDsInParams) ([]string, error) {
+	rows, err := q.db.Query(ctx, findGroupMemberIDsIn, arg.GroupID, arg.UserIds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []string
+	for rows.Next() {
+		var user_id string
+		if err := rows.Scan(&user_id); err != nil {
+			return nil, err
+		}
+		items = append(items, user_id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const findGroupMemberUsersByGroupID = `-- name: FindGroupMemberUsersByGroupID :many
-SELECT u.id, u.name, u.avatar, u.email
+SELECT u.id, u.name, u.avatar, u.email, u.guest_group_id
 FROM users u
 INNER JOIN group_members gm ON u.id = gm.user_id
 WHERE gm.group_id = $1
@@ -538,10 +1100,11 @@ ORDER BY gm.created_at ASC
 `
 
 type FindGroupMemberUsersByGroupIDRow struct {
-	ID     string
-	Name   string
-	Avatar string
-	Email  string
+	ID           string
+	Name         string
+	Avatar       string
+	Email        string
+	GuestGroupID *string
 }
 
 func (q *Queries) FindGroupMemberUsersByGroupID(ctx context.Context, groupID string) ([]FindGroupMemberUsersByGroupIDRow, error) {
@@ -558,6 +1121,7 @@ func (q *Queries) FindGroupMemberUsersByGroupID(ctx context.Context, groupID str
 			&i.Name,
 			&i.Avatar,
 			&i.Email,
+			&i.GuestGroupID,
 		); err != nil {
 			return nil, err
 		}
@@ -594,8 +1158,47 @@ func (q *Queries) FindGroupMembersByGroupID(ctx context.Context, groupID string)
 	return items, nil
 }
 
+const findGroupSuccessorsByCreatedBy = `-- name: FindGroupSuccessorsByCreatedBy :many
+SELECT g.id, COALESCE(s.user_id, '') AS successor_id
+FROM groups g
+LEFT JOIN LATERAL (
+  SELECT gm.user_id FROM group_members gm
+  WHERE gm.group_id = g.id AND gm.user_id != g.created_by
+  ORDER BY gm.created_at ASC
+  LIMIT 1
+) s ON true
+WHERE g.created_by = $1
+ORDER BY g.id
+`
+
+type FindGroupSuccessorsByCreatedByRow struct {
+	ID          string
+	SuccessorID string
+}
+
+// 作成者の次に古くから所属しているメンバー (他にメンバーがいない場合は空文字)
+func (q *Queries) FindGroupSuccessorsByCreatedBy(ctx context.Context, createdBy string) ([]FindGroupSuccessorsByCreatedByRow, error) {
+	rows, err := q.db.Query(ctx, findGroupSuccessorsByCreatedBy, createdBy)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []FindGroupSuccessorsByCreatedByRow
+	for rows.Next() {
+		var i FindGroupSuccessorsByCreatedByRow
+		if err := rows.Scan(&i.ID, &i.SuccessorID); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const findGroupsByMemberUserID = `-- name: FindGroupsByMemberUserID :many
-SELECT g.id, g.name, g.created_by, g.created_at, g.updated_at
+SELECT g.id, g.name, g.created_by, g.created_at, g.updated_at, g.payment_terms_days, g.version
 FROM groups g
 INNER JOIN group_members gm ON g.id = gm.group_id
 WHERE gm.user_id = $1
@@ -617,6 +1220,8 @@ func (q *Queries) FindGroupsByMemberUserID(ctx context.Context, userID string) (
 			&i.CreatedBy,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.PaymentTermsDays,
+			&i.Version,
 		); err != nil {
 			return nil, err
 		}
@@ -628,55 +1233,64 @@ func (q *Queries) FindGroupsByMemberUserID(ctx context.Context, userID string) (
 	return items, nil
 }
 
-const findPaymentByDebtorId = `-- name: FindPaymentByDebtorId :one
-SELECT p.id, p.payer_id, p.debtor_id, p.amount, p.created_at, p.updated_at
-FROM payments p
-INNER JOIN event_payments ep ON p.id = ep.payment_id
-WHERE ep.event_id = $1 AND p.debtor_id = $2 LIMIT 1
+const findGuestByInviteToken = `-- name: FindGuestByInviteToken :one
+SELECT id, name, email, guest_group_id::text AS group_id, invite_token::text AS invite_token
+FROM users
+WHERE invite_token = $1 AND guest_group_id IS NOT NULL
+LIMIT 1
 `
 
-type FindPaymentByDebtorIdParams struct {
-	EventID  string
-	DebtorID string
+type FindGuestByInviteTokenRow struct {
+	ID          string
+	Name        string
+	Email       string
+	GroupID     string
+	InviteToken string
 }
 
-func (q *Queries) FindPaymentByDebtorId(ctx context.Context, arg FindPaymentByDebtorIdParams) (Payment, error) {
-	row := q.db.QueryRow(ctx, findPaymentByDebtorId, arg.EventID, arg.DebtorID)
-	var i Payment
+func (q *Queries) FindGuestByInviteToken(ctx context.Context, inviteToken *string) (FindGuestByInviteTokenRow, error) {
+	row := q.db.QueryRow(ctx, findGuestByInviteToken, inviteToken)
+	var i FindGuestByInviteTokenRow
 	err := row.Scan(
 		&i.ID,
-		&i.PayerID,
-		&i.DebtorID,
-		&i.Amount,
-		&i.CreatedAt,
-		&i.UpdatedAt,
+		&i.Name,
+		&i.Email,
+		&i.GroupID,
+		&i.InviteToken,
 	)
 	return i, err
 }
 
-const findPaymentsByEventId = `-- name: FindPaymentsByEventId :many
-SELECT p.id, p.payer_id, p.debtor_id, p.amount, p.created_at, p.updated_at
-FROM payments p
-INNER JOIN event_payments ep ON p.id = ep.payment_id
-WHERE ep.event_id = $1
+const findGuestsByEmail = `-- name: FindGuestsByEmail :many
+SELECT id, name, email, guest_group_id::text AS group_id, invite_token::text AS invite_token
+FROM users
+WHERE email = $1 AND guest_group_id IS NOT NULL
+ORDER BY created_at ASC
 `
 
-func (q *Queries) FindPaymentsByEventId(ctx context.Context, eventID string) ([]Payment, error) {
-	rows, err := q.db.Query(ctx, findPaymentsByEventId, eventID)
+type FindGuestsByEmailRow struct {
+	ID          string
+	Name        string
+	Email       string
+	GroupID     string
+	InviteToken string
+}
+
+func (q *Queries) FindGuestsByEmail(ctx context.Context, email string) ([]FindGuestsByEmailRow, error) {
+	rows, err := q.db.Query(ctx, findGuestsByEmail, email)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []Payment
+	var items []FindGuestsByEmailRow
 	for rows.Next() {
-		var i Payment
+		var i FindGuestsByEmailRow
 		if err := rows.Scan(
 			&i.ID,
-			&i.PayerID,
-			&i.DebtorID,
-			&i.Amount,
-			&i.CreatedAt,
-			&i.UpdatedAt,
+			&i.Name,
+			&i.Email,
+			&i.GroupID,
+			&i.InviteToken,
 		); err != nil {
 			return nil, err
 		}
@@ -688,44 +1302,403 @@ func (q *Queries) FindPaymentsByEventId(ctx context.Context, eventID string) ([]
 	return items, nil
 }
 
-const findRepaymentByID = `-- name: FindRepaymentByID :one
-SELECT p.id, p.payer_id, p.debtor_id, p.amount, p.created_at, p.updated_at
-FROM payments p
-LEFT JOIN event_payments ep ON p.id = ep.payment_id
-WHERE p.id = $1 AND ep.event_id IS NULL
-LIMIT 1
+const findGuestsByGroupID = `-- name: FindGuestsByGroupID :many
+SELECT id, name, email, guest_group_id::text AS group_id, invite_token::text AS invite_token
+FROM users
+WHERE guest_group_id = $1
+ORDER BY created_at ASC
 `
 
-func (q *Queries) FindRepaymentByID(ctx context.Context, id string) (Payment, error) {
-	row := q.db.QueryRow(ctx, findRepaymentByID, id)
-	var i Payment
-	err := row.Scan(
-		&i.ID,
-		&i.PayerID,
-		&i.DebtorID,
-		&i.Amount,
-		&i.CreatedAt,
-		&i.UpdatedAt,
-	)
-	return i, err
+type FindGuestsByGroupIDRow struct {
+	ID          string
+	Name        string
+	Email       string
+	GroupID     string
+	InviteToken string
 }
 
-const findRepaymentsByPayerIDWithCursor = `-- name: FindRepaymentsByPayerIDWithCursor :many
-SELECT p.id, p.payer_id, p.debtor_id, p.amount, p.created_at, p.updated_at
-FROM payments p
-LEFT JOIN event_payments ep ON p.id = ep.payment_id
-WHERE p.payer_id = $1
-  AND ep.event_id IS NULL
-  AND ($2::text IS NULL OR p.id < $2)
-ORDER BY p.id DESC
-LIMIT $3
-`
-
-type FindRepaymentsByPayerIDWithCursorParams struct {
-	PayerID string
-	Cursor  *string
-	Limit   int32
-}
+func (q *Queries) FindGuestsByGroupID(ctx context.Context, guestGroupID *string) ([]FindGuestsByGroupIDRow, error) {
+	rows, err := q.db.Query(ctx, findGuestsByGroupID, guestGroupID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []FindGuestsByGroupIDRow
+	for rows.Next() {
+		var i FindGuestsByGroupIDRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Email,
+			&i.GroupID,
+			&i.InviteToken,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const findIdempotencyKey = `-- name: FindIdempotencyKey :one
+SELECT user_id, key, method, path, request_hash, status_code, content_type, response_body, created_at
+FROM idempotency_keys
+WHERE user_id = $1 AND key = $2
+LIMIT 1
+`
+
+type FindIdempotencyKeyParams struct {
+	UserID string
+	Key    string
+}
+
+func (q *Queries) FindIdempotencyKey(ctx context.Context, arg FindIdempotencyKeyParams) (IdempotencyKey, error) {
+	row := q.db.QueryRow(ctx, findIdempotencyKey, arg.UserID, arg.Key)
+	var i IdempotencyKey
+	err := row.Scan(
+		&i.UserID,
+		&i.Key,
+		&i.Method,
+		&i.Path,
+		&i.RequestHash,
+		&i.StatusCode,
+		&i.ContentType,
+		&i.ResponseBody,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const findLendingCommentByID = `-- name: FindLendingCommentByID :one
+SELECT c.id, c.event_id, c.body, c.created_at, c.updated_at, u.id AS user_id, u.name AS user_name, u.avatar AS user_avatar, u.email AS user_email
+FROM lending_comments c
+INNER JOIN users u ON c.user_id = u.id
+WHERE c.id = $1
+LIMIT 1
+`
+
+type FindLendingCommentByIDRow struct {
+	ID         string
+	EventID    string
+	Body       string
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+	UserID     string
+	UserName   string
+	UserAvatar string
+	UserEmail  string
+}
+
+func (q *Queries) FindLendingCommentByID(ctx context.Context, id string) (FindLendingCommentByIDRow, error) {
+	row := q.db.QueryRow(ctx, findLendingCommentByID, id)
+	var i FindLendingCommentByIDRow
+	err := row.Scan(
+		&i.ID,
+		&i.EventID,
+		&i.Body,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.UserID,
+		&i.UserName,
+		&i.UserAvatar,
+		&i.UserEmail,
+	)
+	return i, err
+}
+
+const findLendingCommentsByEventIDWithCursor = `-- name: FindLendingCommentsByEventIDWithCursor :many
+SELECT c.id, c.event_id, c.body, c.created_at, c.updated_at, u.id AS user_id, u.name AS user_name, u.avatar AS user_avatar, u.email AS user_email
+FROM lending_comments c
+INNER JOIN users u ON c.user_id = u.id
+WHERE c.event_id = $1
+  AND ($2::text IS NULL OR c.id > $2)
+ORDER BY c.id ASC
+LIMIT $3
+`
+
+type FindLendingCommentsByEventIDWithCursorParams struct {
+	EventID string
+	Cursor  *string
+	Limit   int32
+}
+
+type FindLendingCommentsByEventIDWithCursorRow struct {
+	ID         string
+	EventID    string
+	Body       string
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+	UserID     string
+	UserName   string
+	UserAvatar string
+	UserEmail  string
+}
+
+func (q *Queries) FindLendingCommentsByEventIDWithCursor(ctx context.Context, arg FindLendingCommentsByEventIDWithCursorParams) ([]FindLendingCommentsByEventIDWithCursorRow, error) {
+	rows, err := q.db.Query(ctx, findLendingCommentsByEventIDWithCursor, arg.EventID, arg.Cursor, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []FindLendingCommentsByEventIDWithCursorRow
+	for rows.Next() {
+		var i FindLendingCommentsByEventIDWithCursorRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.EventID,
+			&i.Body,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.UserID,
+			&i.UserName,
+			&i.UserAvatar,
+			&i.UserEmail,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const findLendingsByUserIDWithCursor = `-- name: FindLendingsByUserIDWithCursor :many
+SELECT e.id, e.group_id
+FROM events e
+WHERE EXISTS (
+    SELECT 1
+    FROM event_payments ep
+    INNER JOIN payments p ON ep.payment_id = p.id
+    WHERE ep.event_id = e.id
+      AND (p.payer_id = $1 OR p.debtor_id = $1)
+  )
+  AND ($2::text IS NULL OR e.id < $2)
+ORDER BY e.id DESC
+LIMIT $3
+`
+
+type FindLendingsByUserIDWithCursorParams struct {
+	UserID string
+	Cursor *string
+	Limit  int32
+}
+
+type FindLendingsByUserIDWithCursorRow struct {
+	ID      string
+	GroupID *string
+}
+
+func (q *Queries) FindLendingsByUserIDWithCursor(ctx context.Context, arg FindLendingsByUserIDWithCursorParams) ([]FindLendingsByUserIDWithCursorRow, error) {
+	rows, err := q.db.Query(ctx, findLendingsByUserIDWithCursor, arg.UserID, arg.Cursor, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []FindLendingsByUserIDWithCursorRow
+	for rows.Next() {
+		var i FindLendingsByUserIDWithCursorRow
+		if err := rows.Scan(&i.ID, &i.GroupID); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const findPaymentByDebtorId = `-- name: FindPaymentByDebtorId :one
+SELECT p.id, p.payer_id, p.debtor_id, p.amount, p.created_at, p.updated_at, p.version
+FROM payments p
+INNER JOIN event_payments ep ON p.id = ep.payment_id
+WHERE ep.event_id = $1 AND p.debtor_id = $2 LIMIT 1
+`
+
+type FindPaymentByDebtorIdParams struct {
+	EventID  string
+	DebtorID string
+}
+
+func (q *Queries) FindPaymentByDebtorId(ctx context.Context, arg FindPaymentByDebtorIdParams) (Payment, error) {
+	row := q.db.QueryRow(ctx, findPaymentByDebtorId, arg.EventID, arg.DebtorID)
+	var i Payment
+	err := row.Scan(
+		&i.ID,
+		&i.PayerID,
+		&i.DebtorID,
+		&i.Amount,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Version,
+	)
+	return i, err
+}
+
+const findPaymentsByEventIDs = `-- name: FindPaymentsByEventIDs :many
+SELECT ep.event_id, p.payer_id, p.debtor_id, p.amount
+FROM payments p
+INNER JOIN event_payments ep ON p.id = ep.payment_id
+WHERE ep.event_id = ANY($1::text[])
+ORDER BY ep.event_id, p.id
+`
+
+type FindPaymentsByEventIDsRow struct {
+	EventID  string
+	PayerID  string
+	DebtorID string
+	Amount   int32
+}
+
+func (q *Queries) FindPaymentsByEventIDs(ctx context.Context, eventIds []string) ([]FindPaymentsByEventIDsRow, error) {
+	rows, err := q.db.Query(ctx, findPaymentsByEventIDs, eventIds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []FindPaymentsByEventIDsRow
+	for rows.Next() {
+		var i FindPaymentsByEventIDsRow
+		if err := rows.Scan(
+			&i.EventID,
+			&i.PayerID,
+			&i.DebtorID,
+			&i.Amount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const findPaymentsByEventId = `-- name: FindPaymentsByEventId :many
+SELECT p.id, p.payer_id, p.debtor_id, p.amount, p.created_at, p.updated_at, p.version
+FROM payments p
+INNER JOIN event_payments ep ON p.id = ep.payment_id
+WHERE ep.event_id = $1
+`
+
+func (q *Queries) FindPaymentsByEventId(ctx context.Context, eventID string) ([]Payment, error) {
+	rows, err := q.db.Query(ctx, findPaymentsByEventId, eventID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Payment
+	for rows.Next() {
+		var i Payment
+		if err := rows.Scan(
+			&i.ID,
+			&i.PayerID,
+			&i.DebtorID,
+			&i.Amount,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Version,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const findRepaymentByID = `-- name: FindRepaymentByID :one
+SELECT p.id, p.payer_id, p.debtor_id, p.amount, p.created_at, p.updated_at, p.version
+FROM payments p
+LEFT JOIN event_payments ep ON p.id = ep.payment_id
+WHERE p.id = $1 AND ep.event_id IS NULL
+LIMIT 1
+`
+
+func (q *Queries) FindRepaymentByID(ctx context.Context, id string) (Payment, error) {
+	row := q.db.QueryRow(ctx, findRepaymentByID, id)
+	var i Payment
+	err := row.Scan(
+		&i.ID,
+		&i.PayerID,
+		&i.DebtorID,
+		&i.Amount,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Version,
+	)
+	return i, err
+}
+
+const findRepaymentsByDebtorIDWithCursor = `-- name: FindRepaymentsByDebtorIDWithCursor :many
+SELECT p.id, p.payer_id, p.debtor_id, p.amount, p.created_at, p.updated_at, p.version
+FROM payments p
+LEFT JOIN event_payments ep ON p.id = ep.payment_id
+WHERE p.debtor_id = $1
+  AND ep.event_id IS NULL
+  AND ($2::text IS NULL OR p.id < $2)
+ORDER BY p.id DESC
+LIMIT $3
+`
+
+type FindRepaymentsByDebtorIDWithCursorParams struct {
+	DebtorID string
+	Cursor   *string
+	Limit    int32
+}
+
+func (q *Queries) FindRepaymentsByDebtorIDWithCursor(ctx context.Context, arg FindRepaymentsByDebtorIDWithCursorParams) ([]Payment, error) {
+	rows, err := q.db.Query(ctx, findRepaymentsByDebtorIDWithCursor, arg.DebtorID, arg.Cursor, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Payment
+	for rows.Next() {
+		var i Payment
+		if err := rows.Scan(
+			&i.ID,
+			&i.PayerID,
+			&i.DebtorID,
+			&i.Amount,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Version,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const findRepaymentsByPayerIDWithCursor = `-- name: FindRepaymentsByPayerIDWithCursor :many
+SELECT p.id, p.payer_id, p.debtor_id, p.amount, p.created_at, p.updated_at, p.version
+FROM payments p
+LEFT JOIN event_payments ep ON p.id = ep.payment_id
+WHERE p.payer_id = $1
+  AND ep.event_id IS NULL
+  AND ($2::text IS NULL OR p.id < $2)
+ORDER BY p.id DESC
+LIMIT $3
+`
+
+type FindRepaymentsByPayerIDWithCursorParams struct {
+	PayerID string
+	Cursor  *string
+	Limit   int32
+}
 
 func (q *Queries) FindRepaymentsByPayerIDWithCursor(ctx context.Context, arg FindRepaymentsByPayerIDWithCursorParams) ([]Payment, error) {
 	rows, err := q.db.Query(ctx, findRepaymentsByPayerIDWithCursor, arg.PayerID, arg.Cursor, arg.Limit)
@@ -743,6 +1716,7 @@ func (q *Queries) FindRepaymentsByPayerIDWithCursor(ctx context.Context, arg Fin
 			&i.Amount,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.Version,
 		); err != nil {
 			return nil, err
 		}
@@ -754,13 +1728,22 @@ func (q *Queries) FindRepaymentsByPayerIDWithCursor(ctx context.Context, arg Fin
 	return items, nil
 }
 
-const findUserByEmail = `-- name: FindUserByEmail :one
-SELECT id, name, avatar, email, created_at, updated_at FROM users WHERE email = $1 LIMIT 1
-`
-
-func (q *Queries) FindUserByEmail(ctx context.Context, email string) (User, error) {
+const findUserByEmail = `-- name: FindUserByEmail :one
+SELECT id, name, avatar, email, created_at, updated_at FROM users WHERE email = $1 AND guest_group_id IS NULL LIMIT 1
+`
+
+type FindUserByEmailRow struct {
+	ID        string
+	Name      string
+	Avatar    string
+	Email     string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func (q *Queries) FindUserByEmail(ctx context.Context, email string) (FindUserByEmailRow, error) {
 	row := q.db.QueryRow(ctx, findUserByEmail, email)
-	var i User
+	var i FindUserByEmailRow
 	err := row.Scan(
 		&i.ID,
 		&i.Name,
@@ -773,54 +1756,74 @@ func (q *Queries) FindUserByEmail(ctx context.Context, email string) (User, erro
 }
 
 const findUserByID = `-- name: FindUserByID :one
-SELECT id, name, avatar, email, created_at, updated_at FROM users WHERE id = $1 LIMIT 1
+SELECT id, name, avatar, email, guest_group_id, created_at, updated_at FROM users WHERE id = $1 LIMIT 1
 `
 
-func (q *Queries) FindUserByID(ctx context.Context, id string) (User, error) {
+type FindUserByIDRow struct {
+	ID           string
+	Name         string
+	Avatar       string
+	Email        string
+	GuestGroupID *string
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+func (q *Queries) FindUserByID(ctx context.Context, id string) (FindUserByIDRow, error) {
 	row := q.db.QueryRow(ctx, findUserByID, id)
-	var i User
+	var i FindUserByIDRow
 	err := row.Scan(
 		&i.ID,
 		&i.Name,
 		&i.Avatar,
 		&i.Email,
+		&i.GuestGroupID,
 		&i.CreatedAt,
 		&i.UpdatedAt,
 	)
 	return i, err
 }
 
-const findUsersBySearch = `-- name: FindUsersBySearch :many
-SELECT id, name, avatar, email, created_at, updated_at
-FROM users
-WHERE ($1::text IS NOT NULL AND name ILIKE '%' || $1 || '%')
-   OR ($2::text IS NOT NULL AND email ILIKE '%' || $2 || '%')
-ORDER BY name ASC
-LIMIT $3
+const findUserPrivacyByID = `-- name: FindUserPrivacyByID :one
+SELECT discoverable_by_email FROM users WHERE id = $1 LIMIT 1
+`
+
+func (q *Queries) FindUserPrivacyByID(ctx context.Context, id string) (bool, error) {
+	row := q.db.QueryRow(ctx, findUserPrivacyByID, id)
+	var discoverable_by_email bool
+	err := row.Scan(&discoverable_by_email)
+	return discoverable_by_email, err
+}
+
+const findUsersByIDs = `-- name: FindUsersByIDs :many
+SELECT id, name, avatar, email, guest_group_id FROM users
+WHERE id = ANY($1::text[])
+ORDER BY id
 `
 
-type FindUsersBySearchParams struct {
-	Name  *string
-	Email *string
-	Limit int32
+type FindUsersByIDsRow struct {
+	ID           string
+	Name         string
+	Avatar       string
+	Email        string
+	GuestGroupID *string
 }
 
-func (q *Queries) FindUsersBySearch(ctx context.Context, arg FindUsersBySearchParams) ([]User, error) {
-	rows, err := q.db.Query(ctx, findUsersBySearch, arg.Name, arg.Email, arg.Limit)
+func (q *Queries) FindUsersByIDs(ctx context.Context, ids []string) ([]FindUsersByIDsRow, error) {
+	rows, err := q.db.Query(ctx, findUsersByIDs, ids)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []User
+	var items []FindUsersByIDsRow
 	for rows.Next() {
-		var i User
+		var i FindUsersByIDsRow
 		if err := rows.Scan(
 			&i.ID,
 			&i.Name,
 			&i.Avatar,
 			&i.Email,
-			&i.CreatedAt,
-			&i.UpdatedAt,
+			&i.GuestGroupID,
 		); err != nil {
 			return nil, err
 		}
@@ -832,30 +1835,49 @@ func (q *Queries) FindUsersBySearch(ctx context.Context, arg FindUsersBySearchPa
 	return items, nil
 }
 
-const listBorrowingCreditAmountsByUserID = `-- name: ListBorrowingCreditAmountsByUserID :many
-SELECT payer_id AS user_id, SUM(amount)::bigint AS amount
-FROM payments
-WHERE debtor_id = $1
-  AND payer_id != debtor_id
-GROUP BY payer_id
-ORDER BY payer_id
+const listBalanceMismatches = `-- name: ListBalanceMismatches :many
+SELECT
+  COALESCE(p.user_id, b.user_id)::text AS user_id,
+  COALESCE(p.other_user_id, b.other_user_id)::text AS other_user_id,
+  COALESCE(p.amount, 0)::bigint AS expected,
+  COALESCE(b.amount, 0)::bigint AS actual
+FROM (
+  SELECT payer_id AS user_id, debtor_id AS other_user_id, SUM(amount) AS amount
+  FROM (
+    SELECT payer_id, debtor_id, amount FROM payments WHERE payer_id != debtor_id
+    UNION ALL
+    SELECT debtor_id, payer_id, -amount FROM payments WHERE payer_id != debtor_id
+  ) AS directed
+  GROUP BY payer_id, debtor_id
+) AS p
+FULL OUTER JOIN balances b ON p.user_id = b.user_id AND p.other_user_id = b.other_user_id
+WHERE COALESCE(p.amount, 0) != COALESCE(b.amount, 0)
+ORDER BY 1, 2
 `
 
-type ListBorrowingCreditAmountsByUserIDRow struct {
-	UserID string
-	Amount int64
+type ListBalanceMismatchesRow struct {
+	UserID      string
+	OtherUserID string
+	Expected    int64
+	Actual      int64
 }
 
-func (q *Queries) ListBorrowingCreditAmountsByUserID(ctx context.Context, debtorID string) ([]ListBorrowingCreditAmountsByUserIDRow, error) {
-	rows, err := q.db.Query(ctx, listBorrowingCreditAmountsByUserID, debtorID)
+// 支払いから集計した残高と残高テーブルの値が異なるユーザーの組
+func (q *Queries) ListBalanceMismatches(ctx context.Context) ([]ListBalanceMismatchesRow, error) {
+	rows, err := q.db.Query(ctx, listBalanceMismatches)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []ListBorrowingCreditAmountsByUserIDRow
+	var items []ListBalanceMismatchesRow
 	for rows.Next() {
-		var i ListBorrowingCreditAmountsByUserIDRow
-		if err := rows.Scan(&i.UserID, &i.Amount); err != nil {
+		var i ListBalanceMismatchesRow
+		if err := rows.Scan(
+			&i.UserID,
+			&i.OtherUserID,
+			&i.Expected,
+			&i.Actual,
+		); err != nil {
 			return nil, err
 		}
 		items = append(items, i)
@@ -866,29 +1888,28 @@ func (q *Queries) ListBorrowingCreditAmountsByUserID(ctx context.Context, debtor
 	return items, nil
 }
 
-const listLendingCreditAmountsByUserID = `-- name: ListLendingCreditAmountsByUserID :many
-SELECT debtor_id AS user_id, SUM(amount)::bigint AS amount
-FROM payments
-WHERE payer_id = $1
-  AND payer_id != debtor_id
-GROUP BY debtor_id
-ORDER BY debtor_id
+const listBalancesByUserID = `-- name: ListBalancesByUserID :many
+SELECT other_user_id AS user_id, amount
+FROM balances
+WHERE user_id = $1
+  AND amount != 0
+ORDER BY other_user_id
 `
 
-type ListLendingCreditAmountsByUserIDRow struct {
+type ListBalancesByUserIDRow struct {
 	UserID string
 	Amount int64
 }
 
-func (q *Queries) ListLendingCreditAmountsByUserID(ctx context.Context, payerID string) ([]ListLendingCreditAmountsByUserIDRow, error) {
-	rows, err := q.db.Query(ctx, listLendingCreditAmountsByUserID, payerID)
+func (q *Queries) ListBalancesByUserID(ctx context.Context, userID string) ([]ListBalancesByUserIDRow, error) {
+	rows, err := q.db.Query(ctx, listBalancesByUserID, userID)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []ListLendingCreditAmountsByUserIDRow
+	var items []ListBalancesByUserIDRow
 	for rows.Next() {
-		var i ListLendingCreditAmountsByUserIDRow
+		var i ListBalancesByUserIDRow
 		if err := rows.Scan(&i.UserID, &i.Amount); err != nil {
 			return nil, err
 		}
@@ -900,13 +1921,390 @@ func (q *Queries) ListLendingCreditAmountsByUserID(ctx context.Context, payerID
 	return items, nil
 }
 
-const updateEvent = `-- name: UpdateEvent :exec
+const listDatedDebtsByUserID = `-- name: ListDatedDebtsByUserID :many
+SELECT
+  (CASE WHEN p.payer_id = $1 THEN p.debtor_id ELSE p.payer_id END)::text AS user_id,
+  COALESCE(SUM(p.amount) FILTER (WHERE p.payer_id = $1), 0)::bigint AS lent,
+  COALESCE(SUM(p.amount) FILTER (WHERE p.debtor_id = $1), 0)::bigint AS borrowed,
+  COALESCE(SUM(p.amount) FILTER (WHERE p.payer_id = $1 AND e.due_date >= current_timestamp), 0)::bigint AS lent_not_due,
+  COALESCE(SUM(p.amount) FILTER (WHERE p.debtor_id = $1 AND e.due_date >= current_timestamp), 0)::bigint AS borrowed_not_due
+FROM events e
+INNER JOIN event_payments ep ON e.id = ep.event_id
+INNER JOIN payments p ON ep.payment_id = p.id
+WHERE e.due_date IS NOT NULL
+  AND (p.payer_id = $1 OR p.debtor_id = $1)
+  AND p.payer_id <> p.debtor_id
+  AND ($2::text IS NULL OR p.payer_id = $2 OR p.debtor_id = $2)
+GROUP BY 1
+`
+
+type ListDatedDebtsByUserIDParams struct {
+	UserID      string
+	OtherUserID *string
+}
+
+type ListDatedDebtsByUserIDRow struct {
+	UserID         string
+	Lent           int64
+	Borrowed       int64
+	LentNotDue     int64
+	BorrowedNotDue int64
+}
+
+// 返済期限のある立て替えによる相手ユーザーごとの貸し/借りの合計と、そのうち返済期限前のもの
+// 返済期限のない立て替えは延滞の判定に使わないため集計しない
+// other_user_idを指定した場合はそのユーザーとの分のみ集計する
+func (q *Queries) ListDatedDebtsByUserID(ctx context.Context, arg ListDatedDebtsByUserIDParams) ([]ListDatedDebtsByUserIDRow, error) {
+	rows, err := q.db.Query(ctx, listDatedDebtsByUserID, arg.UserID, arg.OtherUserID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListDatedDebtsByUserIDRow
+	for rows.Next() {
+		var i ListDatedDebtsByUserIDRow
+		if err := rows.Scan(
+			&i.UserID,
+			&i.Lent,
+			&i.Borrowed,
+			&i.LentNotDue,
+			&i.BorrowedNotDue,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listUnbalancedEvents = `-- name: ListUnbalancedEvents :many
+SELECT e.id, e.group_id, e.name, e.amount, COALESCE(SUM(p.amount), 0)::bigint AS paid
+FROM events e
+LEFT JOIN event_payments ep ON e.id = ep.event_id
+LEFT JOIN payments p ON ep.payment_id = p.id
+GROUP BY e.id
+HAVING COALESCE(SUM(p.amount), 0) != e.amount
+ORDER BY e.id
+`
+
+type ListUnbalancedEventsRow struct {
+	ID      string
+	GroupID *string
+	Name    string
+	Amount  int32
+	Paid    int64
+}
+
+func (q *Queries) ListUnbalancedEvents(ctx context.Context) ([]ListUnbalancedEventsRow, error) {
+	rows, err := q.db.Query(ctx, listUnbalancedEvents)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListUnbalancedEventsRow
+	for rows.Next() {
+		var i ListUnbalancedEventsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.GroupID,
+			&i.Name,
+			&i.Amount,
+			&i.Paid,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const lockPaymentsForBalanceRebuild = `-- name: LockPaymentsForBalanceRebuild :exec
+LOCK TABLE payments IN SHARE MODE
+`
+
+// 作り直している間に支払いが変更されないようにする
+func (q *Queries) LockPaymentsForBalanceRebuild(ctx context.Context) error {
+	_, err := q.db.Exec(ctx, lockPaymentsForBalanceRebuild)
+	return err
+}
+
+const lockUser = `-- name: LockUser :exec
+SELECT id FROM users WHERE id = $1 FOR UPDATE
+`
+
+// 退会の確認中に支払いが追加されないようにする
+func (q *Queries) LockUser(ctx context.Context, id string) error {
+	_, err := q.db.Exec(ctx, lockUser, id)
+	return err
+}
+
+const moveContacts = `-- name: MoveContacts :exec
+INSERT INTO contacts (user_id, contact_id, created_at)
+SELECT $1, c.contact_id, c.created_at
+FROM contacts c
+WHERE c.user_id = $2 AND c.contact_id <> $1
+ON CONFLICT DO NOTHING
+`
+
+type MoveContactsParams struct {
+	ToID   string
+	FromID string
+}
+
+func (q *Queries) MoveContacts(ctx context.Context, arg MoveContactsParams) error {
+	_, err := q.db.Exec(ctx, moveContacts, arg.ToID, arg.FromID)
+	return err
+}
+
+const moveContactsContactID = `-- name: MoveContactsContactID :exec
+INSERT INTO contacts (user_id, contact_id, created_at)
+SELECT c.user_id, $1, c.created_at
+FROM contacts c
+WHERE c.contact_id = $2 AND c.user_id <> $1
+ON CONFLICT DO NOTHING
+`
+
+type MoveContactsContactIDParams struct {
+	ToID   string
+	FromID string
+}
+
+func (q *Queries) MoveContactsContactID(ctx context.Context, arg MoveContactsContactIDParams) error {
+	_, err := q.db.Exec(ctx, moveContactsContactID, arg.ToID, arg.FromID)
+	return err
+}
+
+const moveEventsCreatedBy = `-- name: MoveEventsCreatedBy :exec
+UPDATE events SET created_by = $1 WHERE created_by = $2
+`
+
+type MoveEventsCreatedByParams struct {
+	ToID   *string
+	FromID *string
+}
+
+func (q *Queries) MoveEventsCreatedBy(ctx context.Context, arg MoveEventsCreatedByParams) error {
+	_, err := q.db.Exec(ctx, moveEventsCreatedBy, arg.ToID, arg.FromID)
+	return err
+}
+
+const moveGroupMembers = `-- name: MoveGroupMembers :exec
+INSERT INTO group_members (group_id, user_id, created_at)
+SELECT gm.group_id, $1, gm.created_at
+FROM group_members gm
+WHERE gm.user_id = $2
+ON CONFLICT (group_id, user_id) DO NOTHING
+`
+
+type MoveGroupMembersParams struct {
+	ToID   string
+	FromID string
+}
+
+func (q *Queries) MoveGroupMembers(ctx context.Context, arg MoveGroupMembersParams) error {
+	_, err := q.db.Exec(ctx, moveGroupMembers, arg.ToID, arg.FromID)
+	return err
+}
+
+const moveGroupsCreatedBy = `-- name: MoveGroupsCreatedBy :exec
+UPDATE groups SET created_by = $1 WHERE created_by = $2
+`
+
+type MoveGroupsCreatedByParams struct {
+	ToID   string
+	FromID string
+}
+
+func (q *Queries) MoveGroupsCreatedBy(ctx context.Context, arg MoveGroupsCreatedByParams) error {
+	_, err := q.db.Exec(ctx, moveGroupsCreatedBy, arg.ToID, arg.FromID)
+	return err
+}
+
+const moveLendingCommentsUserID = `-- name: MoveLendingCommentsUserID :exec
+UPDATE lending_comments SET user_id = $1 WHERE user_id = $2
+`
+
+type MoveLendingCommentsUserIDParams struct {
+	ToID   string
+	FromID string
+}
+
+func (q *Queries) MoveLendingCommentsUserID(ctx context.Context, arg MoveLendingCommentsUserIDParams) error {
+	_, err := q.db.Exec(ctx, moveLendingCommentsUserID, arg.ToID, arg.FromID)
+	return err
+}
+
+const movePaymentsDebtorID = `-- name: MovePaymentsDebtorID :exec
+UPDATE payments SET debtor_id = $1, updated_at = current_timestamp WHERE debtor_id = $2
+`
+
+type MovePaymentsDebtorIDParams struct {
+	ToID   string
+	FromID string
+}
+
+func (q *Queries) MovePaymentsDebtorID(ctx context.Context, arg MovePaymentsDebtorIDParams) error {
+	_, err := q.db.Exec(ctx, movePaymentsDebtorID, arg.ToID, arg.FromID)
+	return err
+}
+
+const movePaymentsPayerID = `-- name: MovePaymentsPayerID :exec
+UPDATE payments SET payer_id = $1, updated_at = current_timestamp WHERE payer_id = $2
+`
+
+type MovePaymentsPayerIDParams struct {
+	ToID   string
+	FromID string
+}
+
+func (q *Queries) MovePaymentsPayerID(ctx context.Context, arg MovePaymentsPayerIDParams) error {
+	_, err := q.db.Exec(ctx, movePaymentsPayerID, arg.ToID, arg.FromID)
+	return err
+}
+
+const rebuildBalances = `-- name: RebuildBalances :execrows
+INSERT INTO balances (user_id, other_user_id, amount)
+SELECT payer_id, debtor_id, SUM(amount)
+FROM (
+  SELECT payer_id, debtor_id, amount FROM payments WHERE payer_id != debtor_id
+  UNION ALL
+  SELECT debtor_id, payer_id, -amount FROM payments WHERE payer_id != debtor_id
+) AS directed
+GROUP BY payer_id, debtor_id
+`
+
+func (q *Queries) RebuildBalances(ctx context.Context) (int64, error) {
+	result, err := q.db.Exec(ctx, rebuildBalances)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const searchLendingsByGroupIDsAndUserID = `-- name: SearchLendingsByGroupIDsAndUserID :many
+SELECT
+  e.id,
+  e.group_id,
+  e.name,
+  e.amount,
+  e.event_date,
+  e.created_at,
+  e.updated_at,
+  GREATEST(similarity(e.name, $1::text), word_similarity($1::text, e.name))::real AS rank
+FROM events e
+WHERE e.group_id = ANY($2::text[])
+  AND EXISTS (
+    SELECT 1
+    FROM event_payments ep
+    INNER JOIN payments p ON ep.payment_id = p.id
+    WHERE ep.event_id = e.id
+      AND (p.payer_id = $3 OR p.debtor_id = $3)
+  )
+  AND (e.name ILIKE '%' || $4::text || '%' ESCAPE '\' OR $1::text <% e.name)
+ORDER BY rank DESC, e.event_date DESC, e.id DESC
+LIMIT $5
+`
+
+type SearchLendingsByGroupIDsAndUserIDParams struct {
+	Query    string
+	GroupIds []string
+	UserID   string
+	Pattern  string
+	Limit    int32
+}
+
+type SearchLendingsByGroupIDsAndUserIDRow struct {
+	ID        string
+	GroupID   *string
+	Name      string
+	Amount    int32
+	EventDate time.Time
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	Rank      float32
+}
+
+func (q *Queries) SearchLendingsByGroupIDsAndUserID(ctx context.Context, arg SearchLendingsByGroupIDsAndUserIDParams) ([]SearchLendingsByGroupIDsAndUserIDRow, error) {
+	rows, err := q.db.Query(ctx, searchLendingsByGroupIDsAndUserID,
+		arg.Query,
+		arg.GroupIds,
+		arg.UserID,
+		arg.Pattern,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SearchLendingsByGroupIDsAndUserIDRow
+	for rows.Next() {
+		var i SearchLendingsByGroupIDsAndUserIDRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.GroupID,
+			&i.Name,
+			&i.Amount,
+			&i.EventDate,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Rank,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const sumOutstandingCreditAmount = `-- name: SumOutstandingCreditAmount :one
+SELECT COALESCE(SUM(amount), 0)::bigint AS amount
+FROM balances
+WHERE amount > 0
+`
+
+func (q *Queries) SumOutstandingCreditAmount(ctx context.Context) (int64, error) {
+	row := q.db.QueryRow(ctx, sumOutstandingCreditAmount)
+	var amount int64
+	err := row.Scan(&amount)
+	return amount, err
+}
+
+const transferGroupOwnership = `-- name: TransferGroupOwnership :exec
+UPDATE groups
+SET created_by = $1,
+    updated_at = current_timestamp,
+    version = version + 1
+WHERE id = $2
+`
+
+type TransferGroupOwnershipParams struct {
+	CreatedBy string
+	ID        string
+}
+
+func (q *Queries) TransferGroupOwnership(ctx context.Context, arg TransferGroupOwnershipParams) error {
+	_, err := q.db.Exec(ctx, transferGroupOwnership, arg.CreatedBy, arg.ID)
+	return err
+}
+
+const updateEvent = `-- name: UpdateEvent :execrows
 UPDATE events
 SET name = $2,
     amount = $3,
     event_date = $4,
-    updated_at = $5
-WHERE id = $1
+    updated_at = $5,
+    version = $6,
+    due_date = $7
+WHERE id = $1 AND version = $6 - 1
 `
 
 type UpdateEventParams struct {
@@ -915,35 +2313,101 @@ type UpdateEventParams struct {
 	Amount    int32
 	EventDate time.Time
 	UpdatedAt time.Time
+	Version   int32
+	DueDate   *time.Time
 }
 
-func (q *Queries) UpdateEvent(ctx context.Context, arg UpdateEventParams) error {
-	_, err := q.db.Exec(ctx, updateEvent,
+func (q *Queries) UpdateEvent(ctx context.Context, arg UpdateEventParams) (int64, error) {
+	result, err := q.db.Exec(ctx, updateEvent,
 		arg.ID,
 		arg.Name,
 		arg.Amount,
 		arg.EventDate,
 		arg.UpdatedAt,
+		arg.Version,
+		arg.DueDate,
 	)
-	return err
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
 }
 
-const updateGroup = `-- name: UpdateGroup :exec
+const updateGroup = `-- name: UpdateGroup :execrows
 UPDATE groups
 SET name = $2,
-    updated_at = $3
-WHERE id = $1
+    created_by = $3,
+    updated_at = $4,
+    version = $5,
+    payment_terms_days = $6
+WHERE id = $1 AND version = $5 - 1
 `
 
 type UpdateGroupParams struct {
+	ID               string
+	Name             string
+	CreatedBy        string
+	UpdatedAt        time.Time
+	Version          int32
+	PaymentTermsDays *int32
+}
+
+func (q *Queries) UpdateGroup(ctx context.Context, arg UpdateGroupParams) (int64, error) {
+	result, err := q.db.Exec(ctx, updateGroup,
+		arg.ID,
+		arg.Name,
+		arg.CreatedBy,
+		arg.UpdatedAt,
+		arg.Version,
+		arg.PaymentTermsDays,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const updateIdempotencyKeyResponse = `-- name: UpdateIdempotencyKeyResponse :exec
+UPDATE idempotency_keys
+SET status_code = $3, content_type = $4, response_body = $5
+WHERE user_id = $1 AND key = $2
+`
+
+type UpdateIdempotencyKeyResponseParams struct {
+	UserID       string
+	Key          string
+	StatusCode   *int32
+	ContentType  *string
+	ResponseBody []byte
+}
+
+func (q *Queries) UpdateIdempotencyKeyResponse(ctx context.Context, arg UpdateIdempotencyKeyResponseParams) error {
+	_, err := q.db.Exec(ctx, updateIdempotencyKeyResponse,
+		arg.UserID,
+		arg.Key,
+		arg.StatusCode,
+		arg.ContentType,
+		arg.ResponseBody,
+	)
+	return err
+}
+
+const updateLendingComment = `-- name: UpdateLendingComment :execrows
+UPDATE lending_comments SET body = $2, updated_at = $3 WHERE id = $1
+`
+
+type UpdateLendingCommentParams struct {
 	ID        string
-	Name      string
+	Body      string
 	UpdatedAt time.Time
 }
 
-func (q *Queries) UpdateGroup(ctx context.Context, arg UpdateGroupParams) error {
-	_, err := q.db.Exec(ctx, updateGroup, arg.ID, arg.Name, arg.UpdatedAt)
-	return err
+func (q *Queries) UpdateLendingComment(ctx context.Context, arg UpdateLendingCommentParams) (int64, error) {
+	result, err := q.db.Exec(ctx, updateLendingComment, arg.ID, arg.Body, arg.UpdatedAt)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
 }
 
 const updatePaymentAmount = `-- name: UpdatePaymentAmount :exec
@@ -963,21 +2427,30 @@ func (q *Queries) UpdatePaymentAmount(ctx context.Context, arg UpdatePaymentAmou
 	return err
 }
 
-const updateRepayment = `-- name: UpdateRepayment :exec
+const updateRepayment = `-- name: UpdateRepayment :execrows
 UPDATE payments
-SET amount = $2, updated_at = $3
-WHERE id = $1
+SET amount = $2, updated_at = $3, version = $4
+WHERE id = $1 AND version = $4 - 1
 `
 
 type UpdateRepaymentParams struct {
 	ID        string
 	Amount    int32
 	UpdatedAt time.Time
+	Version   int32
 }
 
-func (q *Queries) UpdateRepayment(ctx context.Context, arg UpdateRepaymentParams) error {
-	_, err := q.db.Exec(ctx, updateRepayment, arg.ID, arg.Amount, arg.UpdatedAt)
-	return err
+func (q *Queries) UpdateRepayment(ctx context.Context, arg UpdateRepaymentParams) (int64, error) {
+	result, err := q.db.Exec(ctx, updateRepayment,
+		arg.ID,
+		arg.Amount,
+		arg.UpdatedAt,
+		arg.Version,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
 }
 
 const updateUser = `-- name: UpdateUser :exec
@@ -1010,3 +2483,17 @@ func (q *Queries) UpdateUserID(ctx context.Context, arg UpdateUserIDParams) erro
 	_, err := q.db.Exec(ctx, updateUserID, arg.ID, arg.ID_2)
 	return err
 }
+
+const updateUserPrivacy = `-- name: UpdateUserPrivacy :exec
+UPDATE users SET discoverable_by_email = $2, updated_at = current_timestamp WHERE id = $1
+`
+
+type UpdateUserPrivacyParams struct {
+	ID                  string
+	DiscoverableByEmail bool
+}
+
+func (q *Queries) UpdateUserPrivacy(ctx context.Context, arg UpdateUserPrivacyParams) error {
+	_, err := q.db.Exec(ctx, updateUserPrivacy, arg.ID, arg.DiscoverableByEmail)
+	return err
+}
@@ -0,0 +1,206 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/haebeal/datti/internal/domain"
+	"github.com/haebeal/datti/internal/gateway/postgres"
+	"github.com/jackc/pgx/v5"
+	"github.com/oklog/ulid/v2"
+	"go.opentelemetry.io/otel/codes"
+)
+
+// CommentRepositoryImpl コメントリポジトリの実装
+type CommentRepositoryImpl struct {
+	queries *postgres.Queries
+}
+
+// NewCommentRepository CommentRepositoryImplのファクトリ関数
+func NewCommentRepository(queries *postgres.Queries) *CommentRepositoryImpl {
+	return &CommentRepositoryImpl{
+		queries: queries,
+	}
+}
+
+// Create コメントを作成する
+func (cr *CommentRepositoryImpl) Create(ctx context.Context, c *domain.Comment) (err error) {
+	ctx, span := tracer.Start(ctx, "repository.Comment.Create")
+	defer func() {
+		if err != nil {
+			span.SetStatus(codes.Error, err.Error())
+			span.RecordError(err)
+		}
+		span.End()
+	}()
+
+	err = cr.queries.CreateLendingComment(ctx, postgres.CreateLendingCommentParams{
+		ID:        c.ID().String(),
+		EventID:   c.LendingID().String(),
+		UserID:    c.Author().ID(),
+		Body:      c.Body(),
+		CreatedAt: c.CreatedAt(),
+		UpdatedAt: c.UpdatedAt(),
+	})
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// FindByID IDでコメントを取得する
+func (cr *CommentRepositoryImpl) FindByID(ctx context.Context, id ulid.ULID) (c *domain.Comment, err error) {
+	ctx, span := tracer.Start(ctx, "repository.Comment.FindByID")
+	defer func() {
+		if err != nil {
+			span.SetStatus(codes.Error, err.Error())
+			span.RecordError(err)
+		}
+		span.End()
+	}()
+
+	row, err := cr.queries.FindLendingCommentByID(ctx, id.String())
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.NewNotFoundError("comment", id.String())
+		}
+		return nil, err
+	}
+
+	return newComment(ctx, row.ID, row.EventID, row.UserID, row.UserName, row.UserAvatar, row.UserEmail, row.Body, row.CreatedAt, row.UpdatedAt)
+}
+
+// FindByLendingID 立て替えのコメント一覧を古い順に取得する
+func (cr *CommentRepositoryImpl) FindByLendingID(ctx context.Context, lendingID ulid.ULID, cursor *string, limit int32) (comments []*domain.Comment, err error) {
+	ctx, span := tracer.Start(ctx, "repository.Comment.FindByLendingID")
+	defer func() {
+		if err != nil {
+			span.SetStatus(codes.Error, err.Error())
+			span.RecordError(err)
+		}
+		span.End()
+	}()
+
+	rows, err := cr.queries.FindLendingCommentsByEventIDWithCursor(ctx, postgres.FindLendingCommentsByEventIDWithCursorParams{
+		EventID: lendingID.String(),
+		Cursor:  cursor,
+		Limit:   limit,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	comments = make([]*domain.Comment, 0, len(rows))
+	for _, row := range rows {
+		comment, err := newComment(ctx, row.ID, row.EventID, row.UserID, row.UserName, row.UserAvatar, row.UserEmail, row.Body, row.CreatedAt, row.UpdatedAt)
+		if err != nil {
+			return nil, err
+		}
+		comments = append(comments, comment)
+	}
+
+	return comments, nil
+}
+
+// CountByLendingIDs 立て替えごとのコメント数を取得する (コメントがない立て替えは含まない)
+func (cr *CommentRepositoryImpl) CountByLendingIDs(ctx context.Context, lendingIDs []ulid.ULID) (counts map[ulid.ULID]int32, err error) {
+	ctx, span := tracer.Start(ctx, "repository.Comment.CountByLendingIDs")
+	defer func() {
+		if err != nil {
+			span.SetStatus(codes.Error, err.Error())
+			span.RecordError(err)
+		}
+		span.End()
+	}()
+
+	counts = make(map[ulid.ULID]int32, len(lendingIDs))
+	if len(lendingIDs) == 0 {
+		return counts, nil
+	}
+
+	ids := make([]string, 0, len(lendingIDs))
+	for _, id := range lendingIDs {
+		ids = append(ids, id.String())
+	}
+
+	rows, err := cr.queries.CountLendingCommentsByEventIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		id, err := ulid.Parse(row.EventID)
+		if err != nil {
+			return nil, err
+		}
+		counts[id] = row.Count
+	}
+
+	return counts, nil
+}
+
+// Update コメントを更新する
+func (cr *CommentRepositoryImpl) Update(ctx context.Context, c *domain.Comment) (err error) {
+	ctx, span := tracer.Start(ctx, "repository.Comment.Update")
+	defer func() {
+		if err != nil {
+			span.SetStatus(codes.Error, err.Error())
+			span.RecordError(err)
+		}
+		span.End()
+	}()
+
+	rows, err := cr.queries.UpdateLendingComment(ctx, postgres.UpdateLendingCommentParams{
+		ID:        c.ID().String(),
+		Body:      c.Body(),
+		UpdatedAt: c.UpdatedAt(),
+	})
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return domain.NewNotFoundError("comment", c.ID().String())
+	}
+
+	return nil
+}
+
+// Delete コメントを削除する
+func (cr *CommentRepositoryImpl) Delete(ctx context.Context, id ulid.ULID) (err error) {
+	ctx, span := tracer.Start(ctx, "repository.Comment.Delete")
+	defer func() {
+		if err != nil {
+			span.SetStatus(codes.Error, err.Error())
+			span.RecordError(err)
+		}
+		span.End()
+	}()
+
+	rows, err := cr.queries.DeleteLendingComment(ctx, id.String())
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return domain.NewNotFoundError("comment", id.String())
+	}
+
+	return nil
+}
+
+func newComment(ctx context.Context, id, eventID, userID, userName, userAvatar, userEmail, body string, createdAt, updatedAt time.Time) (*domain.Comment, error) {
+	commentID, err := ulid.Parse(id)
+	if err != nil {
+		return nil, err
+	}
+	lendingID, err := ulid.Parse(eventID)
+	if err != nil {
+		return nil, err
+	}
+	author, err := domain.NewUser(ctx, userID, userName, userAvatar, userEmail)
+	if err != nil {
+		return nil, err
+	}
+	return domain.NewComment(ctx, commentID, lendingID, author, body, createdAt, updatedAt)
+}
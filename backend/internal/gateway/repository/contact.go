@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/haebeal/datti/internal/domain"
+	"github.com/haebeal/datti/internal/gateway/postgres"
+	"go.opentelemetry.io/otel/codes"
+)
+
+// ContactRepositoryImpl 連絡先リポジトリの実装
+type ContactRepositoryImpl struct {
+	queries *postgres.Queries
+}
+
+// NewContactRepository ContactRepositoryImplのファクトリ関数
+func NewContactRepository(queries *postgres.Queries) *ContactRepositoryImpl {
+	return &ContactRepositoryImpl{
+		queries: queries,
+	}
+}
+
+// Add ユーザーの連絡先にcontactを追加する
+func (cr *ContactRepositoryImpl) Add(ctx context.Context, userID string, contact *domain.User) (err error) {
+	ctx, span := tracer.Start(ctx, "repository.Contact.Add")
+	defer func() {
+		if err != nil {
+			span.SetStatus(codes.Error, err.Error())
+			span.RecordError(err)
+		}
+		span.End()
+	}()
+
+	err = cr.queries.AddContact(ctx, postgres.AddContactParams{
+		UserID:    userID,
+		ContactID: contact.ID(),
+	})
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Remove 明示的に追加した連絡先を削除する
+func (cr *ContactRepositoryImpl) Remove(ctx context.Context, userID string, contactID string) (err error) {
+	ctx, span := tracer.Start(ctx, "repository.Contact.Remove")
+	defer func() {
+		if err != nil {
+			span.SetStatus(codes.Error, err.Error())
+			span.RecordError(err)
+		}
+		span.End()
+	}()
+
+	rows, err := cr.queries.DeleteContact(ctx, postgres.DeleteContactParams{
+		UserID:    userID,
+		ContactID: contactID,
+	})
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return domain.NewNotFoundError("contact", contactID)
+	}
+
+	return nil
+}
+
+// FindByUserID ユーザーの連絡先一覧を取得する
+func (cr *ContactRepositoryImpl) FindByUserID(ctx context.Context, userID string) (contacts []*domain.Contact, err error) {
+	ctx, span := tracer.Start(ctx, "repository.Contact.FindByUserID")
+	defer func() {
+		if err != nil {
+			span.SetStatus(codes.Error, err.Error())
+			span.RecordError(err)
+		}
+		span.End()
+	}()
+
+	rows, err := cr.queries.FindContactsByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	contacts = make([]*domain.Contact, 0, len(rows))
+	for _, row := range rows {
+		user, err := domain.NewUser(ctx, row.ID, row.Name, row.Avatar, row.Email)
+		if err != nil {
+			return nil, err
+		}
+		contact, err := domain.NewContact(user, row.Added)
+		if err != nil {
+			return nil, err
+		}
+		contacts = append(contacts, contact)
+	}
+
+	return contacts, nil
+}
@@ -2,13 +2,17 @@ package repository
 
 import (
 	"context"
+	"errors"
 
 	"github.com/haebeal/datti/internal/domain"
 	"github.com/haebeal/datti/internal/gateway/postgres"
+	"github.com/jackc/pgx/v5"
 	"go.opentelemetry.io/otel/codes"
 )
 
 // CreditRepositoryImpl 債権/債務リポジトリの実装
+// paymentsのトリガーで更新されるユーザーの組ごとの残高テーブルから取得する
+// 延滞額は返済期限のある立て替えの支払いのみを集計し、残高と合わせて求める
 type CreditRepositoryImpl struct {
 	queries *postgres.Queries
 }
@@ -32,34 +36,19 @@ func (r *CreditRepositoryImpl) FindByUserID(ctx context.Context, userID string)
 		span.End()
 	}()
 
-	// 貸している金額を取得
-	lendings, err := r.queries.ListLendingCreditAmountsByUserID(ctx, userID)
+	rows, err := r.queries.ListBalancesByUserID(ctx, userID)
 	if err != nil {
 		return nil, err
 	}
 
-	// 借りている金額を取得
-	borrowings, err := r.queries.ListBorrowingCreditAmountsByUserID(ctx, userID)
+	dated, err := r.datedDebts(ctx, userID, nil)
 	if err != nil {
 		return nil, err
 	}
 
-	// ユーザーごとの残高を計算
-	balances := make(map[string]int64)
-	for _, l := range lendings {
-		balances[l.UserID] += l.Amount // 貸し = 正
-	}
-	for _, b := range borrowings {
-		balances[b.UserID] -= b.Amount // 借り = 負
-	}
-
-	// 0以外のCreditを作成
-	credits = make([]*domain.Credit, 0, len(balances))
-	for otherUserID, amount := range balances {
-		if amount == 0 {
-			continue
-		}
-		credit, err := domain.NewCredit(ctx, otherUserID, amount)
+	credits = make([]*domain.Credit, 0, len(rows))
+	for _, row := range rows {
+		credit, err := domain.NewCredit(ctx, row.UserID, row.Amount, domain.OverdueAmount(row.Amount, dated[row.UserID]))
 		if err != nil {
 			return nil, err
 		}
@@ -81,34 +70,61 @@ func (r *CreditRepositoryImpl) FindByUserIDAndOtherUserID(ctx context.Context, u
 		span.End()
 	}()
 
-	// 貸している金額を取得
-	lendings, err := r.queries.ListLendingCreditAmountsByUserID(ctx, userID)
-	if err != nil {
+	amount, err := r.queries.FindBalance(ctx, postgres.FindBalanceParams{
+		UserID:      userID,
+		OtherUserID: otherUserID,
+	})
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
 		return nil, err
 	}
 
-	// 借りている金額を取得
-	borrowings, err := r.queries.ListBorrowingCreditAmountsByUserID(ctx, userID)
+	if amount == 0 {
+		return nil, domain.NewNotFoundError("credit", otherUserID)
+	}
+
+	dated, err := r.datedDebts(ctx, userID, &otherUserID)
 	if err != nil {
 		return nil, err
 	}
 
-	// 対象ユーザーとの残高を計算
-	var amount int64
-	for _, l := range lendings {
-		if l.UserID == otherUserID {
-			amount += l.Amount // 貸し = 正
-		}
+	return domain.NewCredit(ctx, otherUserID, amount, domain.OverdueAmount(amount, dated[otherUserID]))
+}
+
+// datedDebts 返済期限のある立て替えによる相手ユーザーごとの貸し/借りの合計を取得する
+// otherUserIDを指定した場合はそのユーザーとの分のみ取得する
+func (r *CreditRepositoryImpl) datedDebts(ctx context.Context, userID string, otherUserID *string) (map[string]domain.DatedDebts, error) {
+	rows, err := r.queries.ListDatedDebtsByUserID(ctx, postgres.ListDatedDebtsByUserIDParams{
+		UserID:      userID,
+		OtherUserID: otherUserID,
+	})
+	if err != nil {
+		return nil, err
 	}
-	for _, b := range borrowings {
-		if b.UserID == otherUserID {
-			amount -= b.Amount // 借り = 負
+
+	debts := make(map[string]domain.DatedDebts, len(rows))
+	for _, row := range rows {
+		debts[row.UserID] = domain.DatedDebts{
+			Lent:           row.Lent,
+			Borrowed:       row.Borrowed,
+			LentNotDue:     row.LentNotDue,
+			BorrowedNotDue: row.BorrowedNotDue,
 		}
 	}
 
-	if amount == 0 {
-		return nil, domain.NewNotFoundError("credit", otherUserID)
-	}
+	return debts, nil
+}
+
+// TotalOutstanding 全ユーザー間の未精算の金額の合計を取得
+// ユーザーの組ごとに相殺した残高の絶対値を合計する
+func (r *CreditRepositoryImpl) TotalOutstanding(ctx context.Context) (amount int64, err error) {
+	ctx, span := tracer.Start(ctx, "repository.Credit.TotalOutstanding")
+	defer func() {
+		if err != nil {
+			span.SetStatus(codes.Error, err.Error())
+			span.RecordError(err)
+		}
+		span.End()
+	}()
 
-	return domain.NewCredit(ctx, otherUserID, amount)
+	return r.queries.SumOutstandingCreditAmount(ctx)
 }
@@ -33,11 +33,12 @@ func (gr *GroupRepositoryImpl) Create(ctx context.Context, g *domain.Group) (err
 	}()
 
 	err = gr.queries.CreateGroup(ctx, postgres.CreateGroupParams{
-		ID:        g.ID().String(),
-		Name:      g.Name(),
-		CreatedBy: g.CreatedBy(),
-		CreatedAt: g.CreatedAt(),
-		UpdatedAt: g.UpdatedAt(),
+		ID:               g.ID().String(),
+		Name:             g.Name(),
+		CreatedBy:        g.CreatedBy(),
+		CreatedAt:        g.CreatedAt(),
+		UpdatedAt:        g.UpdatedAt(),
+		PaymentTermsDays: g.PaymentTermsDays(),
 	})
 	if err != nil {
 		return err
@@ -68,7 +69,7 @@ func (gr *GroupRepositoryImpl) FindByMemberUserID(ctx context.Context, userID st
 		if err != nil {
 			return nil, err
 		}
-		group, err := domain.NewGroup(ctx, groupID, row.Name, row.CreatedBy, row.CreatedAt, row.UpdatedAt)
+		group, err := domain.NewGroup(ctx, groupID, row.Name, row.CreatedBy, row.PaymentTermsDays, row.CreatedAt, row.UpdatedAt, row.Version)
 		if err != nil {
 			return nil, err
 		}
@@ -99,7 +100,7 @@ func (gr *GroupRepositoryImpl) FindByID(ctx context.Context, id ulid.ULID) (g *d
 		return nil, err
 	}
 
-	g, err = domain.NewGroup(ctx, parsedID, row.Name, row.CreatedBy, row.CreatedAt, row.UpdatedAt)
+	g, err = domain.NewGroup(ctx, parsedID, row.Name, row.CreatedBy, row.PaymentTermsDays, row.CreatedAt, row.UpdatedAt, row.Version)
 	if err != nil {
 		return nil, err
 	}
@@ -118,14 +119,21 @@ func (gr *GroupRepositoryImpl) Update(ctx context.Context, g *domain.Group) (err
 		span.End()
 	}()
 
-	err = gr.queries.UpdateGroup(ctx, postgres.UpdateGroupParams{
-		ID:        g.ID().String(),
-		Name:      g.Name(),
-		UpdatedAt: g.UpdatedAt(),
+	// 読み込み時のバージョンから変わっていない場合のみ更新する
+	rows, err := gr.queries.UpdateGroup(ctx, postgres.UpdateGroupParams{
+		ID:               g.ID().String(),
+		Name:             g.Name(),
+		CreatedBy:        g.CreatedBy(),
+		UpdatedAt:        g.UpdatedAt(),
+		Version:          g.Version(),
+		PaymentTermsDays: g.PaymentTermsDays(),
 	})
 	if err != nil {
 		return err
 	}
+	if rows == 0 {
+		return domain.NewStaleVersionError("group")
+	}
 
 	return nil
 }
@@ -196,7 +204,12 @@ func (gr *GroupRepositoryImpl) FindMembersByID(ctx context.Context, id ulid.ULID
 
 	members = make([]*domain.User, 0, len(rows))
 	for _, row := range rows {
-		user, err := domain.NewUser(ctx, row.ID, row.Name, row.Avatar, row.Email)
+		var user *domain.User
+		if row.GuestGroupID != nil {
+			user, err = domain.NewGuestUser(ctx, row.ID, row.Name, row.Email)
+		} else {
+			user, err = domain.NewUser(ctx, row.ID, row.Name, row.Avatar, row.Email)
+		}
 		if err != nil {
 			return nil, err
 		}
@@ -206,7 +219,42 @@ func (gr *GroupRepositoryImpl) FindMembersByID(ctx context.Context, id ulid.ULID
 	return members, nil
 }
 
+// IsMember ユーザーがグループのメンバーであるかを確認する
+func (gr *GroupRepositoryImpl) IsMember(ctx context.Context, id ulid.ULID, userID string) (isMember bool, err error) {
+	ctx, span := tracer.Start(ctx, "repository.Group.IsMember")
+	defer func() {
+		if err != nil {
+			span.SetStatus(codes.Error, err.Error())
+			span.RecordError(err)
+		}
+		span.End()
+	}()
+
+	return gr.queries.ExistsGroupMember(ctx, postgres.ExistsGroupMemberParams{
+		GroupID: id.String(),
+		UserID:  userID,
+	})
+}
+
+// FindMemberIDs userIDsのうちグループのメンバーであるユーザーのIDをID順に取得する
+func (gr *GroupRepositoryImpl) FindMemberIDs(ctx context.Context, id ulid.ULID, userIDs []string) (memberIDs []string, err error) {
+	ctx, span := tracer.Start(ctx, "repository.Group.FindMemberIDs")
+	defer func() {
+		if err != nil {
+			span.SetStatus(codes.Error, err.Error())
+			span.RecordError(err)
+		}
+		span.End()
+	}()
+
+	return gr.queries.FindGroupMemberIDsIn(ctx, postgres.FindGroupMemberIDsInParams{
+		GroupID: id.String(),
+		UserIds: userIDs,
+	})
+}
+
 // RemoveMember グループからメンバーを削除する
+// 脱退しても貸し借りが消えないよう、メンバーが関与する立て替えの支払いは残す
 func (gr *GroupRepositoryImpl) RemoveMember(ctx context.Context, g *domain.Group, u *domain.User) (err error) {
 	ctx, span := tracer.Start(ctx, "repository.Group.RemoveMember")
 	defer func() {
@@ -217,15 +265,6 @@ func (gr *GroupRepositoryImpl) RemoveMember(ctx context.Context, g *domain.Group
 		span.End()
 	}()
 
-	// メンバーに関連するpaymentsを先に削除
-	err = gr.queries.DeletePaymentsByGroupIDAndUserID(ctx, postgres.DeletePaymentsByGroupIDAndUserIDParams{
-		GroupID: g.ID().String(),
-		PayerID: u.ID(),
-	})
-	if err != nil {
-		return err
-	}
-
 	// グループメンバーを削除
 	err = gr.queries.DeleteGroupMember(ctx, postgres.DeleteGroupMemberParams{
 		GroupID: g.ID().String(),
@@ -235,5 +274,14 @@ func (gr *GroupRepositoryImpl) RemoveMember(ctx context.Context, g *domain.Group
 		return err
 	}
 
+	// ゲストは他のグループに所属できないため、グループから外れたら削除する
+	// 立て替えや返済の記録が残っている場合は履歴を表示できるように残す
+	if u.IsGuest() {
+		err = gr.queries.DeleteGuestIfUnreferenced(ctx, u.ID())
+		if err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
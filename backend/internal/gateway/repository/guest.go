@@ -0,0 +1,169 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/haebeal/datti/internal/domain"
+	"github.com/haebeal/datti/internal/gateway/postgres"
+	"github.com/jackc/pgx/v5"
+	"github.com/oklog/ulid/v2"
+	"go.opentelemetry.io/otel/codes"
+)
+
+// GuestRepositoryImpl ゲストリポジトリの実装
+type GuestRepositoryImpl struct {
+	db      DB
+	queries *postgres.Queries
+}
+
+// NewGuestRepository GuestRepositoryImplのファクトリ関数
+func NewGuestRepository(db DB) *GuestRepositoryImpl {
+	return &GuestRepositoryImpl{
+		db:      db,
+		queries: postgres.New(db),
+	}
+}
+
+// Create ゲストを作成し、グループのメンバーに追加する
+func (gr *GuestRepositoryImpl) Create(ctx context.Context, g *domain.Guest) (err error) {
+	ctx, span := tracer.Start(ctx, "repository.Guest.Create")
+	defer func() {
+		if err != nil {
+			span.SetStatus(codes.Error, err.Error())
+			span.RecordError(err)
+		}
+		span.End()
+	}()
+
+	groupID := g.GroupID().String()
+	inviteToken := g.InviteToken()
+	err = gr.queries.CreateGuest(ctx, postgres.CreateGuestParams{
+		ID:           g.ID(),
+		Name:         g.Name(),
+		Email:        g.Email(),
+		GuestGroupID: &groupID,
+		InviteToken:  &inviteToken,
+	})
+	if err != nil {
+		return err
+	}
+
+	err = gr.queries.AddGroupMember(ctx, postgres.AddGroupMemberParams{
+		GroupID: groupID,
+		UserID:  g.ID(),
+	})
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// FindByGroupID グループのゲスト一覧を取得する
+func (gr *GuestRepositoryImpl) FindByGroupID(ctx context.Context, groupID ulid.ULID) (guests []*domain.Guest, err error) {
+	ctx, span := tracer.Start(ctx, "repository.Guest.FindByGroupID")
+	defer func() {
+		if err != nil {
+			span.SetStatus(codes.Error, err.Error())
+			span.RecordError(err)
+		}
+		span.End()
+	}()
+
+	id := groupID.String()
+	rows, err := gr.queries.FindGuestsByGroupID(ctx, &id)
+	if err != nil {
+		return nil, err
+	}
+
+	guests = make([]*domain.Guest, 0, len(rows))
+	for _, row := range rows {
+		guest, err := newGuest(ctx, row.ID, row.Name, row.Email, row.GroupID, row.InviteToken)
+		if err != nil {
+			return nil, err
+		}
+		guests = append(guests, guest)
+	}
+
+	return guests, nil
+}
+
+// FindByInviteToken 招待トークンでゲストを取得する
+func (gr *GuestRepositoryImpl) FindByInviteToken(ctx context.Context, token string) (guest *domain.Guest, err error) {
+	ctx, span := tracer.Start(ctx, "repository.Guest.FindByInviteToken")
+	defer func() {
+		if err != nil {
+			span.SetStatus(codes.Error, err.Error())
+			span.RecordError(err)
+		}
+		span.End()
+	}()
+
+	row, err := gr.queries.FindGuestByInviteToken(ctx, &token)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			// 招待トークンはエラーの詳細に含めない
+			return nil, domain.NewNotFoundError("guest", "")
+		}
+		return nil, err
+	}
+
+	return newGuest(ctx, row.ID, row.Name, row.Email, row.GroupID, row.InviteToken)
+}
+
+// FindByEmail メールアドレスが一致するゲスト一覧を取得する
+func (gr *GuestRepositoryImpl) FindByEmail(ctx context.Context, email string) (guests []*domain.Guest, err error) {
+	ctx, span := tracer.Start(ctx, "repository.Guest.FindByEmail")
+	defer func() {
+		if err != nil {
+			span.SetStatus(codes.Error, err.Error())
+			span.RecordError(err)
+		}
+		span.End()
+	}()
+
+	rows, err := gr.queries.FindGuestsByEmail(ctx, email)
+	if err != nil {
+		return nil, err
+	}
+
+	guests = make([]*domain.Guest, 0, len(rows))
+	for _, row := range rows {
+		guest, err := newGuest(ctx, row.ID, row.Name, row.Email, row.GroupID, row.InviteToken)
+		if err != nil {
+			return nil, err
+		}
+		guests = append(guests, guest)
+	}
+
+	return guests, nil
+}
+
+// Claim ゲストのグループ所属や支払いをuに付け替えてゲストを削除する
+func (gr *GuestRepositoryImpl) Claim(ctx context.Context, g *domain.Guest, u *domain.User) (err error) {
+	ctx, span := tracer.Start(ctx, "repository.Guest.Claim")
+	defer func() {
+		if err != nil {
+			span.SetStatus(codes.Error, err.Error())
+			span.RecordError(err)
+		}
+		span.End()
+	}()
+
+	return transaction(ctx, gr.db, func(queries *postgres.Queries) error {
+		return mergeUser(ctx, queries, g.ID(), u.ID())
+	})
+}
+
+func newGuest(ctx context.Context, id, name, email, groupID, inviteToken string) (*domain.Guest, error) {
+	user, err := domain.NewGuestUser(ctx, id, name, email)
+	if err != nil {
+		return nil, err
+	}
+	gid, err := ulid.Parse(groupID)
+	if err != nil {
+		return nil, err
+	}
+	return domain.NewGuest(ctx, user, gid, inviteToken)
+}
@@ -0,0 +1,119 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/haebeal/datti/internal/domain"
+	"github.com/haebeal/datti/internal/gateway/postgres"
+	"go.opentelemetry.io/otel/codes"
+)
+
+// IdempotencyKeyRepositoryImpl 冪等性キーリポジトリの実装
+type IdempotencyKeyRepositoryImpl struct {
+	queries *postgres.Queries
+}
+
+// NewIdempotencyKeyRepository IdempotencyKeyRepositoryImplのファクトリ関数
+func NewIdempotencyKeyRepository(queries *postgres.Queries) *IdempotencyKeyRepositoryImpl {
+	return &IdempotencyKeyRepositoryImpl{
+		queries: queries,
+	}
+}
+
+// Reserve キーを処理中として登録する
+func (ir *IdempotencyKeyRepositoryImpl) Reserve(ctx context.Context, k *domain.IdempotencyKey, expiresBefore time.Time) (existing *domain.IdempotencyKey, err error) {
+	ctx, span := tracer.Start(ctx, "repository.IdempotencyKey.Reserve")
+	defer func() {
+		if err != nil {
+			span.SetStatus(codes.Error, err.Error())
+			span.RecordError(err)
+		}
+		span.End()
+	}()
+
+	// 有効期限切れのキーは再利用できるように削除する
+	err = ir.queries.DeleteExpiredIdempotencyKey(ctx, postgres.DeleteExpiredIdempotencyKeyParams{
+		UserID:    k.UserID(),
+		Key:       k.Key(),
+		CreatedAt: expiresBefore,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := ir.queries.CreateIdempotencyKey(ctx, postgres.CreateIdempotencyKeyParams{
+		UserID:      k.UserID(),
+		Key:         k.Key(),
+		Method:      k.Method(),
+		Path:        k.Path(),
+		RequestHash: k.RequestHash(),
+		CreatedAt:   k.CreatedAt(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if rows > 0 {
+		return nil, nil
+	}
+
+	// 既に登録されているキーを返す
+	row, err := ir.queries.FindIdempotencyKey(ctx, postgres.FindIdempotencyKeyParams{
+		UserID: k.UserID(),
+		Key:    k.Key(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var statusCode int
+	if row.StatusCode != nil {
+		statusCode = int(*row.StatusCode)
+	}
+	var contentType string
+	if row.ContentType != nil {
+		contentType = *row.ContentType
+	}
+
+	return domain.NewIdempotencyKey(row.UserID, row.Key, row.Method, row.Path, row.RequestHash, statusCode, contentType, row.ResponseBody, row.CreatedAt)
+}
+
+// Complete 処理結果のレスポンスを保存する
+func (ir *IdempotencyKeyRepositoryImpl) Complete(ctx context.Context, k *domain.IdempotencyKey) (err error) {
+	ctx, span := tracer.Start(ctx, "repository.IdempotencyKey.Complete")
+	defer func() {
+		if err != nil {
+			span.SetStatus(codes.Error, err.Error())
+			span.RecordError(err)
+		}
+		span.End()
+	}()
+
+	statusCode := int32(k.StatusCode())
+	contentType := k.ContentType()
+
+	return ir.queries.UpdateIdempotencyKeyResponse(ctx, postgres.UpdateIdempotencyKeyResponseParams{
+		UserID:       k.UserID(),
+		Key:          k.Key(),
+		StatusCode:   &statusCode,
+		ContentType:  &contentType,
+		ResponseBody: k.ResponseBody(),
+	})
+}
+
+// Delete キーを削除する
+func (ir *IdempotencyKeyRepositoryImpl) Delete(ctx context.Context, k *domain.IdempotencyKey) (err error) {
+	ctx, span := tracer.Start(ctx, "repository.IdempotencyKey.Delete")
+	defer func() {
+		if err != nil {
+			span.SetStatus(codes.Error, err.Error())
+			span.RecordError(err)
+		}
+		span.End()
+	}()
+
+	return ir.queries.DeleteIdempotencyKey(ctx, postgres.DeleteIdempotencyKeyParams{
+		UserID: k.UserID(),
+		Key:    k.Key(),
+	})
+}
@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"errors"
+	"strings"
 
 	"github.com/haebeal/datti/internal/domain"
 	"github.com/haebeal/datti/internal/gateway/postgres"
@@ -34,44 +35,57 @@ func (lr *LendingRepositoryImpl) Create(ctx context.Context, g *domain.Group, l
 		span.End()
 	}()
 
-	// イベントを作成
+	// イベントを作成 (個人間の立て替えはグループを持たない)
+	var groupID *string
+	if g != nil {
+		id := g.ID().String()
+		groupID = &id
+	}
+	createdBy := l.Payer().ID()
 	err = lr.queries.CreateEvent(ctx, postgres.CreateEventParams{
 		ID:        l.ID().String(),
-		GroupID:   g.ID().String(),
+		GroupID:   groupID,
 		Name:      l.Name(),
 		Amount:    int32(l.Amount()),
 		EventDate: l.EventDate(),
 		CreatedAt: l.CreatedAt(),
 		UpdatedAt: l.UpdatedAt(),
+		CreatedBy: &createdBy,
+		DueDate:   l.DueDate(),
 	})
 	if err != nil {
 		return err
 	}
 
-	// 各債務者に対して支払いを作成
-	for _, debtor := range l.Debtors() {
-		paymentID := ulid.Make()
+	// 支払いマトリクスの各要素を支払いとして作成
+	err = lr.createAllocationPayments(ctx, l)
+	if err != nil {
+		return err
+	}
 
-		err = lr.queries.CreatePayment(ctx, postgres.CreatePaymentParams{
-			ID:       paymentID.String(),
-			PayerID:  l.Payer().ID(),
-			DebtorID: debtor.ID(),
-			Amount:   int32(debtor.Amount()),
-		})
-		if err != nil {
-			return err
-		}
+	return nil
+}
 
-		err = lr.queries.CreateEventPayment(ctx, postgres.CreateEventPaymentParams{
-			EventID:   l.ID().String(),
-			PaymentID: paymentID.String(),
-		})
-		if err != nil {
-			return err
-		}
+// createAllocationPayments 支払いマトリクスの各要素を支払いとして一括で作成する
+// 債権/債務の集計では payer_id = debtor_id の行は除外される
+func (lr *LendingRepositoryImpl) createAllocationPayments(ctx context.Context, l *domain.Lending) error {
+	allocations := l.Allocations()
+
+	params := postgres.CreateEventPaymentsParams{
+		EventID:   l.ID().String(),
+		Ids:       make([]string, 0, len(allocations)),
+		PayerIds:  make([]string, 0, len(allocations)),
+		DebtorIds: make([]string, 0, len(allocations)),
+		Amounts:   make([]int32, 0, len(allocations)),
+	}
+	for _, allocation := range allocations {
+		params.Ids = append(params.Ids, ulid.Make().String())
+		params.PayerIds = append(params.PayerIds, allocation.PayerID)
+		params.DebtorIds = append(params.DebtorIds, allocation.DebtorID)
+		params.Amounts = append(params.Amounts, int32(allocation.Amount))
 	}
 
-	return nil
+	return lr.queries.CreateEventPayments(ctx, params)
 }
 
 // FindByID 立て替えをIDで取得する
@@ -94,34 +108,156 @@ func (lr *LendingRepositoryImpl) FindByID(ctx context.Context, id ulid.ULID) (l
 		return nil, err
 	}
 
-	// 支払い情報を取得（Payer/Debtor情報含む）
-	payments, err := lr.queries.FindPaymentsByEventId(ctx, id.String())
+	return lr.restore(ctx, event)
+}
+
+// FindPersonalByID 個人間の立て替えをIDで取得する
+func (lr *LendingRepositoryImpl) FindPersonalByID(ctx context.Context, id ulid.ULID) (l *domain.Lending, err error) {
+	ctx, span := tracer.Start(ctx, "repository.Lending.FindPersonalByID")
+	defer func() {
+		if err != nil {
+			span.SetStatus(codes.Error, err.Error())
+			span.RecordError(err)
+		}
+		span.End()
+	}()
+
+	event, err := lr.queries.FindEventById(ctx, id.String())
 	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.NewNotFoundError("lending", id.String())
+		}
 		return nil, err
 	}
+	if event.GroupID != nil {
+		return nil, domain.NewNotFoundError("lending", id.String())
+	}
 
-	if len(payments) == 0 {
-		return nil, domain.NewNotFoundError("lending payments", id.String())
+	return lr.restore(ctx, event)
+}
+
+// restore イベントと支払いマトリクスからLending集約を復元する
+func (lr *LendingRepositoryImpl) restore(ctx context.Context, event postgres.Event) (*domain.Lending, error) {
+	lendings, err := lr.restoreAll(ctx, []postgres.Event{event})
+	if err != nil {
+		return nil, err
+	}
+
+	return lendings[0], nil
+}
+
+// restoreAll 複数のイベントのLending集約を復元する
+// 支払いとユーザーはイベントの件数によらずまとめて取得し、イベントと同じ順で返す
+func (lr *LendingRepositoryImpl) restoreAll(ctx context.Context, events []postgres.Event) ([]*domain.Lending, error) {
+	eventIDs := make([]string, 0, len(events))
+	for _, event := range events {
+		eventIDs = append(eventIDs, event.ID)
 	}
 
-	// Payerを取得
-	payerUser, err := lr.queries.FindUserByID(ctx, payments[0].PayerID)
+	// 支払い情報をイベントごとにまとめる
+	rows, err := lr.queries.FindPaymentsByEventIDs(ctx, eventIDs)
 	if err != nil {
 		return nil, err
 	}
-	payer, err := domain.NewPayer(payerUser.ID, payerUser.Name, payerUser.Avatar, payerUser.Email)
+	paymentsByEventID := make(map[string][]postgres.FindPaymentsByEventIDsRow, len(events))
+	userIDs := make([]string, 0, len(rows))
+	for _, row := range rows {
+		paymentsByEventID[row.EventID] = append(paymentsByEventID[row.EventID], row)
+		userIDs = append(userIDs, row.PayerID, row.DebtorID)
+	}
+
+	// 支払い者と債務者のユーザー情報を取得
+	users, err := lr.queries.FindUsersByIDs(ctx, userIDs)
 	if err != nil {
 		return nil, err
 	}
+	userByID := make(map[string]postgres.FindUsersByIDsRow, len(users))
+	for _, u := range users {
+		userByID[u.ID] = u
+	}
 
-	// Debtorsを取得
-	debtors := make(map[string]*domain.Debtor, len(payments))
-	for _, p := range payments {
-		debtorUser, err := lr.queries.FindUserByID(ctx, p.DebtorID)
+	lendings := make([]*domain.Lending, 0, len(events))
+	for _, event := range events {
+		lending, err := restoreLending(ctx, event, paymentsByEventID[event.ID], userByID)
 		if err != nil {
 			return nil, err
 		}
-		debtor, err := domain.NewDebtor(debtorUser.ID, debtorUser.Name, debtorUser.Avatar, debtorUser.Email, int64(p.Amount))
+		lendings = append(lendings, lending)
+	}
+
+	return lendings, nil
+}
+
+// findAllByIDs 複数のイベントをまとめて取得し、eventIDsと同じ順でLending集約を復元する
+// イベントの件数によらずクエリの回数は一定となる
+func (lr *LendingRepositoryImpl) findAllByIDs(ctx context.Context, eventIDs []string) ([]*domain.Lending, error) {
+	if len(eventIDs) == 0 {
+		return []*domain.Lending{}, nil
+	}
+
+	found, err := lr.queries.FindEventsByIDs(ctx, eventIDs)
+	if err != nil {
+		return nil, err
+	}
+	eventByID := make(map[string]postgres.Event, len(found))
+	for _, event := range found {
+		eventByID[event.ID] = event
+	}
+	events := make([]postgres.Event, 0, len(eventIDs))
+	for _, id := range eventIDs {
+		event, ok := eventByID[id]
+		if !ok {
+			return nil, domain.NewNotFoundError("lending", id)
+		}
+		events = append(events, event)
+	}
+
+	return lr.restoreAll(ctx, events)
+}
+
+// restoreLending 取得済みの支払いとユーザー情報からLending集約を復元する
+func restoreLending(ctx context.Context, event postgres.Event, payments []postgres.FindPaymentsByEventIDsRow, userByID map[string]postgres.FindUsersByIDsRow) (*domain.Lending, error) {
+	if len(payments) == 0 {
+		return nil, domain.NewNotFoundError("lending payments", event.ID)
+	}
+
+	// 支払いマトリクスから各ユーザーの立て替え額と負担額を集計
+	paid := make(map[string]int64)
+	owed := make(map[string]int64)
+	userIDs := make(map[string]struct{}, len(payments))
+	for _, p := range payments {
+		paid[p.PayerID] += int64(p.Amount)
+		owed[p.DebtorID] += int64(p.Amount)
+		userIDs[p.PayerID] = struct{}{}
+		userIDs[p.DebtorID] = struct{}{}
+	}
+
+	// 作成者を特定 (作成者が記録されていない既存データは最初の支払い者とみなす)
+	createdBy := payments[0].PayerID
+	if event.CreatedBy != nil {
+		if _, exists := paid[*event.CreatedBy]; exists {
+			createdBy = *event.CreatedBy
+		}
+	}
+
+	payers := make(map[string]*domain.Payer, len(paid))
+	debtors := make(map[string]*domain.Debtor, len(owed))
+	for userID := range userIDs {
+		user, ok := userByID[userID]
+		if !ok {
+			return nil, domain.NewNotFoundError("user", userID)
+		}
+
+		if amount, isPayer := paid[userID]; isPayer {
+			payer, err := domain.NewPayer(user.ID, user.Name, user.Avatar, user.Email, amount, owed[userID])
+			if err != nil {
+				return nil, err
+			}
+			payers[payer.ID()] = payer
+			continue
+		}
+
+		debtor, err := domain.NewDebtor(user.ID, user.Name, user.Avatar, user.Email, owed[userID])
 		if err != nil {
 			return nil, err
 		}
@@ -134,12 +270,7 @@ func (lr *LendingRepositoryImpl) FindByID(ctx context.Context, id ulid.ULID) (l
 		return nil, err
 	}
 
-	l, err = domain.NewLending(ctx, eventID, event.Name, int64(event.Amount), event.EventDate, payer, debtors, event.CreatedAt, event.UpdatedAt)
-	if err != nil {
-		return nil, err
-	}
-
-	return l, nil
+	return domain.NewLending(ctx, eventID, event.Name, int64(event.Amount), event.EventDate, event.DueDate, payers[createdBy], payers, debtors, event.CreatedAt, event.UpdatedAt, event.Version)
 }
 
 // FindByGroupAndUserID グループとユーザーIDで立て替え一覧を取得する
@@ -164,26 +295,150 @@ func (lr *LendingRepositoryImpl) FindByGroupAndUserID(ctx context.Context, g *do
 		return nil, err
 	}
 
-	lendings = make([]*domain.Lending, 0, len(events))
-
+	eventIDs := make([]string, 0, len(events))
 	for _, event := range events {
-		eventID, err := ulid.Parse(event.ID)
+		eventIDs = append(eventIDs, event.ID)
+	}
+
+	// イベントごとに取得せず、まとめてLending集約を復元する
+	return lr.findAllByIDs(ctx, eventIDs)
+}
+
+// FindByUserID グループと個人間の立て替えのうち、ユーザーが関与するものを新しい順に取得する
+func (lr *LendingRepositoryImpl) FindByUserID(ctx context.Context, userID string, cursor *string, limit int32) (results []*domain.LendingSearchResult, err error) {
+	ctx, span := tracer.Start(ctx, "repository.Lending.FindByUserID")
+	defer func() {
 		if err != nil {
-			return nil, err
+			span.SetStatus(codes.Error, err.Error())
+			span.RecordError(err)
 		}
+		span.End()
+	}()
+
+	rows, err := lr.queries.FindLendingsByUserIDWithCursor(ctx, postgres.FindLendingsByUserIDWithCursorParams{
+		UserID: userID,
+		Cursor: cursor,
+		Limit:  limit,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	eventIDs := make([]string, 0, len(rows))
+	for _, row := range rows {
+		eventIDs = append(eventIDs, row.ID)
+	}
 
-		// 各イベントのLending集約を取得
-		lending, err := lr.FindByID(ctx, eventID)
+	// イベントごとに取得せず、まとめてLending集約を復元する
+	lendings, err := lr.findAllByIDs(ctx, eventIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	groupByID := make(map[string]*domain.Group)
+	results = make([]*domain.LendingSearchResult, 0, len(rows))
+	for i, row := range rows {
+		// 個人間の立て替えはグループを持たない
+		var group *domain.Group
+		if row.GroupID != nil {
+			group, err = lr.findGroup(ctx, groupByID, *row.GroupID)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		results = append(results, &domain.LendingSearchResult{
+			Group:   group,
+			Lending: lendings[i],
+		})
+	}
+
+	return results, nil
+}
+
+// findGroup 立て替えが属するグループを取得する (取得済みのグループはcacheから返す)
+func (lr *LendingRepositoryImpl) findGroup(ctx context.Context, cache map[string]*domain.Group, id string) (*domain.Group, error) {
+	if g, ok := cache[id]; ok {
+		return g, nil
+	}
+
+	row, err := lr.queries.FindGroupByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	groupID, err := ulid.Parse(row.ID)
+	if err != nil {
+		return nil, err
+	}
+	g, err := domain.NewGroup(ctx, groupID, row.Name, row.CreatedBy, row.PaymentTermsDays, row.CreatedAt, row.UpdatedAt, row.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	cache[id] = g
+	return g, nil
+}
+
+// Search 指定グループ内でユーザーが関与する立て替えを名前で検索する
+func (lr *LendingRepositoryImpl) Search(ctx context.Context, groups []*domain.Group, userID string, query string, limit int32) (results []*domain.LendingSearchResult, err error) {
+	ctx, span := tracer.Start(ctx, "repository.Lending.Search")
+	defer func() {
 		if err != nil {
-			return nil, err
+			span.SetStatus(codes.Error, err.Error())
+			span.RecordError(err)
 		}
+		span.End()
+	}()
 
-		lendings = append(lendings, lending)
+	if len(groups) == 0 {
+		return []*domain.LendingSearchResult{}, nil
 	}
 
-	return lendings, nil
+	groupByID := make(map[string]*domain.Group, len(groups))
+	groupIDs := make([]string, 0, len(groups))
+	for _, g := range groups {
+		groupByID[g.ID().String()] = g
+		groupIDs = append(groupIDs, g.ID().String())
+	}
+
+	rows, err := lr.queries.SearchLendingsByGroupIDsAndUserID(ctx, postgres.SearchLendingsByGroupIDsAndUserIDParams{
+		Query:    query,
+		GroupIds: groupIDs,
+		UserID:   userID,
+		Pattern:  likeEscaper.Replace(query),
+		Limit:    limit,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return []*domain.LendingSearchResult{}, nil
+	}
+
+	// ヒットしたイベントをまとめて取得し、検索結果の順に並べる
+	eventIDs := make([]string, 0, len(rows))
+	for _, row := range rows {
+		eventIDs = append(eventIDs, row.ID)
+	}
+	lendings, err := lr.findAllByIDs(ctx, eventIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	results = make([]*domain.LendingSearchResult, 0, len(rows))
+	for i, row := range rows {
+		results = append(results, &domain.LendingSearchResult{
+			Group:   groupByID[*row.GroupID],
+			Lending: lendings[i],
+		})
+	}
+
+	return results, nil
 }
 
+// likeEscaper LIKEのパターンで特殊な意味を持つ文字 (\, %, _) をエスケープする
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
 // Update 立て替えを更新する
 func (lr *LendingRepositoryImpl) Update(ctx context.Context, l *domain.Lending) (err error) {
 	ctx, span := tracer.Start(ctx, "repository.Lending.Update")
@@ -195,73 +450,41 @@ func (lr *LendingRepositoryImpl) Update(ctx context.Context, l *domain.Lending)
 		span.End()
 	}()
 
-	// イベント情報を更新
-	err = lr.queries.UpdateEvent(ctx, postgres.UpdateEventParams{
+	// イベント情報を更新 (読み込み時のバージョンから変わっていない場合のみ)
+	rows, err := lr.queries.UpdateEvent(ctx, postgres.UpdateEventParams{
 		ID:        l.ID().String(),
 		Name:      l.Name(),
 		Amount:    int32(l.Amount()),
 		EventDate: l.EventDate(),
 		UpdatedAt: l.UpdatedAt(),
+		Version:   l.Version(),
+		DueDate:   l.DueDate(),
 	})
 	if err != nil {
 		return err
 	}
+	if rows == 0 {
+		return domain.NewStaleVersionError("lending")
+	}
 
-	// 既存の支払い情報を取得
+	// 既存の支払いを削除して支払いマトリクスを作り直す
 	existingPayments, err := lr.queries.FindPaymentsByEventId(ctx, l.ID().String())
 	if err != nil {
 		return err
 	}
 
-	// 既存の債務者IDセットを作成
-	existingDebtorIDs := make(map[string]string) // debtorID -> paymentID
 	for _, p := range existingPayments {
-		existingDebtorIDs[p.DebtorID] = p.ID
-	}
-
-	// 新しい債務者を追加、既存の債務者を更新
-	for _, debtor := range l.Debtors() {
-		if paymentID, exists := existingDebtorIDs[debtor.ID()]; exists {
-			// 既存の債務者を更新
-			err = lr.queries.UpdatePaymentAmount(ctx, postgres.UpdatePaymentAmountParams{
-				ID:     paymentID,
-				Amount: int32(debtor.Amount()),
-			})
-			if err != nil {
-				return err
-			}
-			delete(existingDebtorIDs, debtor.ID())
-		} else {
-			// 新しい債務者を追加
-			paymentID := ulid.Make()
-			err = lr.queries.CreatePayment(ctx, postgres.CreatePaymentParams{
-				ID:       paymentID.String(),
-				PayerID:  l.Payer().ID(),
-				DebtorID: debtor.ID(),
-				Amount:   int32(debtor.Amount()),
-			})
-			if err != nil {
-				return err
-			}
-
-			err = lr.queries.CreateEventPayment(ctx, postgres.CreateEventPaymentParams{
-				EventID:   l.ID().String(),
-				PaymentID: paymentID.String(),
-			})
-			if err != nil {
-				return err
-			}
-		}
-	}
-
-	// 削除された債務者の支払いを削除
-	for _, paymentID := range existingDebtorIDs {
-		err = lr.queries.DeletePayment(ctx, paymentID)
+		err = lr.queries.DeletePayment(ctx, p.ID)
 		if err != nil {
 			return err
 		}
 	}
 
+	err = lr.createAllocationPayments(ctx, l)
+	if err != nil {
+		return err
+	}
+
 	return nil
 }
 
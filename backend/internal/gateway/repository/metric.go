@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/metric"
+)
+
+var meter metric.Meter = otel.Meter("github.com/haebeal/datti/internal/gateway/repository")
+
+// RegisterPoolMetrics コネクションプールの統計情報を計測するメトリクスを登録する
+func RegisterPoolMetrics(pool *pgxpool.Pool) error {
+	total, err := meter.Int64ObservableGauge("db.client.connections.total",
+		metric.WithDescription("プール内のコネクション数"),
+		metric.WithUnit("{connection}"))
+	if err != nil {
+		return err
+	}
+	idle, err := meter.Int64ObservableGauge("db.client.connections.idle",
+		metric.WithDescription("アイドル状態のコネクション数"),
+		metric.WithUnit("{connection}"))
+	if err != nil {
+		return err
+	}
+	acquired, err := meter.Int64ObservableGauge("db.client.connections.acquired",
+		metric.WithDescription("使用中のコネクション数"),
+		metric.WithUnit("{connection}"))
+	if err != nil {
+		return err
+	}
+	maxConns, err := meter.Int64ObservableGauge("db.client.connections.max",
+		metric.WithDescription("プールの最大コネクション数"),
+		metric.WithUnit("{connection}"))
+	if err != nil {
+		return err
+	}
+	acquireCount, err := meter.Int64ObservableCounter("db.client.connections.acquire.count",
+		metric.WithDescription("コネクションの取得回数"),
+		metric.WithUnit("{acquire}"))
+	if err != nil {
+		return err
+	}
+	emptyAcquireCount, err := meter.Int64ObservableCounter("db.client.connections.acquire.empty_count",
+		metric.WithDescription("空きコネクションがなく待機した取得回数"),
+		metric.WithUnit("{acquire}"))
+	if err != nil {
+		return err
+	}
+	acquireDuration, err := meter.Float64ObservableCounter("db.client.connections.acquire.duration",
+		metric.WithDescription("コネクションの取得に要した時間の合計"),
+		metric.WithUnit("s"))
+	if err != nil {
+		return err
+	}
+
+	_, err = meter.RegisterCallback(func(_ context.Context, o metric.Observer) error {
+		stat := pool.Stat()
+		o.ObserveInt64(total, int64(stat.TotalConns()))
+		o.ObserveInt64(idle, int64(stat.IdleConns()))
+		o.ObserveInt64(acquired, int64(stat.AcquiredConns()))
+		o.ObserveInt64(maxConns, int64(stat.MaxConns()))
+		o.ObserveInt64(acquireCount, stat.AcquireCount())
+		o.ObserveInt64(emptyAcquireCount, stat.EmptyAcquireCount())
+		o.ObserveFloat64(acquireDuration, stat.AcquireDuration().Seconds())
+		return nil
+	}, total, idle, acquired, maxConns, acquireCount, emptyAcquireCount, acquireDuration)
+	return err
+}
@@ -0,0 +1,39 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/haebeal/datti/internal/config"
+	"github.com/haebeal/datti/internal/gateway/postgres"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// DB 複数のクエリをトランザクション内で実行するリポジトリが使用する接続
+// *pgxpool.Poolとpgx.Txのどちらも満たし、pgx.Txの場合はセーブポイントを使用する
+type DB interface {
+	postgres.DBTX
+	Begin(ctx context.Context) (pgx.Tx, error)
+}
+
+// NewPool 設定に従ってコネクションプールを作成する
+func NewPool(ctx context.Context, cfg config.DatabaseConfig) (*pgxpool.Pool, error) {
+	poolCfg, err := pgxpool.ParseConfig(cfg.DSN)
+	if err != nil {
+		return nil, fmt.Errorf("POSTGRES_DSNの形式が正しくありません: %w", err)
+	}
+	poolCfg.MaxConns = cfg.MaxConns
+	poolCfg.MinConns = cfg.MinConns
+	poolCfg.MaxConnLifetime = cfg.MaxConnLifetime
+	poolCfg.MaxConnIdleTime = cfg.MaxConnIdleTime
+
+	return pgxpool.NewWithConfig(ctx, poolCfg)
+}
+
+// transaction fnをトランザクション内で実行し、エラーがなければコミットする
+func transaction(ctx context.Context, db DB, fn func(queries *postgres.Queries) error) error {
+	return pgx.BeginFunc(ctx, db, func(tx pgx.Tx) error {
+		return fn(postgres.New(tx))
+	})
+}
@@ -73,7 +73,7 @@ func (rr *RepaymentRepositoryImpl) FindByID(ctx context.Context, id ulid.ULID) (
 		return nil, err
 	}
 
-	r, err = domain.NewRepayment(ctx, parsedID, p.PayerID, p.DebtorID, int64(p.Amount), p.CreatedAt, p.UpdatedAt)
+	r, err = domain.NewRepayment(ctx, parsedID, p.PayerID, p.DebtorID, int64(p.Amount), p.CreatedAt, p.UpdatedAt, p.Version)
 	if err != nil {
 		return nil, err
 	}
@@ -109,7 +109,46 @@ func (rr *RepaymentRepositoryImpl) FindByPayerID(ctx context.Context, payerID st
 			return nil, err
 		}
 
-		repayment, err := domain.NewRepayment(ctx, id, p.PayerID, p.DebtorID, int64(p.Amount), p.CreatedAt, p.UpdatedAt)
+		repayment, err := domain.NewRepayment(ctx, id, p.PayerID, p.DebtorID, int64(p.Amount), p.CreatedAt, p.UpdatedAt, p.Version)
+		if err != nil {
+			return nil, err
+		}
+
+		repayments = append(repayments, repayment)
+	}
+
+	return repayments, nil
+}
+
+// FindByDebtorID 受取人IDで返済一覧を取得する
+func (rr *RepaymentRepositoryImpl) FindByDebtorID(ctx context.Context, debtorID string, cursor *string, limit *int32) (repayments []*domain.Repayment, err error) {
+	ctx, span := tracer.Start(ctx, "repository.Repayment.FindByDebtorID")
+	defer func() {
+		if err != nil {
+			span.SetStatus(codes.Error, err.Error())
+			span.RecordError(err)
+		}
+		span.End()
+	}()
+
+	payments, err := rr.queries.FindRepaymentsByDebtorIDWithCursor(ctx, postgres.FindRepaymentsByDebtorIDWithCursorParams{
+		DebtorID: debtorID,
+		Cursor:   cursor,
+		Limit:    *limit,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	repayments = make([]*domain.Repayment, 0, len(payments))
+
+	for _, p := range payments {
+		id, err := ulid.Parse(p.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		repayment, err := domain.NewRepayment(ctx, id, p.PayerID, p.DebtorID, int64(p.Amount), p.CreatedAt, p.UpdatedAt, p.Version)
 		if err != nil {
 			return nil, err
 		}
@@ -131,14 +170,19 @@ func (rr *RepaymentRepositoryImpl) Update(ctx context.Context, r *domain.Repayme
 		span.End()
 	}()
 
-	err = rr.queries.UpdateRepayment(ctx, postgres.UpdateRepaymentParams{
+	// 読み込み時のバージョンから変わっていない場合のみ更新する
+	rows, err := rr.queries.UpdateRepayment(ctx, postgres.UpdateRepaymentParams{
 		ID:        r.ID().String(),
 		Amount:    int32(r.Amount()),
 		UpdatedAt: r.UpdatedAt(),
+		Version:   r.Version(),
 	})
 	if err != nil {
 		return err
 	}
+	if rows == 0 {
+		return domain.NewStaleVersionError("repayment")
+	}
 
 	return nil
 }
@@ -0,0 +1,328 @@
+package repository_test
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/haebeal/datti/internal/domain"
+	"github.com/haebeal/datti/internal/gateway/migration"
+	"github.com/haebeal/datti/internal/gateway/postgres"
+	"github.com/haebeal/datti/internal/gateway/repository"
+	"github.com/haebeal/datti/internal/gateway/repositorytest"
+	"github.com/haebeal/datti/sql/migrations"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/oklog/ulid/v2"
+)
+
+// TestRepositories TEST_POSTGRES_DSNを指定した場合のみ実行する
+// サブテストごとにスキーマを作成してマイグレーションを適用し、終了後に削除する
+func TestRepositories(t *testing.T) {
+	dsn, ok := os.LookupEnv("TEST_POSTGRES_DSN")
+	if !ok {
+		t.Skip("TEST_POSTGRES_DSN is not set")
+	}
+
+	repositorytest.Run(t, func(t *testing.T) repositorytest.Repositories {
+		pool := newSchemaPool(t, dsn)
+		queries := postgres.New(pool)
+		return repositorytest.Repositories{
+			User:           repository.NewUserRepository(pool),
+			Guest:          repository.NewGuestRepository(pool),
+			Contact:        repository.NewContactRepository(queries),
+			Credit:         repository.NewCreditRepository(queries),
+			Lending:        repository.NewLendingRepository(queries),
+			Group:          repository.NewGroupRepository(queries),
+			Repayment:      repository.NewRepaymentRepository(queries),
+			Comment:        repository.NewCommentRepository(queries),
+			IdempotencyKey: repository.NewIdempotencyKeyRepository(queries),
+		}
+	})
+}
+
+// newSchemaPool 新しいスキーマを検索パスの先頭にした接続プールを作成し、マイグレーションを適用する
+func newSchemaPool(t *testing.T, dsn string) *pgxpool.Pool {
+	t.Helper()
+
+	pool := newEmptySchemaPool(t, dsn)
+	migrate(t, pool, migrations.FS)
+
+	return pool
+}
+
+// newEmptySchemaPool 新しいスキーマを検索パスの先頭にした接続プールを作成する
+func newEmptySchemaPool(t *testing.T, dsn string) *pgxpool.Pool {
+	t.Helper()
+	ctx := context.Background()
+
+	admin, err := pgxpool.New(ctx, dsn)
+	if err != nil {
+		t.Fatalf("pgxpool.New() error = %v", err)
+	}
+	t.Cleanup(admin.Close)
+
+	schema := "test_" + strings.ToLower(ulid.Make().String())
+	if _, err := admin.Exec(ctx, fmt.Sprintf("CREATE SCHEMA %s", schema)); err != nil {
+		t.Fatalf("CREATE SCHEMA error = %v", err)
+	}
+	t.Cleanup(func() {
+		if _, err := admin.Exec(context.Background(), fmt.Sprintf("DROP SCHEMA %s CASCADE", schema)); err != nil {
+			t.Errorf("DROP SCHEMA error = %v", err)
+		}
+	})
+
+	cfg, err := pgxpool.ParseConfig(dsn)
+	if err != nil {
+		t.Fatalf("pgxpool.ParseConfig() error = %v", err)
+	}
+	cfg.ConnConfig.RuntimeParams["search_path"] = schema + ",public"
+	pool, err := pgxpool.NewWithConfig(ctx, cfg)
+	if err != nil {
+		t.Fatalf("pgxpool.NewWithConfig() error = %v", err)
+	}
+	t.Cleanup(pool.Close)
+
+	return pool
+}
+
+// migrate fsysに含まれるマイグレーションを適用する
+func migrate(t *testing.T, pool *pgxpool.Pool, fsys fs.FS) {
+	t.Helper()
+
+	m, err := migration.NewMigrator(pool, fsys)
+	if err != nil {
+		t.Fatalf("NewMigrator() error = %v", err)
+	}
+	if _, err := m.Up(context.Background()); err != nil {
+		t.Fatalf("Up() error = %v", err)
+	}
+}
+
+// migrationsBefore versionより前のマイグレーションのみを含むファイルシステムを返す
+func migrationsBefore(t *testing.T, version int64) fs.FS {
+	t.Helper()
+
+	all, err := migration.Load(migrations.FS)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	fsys := fstest.MapFS{}
+	for _, m := range all {
+		if m.Version >= version {
+			continue
+		}
+		name := fmt.Sprintf("%04d_%s", m.Version, m.Name)
+		fsys[name+".up.sql"] = &fstest.MapFile{Data: []byte(m.Up)}
+		fsys[name+".down.sql"] = &fstest.MapFile{Data: []byte(m.Down)}
+	}
+
+	return fsys
+}
+
+// TestLendingBreakdownMigration 支払い者自身の負担額を記録する前の立て替えが、
+// 金額と支払いの合計が一致するように補正されることを確認する
+func TestLendingBreakdownMigration(t *testing.T) {
+	dsn, ok := os.LookupEnv("TEST_POSTGRES_DSN")
+	if !ok {
+		t.Skip("TEST_POSTGRES_DSN is not set")
+	}
+
+	ctx := context.Background()
+	pool := newEmptySchemaPool(t, dsn)
+	migrate(t, pool, migrationsBefore(t, 14))
+
+	groupID := ulid.Make()
+	shortfallID := ulid.Make()
+	excessID := ulid.Make()
+	now := time.Now()
+	statements := []struct {
+		sql  string
+		args []any
+	}{
+		{"INSERT INTO users (id, name, avatar, email) VALUES ($1, $1, '', $1 || '@example.com')", []any{"alice"}},
+		{"INSERT INTO users (id, name, avatar, email) VALUES ($1, $1, '', $1 || '@example.com')", []any{"bob"}},
+		{"INSERT INTO users (id, name, avatar, email) VALUES ($1, $1, '', $1 || '@example.com')", []any{"carol"}},
+		{"INSERT INTO groups (id, name, created_by) VALUES ($1, 'legacy', 'alice')", []any{groupID.String()}},
+		{"INSERT INTO group_members (group_id, user_id) VALUES ($1, 'alice'), ($1, 'bob'), ($1, 'carol')", []any{groupID.String()}},
+		// 支払い者自身の負担分が記録されていない立て替え (金額3000のうち債務は1000)
+		{"INSERT INTO events (id, group_id, name, amount, event_date, created_at, updated_at) VALUES ($1, $2, 'shortfall', 3000, $3, $3, $3)", []any{shortfallID.String(), groupID.String(), now}},
+		{"INSERT INTO payments (id, payer_id, debtor_id, amount, created_at, updated_at) VALUES ($1, 'alice', 'bob', 1000, $2, $2)", []any{ulid.Make().String(), now}},
+		// 債務の合計が金額を超えている立て替え (金額1000に対して債務は1500)
+		{"INSERT INTO events (id, group_id, name, amount, event_date, created_at, updated_at) VALUES ($1, $2, 'excess', 1000, $3, $3, $3)", []any{excessID.String(), groupID.String(), now.Add(-time.Hour)}},
+		{"INSERT INTO payments (id, payer_id, debtor_id, amount, created_at, updated_at) VALUES ($1, 'alice', 'bob', 800, $2, $2)", []any{ulid.Make().String(), now}},
+		{"INSERT INTO payments (id, payer_id, debtor_id, amount, created_at, updated_at) VALUES ($1, 'alice', 'carol', 700, $2, $2)", []any{ulid.Make().String(), now}},
+		{"INSERT INTO event_payments (event_id, payment_id) SELECT $1, id FROM payments WHERE amount = 1000", []any{shortfallID.String()}},
+		{"INSERT INTO event_payments (event_id, payment_id) SELECT $1, id FROM payments WHERE amount IN (800, 700)", []any{excessID.String()}},
+	}
+	for _, s := range statements {
+		if _, err := pool.Exec(ctx, s.sql, s.args...); err != nil {
+			t.Fatalf("Exec(%q) error = %v", s.sql, err)
+		}
+	}
+
+	migrate(t, pool, migrations.FS)
+
+	queries := postgres.New(pool)
+	g, err := repository.NewGroupRepository(queries).FindByID(ctx, groupID)
+	if err != nil {
+		t.Fatalf("FindByID() error = %v", err)
+	}
+	limit := int32(10)
+	lendings, err := repository.NewLendingRepository(queries).FindByGroupAndUserID(ctx, g, "alice", nil, &limit)
+	if err != nil {
+		t.Fatalf("FindByGroupAndUserID() error = %v", err)
+	}
+	got := make(map[ulid.ULID][2]int64, len(lendings))
+	for _, l := range lendings {
+		got[l.ID()] = [2]int64{l.Amount(), l.PayerShare()}
+	}
+	// 不足分は作成者の負担額とし、超過分は金額を債務の合計に合わせる
+	if got[shortfallID] != [2]int64{3000, 2000} || got[excessID] != [2]int64{1500, 0} {
+		t.Errorf("FindByGroupAndUserID() = %v, want shortfall {3000 2000}, excess {1500 0}", got)
+	}
+
+	// 補正しても残高は変わらない
+	credits, err := repository.NewCreditRepository(queries).FindByUserID(ctx, "alice")
+	if err != nil {
+		t.Fatalf("FindByUserID() error = %v", err)
+	}
+	balances := make(map[string]int64, len(credits))
+	for _, c := range credits {
+		balances[c.UserID()] = c.Amount()
+	}
+	if len(balances) != 2 || balances["bob"] != 1800 || balances["carol"] != 700 {
+		t.Errorf("FindByUserID(alice) = %v, want map[bob:1800 carol:700]", balances)
+	}
+}
+
+// countingDBTX 実行したクエリの回数を数えるpostgres.DBTX
+type countingDBTX struct {
+	postgres.DBTX
+	count int
+}
+
+func (c *countingDBTX) Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	c.count++
+	return c.DBTX.Exec(ctx, sql, args...)
+}
+
+func (c *countingDBTX) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	c.count++
+	return c.DBTX.Query(ctx, sql, args...)
+}
+
+func (c *countingDBTX) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	c.count++
+	return c.DBTX.QueryRow(ctx, sql, args...)
+}
+
+// TestLendingListQueryCount 立て替え一覧の取得で、件数によらずクエリの回数が一定であることを確認する
+func TestLendingListQueryCount(t *testing.T) {
+	dsn, ok := os.LookupEnv("TEST_POSTGRES_DSN")
+	if !ok {
+		t.Skip("TEST_POSTGRES_DSN is not set")
+	}
+
+	ctx := context.Background()
+	pool := newSchemaPool(t, dsn)
+	queries := postgres.New(pool)
+	ur := repository.NewUserRepository(pool)
+	gr := repository.NewGroupRepository(queries)
+	lr := repository.NewLendingRepository(queries)
+
+	var users []*domain.User
+	for _, id := range []string{"alice", "bob"} {
+		u, err := domain.NewUser(ctx, id, id, "", id+"@example.com")
+		if err != nil {
+			t.Fatalf("NewUser() error = %v", err)
+		}
+		if err := ur.Create(ctx, u); err != nil {
+			t.Fatalf("User.Create() error = %v", err)
+		}
+		users = append(users, u)
+	}
+	alice, bob := users[0], users[1]
+
+	g, err := domain.CreateGroup(ctx, "group", alice.ID(), nil)
+	if err != nil {
+		t.Fatalf("CreateGroup() error = %v", err)
+	}
+	if err := gr.Create(ctx, g); err != nil {
+		t.Fatalf("Group.Create() error = %v", err)
+	}
+	for _, u := range users {
+		if err := gr.AddMember(ctx, g, u); err != nil {
+			t.Fatalf("Group.AddMember(%s) error = %v", u.ID(), err)
+		}
+	}
+
+	createLending := func() {
+		t.Helper()
+		payer, err := domain.NewPayer(alice.ID(), alice.Name(), alice.Avatar(), alice.Email(), 1000, 500)
+		if err != nil {
+			t.Fatalf("NewPayer() error = %v", err)
+		}
+		l, err := domain.CreateLending(ctx, "lending", 1000, time.Now(), nil, payer)
+		if err != nil {
+			t.Fatalf("CreateLending() error = %v", err)
+		}
+		debtor, err := domain.NewDebtor(bob.ID(), bob.Name(), bob.Avatar(), bob.Email(), 500)
+		if err != nil {
+			t.Fatalf("NewDebtor() error = %v", err)
+		}
+		if err := l.AddDebtor(debtor); err != nil {
+			t.Fatalf("AddDebtor() error = %v", err)
+		}
+		if err := lr.Create(ctx, g, l); err != nil {
+			t.Fatalf("Lending.Create() error = %v", err)
+		}
+	}
+
+	counter := &countingDBTX{DBTX: pool}
+	counted := repository.NewLendingRepository(postgres.New(counter))
+	limit := int32(10)
+	countQueries := func(want int) (byGroup int, byUser int) {
+		t.Helper()
+		counter.count = 0
+		lendings, err := counted.FindByGroupAndUserID(ctx, g, alice.ID(), nil, &limit)
+		if err != nil {
+			t.Fatalf("FindByGroupAndUserID() error = %v", err)
+		}
+		if len(lendings) != want {
+			t.Fatalf("FindByGroupAndUserID() = %d lendings, want %d", len(lendings), want)
+		}
+		byGroup = counter.count
+
+		counter.count = 0
+		results, err := counted.FindByUserID(ctx, alice.ID(), nil, limit)
+		if err != nil {
+			t.Fatalf("FindByUserID() error = %v", err)
+		}
+		if len(results) != want {
+			t.Fatalf("FindByUserID() = %d lendings, want %d", len(results), want)
+		}
+		return byGroup, counter.count
+	}
+
+	createLending()
+	byGroup, byUser := countQueries(1)
+
+	createLending()
+	createLending()
+	gotByGroup, gotByUser := countQueries(3)
+
+	if gotByGroup != byGroup {
+		t.Errorf("FindByGroupAndUserID() queries = %d for 3 lendings, want %d (same as for 1)", gotByGroup, byGroup)
+	}
+	if gotByUser != byUser {
+		t.Errorf("FindByUserID() queries = %d for 3 lendings, want %d (same as for 1)", gotByUser, byUser)
+	}
+}
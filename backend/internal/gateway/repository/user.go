@@ -12,12 +12,14 @@ import (
 )
 
 type UserRepositoryImpl struct {
+	db      DB
 	queries *postgres.Queries
 }
 
-func NewUserRepository(queries *postgres.Queries) *UserRepositoryImpl {
+func NewUserRepository(db DB) *UserRepositoryImpl {
 	return &UserRepositoryImpl{
-		queries: queries,
+		db:      db,
+		queries: postgres.New(db),
 	}
 }
 
@@ -39,7 +41,12 @@ func (ur *UserRepositoryImpl) FindByID(ctx context.Context, id string) (*domain.
 	}
 	querySpan.End()
 
-	user, err := domain.NewUser(ctx, row.ID, row.Name, row.Avatar, row.Email)
+	var user *domain.User
+	if row.GuestGroupID != nil {
+		user, err = domain.NewGuestUser(ctx, row.ID, row.Name, row.Email)
+	} else {
+		user, err = domain.NewUser(ctx, row.ID, row.Name, row.Avatar, row.Email)
+	}
 	if err != nil {
 		span.SetStatus(codes.Error, err.Error())
 		span.RecordError(err)
@@ -49,15 +56,49 @@ func (ur *UserRepositoryImpl) FindByID(ctx context.Context, id string) (*domain.
 	return user, nil
 }
 
+// FindByIDs IDで複数のユーザーをまとめてID順に取得する (存在しないIDのユーザーは含まない)
+func (ur *UserRepositoryImpl) FindByIDs(ctx context.Context, ids []string) (users []*domain.User, err error) {
+	ctx, span := tracer.Start(ctx, "repository.User.FindByIDs")
+	defer func() {
+		if err != nil {
+			span.SetStatus(codes.Error, err.Error())
+			span.RecordError(err)
+		}
+		span.End()
+	}()
+
+	rows, err := ur.queries.FindUsersByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	users = make([]*domain.User, 0, len(rows))
+	for _, row := range rows {
+		var user *domain.User
+		if row.GuestGroupID != nil {
+			user, err = domain.NewGuestUser(ctx, row.ID, row.Name, row.Email)
+		} else {
+			user, err = domain.NewUser(ctx, row.ID, row.Name, row.Avatar, row.Email)
+		}
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, user)
+	}
+
+	return users, nil
+}
+
 func (ur *UserRepositoryImpl) FindByQuery(ctx context.Context, query domain.UserSearchQuery) ([]*domain.User, error) {
 	ctx, span := tracer.Start(ctx, "user.FindByQuery")
 	defer span.End()
 
-	ctx, querySpan := tracer.Start(ctx, "SELECT * FROM users WHERE name ILIKE $1 OR email ILIKE $2")
-	rows, err := ur.queries.FindUsersBySearch(ctx, postgres.FindUsersBySearchParams{
-		Name:  query.Name,
-		Email: query.Email,
-		Limit: query.Limit,
+	ctx, querySpan := tracer.Start(ctx, "SELECT * FROM users WHERE (contact of $1) AND (name ILIKE $2 OR email ILIKE $3)")
+	rows, err := ur.queries.FindContactsBySearch(ctx, postgres.FindContactsBySearchParams{
+		UserID: query.UserID,
+		Name:   query.Name,
+		Email:  query.Email,
+		Limit:  query.Limit,
 	})
 	if err != nil {
 		querySpan.SetStatus(codes.Error, err.Error())
@@ -170,3 +211,244 @@ func (ur *UserRepositoryImpl) UpdateID(ctx context.Context, oldID, newID string)
 
 	return nil
 }
+
+// FindDiscoverableByEmail メールアドレスでの検索を許可しているユーザーをメールアドレスの完全一致で取得する
+func (ur *UserRepositoryImpl) FindDiscoverableByEmail(ctx context.Context, email string) (user *domain.User, err error) {
+	ctx, span := tracer.Start(ctx, "repository.User.FindDiscoverableByEmail")
+	defer func() {
+		if err != nil {
+			span.SetStatus(codes.Error, err.Error())
+			span.RecordError(err)
+		}
+		span.End()
+	}()
+
+	row, err := ur.queries.FindDiscoverableUserByEmail(ctx, email)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.NewNotFoundError("user", email)
+		}
+		return nil, err
+	}
+
+	return domain.NewUser(ctx, row.ID, row.Name, row.Avatar, row.Email)
+}
+
+// FindPrivacy ユーザーのプライバシー設定を取得する
+func (ur *UserRepositoryImpl) FindPrivacy(ctx context.Context, id string) (privacy *domain.UserPrivacy, err error) {
+	ctx, span := tracer.Start(ctx, "repository.User.FindPrivacy")
+	defer func() {
+		if err != nil {
+			span.SetStatus(codes.Error, err.Error())
+			span.RecordError(err)
+		}
+		span.End()
+	}()
+
+	discoverableByEmail, err := ur.queries.FindUserPrivacyByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.NewNotFoundError("user", id)
+		}
+		return nil, err
+	}
+
+	return domain.NewUserPrivacy(discoverableByEmail), nil
+}
+
+// UpdatePrivacy ユーザーのプライバシー設定を更新する
+func (ur *UserRepositoryImpl) UpdatePrivacy(ctx context.Context, id string, p *domain.UserPrivacy) (err error) {
+	ctx, span := tracer.Start(ctx, "repository.User.UpdatePrivacy")
+	defer func() {
+		if err != nil {
+			span.SetStatus(codes.Error, err.Error())
+			span.RecordError(err)
+		}
+		span.End()
+	}()
+
+	return ur.queries.UpdateUserPrivacy(ctx, postgres.UpdateUserPrivacyParams{
+		ID:                  id,
+		DiscoverableByEmail: p.DiscoverableByEmail(),
+	})
+}
+
+// Anonymize ユーザーをanonymizedの内容で匿名化し、グループから脱退させる
+// 支払いやグループの参照はON UPDATE CASCADEにより新しいIDに付け替えられる
+// 未精算の貸し借りの確認から匿名化までを1つのトランザクションで行う
+func (ur *UserRepositoryImpl) Anonymize(ctx context.Context, u *domain.User, anonymized *domain.User) (err error) {
+	ctx, span := tracer.Start(ctx, "repository.User.Anonymize")
+	defer func() {
+		if err != nil {
+			span.SetStatus(codes.Error, err.Error())
+			span.RecordError(err)
+		}
+		span.End()
+	}()
+
+	return transaction(ctx, ur.db, func(queries *postgres.Queries) error {
+		// 支払いはusersを参照しているため、行をロックすると確認後に貸し借りが追加されない
+		err := queries.LockUser(ctx, u.ID())
+		if err != nil {
+			return err
+		}
+
+		outstanding, err := queries.ExistsBalanceByUserID(ctx, u.ID())
+		if err != nil {
+			return err
+		}
+		if outstanding {
+			return domain.NewConflictError("credit", "未精算の貸し借りがあるため退会できません")
+		}
+
+		// 作成したグループは最も古くから所属しているメンバーに引き継ぐ
+		successors, err := queries.FindGroupSuccessorsByCreatedBy(ctx, u.ID())
+		if err != nil {
+			return err
+		}
+		for _, s := range successors {
+			if s.SuccessorID == "" {
+				// 他にメンバーがいないグループも脱退したメンバーとの立て替えの記録を残すため、
+				// 匿名化したユーザーを作成者としたまま残す
+				continue
+			}
+
+			err = queries.TransferGroupOwnership(ctx, postgres.TransferGroupOwnershipParams{
+				ID:        s.ID,
+				CreatedBy: s.SuccessorID,
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		err = queries.AnonymizeUser(ctx, postgres.AnonymizeUserParams{
+			ID:     u.ID(),
+			NewID:  anonymized.ID(),
+			Name:   anonymized.Name(),
+			Avatar: anonymized.Avatar(),
+			Email:  anonymized.Email(),
+		})
+		if err != nil {
+			return err
+		}
+
+		// RemoveMemberと同様に、他のメンバーとの立て替えの記録を残すため支払いは削除しない
+		err = queries.DeleteGroupMembersByUserID(ctx, anonymized.ID())
+		if err != nil {
+			return err
+		}
+
+		err = queries.DeleteContactsByUserID(ctx, anonymized.ID())
+		if err != nil {
+			return err
+		}
+
+		return queries.DeleteIdempotencyKeysByUserID(ctx, u.ID())
+	})
+}
+
+// Merge fromのグループ所属や支払いをintoに付け替えてfromを削除する
+// 複数のクエリを実行するため、トランザクション内で呼び出す必要がある
+func (ur *UserRepositoryImpl) Merge(ctx context.Context, from, into *domain.User) (err error) {
+	ctx, span := tracer.Start(ctx, "repository.User.Merge")
+	defer func() {
+		if err != nil {
+			span.SetStatus(codes.Error, err.Error())
+			span.RecordError(err)
+		}
+		span.End()
+	}()
+
+	return mergeUser(ctx, ur.queries, from.ID(), into.ID())
+}
+
+// mergeUser fromIDのグループ所属や支払いをintoIDに付け替えてfromIDのユーザーを削除する
+// ユーザーの統合とゲストの引き継ぎで共通して使用する
+func mergeUser(ctx context.Context, queries *postgres.Queries, fromID, intoID string) error {
+	// 両方が所属しているグループは重複させない
+	err := queries.MoveGroupMembers(ctx, postgres.MoveGroupMembersParams{
+		ToID:   intoID,
+		FromID: fromID,
+	})
+	if err != nil {
+		return err
+	}
+	err = queries.DeleteGroupMembersByUserID(ctx, fromID)
+	if err != nil {
+		return err
+	}
+
+	err = queries.MoveGroupsCreatedBy(ctx, postgres.MoveGroupsCreatedByParams{
+		ToID:   intoID,
+		FromID: fromID,
+	})
+	if err != nil {
+		return err
+	}
+	err = queries.MoveEventsCreatedBy(ctx, postgres.MoveEventsCreatedByParams{
+		ToID:   &intoID,
+		FromID: &fromID,
+	})
+	if err != nil {
+		return err
+	}
+
+	// 立て替えの支払いは付け替えると支払い者自身の負担分になる
+	err = queries.MovePaymentsPayerID(ctx, postgres.MovePaymentsPayerIDParams{
+		ToID:   intoID,
+		FromID: fromID,
+	})
+	if err != nil {
+		return err
+	}
+	err = queries.MovePaymentsDebtorID(ctx, postgres.MovePaymentsDebtorIDParams{
+		ToID:   intoID,
+		FromID: fromID,
+	})
+	if err != nil {
+		return err
+	}
+
+	// 統合した2人の間の返済は意味を持たないため削除する
+	err = queries.DeleteSelfRepaymentsByUserID(ctx, intoID)
+	if err != nil {
+		return err
+	}
+
+	// 連絡先は両方向とも付け替え、重複するものは統合元と一緒に削除される
+	err = queries.MoveContacts(ctx, postgres.MoveContactsParams{
+		ToID:   intoID,
+		FromID: fromID,
+	})
+	if err != nil {
+		return err
+	}
+	err = queries.MoveContactsContactID(ctx, postgres.MoveContactsContactIDParams{
+		ToID:   intoID,
+		FromID: fromID,
+	})
+	if err != nil {
+		return err
+	}
+
+	err = queries.MoveLendingCommentsUserID(ctx, postgres.MoveLendingCommentsUserIDParams{
+		ToID:   intoID,
+		FromID: fromID,
+	})
+	if err != nil {
+		return err
+	}
+
+	err = queries.DeleteIdempotencyKeysByUserID(ctx, fromID)
+	if err != nil {
+		return err
+	}
+
+	err = queries.DeleteUser(ctx, fromID)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
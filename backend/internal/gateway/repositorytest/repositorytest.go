@@ -0,0 +1,1151 @@
+// Package repositorytest リポジトリの実装が共通で満たすべき振る舞いを検証する適合性テスト
+//
+// Postgres実装とメモリ上の実装の両方のテストから実行し、
+// ページネーションや債権/債務の集計などの結果が保存先によって変わらないことを確認する。
+package repositorytest
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/haebeal/datti/internal/domain"
+	"github.com/oklog/ulid/v2"
+)
+
+// Repositories 適合性テストの対象とするリポジトリ
+// 全てのリポジトリは同じ保存先を共有している必要がある
+type Repositories struct {
+	User           domain.UserRepository
+	Guest          domain.GuestRepository
+	Contact        domain.ContactRepository
+	Credit         domain.CreditRepository
+	Lending        domain.LendingRepository
+	Group          domain.GroupRepository
+	Repayment      domain.RepaymentRepository
+	Comment        domain.CommentRepository
+	IdempotencyKey domain.IdempotencyKeyRepository
+}
+
+// Run 全てのリポジトリの適合性テストを実行する
+// newRepositoriesはサブテストごとに呼び出され、空の保存先を使うリポジトリを返す
+func Run(t *testing.T, newRepositories func(t *testing.T) Repositories) {
+	tests := []struct {
+		name string
+		run  func(t *testing.T, r Repositories)
+	}{
+		{"User", testUser},
+		{"UserUpdateID", testUserUpdateID},
+		{"UserMerge", testUserMerge},
+		{"UserAnonymize", testUserAnonymize},
+		{"Contact", testContact},
+		{"Group", testGroup},
+		{"GroupRemoveMember", testGroupRemoveMember},
+		{"Guest", testGuest},
+		{"Lending", testLending},
+		{"LendingZeroShare", testLendingZeroShare},
+		{"LendingPagination", testLendingPagination},
+		{"LendingSearch", testLendingSearch},
+		{"Credit", testCredit},
+		{"CreditOverdue", testCreditOverdue},
+		{"Repayment", testRepayment},
+		{"Comment", testComment},
+		{"IdempotencyKey", testIdempotencyKey},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.run(t, newRepositories(t))
+		})
+	}
+}
+
+func testUser(t *testing.T, r Repositories) {
+	ctx := context.Background()
+	alice := createUser(t, r, "alice", "Alice")
+
+	got, err := r.User.FindByID(ctx, alice.ID())
+	if err != nil {
+		t.Fatalf("FindByID() error = %v", err)
+	}
+	if got.Name() != "Alice" || got.Email() != "alice@example.com" || got.IsGuest() {
+		t.Errorf("FindByID() = {%s, %s, guest=%t}, want {Alice, alice@example.com, guest=false}", got.Name(), got.Email(), got.IsGuest())
+	}
+
+	if _, err := r.User.FindByID(ctx, "missing"); !errors.Is(err, &domain.NotFoundError{}) {
+		t.Errorf("FindByID(missing) error = %v, want NotFoundError", err)
+	}
+
+	// 存在しないIDは無視してID順に取得する
+	createUser(t, r, "bob", "Bob")
+	users, err := r.User.FindByIDs(ctx, []string{"bob", "missing", alice.ID()})
+	if err != nil {
+		t.Fatalf("FindByIDs() error = %v", err)
+	}
+	if got := userIDs(users); !slices.Equal(got, []string{alice.ID(), "bob"}) {
+		t.Errorf("FindByIDs() = %v, want [alice bob]", got)
+	}
+
+	got, err = r.User.FindByEmail(ctx, "alice@example.com")
+	if err != nil {
+		t.Fatalf("FindByEmail() error = %v", err)
+	}
+	if got.ID() != alice.ID() {
+		t.Errorf("FindByEmail() = %s, want %s", got.ID(), alice.ID())
+	}
+
+	renamed, err := alice.UpdateProfile(ctx, "Alice Liddell", "")
+	if err != nil {
+		t.Fatalf("User.UpdateProfile() error = %v", err)
+	}
+	if err := r.User.Update(ctx, renamed); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	if got := findUser(t, r, alice.ID()); got.Name() != "Alice Liddell" {
+		t.Errorf("name after Update() = %s, want Alice Liddell", got.Name())
+	}
+
+	// メールアドレスでの検索は既定で許可され、大文字と小文字を区別しない
+	privacy, err := r.User.FindPrivacy(ctx, alice.ID())
+	if err != nil {
+		t.Fatalf("FindPrivacy() error = %v", err)
+	}
+	if !privacy.DiscoverableByEmail() {
+		t.Error("FindPrivacy().DiscoverableByEmail() = false, want true")
+	}
+	if _, err := r.User.FindDiscoverableByEmail(ctx, "ALICE@example.com"); err != nil {
+		t.Errorf("FindDiscoverableByEmail() error = %v", err)
+	}
+
+	if err := r.User.UpdatePrivacy(ctx, alice.ID(), domain.NewUserPrivacy(false)); err != nil {
+		t.Fatalf("UpdatePrivacy() error = %v", err)
+	}
+	if _, err := r.User.FindDiscoverableByEmail(ctx, "alice@example.com"); !errors.Is(err, &domain.NotFoundError{}) {
+		t.Errorf("FindDiscoverableByEmail() after UpdatePrivacy(false) error = %v, want NotFoundError", err)
+	}
+}
+
+func testUserUpdateID(t *testing.T, r Repositories) {
+	ctx := context.Background()
+	alice := createUser(t, r, "alice", "Alice")
+	bob := createUser(t, r, "bob", "Bob")
+	g := createGroup(t, r, alice, bob)
+	createLending(t, r, g, alice, 2000, map[*domain.User]int64{bob: 1000})
+
+	if err := r.User.UpdateID(ctx, alice.ID(), "alice-2"); err != nil {
+		t.Fatalf("UpdateID() error = %v", err)
+	}
+
+	if _, err := r.User.FindByID(ctx, alice.ID()); !errors.Is(err, &domain.NotFoundError{}) {
+		t.Errorf("FindByID(old id) error = %v, want NotFoundError", err)
+	}
+	assertMembers(t, r, g, "alice-2", bob.ID())
+	assertCredit(t, r, bob.ID(), "alice-2", -1000)
+
+	group, err := r.Group.FindByID(ctx, g.ID())
+	if err != nil {
+		t.Fatalf("Group.FindByID() error = %v", err)
+	}
+	if group.CreatedBy() != "alice-2" {
+		t.Errorf("Group.CreatedBy() = %s, want alice-2", group.CreatedBy())
+	}
+}
+
+func testUserMerge(t *testing.T, r Repositories) {
+	ctx := context.Background()
+	alice := createUser(t, r, "alice", "Alice")
+	dup := createUser(t, r, "alice-dup", "Alice")
+	bob := createUser(t, r, "bob", "Bob")
+	g := createGroup(t, r, alice, dup, bob)
+	createLending(t, r, g, dup, 2000, map[*domain.User]int64{bob: 1000})
+	createRepayment(t, r, alice, dup, 300)
+
+	if err := r.User.Merge(ctx, dup, alice); err != nil {
+		t.Fatalf("Merge() error = %v", err)
+	}
+
+	if _, err := r.User.FindByID(ctx, dup.ID()); !errors.Is(err, &domain.NotFoundError{}) {
+		t.Errorf("FindByID(merged) error = %v, want NotFoundError", err)
+	}
+	assertMembers(t, r, g, alice.ID(), bob.ID())
+	assertCredit(t, r, bob.ID(), alice.ID(), -1000)
+
+	// 統合した2人の間の返済は削除される
+	repayments, err := r.Repayment.FindByPayerID(ctx, alice.ID(), nil, ptr(int32(10)))
+	if err != nil {
+		t.Fatalf("FindByPayerID() error = %v", err)
+	}
+	if len(repayments) != 0 {
+		t.Errorf("FindByPayerID() after Merge() = %d repayments, want 0", len(repayments))
+	}
+}
+
+func testUserAnonymize(t *testing.T, r Repositories) {
+	ctx := context.Background()
+	alice := createUser(t, r, "alice", "Alice")
+	bob := createUser(t, r, "bob", "Bob")
+	carol := createUser(t, r, "carol", "Carol")
+	dave := createUser(t, r, "dave", "Dave")
+	shared := createGroup(t, r, alice, bob, carol)
+	createLending(t, r, shared, alice, 1000, map[*domain.User]int64{bob: 1000})
+
+	// 精算済みのメンバーが脱退し、作成者のみが残ったグループ
+	alone := createGroup(t, r, alice, dave)
+	aloneLending := createLending(t, r, alone, alice, 500, map[*domain.User]int64{dave: 500})
+	createRepayment(t, r, dave, alice, 500)
+	if err := r.Group.RemoveMember(ctx, alone, dave); err != nil {
+		t.Fatalf("RemoveMember() error = %v", err)
+	}
+
+	anonymized, err := alice.Anonymize(ctx)
+	if err != nil {
+		t.Fatalf("User.Anonymize() error = %v", err)
+	}
+
+	// 未精算の貸し借りがある場合は何も変更しない
+	if err := r.User.Anonymize(ctx, alice, anonymized); !errors.Is(err, &domain.ConflictError{}) {
+		t.Fatalf("Anonymize() with credits error = %v, want ConflictError", err)
+	}
+	findUser(t, r, alice.ID())
+	assertMembers(t, r, shared, alice.ID(), bob.ID(), carol.ID())
+
+	createRepayment(t, r, bob, alice, 1000)
+	if err := r.User.Anonymize(ctx, alice, anonymized); err != nil {
+		t.Fatalf("Anonymize() error = %v", err)
+	}
+
+	if _, err := r.User.FindByID(ctx, alice.ID()); !errors.Is(err, &domain.NotFoundError{}) {
+		t.Errorf("FindByID(anonymized) error = %v, want NotFoundError", err)
+	}
+	assertMembers(t, r, shared, bob.ID(), carol.ID())
+
+	// 作成したグループは最も古くから所属しているメンバーに引き継がれる
+	got, err := r.Group.FindByID(ctx, shared.ID())
+	if err != nil {
+		t.Fatalf("Group.FindByID() error = %v", err)
+	}
+	if got.CreatedBy() != bob.ID() || got.Version() != shared.Version()+1 {
+		t.Errorf("Group.FindByID() = {createdBy=%s, version=%d}, want {bob, %d}", got.CreatedBy(), got.Version(), shared.Version()+1)
+	}
+
+	// 他にメンバーがいないグループは立て替えの記録とともに残る
+	got, err = r.Group.FindByID(ctx, alone.ID())
+	if err != nil {
+		t.Fatalf("Group.FindByID(alone) error = %v", err)
+	}
+	if got.CreatedBy() != anonymized.ID() {
+		t.Errorf("Group.FindByID(alone).CreatedBy() = %s, want %s", got.CreatedBy(), anonymized.ID())
+	}
+	lending, err := r.Lending.FindByID(ctx, aloneLending.ID())
+	if err != nil {
+		t.Fatalf("Lending.FindByID(alone) error = %v", err)
+	}
+	if debtor, ok := lending.Debtors()[dave.ID()]; !ok || debtor.Amount() != 500 {
+		t.Errorf("Lending.FindByID(alone).Debtors() = %v, want dave: 500", lending.Debtors())
+	}
+}
+
+func testContact(t *testing.T, r Repositories) {
+	ctx := context.Background()
+	alice := createUser(t, r, "alice", "Alice")
+	bob := createUser(t, r, "bob", "Bob")
+	carol := createUser(t, r, "carol", "Carol")
+	createUser(t, r, "dave", "Dave")
+	createGroup(t, r, alice, carol)
+
+	if err := r.Contact.Add(ctx, alice.ID(), bob); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+
+	// 明示的に追加したユーザーと同じグループのメンバーが名前順に含まれる
+	contacts, err := r.Contact.FindByUserID(ctx, alice.ID())
+	if err != nil {
+		t.Fatalf("FindByUserID() error = %v", err)
+	}
+	if len(contacts) != 2 {
+		t.Fatalf("FindByUserID() = %d contacts, want 2", len(contacts))
+	}
+	if contacts[0].User().ID() != bob.ID() || !contacts[0].Added() {
+		t.Errorf("contacts[0] = {%s, added=%t}, want {bob, added=true}", contacts[0].User().ID(), contacts[0].Added())
+	}
+	if contacts[1].User().ID() != carol.ID() || contacts[1].Added() {
+		t.Errorf("contacts[1] = {%s, added=%t}, want {carol, added=false}", contacts[1].User().ID(), contacts[1].Added())
+	}
+
+	users, err := r.User.FindByQuery(ctx, domain.UserSearchQuery{
+		UserID: alice.ID(),
+		Email:  ptr("EXAMPLE.COM"),
+		Limit:  10,
+	})
+	if err != nil {
+		t.Fatalf("FindByQuery() error = %v", err)
+	}
+	if got := userIDs(users); !slices.Equal(got, []string{bob.ID(), carol.ID()}) {
+		t.Errorf("FindByQuery() = %v, want [bob carol]", got)
+	}
+
+	users, err = r.User.FindByQuery(ctx, domain.UserSearchQuery{
+		UserID: alice.ID(),
+		Name:   ptr("car"),
+		Limit:  10,
+	})
+	if err != nil {
+		t.Fatalf("FindByQuery() error = %v", err)
+	}
+	if got := userIDs(users); !slices.Equal(got, []string{carol.ID()}) {
+		t.Errorf("FindByQuery(name=car) = %v, want [carol]", got)
+	}
+
+	// 同じグループのメンバーは明示的に追加していないため削除できない
+	if err := r.Contact.Remove(ctx, alice.ID(), carol.ID()); !errors.Is(err, &domain.NotFoundError{}) {
+		t.Errorf("Remove(carol) error = %v, want NotFoundError", err)
+	}
+	if err := r.Contact.Remove(ctx, alice.ID(), bob.ID()); err != nil {
+		t.Errorf("Remove(bob) error = %v", err)
+	}
+}
+
+func testGroup(t *testing.T, r Repositories) {
+	ctx := context.Background()
+	alice := createUser(t, r, "alice", "Alice")
+	bob := createUser(t, r, "bob", "Bob")
+	g := createGroup(t, r, alice, bob)
+
+	got, err := r.Group.FindByID(ctx, g.ID())
+	if err != nil {
+		t.Fatalf("FindByID() error = %v", err)
+	}
+	if got.Name() != g.Name() || got.CreatedBy() != alice.ID() || got.Version() != 1 {
+		t.Errorf("FindByID() = {%s, %s, v%d}, want {%s, alice, v1}", got.Name(), got.CreatedBy(), got.Version(), g.Name())
+	}
+	assertMembers(t, r, g, alice.ID(), bob.ID())
+	assertIsMember(t, r, g, bob.ID(), true)
+	assertIsMember(t, r, g, "missing", false)
+
+	createUser(t, r, "carol", "Carol")
+	memberIDs, err := r.Group.FindMemberIDs(ctx, g.ID(), []string{bob.ID(), "carol", alice.ID()})
+	if err != nil {
+		t.Fatalf("FindMemberIDs() error = %v", err)
+	}
+	if !slices.Equal(memberIDs, []string{alice.ID(), bob.ID()}) {
+		t.Errorf("FindMemberIDs() = %v, want [alice bob]", memberIDs)
+	}
+
+	groups, err := r.Group.FindByMemberUserID(ctx, bob.ID())
+	if err != nil {
+		t.Fatalf("FindByMemberUserID() error = %v", err)
+	}
+	if len(groups) != 1 || groups[0].ID() != g.ID() {
+		t.Errorf("FindByMemberUserID() = %d groups, want [%s]", len(groups), g.ID())
+	}
+
+	// 読み込み時のバージョンから変わっている場合は更新しない
+	updated, err := got.Update(ctx, "renamed", ptr(int32(30)), false)
+	if err != nil {
+		t.Fatalf("Group.Update() error = %v", err)
+	}
+	if err := r.Group.Update(ctx, updated); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	if err := r.Group.Update(ctx, updated); !errors.Is(err, &domain.ConflictError{}) {
+		t.Errorf("second Update() error = %v, want ConflictError", err)
+	}
+	got, err = r.Group.FindByID(ctx, g.ID())
+	if err != nil {
+		t.Fatalf("FindByID() after Update() error = %v", err)
+	}
+	if got.Name() != "renamed" || got.PaymentTermsDays() == nil || *got.PaymentTermsDays() != 30 {
+		t.Errorf("FindByID() after Update() = {%s, %v}, want {renamed, 30}", got.Name(), got.PaymentTermsDays())
+	}
+
+	if err := r.Group.Delete(ctx, updated); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	groups, err = r.Group.FindByMemberUserID(ctx, alice.ID())
+	if err != nil {
+		t.Fatalf("FindByMemberUserID() error = %v", err)
+	}
+	if len(groups) != 0 {
+		t.Errorf("FindByMemberUserID() after Delete() = %d groups, want 0", len(groups))
+	}
+	assertIsMember(t, r, g, alice.ID(), false)
+}
+
+func testGroupRemoveMember(t *testing.T, r Repositories) {
+	ctx := context.Background()
+	alice := createUser(t, r, "alice", "Alice")
+	bob := createUser(t, r, "bob", "Bob")
+	carol := createUser(t, r, "carol", "Carol")
+	g := createGroup(t, r, alice, bob, carol)
+	l := createLending(t, r, g, alice, 3000, map[*domain.User]int64{bob: 1000, carol: 1000})
+
+	if err := r.Group.RemoveMember(ctx, g, bob); err != nil {
+		t.Fatalf("RemoveMember() error = %v", err)
+	}
+
+	assertMembers(t, r, g, alice.ID(), carol.ID())
+	assertIsMember(t, r, g, bob.ID(), false)
+
+	// 脱退しても立て替えの債務者として残り、貸し借りは消えない
+	assertCredit(t, r, alice.ID(), bob.ID(), 1000)
+	assertCredit(t, r, alice.ID(), carol.ID(), 1000)
+	got, err := r.Lending.FindByID(ctx, l.ID())
+	if err != nil {
+		t.Fatalf("Lending.FindByID() error = %v", err)
+	}
+	if len(got.Debtors()) != 2 || got.Payer().Amount() != 3000 || got.Payer().Share() != 1000 {
+		t.Errorf("lending after RemoveMember() = {debtors=%d, paid=%d, share=%d}, want {2, 3000, 1000}", len(got.Debtors()), got.Payer().Amount(), got.Payer().Share())
+	}
+
+	// 返済すれば精算済みになる
+	createRepayment(t, r, bob, alice, 1000)
+	assertCredit(t, r, alice.ID(), bob.ID(), 0)
+}
+
+func testGuest(t *testing.T, r Repositories) {
+	ctx := context.Background()
+	alice := createUser(t, r, "alice", "Alice")
+	bob := createUser(t, r, "bob", "Bob")
+	g := createGroup(t, r, alice)
+
+	guest, err := domain.CreateGuest(ctx, g.ID(), "Guest", "guest@example.com")
+	if err != nil {
+		t.Fatalf("CreateGuest() error = %v", err)
+	}
+	if err := r.Guest.Create(ctx, guest); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	assertMembers(t, r, g, alice.ID(), guest.ID())
+	if !findUser(t, r, guest.ID()).IsGuest() {
+		t.Error("User.FindByID(guest).IsGuest() = false, want true")
+	}
+
+	guests, err := r.Guest.FindByGroupID(ctx, g.ID())
+	if err != nil {
+		t.Fatalf("FindByGroupID() error = %v", err)
+	}
+	if len(guests) != 1 || guests[0].ID() != guest.ID() {
+		t.Errorf("FindByGroupID() = %d guests, want [%s]", len(guests), guest.ID())
+	}
+	got, err := r.Guest.FindByInviteToken(ctx, guest.InviteToken())
+	if err != nil {
+		t.Fatalf("FindByInviteToken() error = %v", err)
+	}
+	if got.ID() != guest.ID() || got.GroupID() != g.ID() {
+		t.Errorf("FindByInviteToken() = {%s, %s}, want {%s, %s}", got.ID(), got.GroupID(), guest.ID(), g.ID())
+	}
+	if _, err := r.Guest.FindByInviteToken(ctx, "invalid"); !errors.Is(err, &domain.NotFoundError{}) {
+		t.Errorf("FindByInviteToken(invalid) error = %v, want NotFoundError", err)
+	}
+	guests, err = r.Guest.FindByEmail(ctx, "guest@example.com")
+	if err != nil {
+		t.Fatalf("FindByEmail() error = %v", err)
+	}
+	if len(guests) != 1 {
+		t.Errorf("FindByEmail() = %d guests, want 1", len(guests))
+	}
+
+	// 引き継いだユーザーがゲストの所属と支払いを受け継ぐ
+	createLending(t, r, g, alice, 2000, map[*domain.User]int64{guest.User(): 1000})
+	if err := r.Guest.Claim(ctx, guest, bob); err != nil {
+		t.Fatalf("Claim() error = %v", err)
+	}
+	assertMembers(t, r, g, alice.ID(), bob.ID())
+	assertCredit(t, r, alice.ID(), bob.ID(), 1000)
+	if _, err := r.User.FindByID(ctx, guest.ID()); !errors.Is(err, &domain.NotFoundError{}) {
+		t.Errorf("User.FindByID(claimed guest) error = %v, want NotFoundError", err)
+	}
+}
+
+func testLending(t *testing.T, r Repositories) {
+	ctx := context.Background()
+	alice := createUser(t, r, "alice", "Alice")
+	bob := createUser(t, r, "bob", "Bob")
+	carol := createUser(t, r, "carol", "Carol")
+	g := createGroup(t, r, alice, bob, carol)
+	l := createLending(t, r, g, alice, 3000, map[*domain.User]int64{bob: 1000, carol: 1000})
+
+	got, err := r.Lending.FindByID(ctx, l.ID())
+	if err != nil {
+		t.Fatalf("FindByID() error = %v", err)
+	}
+	if got.Name() != l.Name() || got.Amount() != 3000 || got.Version() != 1 {
+		t.Errorf("FindByID() = {%s, %d, v%d}, want {%s, 3000, v1}", got.Name(), got.Amount(), got.Version(), l.Name())
+	}
+	if got.Payer().ID() != alice.ID() || got.Payer().Amount() != 3000 || got.Payer().Share() != 1000 {
+		t.Errorf("Payer() = {%s, %d, %d}, want {alice, 3000, 1000}", got.Payer().ID(), got.Payer().Amount(), got.Payer().Share())
+	}
+	if d, ok := got.Debtors()[bob.ID()]; !ok || d.Amount() != 1000 || len(got.Debtors()) != 2 {
+		t.Errorf("Debtors() = %d debtors, want bob and carol with 1000", len(got.Debtors()))
+	}
+
+	// グループの立て替えは個人間の立て替えとして取得できない
+	if _, err := r.Lending.FindPersonalByID(ctx, l.ID()); !errors.Is(err, &domain.NotFoundError{}) {
+		t.Errorf("FindPersonalByID(group lending) error = %v, want NotFoundError", err)
+	}
+
+	dueDate := time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)
+	updated, err := got.Update(ctx, "renamed", got.Amount(), got.EventDate(), &dueDate)
+	if err != nil {
+		t.Fatalf("Lending.Update() error = %v", err)
+	}
+	if err := updated.RemoveDebtor(carol.ID()); err != nil {
+		t.Fatalf("RemoveDebtor() error = %v", err)
+	}
+	payer, err := updated.Payer().Update(3000, 2000)
+	if err != nil {
+		t.Fatalf("Payer.Update() error = %v", err)
+	}
+	if err := updated.SetPayers(map[string]*domain.Payer{payer.ID(): payer}); err != nil {
+		t.Fatalf("SetPayers() error = %v", err)
+	}
+	if err := r.Lending.Update(ctx, updated); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	if err := r.Lending.Update(ctx, updated); !errors.Is(err, &domain.ConflictError{}) {
+		t.Errorf("second Update() error = %v, want ConflictError", err)
+	}
+	got, err = r.Lending.FindByID(ctx, l.ID())
+	if err != nil {
+		t.Fatalf("FindByID() after Update() error = %v", err)
+	}
+	if got.Name() != "renamed" || got.Version() != 2 || len(got.Debtors()) != 1 {
+		t.Errorf("FindByID() after Update() = {%s, v%d, debtors=%d}, want {renamed, v2, debtors=1}", got.Name(), got.Version(), len(got.Debtors()))
+	}
+	if got.DueDate() == nil || !got.DueDate().Equal(dueDate) {
+		t.Errorf("DueDate() after Update() = %v, want %v", got.DueDate(), dueDate)
+	}
+	assertCredit(t, r, alice.ID(), carol.ID(), 0)
+
+	// 個人間の立て替えはグループを持たない
+	personal := createLending(t, r, nil, bob, 500, map[*domain.User]int64{alice: 500})
+	if _, err := r.Lending.FindPersonalByID(ctx, personal.ID()); err != nil {
+		t.Errorf("FindPersonalByID() error = %v", err)
+	}
+	results, err := r.Lending.FindByUserID(ctx, alice.ID(), nil, 10)
+	if err != nil {
+		t.Fatalf("FindByUserID() error = %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("FindByUserID() = %d results, want 2", len(results))
+	}
+	if results[0].Lending.ID() != personal.ID() || results[0].Group != nil {
+		t.Errorf("FindByUserID()[0] = %s, want personal lending without group", results[0].Lending.ID())
+	}
+	if results[1].Lending.ID() != l.ID() || results[1].Group == nil || results[1].Group.ID() != g.ID() {
+		t.Errorf("FindByUserID()[1] = %s, want group lending with group", results[1].Lending.ID())
+	}
+
+	if err := r.Lending.Delete(ctx, l.ID()); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if _, err := r.Lending.FindByID(ctx, l.ID()); !errors.Is(err, &domain.NotFoundError{}) {
+		t.Errorf("FindByID() after Delete() error = %v, want NotFoundError", err)
+	}
+	assertCredit(t, r, alice.ID(), bob.ID(), -500)
+}
+
+// testLendingZeroShare 負担額が0の支払い者と債務額が0の債務者の扱いを確認する
+func testLendingZeroShare(t *testing.T, r Repositories) {
+	ctx := context.Background()
+	alice := createUser(t, r, "alice", "Alice")
+	bob := createUser(t, r, "bob", "Bob")
+	carol := createUser(t, r, "carol", "Carol")
+	g := createGroup(t, r, alice, bob, carol)
+
+	// 債務額が0の債務者は支払いとして記録されず復元できないため作成できない
+	if _, err := domain.NewDebtor(carol.ID(), carol.Name(), carol.Avatar(), carol.Email(), 0); !errors.Is(err, &domain.ValidationError{}) {
+		t.Fatalf("NewDebtor(0) error = %v, want ValidationError", err)
+	}
+
+	// 支払い者の負担額が0の立て替えは作成後も一覧と詳細で取得できる
+	l := createLending(t, r, g, alice, 1000, map[*domain.User]int64{bob: 1000})
+
+	lendings, err := r.Lending.FindByGroupAndUserID(ctx, g, alice.ID(), nil, ptr(int32(10)))
+	if err != nil {
+		t.Fatalf("FindByGroupAndUserID() error = %v", err)
+	}
+	if len(lendings) != 1 || lendings[0].ID() != l.ID() {
+		t.Fatalf("FindByGroupAndUserID() = %d lendings, want [%s]", len(lendings), l.ID())
+	}
+
+	got, err := r.Lending.FindByID(ctx, l.ID())
+	if err != nil {
+		t.Fatalf("FindByID() error = %v", err)
+	}
+	debtor, ok := got.Debtors()[bob.ID()]
+	if got.PayerShare() != 0 || len(got.Debtors()) != 1 || !ok || debtor.Amount() != 1000 {
+		t.Errorf("FindByID() = {share=%d, debtors=%d}, want {share=0, bob=1000}", got.PayerShare(), len(got.Debtors()))
+	}
+}
+
+func testLendingPagination(t *testing.T, r Repositories) {
+	ctx := context.Background()
+	alice := createUser(t, r, "alice", "Alice")
+	bob := createUser(t, r, "bob", "Bob")
+	carol := createUser(t, r, "carol", "Carol")
+	g := createGroup(t, r, alice, bob, carol)
+
+	l1 := createLending(t, r, g, alice, 2000, map[*domain.User]int64{bob: 1000})
+	l2 := createLending(t, r, g, bob, 2000, map[*domain.User]int64{alice: 1000})
+	createLending(t, r, g, carol, 2000, map[*domain.User]int64{bob: 1000})
+	l4 := createLending(t, r, g, alice, 2000, map[*domain.User]int64{bob: 1000})
+
+	// 関与していない立て替えを除いて新しい順に取得する
+	page, err := r.Lending.FindByGroupAndUserID(ctx, g, alice.ID(), nil, ptr(int32(2)))
+	if err != nil {
+		t.Fatalf("FindByGroupAndUserID() error = %v", err)
+	}
+	if got := lendingIDs(page); !slices.Equal(got, []string{l4.ID().String(), l2.ID().String()}) {
+		t.Errorf("first page = %v, want [l4 l2]", got)
+	}
+
+	cursor := l2.ID().String()
+	page, err = r.Lending.FindByGroupAndUserID(ctx, g, alice.ID(), &cursor, ptr(int32(2)))
+	if err != nil {
+		t.Fatalf("FindByGroupAndUserID() error = %v", err)
+	}
+	if got := lendingIDs(page); !slices.Equal(got, []string{l1.ID().String()}) {
+		t.Errorf("second page = %v, want [l1]", got)
+	}
+
+	results, err := r.Lending.FindByUserID(ctx, alice.ID(), &cursor, 10)
+	if err != nil {
+		t.Fatalf("FindByUserID() error = %v", err)
+	}
+	if len(results) != 1 || results[0].Lending.ID() != l1.ID() {
+		t.Errorf("FindByUserID() after cursor = %d results, want [l1]", len(results))
+	}
+}
+
+func testLendingSearch(t *testing.T, r Repositories) {
+	ctx := context.Background()
+	alice := createUser(t, r, "alice", "Alice")
+	bob := createUser(t, r, "bob", "Bob")
+	g := createGroup(t, r, alice, bob)
+	other := createGroup(t, r, alice, bob)
+
+	lunch := createNamedLending(t, r, g, "Team lunch", alice, 2000, map[*domain.User]int64{bob: 1000})
+	createNamedLending(t, r, g, "Taxi", alice, 2000, map[*domain.User]int64{bob: 1000})
+	createNamedLending(t, r, other, "Lunch", alice, 2000, map[*domain.User]int64{bob: 1000})
+
+	results, err := r.Lending.Search(ctx, []*domain.Group{g}, bob.ID(), "LUNCH", 10)
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+	if len(results) != 1 || results[0].Lending.ID() != lunch.ID() || results[0].Group.ID() != g.ID() {
+		t.Errorf("Search() = %d results, want [Team lunch]", len(results))
+	}
+
+	// %と_はワイルドカードではなく文字として検索する
+	discount := createNamedLending(t, r, g, "50% off", alice, 2000, map[*domain.User]int64{bob: 1000})
+	results, err = r.Lending.Search(ctx, []*domain.Group{g}, bob.ID(), "%", 10)
+	if err != nil {
+		t.Fatalf("Search(%%) error = %v", err)
+	}
+	if len(results) != 1 || results[0].Lending.ID() != discount.ID() {
+		t.Errorf("Search(%%) = %d results, want [50%% off]", len(results))
+	}
+	results, err = r.Lending.Search(ctx, []*domain.Group{g}, bob.ID(), "_", 10)
+	if err != nil {
+		t.Fatalf("Search(_) error = %v", err)
+	}
+	if len(results) != 0 {
+		t.Errorf("Search(_) = %d results, want 0", len(results))
+	}
+
+	results, err = r.Lending.Search(ctx, nil, bob.ID(), "lunch", 10)
+	if err != nil {
+		t.Fatalf("Search(no groups) error = %v", err)
+	}
+	if len(results) != 0 {
+		t.Errorf("Search(no groups) = %d results, want 0", len(results))
+	}
+}
+
+func testCredit(t *testing.T, r Repositories) {
+	ctx := context.Background()
+	alice := createUser(t, r, "alice", "Alice")
+	bob := createUser(t, r, "bob", "Bob")
+	carol := createUser(t, r, "carol", "Carol")
+	g := createGroup(t, r, alice, bob, carol)
+
+	createLending(t, r, g, alice, 3000, map[*domain.User]int64{bob: 1000, carol: 1000})
+	createLending(t, r, g, bob, 600, map[*domain.User]int64{alice: 300})
+	createRepayment(t, r, bob, alice, 400)
+
+	// 立て替えと返済を相殺した残高 (正=貸している、負=借りている)
+	credits, err := r.Credit.FindByUserID(ctx, alice.ID())
+	if err != nil {
+		t.Fatalf("FindByUserID() error = %v", err)
+	}
+	got := make(map[string]int64, len(credits))
+	for _, c := range credits {
+		got[c.UserID()] = c.Amount()
+	}
+	if len(got) != 2 || got[bob.ID()] != 300 || got[carol.ID()] != 1000 {
+		t.Errorf("FindByUserID(alice) = %v, want map[bob:300 carol:1000]", got)
+	}
+
+	assertCredit(t, r, bob.ID(), alice.ID(), -300)
+	assertCredit(t, r, bob.ID(), carol.ID(), 0)
+
+	total, err := r.Credit.TotalOutstanding(ctx)
+	if err != nil {
+		t.Fatalf("TotalOutstanding() error = %v", err)
+	}
+	if total != 1300 {
+		t.Errorf("TotalOutstanding() = %d, want 1300", total)
+	}
+}
+
+func testCreditOverdue(t *testing.T, r Repositories) {
+	ctx := context.Background()
+	alice := createUser(t, r, "alice", "Alice")
+	bob := createUser(t, r, "bob", "Bob")
+	carol := createUser(t, r, "carol", "Carol")
+	dave := createUser(t, r, "dave", "Dave")
+	g := createGroup(t, r, alice, bob, carol, dave)
+
+	now := time.Now()
+	past := now.AddDate(0, 0, -7)
+	future := now.AddDate(0, 0, 7)
+	createDueLending(t, r, g, alice, 2000, &past, map[*domain.User]int64{bob: 1000})
+	createDueLending(t, r, g, alice, 1000, &future, map[*domain.User]int64{bob: 500})
+	createDueLending(t, r, g, alice, 1000, nil, map[*domain.User]int64{carol: 400})
+	createRepayment(t, r, bob, alice, 300)
+
+	// 返済は期限なしの債務から充当する
+	createDueLending(t, r, g, alice, 1000, nil, map[*domain.User]int64{dave: 400})
+	createDueLending(t, r, g, alice, 1000, &past, map[*domain.User]int64{dave: 300})
+	createRepayment(t, r, dave, alice, 200)
+
+	// その後は期限の早い債務から充当し、期限前と期限なしの債務は延滞としない
+	credits, err := r.Credit.FindByUserID(ctx, alice.ID())
+	if err != nil {
+		t.Fatalf("FindByUserID() error = %v", err)
+	}
+	got := make(map[string][2]int64, len(credits))
+	for _, c := range credits {
+		got[c.UserID()] = [2]int64{c.Amount(), c.Overdue()}
+	}
+	want := map[string][2]int64{bob.ID(): {1200, 700}, carol.ID(): {400, 0}, dave.ID(): {500, 300}}
+	if !maps.Equal(got, want) {
+		t.Errorf("FindByUserID(alice) = %v, want %v", got, want)
+	}
+
+	c, err := r.Credit.FindByUserIDAndOtherUserID(ctx, bob.ID(), alice.ID())
+	if err != nil {
+		t.Fatalf("FindByUserIDAndOtherUserID() error = %v", err)
+	}
+	if c.Amount() != -1200 || c.Overdue() != -700 {
+		t.Errorf("FindByUserIDAndOtherUserID(bob, alice) = {%d, %d}, want {-1200, -700}", c.Amount(), c.Overdue())
+	}
+}
+
+func testRepayment(t *testing.T, r Repositories) {
+	ctx := context.Background()
+	alice := createUser(t, r, "alice", "Alice")
+	bob := createUser(t, r, "bob", "Bob")
+	g := createGroup(t, r, alice, bob)
+
+	// 立て替えの支払いは返済として扱わない
+	createLending(t, r, g, bob, 2000, map[*domain.User]int64{alice: 1000})
+	r1 := createRepayment(t, r, bob, alice, 100)
+	r2 := createRepayment(t, r, bob, alice, 200)
+	r3 := createRepayment(t, r, bob, alice, 300)
+
+	page, err := r.Repayment.FindByPayerID(ctx, bob.ID(), nil, ptr(int32(2)))
+	if err != nil {
+		t.Fatalf("FindByPayerID() error = %v", err)
+	}
+	if got := repaymentIDs(page); !slices.Equal(got, []string{r3.ID().String(), r2.ID().String()}) {
+		t.Errorf("first page = %v, want [r3 r2]", got)
+	}
+	cursor := r2.ID().String()
+	page, err = r.Repayment.FindByPayerID(ctx, bob.ID(), &cursor, ptr(int32(2)))
+	if err != nil {
+		t.Fatalf("FindByPayerID() error = %v", err)
+	}
+	if got := repaymentIDs(page); !slices.Equal(got, []string{r1.ID().String()}) {
+		t.Errorf("second page = %v, want [r1]", got)
+	}
+
+	page, err = r.Repayment.FindByDebtorID(ctx, alice.ID(), nil, ptr(int32(10)))
+	if err != nil {
+		t.Fatalf("FindByDebtorID() error = %v", err)
+	}
+	if len(page) != 3 {
+		t.Errorf("FindByDebtorID() = %d repayments, want 3", len(page))
+	}
+
+	got, err := r.Repayment.FindByID(ctx, r1.ID())
+	if err != nil {
+		t.Fatalf("FindByID() error = %v", err)
+	}
+	updated, err := got.Update(ctx, 150)
+	if err != nil {
+		t.Fatalf("Repayment.Update() error = %v", err)
+	}
+	if err := r.Repayment.Update(ctx, updated); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	if err := r.Repayment.Update(ctx, updated); !errors.Is(err, &domain.ConflictError{}) {
+		t.Errorf("second Update() error = %v, want ConflictError", err)
+	}
+	got, err = r.Repayment.FindByID(ctx, r1.ID())
+	if err != nil {
+		t.Fatalf("FindByID() after Update() error = %v", err)
+	}
+	if got.Amount() != 150 || got.Version() != 2 {
+		t.Errorf("FindByID() after Update() = {%d, v%d}, want {150, v2}", got.Amount(), got.Version())
+	}
+
+	if err := r.Repayment.Delete(ctx, r1.ID()); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if _, err := r.Repayment.FindByID(ctx, r1.ID()); !errors.Is(err, &domain.NotFoundError{}) {
+		t.Errorf("FindByID() after Delete() error = %v, want NotFoundError", err)
+	}
+}
+
+func testComment(t *testing.T, r Repositories) {
+	ctx := context.Background()
+	alice := createUser(t, r, "alice", "Alice")
+	bob := createUser(t, r, "bob", "Bob")
+	g := createGroup(t, r, alice, bob)
+	l := createLending(t, r, g, alice, 2000, map[*domain.User]int64{bob: 1000})
+	other := createLending(t, r, g, alice, 2000, map[*domain.User]int64{bob: 1000})
+
+	c1 := createComment(t, r, l, bob, "first")
+	c2 := createComment(t, r, l, alice, "second")
+	c3 := createComment(t, r, l, bob, "third")
+
+	// 古い順に取得する
+	page, err := r.Comment.FindByLendingID(ctx, l.ID(), nil, 2)
+	if err != nil {
+		t.Fatalf("FindByLendingID() error = %v", err)
+	}
+	if got := commentIDs(page); !slices.Equal(got, []string{c1.ID().String(), c2.ID().String()}) {
+		t.Errorf("first page = %v, want [c1 c2]", got)
+	}
+	if page[0].Author().ID() != bob.ID() || page[0].Body() != "first" {
+		t.Errorf("page[0] = {%s, %s}, want {bob, first}", page[0].Author().ID(), page[0].Body())
+	}
+	cursor := c2.ID().String()
+	page, err = r.Comment.FindByLendingID(ctx, l.ID(), &cursor, 2)
+	if err != nil {
+		t.Fatalf("FindByLendingID() error = %v", err)
+	}
+	if got := commentIDs(page); !slices.Equal(got, []string{c3.ID().String()}) {
+		t.Errorf("second page = %v, want [c3]", got)
+	}
+
+	counts, err := r.Comment.CountByLendingIDs(ctx, []ulid.ULID{l.ID(), other.ID()})
+	if err != nil {
+		t.Fatalf("CountByLendingIDs() error = %v", err)
+	}
+	if len(counts) != 1 || counts[l.ID()] != 3 {
+		t.Errorf("CountByLendingIDs() = %v, want only %s: 3", counts, l.ID())
+	}
+
+	edited, err := c1.Edit(ctx, "edited")
+	if err != nil {
+		t.Fatalf("Comment.Edit() error = %v", err)
+	}
+	if err := r.Comment.Update(ctx, edited); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	got, err := r.Comment.FindByID(ctx, c1.ID())
+	if err != nil {
+		t.Fatalf("FindByID() error = %v", err)
+	}
+	if got.Body() != "edited" {
+		t.Errorf("FindByID().Body() = %s, want edited", got.Body())
+	}
+
+	if err := r.Comment.Delete(ctx, c1.ID()); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if err := r.Comment.Delete(ctx, c1.ID()); !errors.Is(err, &domain.NotFoundError{}) {
+		t.Errorf("second Delete() error = %v, want NotFoundError", err)
+	}
+	if err := r.Comment.Update(ctx, edited); !errors.Is(err, &domain.NotFoundError{}) {
+		t.Errorf("Update() after Delete() error = %v, want NotFoundError", err)
+	}
+
+	// 立て替えを削除するとコメントも削除される
+	if err := r.Lending.Delete(ctx, l.ID()); err != nil {
+		t.Fatalf("Lending.Delete() error = %v", err)
+	}
+	if _, err := r.Comment.FindByID(ctx, c2.ID()); !errors.Is(err, &domain.NotFoundError{}) {
+		t.Errorf("FindByID() after Lending.Delete() error = %v, want NotFoundError", err)
+	}
+}
+
+func testIdempotencyKey(t *testing.T, r Repositories) {
+	ctx := context.Background()
+	alice := createUser(t, r, "alice", "Alice")
+
+	k, err := domain.CreateIdempotencyKey(alice.ID(), "key", "POST", "/groups", "hash")
+	if err != nil {
+		t.Fatalf("CreateIdempotencyKey() error = %v", err)
+	}
+	expiresBefore := time.Now().Add(-time.Hour)
+
+	existing, err := r.IdempotencyKey.Reserve(ctx, k, expiresBefore)
+	if err != nil {
+		t.Fatalf("Reserve() error = %v", err)
+	}
+	if existing != nil {
+		t.Fatal("Reserve() returned an existing key for a new key")
+	}
+
+	existing, err = r.IdempotencyKey.Reserve(ctx, k, expiresBefore)
+	if err != nil {
+		t.Fatalf("second Reserve() error = %v", err)
+	}
+	if existing == nil || existing.Completed() || !existing.Matches("POST", "/groups", "hash") {
+		t.Fatalf("second Reserve() = %v, want the reserved key in progress", existing)
+	}
+
+	completed, err := k.Complete(201, "application/json", []byte(`{"id":"1"}`))
+	if err != nil {
+		t.Fatalf("IdempotencyKey.Complete() error = %v", err)
+	}
+	if err := r.IdempotencyKey.Complete(ctx, completed); err != nil {
+		t.Fatalf("Complete() error = %v", err)
+	}
+	existing, err = r.IdempotencyKey.Reserve(ctx, k, expiresBefore)
+	if err != nil {
+		t.Fatalf("Reserve() after Complete() error = %v", err)
+	}
+	if existing == nil || existing.StatusCode() != 201 || string(existing.ResponseBody()) != `{"id":"1"}` {
+		t.Fatalf("Reserve() after Complete() = %v, want the completed response", existing)
+	}
+
+	// 有効期限切れのキーは再利用できる
+	existing, err = r.IdempotencyKey.Reserve(ctx, k, time.Now().Add(time.Hour))
+	if err != nil {
+		t.Fatalf("Reserve() after expiry error = %v", err)
+	}
+	if existing != nil {
+		t.Error("Reserve() after expiry returned an existing key")
+	}
+
+	if err := r.IdempotencyKey.Delete(ctx, k); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	existing, err = r.IdempotencyKey.Reserve(ctx, k, expiresBefore)
+	if err != nil {
+		t.Fatalf("Reserve() after Delete() error = %v", err)
+	}
+	if existing != nil {
+		t.Error("Reserve() after Delete() returned an existing key")
+	}
+}
+
+func createUser(t *testing.T, r Repositories, id string, name string) *domain.User {
+	t.Helper()
+
+	u, err := domain.NewUser(context.Background(), id, name, "", id+"@example.com")
+	if err != nil {
+		t.Fatalf("NewUser() error = %v", err)
+	}
+	if err := r.User.Create(context.Background(), u); err != nil {
+		t.Fatalf("User.Create() error = %v", err)
+	}
+	return u
+}
+
+func findUser(t *testing.T, r Repositories, id string) *domain.User {
+	t.Helper()
+
+	u, err := r.User.FindByID(context.Background(), id)
+	if err != nil {
+		t.Fatalf("User.FindByID(%s) error = %v", id, err)
+	}
+	return u
+}
+
+// createGroup ownerが作成したグループにmembersを追加する
+func createGroup(t *testing.T, r Repositories, owner *domain.User, members ...*domain.User) *domain.Group {
+	t.Helper()
+	ctx := context.Background()
+
+	g, err := domain.CreateGroup(ctx, "group", owner.ID(), nil)
+	if err != nil {
+		t.Fatalf("CreateGroup() error = %v", err)
+	}
+	if err := r.Group.Create(ctx, g); err != nil {
+		t.Fatalf("Group.Create() error = %v", err)
+	}
+	for _, u := range append([]*domain.User{owner}, members...) {
+		if err := r.Group.AddMember(ctx, g, u); err != nil {
+			t.Fatalf("Group.AddMember(%s) error = %v", u.ID(), err)
+		}
+	}
+	return g
+}
+
+func createLending(t *testing.T, r Repositories, g *domain.Group, payer *domain.User, amount int64, debts map[*domain.User]int64) *domain.Lending {
+	t.Helper()
+	return createNamedLending(t, r, g, "lending", payer, amount, debts)
+}
+
+func createDueLending(t *testing.T, r Repositories, g *domain.Group, payer *domain.User, amount int64, dueDate *time.Time, debts map[*domain.User]int64) *domain.Lending {
+	t.Helper()
+	return createLendingWith(t, r, g, "lending", payer, amount, dueDate, debts)
+}
+
+func createNamedLending(t *testing.T, r Repositories, g *domain.Group, name string, payer *domain.User, amount int64, debts map[*domain.User]int64) *domain.Lending {
+	t.Helper()
+	return createLendingWith(t, r, g, name, payer, amount, nil, debts)
+}
+
+// createLendingWith payerがamountを支払い、debtsを除いた残りを負担する立て替えを作成する
+// gがnilの場合は個人間の立て替えになる
+func createLendingWith(t *testing.T, r Repositories, g *domain.Group, name string, payer *domain.User, amount int64, dueDate *time.Time, debts map[*domain.User]int64) *domain.Lending {
+	t.Helper()
+	ctx := context.Background()
+
+	share := amount
+	for _, debt := range debts {
+		share -= debt
+	}
+	p, err := domain.NewPayer(payer.ID(), payer.Name(), payer.Avatar(), payer.Email(), amount, share)
+	if err != nil {
+		t.Fatalf("NewPayer() error = %v", err)
+	}
+	l, err := domain.CreateLending(ctx, name, amount, time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), dueDate, p)
+	if err != nil {
+		t.Fatalf("CreateLending() error = %v", err)
+	}
+	for u, debt := range debts {
+		d, err := domain.NewDebtor(u.ID(), u.Name(), u.Avatar(), u.Email(), debt)
+		if err != nil {
+			t.Fatalf("NewDebtor() error = %v", err)
+		}
+		if err := l.AddDebtor(d); err != nil {
+			t.Fatalf("AddDebtor() error = %v", err)
+		}
+	}
+	if err := l.ValidateBreakdown(); err != nil {
+		t.Fatalf("ValidateBreakdown() error = %v", err)
+	}
+	if err := r.Lending.Create(ctx, g, l); err != nil {
+		t.Fatalf("Lending.Create() error = %v", err)
+	}
+	return l
+}
+
+func createRepayment(t *testing.T, r Repositories, payer *domain.User, debtor *domain.User, amount int64) *domain.Repayment {
+	t.Helper()
+	ctx := context.Background()
+
+	rp, err := domain.CreateRepayment(ctx, payer.ID(), debtor.ID(), amount)
+	if err != nil {
+		t.Fatalf("CreateRepayment() error = %v", err)
+	}
+	if err := r.Repayment.Create(ctx, rp); err != nil {
+		t.Fatalf("Repayment.Create() error = %v", err)
+	}
+	return rp
+}
+
+func createComment(t *testing.T, r Repositories, l *domain.Lending, author *domain.User, body string) *domain.Comment {
+	t.Helper()
+	ctx := context.Background()
+
+	c, err := domain.CreateComment(ctx, l.ID(), author, body)
+	if err != nil {
+		t.Fatalf("CreateComment() error = %v", err)
+	}
+	if err := r.Comment.Create(ctx, c); err != nil {
+		t.Fatalf("Comment.Create() error = %v", err)
+	}
+	return c
+}
+
+// assertMembers グループのメンバーが参加順にwantと一致することを検証する
+func assertMembers(t *testing.T, r Repositories, g *domain.Group, want ...string) {
+	t.Helper()
+
+	members, err := r.Group.FindMembersByID(context.Background(), g.ID())
+	if err != nil {
+		t.Fatalf("Group.FindMembersByID() error = %v", err)
+	}
+	if got := userIDs(members); !slices.Equal(got, want) {
+		t.Errorf("Group.FindMembersByID() = %v, want %v", got, want)
+	}
+}
+
+func assertIsMember(t *testing.T, r Repositories, g *domain.Group, userID string, want bool) {
+	t.Helper()
+
+	got, err := r.Group.IsMember(context.Background(), g.ID(), userID)
+	if err != nil {
+		t.Fatalf("Group.IsMember(%s) error = %v", userID, err)
+	}
+	if got != want {
+		t.Errorf("Group.IsMember(%s) = %t, want %t", userID, got, want)
+	}
+}
+
+// assertCredit userIDから見たotherUserIDとの残高を検証する (0の場合は債権/債務がないことを検証する)
+func assertCredit(t *testing.T, r Repositories, userID string, otherUserID string, want int64) {
+	t.Helper()
+
+	c, err := r.Credit.FindByUserIDAndOtherUserID(context.Background(), userID, otherUserID)
+	if want == 0 {
+		if !errors.Is(err, &domain.NotFoundError{}) {
+			t.Errorf("Credit.FindByUserIDAndOtherUserID(%s, %s) error = %v, want NotFoundError", userID, otherUserID, err)
+		}
+		return
+	}
+	if err != nil {
+		t.Fatalf("Credit.FindByUserIDAndOtherUserID(%s, %s) error = %v", userID, otherUserID, err)
+	}
+	if c.Amount() != want {
+		t.Errorf("Credit.FindByUserIDAndOtherUserID(%s, %s) = %d, want %d", userID, otherUserID, c.Amount(), want)
+	}
+}
+
+func userIDs(users []*domain.User) []string {
+	ids := make([]string, 0, len(users))
+	for _, u := range users {
+		ids = append(ids, u.ID())
+	}
+	return ids
+}
+
+func lendingIDs(lendings []*domain.Lending) []string {
+	ids := make([]string, 0, len(lendings))
+	for _, l := range lendings {
+		ids = append(ids, l.ID().String())
+	}
+	return ids
+}
+
+func repaymentIDs(repayments []*domain.Repayment) []string {
+	ids := make([]string, 0, len(repayments))
+	for _, r := range repayments {
+		ids = append(ids, r.ID().String())
+	}
+	return ids
+}
+
+func commentIDs(comments []*domain.Comment) []string {
+	ids := make([]string, 0, len(comments))
+	for _, c := range comments {
+		ids = append(ids, c.ID().String())
+	}
+	return ids
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
@@ -38,10 +38,7 @@ func (h authHandler) Login(c echo.Context) error {
 	if !ok {
 		message := "認証情報が取得できませんでした"
 		span.SetStatus(codes.Error, message)
-		res := &api.ErrorResponse{
-			Message: message,
-		}
-		return c.JSON(http.StatusUnauthorized, res)
+		return echo.NewHTTPError(http.StatusUnauthorized, message)
 	}
 
 	input := AuthLoginInput{
@@ -51,17 +48,9 @@ func (h authHandler) Login(c echo.Context) error {
 	err := h.u.Login(ctx, input)
 	if err != nil {
 		if errors.Is(err, &domain.NotFoundError{}) {
-			res := &api.ErrorResponse{
-				Message: "ユーザーが見つかりません",
-			}
-			return c.JSON(http.StatusUnauthorized, res)
-		}
-		span.SetStatus(codes.Error, err.Error())
-		span.RecordError(err)
-		res := &api.ErrorResponse{
-			Message: "サーバーエラーが発生しました",
+			return echo.NewHTTPError(http.StatusUnauthorized, "ユーザーが見つかりません").SetInternal(err)
 		}
-		return c.JSON(http.StatusInternalServerError, res)
+		return err
 	}
 
 	return c.NoContent(http.StatusOK)
@@ -77,41 +66,28 @@ func (h authHandler) Signup(c echo.Context) error {
 	if !ok {
 		message := "認証情報が取得できませんでした"
 		span.SetStatus(codes.Error, message)
-		res := &api.ErrorResponse{
-			Message: message,
-		}
-		return c.JSON(http.StatusUnauthorized, res)
+		return echo.NewHTTPError(http.StatusUnauthorized, message)
 	}
 
 	var req api.AuthSignupRequest
 	if err := c.Bind(&req); err != nil {
-		res := &api.ErrorResponse{
-			Message: "リクエストの形式が正しくありません",
-		}
-		return c.JSON(http.StatusBadRequest, res)
+		return echo.NewHTTPError(http.StatusBadRequest, "リクエストの形式が正しくありません")
 	}
 
+	// 確認済みでない場合は空文字となり、メールアドレスによる引き継ぎを行わない
+	verifiedEmail, _ := c.Get("verifiedEmail").(string)
+
 	input := AuthSignupInput{
-		UID:    uid,
-		Name:   req.Name,
-		Email:  req.Email,
-		Avatar: req.Avatar,
+		UID:           uid,
+		Name:          req.Name,
+		Email:         req.Email,
+		VerifiedEmail: verifiedEmail,
+		Avatar:        req.Avatar,
 	}
 
 	output, err := h.u.Signup(ctx, input)
 	if err != nil {
-		if errors.Is(err, &domain.ConflictError{}) {
-			res := &api.ErrorResponse{
-				Message: err.Error(),
-			}
-			return c.JSON(http.StatusConflict, res)
-		}
-		span.SetStatus(codes.Error, err.Error())
-		span.RecordError(err)
-		res := &api.ErrorResponse{
-			Message: "サーバーエラーが発生しました",
-		}
-		return c.JSON(http.StatusInternalServerError, res)
+		return err
 	}
 
 	res := api.AuthSignupResponse{
@@ -131,10 +107,12 @@ type AuthLoginInput struct {
 
 // AuthSignupInput ユーザー登録の入力パラメータ
 type AuthSignupInput struct {
-	UID    string
-	Name   string
-	Email  string
-	Avatar string
+	UID   string
+	Name  string
+	Email string
+	// VerifiedEmail Cognitoで確認済みのメールアドレス（未確認の場合は空文字）
+	VerifiedEmail string
+	Avatar        string
 }
 
 // AuthSignupOutput ユーザー登録の出力
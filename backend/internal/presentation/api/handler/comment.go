@@ -0,0 +1,323 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/haebeal/datti/internal/domain"
+	"github.com/haebeal/datti/internal/presentation/api"
+	"github.com/labstack/echo/v4"
+	"github.com/oklog/ulid/v2"
+)
+
+// CommentUseCase 立て替えのコメントに関するユースケースのインターフェース
+type CommentUseCase interface {
+	Create(context.Context, CommentCreateInput) (*CommentCreateOutput, error)
+	List(context.Context, CommentListInput) (*CommentListOutput, error)
+	Update(context.Context, CommentUpdateInput) (*CommentUpdateOutput, error)
+	Delete(context.Context, CommentDeleteInput) error
+}
+
+type commentHandler struct {
+	u CommentUseCase
+}
+
+// NewCommentHandler commentHandlerのファクトリ関数
+func NewCommentHandler(u CommentUseCase) commentHandler {
+	return commentHandler{
+		u: u,
+	}
+}
+
+// List グループ内の立て替えのコメント一覧を取得する
+func (h commentHandler) List(c echo.Context, id string, lendingId string, params api.CommentListParams) error {
+	ctx, span := tracer.Start(c.Request().Context(), "comment.List")
+	defer span.End()
+
+	groupID, err := ulid.Parse(id)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "IDの形式が正しくありません")
+	}
+
+	return h.list(ctx, c, &groupID, lendingId, params.Limit, params.Cursor)
+}
+
+// ListPersonal 個人間の立て替えのコメント一覧を取得する
+func (h commentHandler) ListPersonal(c echo.Context, lendingId string, params api.CommentListPersonalParams) error {
+	ctx, span := tracer.Start(c.Request().Context(), "comment.ListPersonal")
+	defer span.End()
+
+	return h.list(ctx, c, nil, lendingId, params.Limit, params.Cursor)
+}
+
+// list 立て替えのコメント一覧を取得する (groupIDがnilの場合は個人間の立て替え)
+func (h commentHandler) list(ctx context.Context, c echo.Context, groupID *ulid.ULID, lendingId string, limitParam *int32, cursor *string) error {
+	lendingID, err := ulid.Parse(lendingId)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "IDの形式が正しくありません")
+	}
+
+	userID, ok := c.Get("uid").(string)
+	if !ok {
+		return echo.NewHTTPError(http.StatusUnauthorized, "認証情報が取得できませんでした")
+	}
+
+	limit, err := parseLimit(limitParam)
+	if err != nil {
+		return err
+	}
+
+	input := CommentListInput{
+		UserID:    userID,
+		GroupID:   groupID,
+		LendingID: lendingID,
+		Limit:     limit,
+		Cursor:    cursor,
+	}
+
+	output, err := h.u.List(ctx, input)
+	if err != nil {
+		return err
+	}
+
+	comments := make([]api.CommentResponse, 0, len(output.Comments))
+	for _, comment := range output.Comments {
+		comments = append(comments, newCommentResponse(comment))
+	}
+
+	res := &api.CommentPaginatedResponse{
+		Comments:   comments,
+		NextCursor: output.NextCursor,
+		HasMore:    output.HasMore,
+	}
+
+	return c.JSON(http.StatusOK, res)
+}
+
+// Create グループ内の立て替えにコメントを投稿する
+func (h commentHandler) Create(c echo.Context, id string, lendingId string) error {
+	ctx, span := tracer.Start(c.Request().Context(), "comment.Create")
+	defer span.End()
+
+	groupID, err := ulid.Parse(id)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "IDの形式が正しくありません")
+	}
+
+	return h.create(ctx, c, &groupID, lendingId)
+}
+
+// CreatePersonal 個人間の立て替えにコメントを投稿する
+func (h commentHandler) CreatePersonal(c echo.Context, lendingId string) error {
+	ctx, span := tracer.Start(c.Request().Context(), "comment.CreatePersonal")
+	defer span.End()
+
+	return h.create(ctx, c, nil, lendingId)
+}
+
+// create 立て替えにコメントを投稿する (groupIDがnilの場合は個人間の立て替え)
+func (h commentHandler) create(ctx context.Context, c echo.Context, groupID *ulid.ULID, lendingId string) error {
+	lendingID, err := ulid.Parse(lendingId)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "IDの形式が正しくありません")
+	}
+
+	userID, ok := c.Get("uid").(string)
+	if !ok {
+		return echo.NewHTTPError(http.StatusUnauthorized, "認証情報が取得できませんでした")
+	}
+
+	var req api.CommentCreateRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "リクエストの形式が正しくありません")
+	}
+
+	input := CommentCreateInput{
+		UserID:    userID,
+		GroupID:   groupID,
+		LendingID: lendingID,
+		Body:      req.Body,
+	}
+
+	output, err := h.u.Create(ctx, input)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusCreated, newCommentResponse(output.Comment))
+}
+
+// Update グループ内の立て替えに投稿したコメントを編集する
+func (h commentHandler) Update(c echo.Context, id string, lendingId string, commentId string) error {
+	ctx, span := tracer.Start(c.Request().Context(), "comment.Update")
+	defer span.End()
+
+	groupID, err := ulid.Parse(id)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "IDの形式が正しくありません")
+	}
+
+	return h.update(ctx, c, &groupID, lendingId, commentId)
+}
+
+// UpdatePersonal 個人間の立て替えに投稿したコメントを編集する
+func (h commentHandler) UpdatePersonal(c echo.Context, lendingId string, commentId string) error {
+	ctx, span := tracer.Start(c.Request().Context(), "comment.UpdatePersonal")
+	defer span.End()
+
+	return h.update(ctx, c, nil, lendingId, commentId)
+}
+
+// update 立て替えに投稿したコメントを編集する (groupIDがnilの場合は個人間の立て替え)
+func (h commentHandler) update(ctx context.Context, c echo.Context, groupID *ulid.ULID, lendingId string, commentId string) error {
+	lendingID, err := ulid.Parse(lendingId)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "IDの形式が正しくありません")
+	}
+
+	commentID, err := ulid.Parse(commentId)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "IDの形式が正しくありません")
+	}
+
+	userID, ok := c.Get("uid").(string)
+	if !ok {
+		return echo.NewHTTPError(http.StatusUnauthorized, "認証情報が取得できませんでした")
+	}
+
+	var req api.CommentUpdateRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "リクエストの形式が正しくありません")
+	}
+
+	input := CommentUpdateInput{
+		UserID:    userID,
+		GroupID:   groupID,
+		LendingID: lendingID,
+		CommentID: commentID,
+		Body:      req.Body,
+	}
+
+	output, err := h.u.Update(ctx, input)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, newCommentResponse(output.Comment))
+}
+
+// Delete グループ内の立て替えに投稿したコメントを削除する
+func (h commentHandler) Delete(c echo.Context, id string, lendingId string, commentId string) error {
+	ctx, span := tracer.Start(c.Request().Context(), "comment.Delete")
+	defer span.End()
+
+	groupID, err := ulid.Parse(id)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "IDの形式が正しくありません")
+	}
+
+	return h.delete(ctx, c, &groupID, lendingId, commentId)
+}
+
+// DeletePersonal 個人間の立て替えに投稿したコメントを削除する
+func (h commentHandler) DeletePersonal(c echo.Context, lendingId string, commentId string) error {
+	ctx, span := tracer.Start(c.Request().Context(), "comment.DeletePersonal")
+	defer span.End()
+
+	return h.delete(ctx, c, nil, lendingId, commentId)
+}
+
+// delete 立て替えに投稿したコメントを削除する (groupIDがnilの場合は個人間の立て替え)
+func (h commentHandler) delete(ctx context.Context, c echo.Context, groupID *ulid.ULID, lendingId string, commentId string) error {
+	lendingID, err := ulid.Parse(lendingId)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "IDの形式が正しくありません")
+	}
+
+	commentID, err := ulid.Parse(commentId)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "IDの形式が正しくありません")
+	}
+
+	userID, ok := c.Get("uid").(string)
+	if !ok {
+		return echo.NewHTTPError(http.StatusUnauthorized, "認証情報が取得できませんでした")
+	}
+
+	input := CommentDeleteInput{
+		UserID:    userID,
+		GroupID:   groupID,
+		LendingID: lendingID,
+		CommentID: commentID,
+	}
+
+	if err := h.u.Delete(ctx, input); err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func newCommentResponse(comment *domain.Comment) api.CommentResponse {
+	return api.CommentResponse{
+		Id:         comment.ID().String(),
+		LendingId:  comment.LendingID().String(),
+		UserId:     comment.Author().ID(),
+		UserName:   comment.Author().Name(),
+		UserAvatar: comment.Author().Avatar(),
+		Body:       comment.Body(),
+		CreatedAt:  comment.CreatedAt(),
+		UpdatedAt:  comment.UpdatedAt(),
+	}
+}
+
+// CommentCreateInput コメント投稿の入力パラメータ (GroupIDがnilの場合は個人間の立て替え)
+type CommentCreateInput struct {
+	UserID    string
+	GroupID   *ulid.ULID
+	LendingID ulid.ULID
+	Body      string
+}
+
+// CommentCreateOutput コメント投稿の出力
+type CommentCreateOutput struct {
+	Comment *domain.Comment
+}
+
+// CommentListInput コメント一覧取得の入力パラメータ (GroupIDがnilの場合は個人間の立て替え)
+type CommentListInput struct {
+	UserID    string
+	GroupID   *ulid.ULID
+	LendingID ulid.ULID
+	Limit     int32
+	Cursor    *string
+}
+
+// CommentListOutput コメント一覧取得の出力
+type CommentListOutput struct {
+	Comments   []*domain.Comment
+	NextCursor *string
+	HasMore    bool
+}
+
+// CommentUpdateInput コメント編集の入力パラメータ (GroupIDがnilの場合は個人間の立て替え)
+type CommentUpdateInput struct {
+	UserID    string
+	GroupID   *ulid.ULID
+	LendingID ulid.ULID
+	CommentID ulid.ULID
+	Body      string
+}
+
+// CommentUpdateOutput コメント編集の出力
+type CommentUpdateOutput struct {
+	Comment *domain.Comment
+}
+
+// CommentDeleteInput コメント削除の入力パラメータ (GroupIDがnilの場合は個人間の立て替え)
+type CommentDeleteInput struct {
+	UserID    string
+	GroupID   *ulid.ULID
+	LendingID ulid.ULID
+	CommentID ulid.ULID
+}
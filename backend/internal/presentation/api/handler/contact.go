@@ -0,0 +1,138 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/haebeal/datti/internal/domain"
+	"github.com/haebeal/datti/internal/presentation/api"
+	"github.com/labstack/echo/v4"
+)
+
+// ContactUseCase 連絡先に関するユースケースのインターフェース
+type ContactUseCase interface {
+	List(context.Context, ContactListInput) (*ContactListOutput, error)
+	Add(context.Context, ContactAddInput) (*ContactAddOutput, error)
+	Remove(context.Context, ContactRemoveInput) error
+}
+
+type contactHandler struct {
+	u ContactUseCase
+}
+
+// NewContactHandler contactHandlerのファクトリ関数
+func NewContactHandler(u ContactUseCase) contactHandler {
+	return contactHandler{
+		u: u,
+	}
+}
+
+// List 認証ユーザーの連絡先一覧を取得する
+func (h contactHandler) List(c echo.Context) error {
+	ctx, span := tracer.Start(c.Request().Context(), "contact.List")
+	defer span.End()
+
+	uid, ok := c.Get("uid").(string)
+	if !ok {
+		return echo.NewHTTPError(http.StatusUnauthorized, "認証情報が取得できませんでした")
+	}
+
+	output, err := h.u.List(ctx, ContactListInput{UID: uid})
+	if err != nil {
+		return err
+	}
+
+	res := make([]api.ContactResponse, 0, len(output.Contacts))
+	for _, contact := range output.Contacts {
+		res = append(res, newContactResponse(contact))
+	}
+
+	return c.JSON(http.StatusOK, res)
+}
+
+// Add 認証ユーザーの連絡先にユーザーを追加する
+func (h contactHandler) Add(c echo.Context) error {
+	ctx, span := tracer.Start(c.Request().Context(), "contact.Add")
+	defer span.End()
+
+	uid, ok := c.Get("uid").(string)
+	if !ok {
+		return echo.NewHTTPError(http.StatusUnauthorized, "認証情報が取得できませんでした")
+	}
+
+	var req api.ContactAddRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "リクエストの形式が正しくありません")
+	}
+
+	input := ContactAddInput{
+		UID:       uid,
+		ContactID: req.UserId,
+	}
+
+	output, err := h.u.Add(ctx, input)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusCreated, newContactResponse(output.Contact))
+}
+
+// Remove 認証ユーザーの連絡先からユーザーを削除する
+func (h contactHandler) Remove(c echo.Context, userId string) error {
+	ctx, span := tracer.Start(c.Request().Context(), "contact.Remove")
+	defer span.End()
+
+	uid, ok := c.Get("uid").(string)
+	if !ok {
+		return echo.NewHTTPError(http.StatusUnauthorized, "認証情報が取得できませんでした")
+	}
+
+	input := ContactRemoveInput{
+		UID:       uid,
+		ContactID: userId,
+	}
+
+	if err := h.u.Remove(ctx, input); err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func newContactResponse(contact *domain.Contact) api.ContactResponse {
+	return api.ContactResponse{
+		Id:     contact.User().ID(),
+		Name:   contact.User().Name(),
+		Avatar: contact.User().Avatar(),
+		Email:  contact.User().Email(),
+		Added:  contact.Added(),
+	}
+}
+
+// ContactListInput 連絡先一覧取得の入力パラメータ
+type ContactListInput struct {
+	UID string
+}
+
+// ContactListOutput 連絡先一覧取得の出力
+type ContactListOutput struct {
+	Contacts []*domain.Contact
+}
+
+// ContactAddInput 連絡先追加の入力パラメータ
+type ContactAddInput struct {
+	UID       string
+	ContactID string
+}
+
+// ContactAddOutput 連絡先追加の出力
+type ContactAddOutput struct {
+	Contact *domain.Contact
+}
+
+// ContactRemoveInput 連絡先削除の入力パラメータ
+type ContactRemoveInput struct {
+	UID       string
+	ContactID string
+}
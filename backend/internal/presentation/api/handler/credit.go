@@ -2,14 +2,12 @@ package handler
 
 import (
 	"context"
-	"errors"
 	"net/http"
 	"sort"
 
 	"github.com/haebeal/datti/internal/domain"
 	"github.com/haebeal/datti/internal/presentation/api"
 	"github.com/labstack/echo/v4"
-	"go.opentelemetry.io/otel/codes"
 )
 
 // CreditUseCase 債権/債務に関するユースケースのインターフェース
@@ -20,6 +18,8 @@ type CreditUseCase interface {
 // CreditListInput 債権/債務一覧取得の入力パラメータ
 type CreditListInput struct {
 	UserID string
+	// OverdueOnly 返済期限を過ぎた金額があるユーザーのみを取得する
+	OverdueOnly bool
 }
 
 // CreditListOutput 債権/債務一覧取得の出力
@@ -45,36 +45,29 @@ func (h creditHandler) List(c echo.Context, params api.CreditsListParams) error
 
 	userID, ok := c.Get("uid").(string)
 	if !ok {
-		res := &api.ErrorResponse{
-			Message: "認証情報が取得できませんでした",
-		}
-		return c.JSON(http.StatusUnauthorized, res)
+		return echo.NewHTTPError(http.StatusUnauthorized, "認証情報が取得できませんでした")
 	}
 
 	input := CreditListInput{UserID: userID}
+	if params.Status != nil {
+		if *params.Status != api.Overdue {
+			return echo.NewHTTPError(http.StatusBadRequest, "statusの値が正しくありません")
+		}
+		input.OverdueOnly = true
+	}
+
 	output, err := h.u.List(ctx, input)
 	if err != nil {
-		
-		if errors.Is(err, &domain.NotFoundError{}) {
-			res := &api.ErrorResponse{
-				Message: "ユーザーが見つかりません",
-			}
-			return c.JSON(http.StatusNotFound, res)
-		}
-		span.SetStatus(codes.Error, err.Error())
-		span.RecordError(err)
-		res := &api.ErrorResponse{
-			Message: "サーバーエラーが発生しました",
-		}
-		return c.JSON(http.StatusInternalServerError, res)
+		return err
 	}
 
 	// リポジトリで残高計算済みなのでそのまま変換
 	summaries := make([]api.Credit, 0, len(output.Credits))
 	for _, credit := range output.Credits {
 		summaries = append(summaries, api.Credit{
-			UserId: credit.UserID(),
-			Amount: credit.Amount(),
+			UserId:        credit.UserID(),
+			Amount:        credit.Amount(),
+			OverdueAmount: credit.Overdue(),
 		})
 	}
 
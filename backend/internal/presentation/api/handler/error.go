@@ -0,0 +1,166 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"github.com/haebeal/datti/internal/domain"
+	"github.com/haebeal/datti/internal/logger"
+	"github.com/haebeal/datti/internal/presentation/api"
+	"github.com/labstack/echo/v4"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// エラーレスポンスのcode (クライアントが判定に使用するため値を変更しないこと)
+const (
+	ErrorCodeBadRequest           = "bad_request"
+	ErrorCodeValidation           = "validation_error"
+	ErrorCodeUnauthorized         = "unauthorized"
+	ErrorCodeForbidden            = "forbidden"
+	ErrorCodeNotFound             = "not_found"
+	ErrorCodeConflict             = "conflict"
+	ErrorCodePreconditionFailed   = "precondition_failed"
+	ErrorCodePreconditionRequired = "precondition_required"
+	ErrorCodeUnprocessableEntity  = "unprocessable_entity"
+	ErrorCodeTooManyRequests      = "too_many_requests"
+	ErrorCodeInternal             = "internal_error"
+)
+
+// MIMEApplicationProblemJSON RFC 7807のエラーレスポンスのContent-Type
+const MIMEApplicationProblemJSON = "application/problem+json"
+
+// ErrorHandler ハンドラーやミドルウェアが返したエラーをRFC 7807形式のレスポンスに変換する
+// echo.HTTPErrorHandlerとして登録する
+func ErrorHandler(err error, c echo.Context) {
+	if c.Response().Committed {
+		return
+	}
+
+	ctx := c.Request().Context()
+	res := newErrorResponse(err, c.Request().Header.Get("If-Match") != "")
+	path := c.Request().URL.Path
+	res.Instance = &path
+
+	if res.Status >= http.StatusInternalServerError {
+		span := trace.SpanFromContext(ctx)
+		span.SetStatus(codes.Error, err.Error())
+		span.RecordError(err)
+		logger.FromContext(ctx).ErrorContext(ctx, "リクエストの処理中にエラーが発生しました", slog.Any("error", err))
+	}
+
+	c.Response().Header().Set(echo.HeaderContentType, MIMEApplicationProblemJSON)
+
+	var writeErr error
+	if c.Request().Method == http.MethodHead {
+		writeErr = c.NoContent(int(res.Status))
+	} else {
+		writeErr = c.JSON(int(res.Status), res)
+	}
+	if writeErr != nil {
+		logger.FromContext(ctx).ErrorContext(ctx, "エラーレスポンスの書き込みに失敗しました", slog.Any("error", writeErr))
+	}
+}
+
+// newErrorResponse エラーの種類からステータスコードとcodeを決定する
+// conditionalはIf-Matchヘッダーによる条件付きリクエストかを表し、バージョンの競合を412とする
+func newErrorResponse(err error, conditional bool) api.ErrorResponse {
+	var httpErr *echo.HTTPError
+	var conflictErr *domain.ConflictError
+	switch {
+	case errors.As(err, &httpErr):
+		message := http.StatusText(httpErr.Code)
+		if httpErr.Message != nil {
+			message = fmt.Sprint(httpErr.Message)
+		}
+		return problem(httpErr.Code, errorCodeFromStatus(httpErr.Code), message)
+	case errors.Is(err, &domain.ValidationError{}):
+		res := problem(http.StatusBadRequest, ErrorCodeValidation, err.Error())
+		fieldErrors := validationErrors(err)
+		res.Errors = &fieldErrors
+		return res
+	case errors.Is(err, &domain.NotFoundError{}):
+		return problem(http.StatusNotFound, ErrorCodeNotFound, err.Error())
+	case errors.Is(err, &domain.ForbiddenError{}):
+		return problem(http.StatusForbidden, ErrorCodeForbidden, err.Error())
+	case conditional && errors.As(err, &conflictErr) && conflictErr.IsStaleVersion():
+		return problem(http.StatusPreconditionFailed, ErrorCodePreconditionFailed, err.Error())
+	case errors.Is(err, &domain.ConflictError{}):
+		return problem(http.StatusConflict, ErrorCodeConflict, err.Error())
+	default:
+		return problem(http.StatusInternalServerError, ErrorCodeInternal, "サーバーエラーが発生しました")
+	}
+}
+
+// problem RFC 7807のエラーレスポンスを生成する
+func problem(status int, code string, detail string) api.ErrorResponse {
+	return api.ErrorResponse{
+		Type:    "about:blank",
+		Title:   http.StatusText(status),
+		Status:  int32(status),
+		Detail:  detail,
+		Code:    code,
+		Message: detail,
+	}
+}
+
+// errorCodeFromStatus ステータスコードに対応するcode
+func errorCodeFromStatus(status int) string {
+	switch status {
+	case http.StatusBadRequest:
+		return ErrorCodeBadRequest
+	case http.StatusUnauthorized:
+		return ErrorCodeUnauthorized
+	case http.StatusForbidden:
+		return ErrorCodeForbidden
+	case http.StatusNotFound, http.StatusMethodNotAllowed:
+		return ErrorCodeNotFound
+	case http.StatusConflict:
+		return ErrorCodeConflict
+	case http.StatusPreconditionFailed:
+		return ErrorCodePreconditionFailed
+	case http.StatusPreconditionRequired:
+		return ErrorCodePreconditionRequired
+	case http.StatusUnprocessableEntity:
+		return ErrorCodeUnprocessableEntity
+	case http.StatusTooManyRequests:
+		return ErrorCodeTooManyRequests
+	}
+	if status >= http.StatusInternalServerError {
+		return ErrorCodeInternal
+	}
+	return ErrorCodeBadRequest
+}
+
+// validationErrors エラーに含まれる全てのValidationErrorを項目ごとの詳細に変換する
+// errors.Joinでまとめられた複数のエラーにも対応する
+func validationErrors(err error) []api.ErrorResponseFieldError {
+	var fieldErrors []api.ErrorResponseFieldError
+
+	var walk func(error)
+	walk = func(err error) {
+		if err == nil {
+			return
+		}
+		if ve, ok := err.(*domain.ValidationError); ok {
+			fieldErrors = append(fieldErrors, api.ErrorResponseFieldError{
+				Field:   ve.Field(),
+				Message: ve.Message(),
+			})
+			return
+		}
+		switch e := err.(type) {
+		case interface{ Unwrap() []error }:
+			for _, inner := range e.Unwrap() {
+				walk(inner)
+			}
+		case interface{ Unwrap() error }:
+			walk(e.Unwrap())
+		}
+	}
+	walk(err)
+
+	return fieldErrors
+}
@@ -0,0 +1,41 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+// parseVersion 更新元のバージョンを取得する
+// If-Matchヘッダー (`"<version>"` 形式のETag、弱いETagも可) を優先し、
+// 指定がない場合はリクエストボディのバージョンを使用する
+// どちらも指定がない場合は更新の競合を検出できないため428を返す (If-Match: *の場合は確認しない)
+func parseVersion(ifMatch *string, version *int32) (*int32, error) {
+	if ifMatch == nil {
+		if version == nil {
+			return nil, echo.NewHTTPError(http.StatusPreconditionRequired, "If-Matchヘッダーまたはversionで更新元のバージョンを指定してください")
+		}
+		return version, nil
+	}
+
+	tag := strings.TrimPrefix(strings.TrimSpace(*ifMatch), "W/")
+	if tag == "*" {
+		return version, nil
+	}
+
+	v, err := strconv.ParseInt(strings.Trim(tag, `"`), 10, 32)
+	if err != nil {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, "If-Matchヘッダーの形式が正しくありません")
+	}
+	parsed := int32(v)
+
+	return &parsed, nil
+}
+
+// setETag バージョンをETagヘッダーに設定する
+func setETag(c echo.Context, version int32) {
+	c.Response().Header().Set("ETag", fmt.Sprintf(`"%d"`, version))
+}
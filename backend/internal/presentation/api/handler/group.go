@@ -2,14 +2,17 @@ package handler
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
 	"net/http"
+	"time"
 
 	"github.com/haebeal/datti/internal/domain"
 	"github.com/haebeal/datti/internal/presentation/api"
 	"github.com/labstack/echo/v4"
 	"github.com/oklog/ulid/v2"
-	"go.opentelemetry.io/otel/codes"
 )
 
 // GroupUseCase グループに関するユースケースのインターフェース
@@ -22,6 +25,7 @@ type GroupUseCase interface {
 	AddMember(context.Context, GroupAddMemberInput) error
 	RemoveMember(context.Context, GroupRemoveMemberInput) error
 	ListMembers(context.Context, GroupListMembersInput) (*GroupListMembersOutput, error)
+	Subscribe(context.Context, GroupSubscribeInput) (*GroupSubscribeOutput, error)
 }
 
 type groupHandler struct {
@@ -42,43 +46,36 @@ func (h groupHandler) Create(c echo.Context) error {
 
 	var req api.GroupCreateRequest
 	if err := c.Bind(&req); err != nil {
-		res := &api.ErrorResponse{
-			Message: "リクエストの形式が正しくありません",
-		}
-		return c.JSON(http.StatusBadRequest, res)
+		return echo.NewHTTPError(http.StatusBadRequest, "リクエストの形式が正しくありません")
 	}
 
 	createdBy, ok := c.Get("uid").(string)
 	if !ok {
-		res := &api.ErrorResponse{
-			Message: "認証情報が取得できませんでした",
-		}
-		return c.JSON(http.StatusUnauthorized, res)
+		return echo.NewHTTPError(http.StatusUnauthorized, "認証情報が取得できませんでした")
 	}
 
 	input := GroupCreateInput{
-		CreatedBy: createdBy,
-		Name:      req.Name,
+		CreatedBy:        createdBy,
+		Name:             req.Name,
+		PaymentTermsDays: req.PaymentTermsDays,
 	}
 
 	output, err := h.u.Create(ctx, input)
 	if err != nil {
-		span.SetStatus(codes.Error, err.Error())
-		span.RecordError(err)
-		res := &api.ErrorResponse{
-			Message: "サーバーエラーが発生しました",
-		}
-		return c.JSON(http.StatusInternalServerError, res)
+		return err
 	}
 
 	res := &api.GroupCreateResponse{
-		CreatedBy: output.Group.CreatedBy(),
-		Id:        output.Group.ID().String(),
-		Name:      output.Group.Name(),
-		CreatedAt: output.Group.CreatedAt(),
-		UpdatedAt: output.Group.UpdatedAt(),
+		CreatedBy:        output.Group.CreatedBy(),
+		Id:               output.Group.ID().String(),
+		Name:             output.Group.Name(),
+		PaymentTermsDays: output.Group.PaymentTermsDays(),
+		CreatedAt:        output.Group.CreatedAt(),
+		UpdatedAt:        output.Group.UpdatedAt(),
+		Version:          output.Group.Version(),
 	}
 
+	setETag(c, output.Group.Version())
 	return c.JSON(http.StatusCreated, res)
 }
 
@@ -89,10 +86,7 @@ func (h groupHandler) GetAll(c echo.Context) error {
 
 	userID, ok := c.Get("uid").(string)
 	if !ok {
-		res := &api.ErrorResponse{
-			Message: "認証情報が取得できませんでした",
-		}
-		return c.JSON(http.StatusUnauthorized, res)
+		return echo.NewHTTPError(http.StatusUnauthorized, "認証情報が取得できませんでした")
 	}
 
 	input := GroupGetAllInput{
@@ -101,22 +95,19 @@ func (h groupHandler) GetAll(c echo.Context) error {
 
 	output, err := h.u.GetAll(ctx, input)
 	if err != nil {
-		span.SetStatus(codes.Error, err.Error())
-		span.RecordError(err)
-		res := &api.ErrorResponse{
-			Message: "サーバーエラーが発生しました",
-		}
-		return c.JSON(http.StatusInternalServerError, res)
+		return err
 	}
 
 	res := []api.GroupGetAllResponse{}
 	for _, group := range output.Groups {
 		res = append(res, api.GroupGetAllResponse{
-			Id:        group.ID().String(),
-			Name:      group.Name(),
-			CreatedBy: group.CreatedBy(),
-			CreatedAt: group.CreatedAt(),
-			UpdatedAt: group.UpdatedAt(),
+			Id:               group.ID().String(),
+			Name:             group.Name(),
+			PaymentTermsDays: group.PaymentTermsDays(),
+			CreatedBy:        group.CreatedBy(),
+			CreatedAt:        group.CreatedAt(),
+			UpdatedAt:        group.UpdatedAt(),
+			Version:          group.Version(),
 		})
 	}
 
@@ -130,18 +121,12 @@ func (h groupHandler) Get(c echo.Context, id string) error {
 
 	groupID, err := ulid.Parse(id)
 	if err != nil {
-		res := &api.ErrorResponse{
-			Message: "IDの形式が正しくありません",
-		}
-		return c.JSON(http.StatusBadRequest, res)
+		return echo.NewHTTPError(http.StatusBadRequest, "IDの形式が正しくありません")
 	}
 
 	userID, ok := c.Get("uid").(string)
 	if !ok {
-		res := &api.ErrorResponse{
-			Message: "認証情報が取得できませんでした",
-		}
-		return c.JSON(http.StatusUnauthorized, res)
+		return echo.NewHTTPError(http.StatusUnauthorized, "認証情報が取得できませんでした")
 	}
 
 	input := GroupGetInput{
@@ -151,106 +136,75 @@ func (h groupHandler) Get(c echo.Context, id string) error {
 
 	output, err := h.u.Get(ctx, input)
 	if err != nil {
-		
-		if errors.Is(err, &domain.NotFoundError{}) {
-			res := &api.ErrorResponse{
-				Message: "グループが見つかりません",
-			}
-			return c.JSON(http.StatusNotFound, res)
-		}
-		
-		if errors.Is(err, &domain.ForbiddenError{}) {
-			res := &api.ErrorResponse{
-				Message: err.Error(),
-			}
-			return c.JSON(http.StatusForbidden, res)
-		}
-		span.SetStatus(codes.Error, err.Error())
-		span.RecordError(err)
-		res := &api.ErrorResponse{
-			Message: "サーバーエラーが発生しました",
-		}
-		return c.JSON(http.StatusInternalServerError, res)
+		return err
 	}
 
 	res := &api.GroupGetResponse{
-		Id:        output.Group.ID().String(),
-		Name:      output.Group.Name(),
-		CreatedBy: output.Group.CreatedBy(),
-		CreatedAt: output.Group.CreatedAt(),
-		UpdatedAt: output.Group.UpdatedAt(),
+		Id:               output.Group.ID().String(),
+		Name:             output.Group.Name(),
+		PaymentTermsDays: output.Group.PaymentTermsDays(),
+		CreatedBy:        output.Group.CreatedBy(),
+		CreatedAt:        output.Group.CreatedAt(),
+		UpdatedAt:        output.Group.UpdatedAt(),
+		Version:          output.Group.Version(),
 	}
 
+	setETag(c, output.Group.Version())
 	return c.JSON(http.StatusOK, res)
 }
 
 // Update グループ情報を更新する
-func (h groupHandler) Update(c echo.Context, id string) error {
+func (h groupHandler) Update(c echo.Context, id string, params api.GroupUpdateParams) error {
 	ctx, span := tracer.Start(c.Request().Context(), "group.Update")
 	defer span.End()
 
 	groupID, err := ulid.Parse(id)
 	if err != nil {
-		res := &api.ErrorResponse{
-			Message: "IDの形式が正しくありません",
-		}
-		return c.JSON(http.StatusBadRequest, res)
+		return echo.NewHTTPError(http.StatusBadRequest, "IDの形式が正しくありません")
 	}
 
 	var req api.GroupUpdateRequest
 	if err := c.Bind(&req); err != nil {
-		res := &api.ErrorResponse{
-			Message: "リクエストの形式が正しくありません",
-		}
-		return c.JSON(http.StatusBadRequest, res)
+		return echo.NewHTTPError(http.StatusBadRequest, "リクエストの形式が正しくありません")
 	}
 
 	userID, ok := c.Get("uid").(string)
 	if !ok {
-		res := &api.ErrorResponse{
-			Message: "認証情報が取得できませんでした",
-		}
-		return c.JSON(http.StatusUnauthorized, res)
+		return echo.NewHTTPError(http.StatusUnauthorized, "認証情報が取得できませんでした")
+	}
+
+	version, err := parseVersion(params.IfMatch, req.Version)
+	if err != nil {
+		return err
 	}
 
 	input := GroupUpdateInput{
-		UserID:  userID,
-		GroupID: groupID,
-		Name:    req.Name,
+		UserID:           userID,
+		GroupID:          groupID,
+		Name:             req.Name,
+		PaymentTermsDays: req.PaymentTermsDays,
+		Version:          version,
+	}
+	if req.ClearPaymentTermsDays != nil {
+		input.ClearPaymentTermsDays = *req.ClearPaymentTermsDays
 	}
 
 	output, err := h.u.Update(ctx, input)
 	if err != nil {
-		
-		if errors.Is(err, &domain.NotFoundError{}) {
-			res := &api.ErrorResponse{
-				Message: "グループが見つかりません",
-			}
-			return c.JSON(http.StatusNotFound, res)
-		}
-		
-		if errors.Is(err, &domain.ForbiddenError{}) {
-			res := &api.ErrorResponse{
-				Message: err.Error(),
-			}
-			return c.JSON(http.StatusForbidden, res)
-		}
-		span.SetStatus(codes.Error, err.Error())
-		span.RecordError(err)
-		res := &api.ErrorResponse{
-			Message: "サーバーエラーが発生しました",
-		}
-		return c.JSON(http.StatusInternalServerError, res)
+		return err
 	}
 
 	res := &api.GroupUpdateResponse{
-		Id:        output.Group.ID().String(),
-		Name:      output.Group.Name(),
-		CreatedBy: output.Group.CreatedBy(),
-		CreatedAt: output.Group.CreatedAt(),
-		UpdatedAt: output.Group.UpdatedAt(),
+		Id:               output.Group.ID().String(),
+		Name:             output.Group.Name(),
+		PaymentTermsDays: output.Group.PaymentTermsDays(),
+		CreatedBy:        output.Group.CreatedBy(),
+		CreatedAt:        output.Group.CreatedAt(),
+		UpdatedAt:        output.Group.UpdatedAt(),
+		Version:          output.Group.Version(),
 	}
 
+	setETag(c, output.Group.Version())
 	return c.JSON(http.StatusOK, res)
 }
 
@@ -261,18 +215,12 @@ func (h groupHandler) Delete(c echo.Context, id string) error {
 
 	groupID, err := ulid.Parse(id)
 	if err != nil {
-		res := &api.ErrorResponse{
-			Message: "IDの形式が正しくありません",
-		}
-		return c.JSON(http.StatusBadRequest, res)
+		return echo.NewHTTPError(http.StatusBadRequest, "IDの形式が正しくありません")
 	}
 
 	userID, ok := c.Get("uid").(string)
 	if !ok {
-		res := &api.ErrorResponse{
-			Message: "認証情報が取得できませんでした",
-		}
-		return c.JSON(http.StatusUnauthorized, res)
+		return echo.NewHTTPError(http.StatusUnauthorized, "認証情報が取得できませんでした")
 	}
 
 	input := GroupDeleteInput{
@@ -281,26 +229,7 @@ func (h groupHandler) Delete(c echo.Context, id string) error {
 	}
 
 	if err := h.u.Delete(ctx, input); err != nil {
-		
-		if errors.Is(err, &domain.NotFoundError{}) {
-			res := &api.ErrorResponse{
-				Message: "グループが見つかりません",
-			}
-			return c.JSON(http.StatusNotFound, res)
-		}
-		
-		if errors.Is(err, &domain.ForbiddenError{}) {
-			res := &api.ErrorResponse{
-				Message: err.Error(),
-			}
-			return c.JSON(http.StatusForbidden, res)
-		}
-		span.SetStatus(codes.Error, err.Error())
-		span.RecordError(err)
-		res := &api.ErrorResponse{
-			Message: "サーバーエラーが発生しました",
-		}
-		return c.JSON(http.StatusInternalServerError, res)
+		return err
 	}
 
 	return c.NoContent(http.StatusNoContent)
@@ -313,26 +242,17 @@ func (h groupHandler) AddMember(c echo.Context, id string) error {
 
 	groupID, err := ulid.Parse(id)
 	if err != nil {
-		res := &api.ErrorResponse{
-			Message: "IDの形式が正しくありません",
-		}
-		return c.JSON(http.StatusBadRequest, res)
+		return echo.NewHTTPError(http.StatusBadRequest, "IDの形式が正しくありません")
 	}
 
 	var req api.GroupAddMemberRequest
 	if err := c.Bind(&req); err != nil {
-		res := &api.ErrorResponse{
-			Message: "リクエストの形式が正しくありません",
-		}
-		return c.JSON(http.StatusBadRequest, res)
+		return echo.NewHTTPError(http.StatusBadRequest, "リクエストの形式が正しくありません")
 	}
 
 	userID, ok := c.Get("uid").(string)
 	if !ok {
-		res := &api.ErrorResponse{
-			Message: "認証情報が取得できませんでした",
-		}
-		return c.JSON(http.StatusUnauthorized, res)
+		return echo.NewHTTPError(http.StatusUnauthorized, "認証情報が取得できませんでした")
 	}
 
 	input := GroupAddMemberInput{
@@ -342,33 +262,7 @@ func (h groupHandler) AddMember(c echo.Context, id string) error {
 	}
 
 	if err := h.u.AddMember(ctx, input); err != nil {
-		
-		if errors.Is(err, &domain.NotFoundError{}) {
-			res := &api.ErrorResponse{
-				Message: "メンバーが見つかりません",
-			}
-			return c.JSON(http.StatusNotFound, res)
-		}
-		
-		if errors.Is(err, &domain.ConflictError{}) {
-			res := &api.ErrorResponse{
-				Message: err.Error(),
-			}
-			return c.JSON(http.StatusConflict, res)
-		}
-		
-		if errors.Is(err, &domain.ForbiddenError{}) {
-			res := &api.ErrorResponse{
-				Message: err.Error(),
-			}
-			return c.JSON(http.StatusForbidden, res)
-		}
-		span.SetStatus(codes.Error, err.Error())
-		span.RecordError(err)
-		res := &api.ErrorResponse{
-			Message: "サーバーエラーが発生しました",
-		}
-		return c.JSON(http.StatusInternalServerError, res)
+		return err
 	}
 
 	return c.NoContent(http.StatusNoContent)
@@ -381,18 +275,12 @@ func (h groupHandler) GetMembers(c echo.Context, id string) error {
 
 	groupID, err := ulid.Parse(id)
 	if err != nil {
-		res := &api.ErrorResponse{
-			Message: "IDの形式が正しくありません",
-		}
-		return c.JSON(http.StatusBadRequest, res)
+		return echo.NewHTTPError(http.StatusBadRequest, "IDの形式が正しくありません")
 	}
 
 	userID, ok := c.Get("uid").(string)
 	if !ok {
-		res := &api.ErrorResponse{
-			Message: "認証情報が取得できませんでした",
-		}
-		return c.JSON(http.StatusUnauthorized, res)
+		return echo.NewHTTPError(http.StatusUnauthorized, "認証情報が取得できませんでした")
 	}
 
 	input := GroupListMembersInput{
@@ -402,26 +290,7 @@ func (h groupHandler) GetMembers(c echo.Context, id string) error {
 
 	output, err := h.u.ListMembers(ctx, input)
 	if err != nil {
-		
-		if errors.Is(err, &domain.NotFoundError{}) {
-			res := &api.ErrorResponse{
-				Message: "グループが見つかりません",
-			}
-			return c.JSON(http.StatusNotFound, res)
-		}
-		
-		if errors.Is(err, &domain.ForbiddenError{}) {
-			res := &api.ErrorResponse{
-				Message: err.Error(),
-			}
-			return c.JSON(http.StatusForbidden, res)
-		}
-		span.SetStatus(codes.Error, err.Error())
-		span.RecordError(err)
-		res := &api.ErrorResponse{
-			Message: "サーバーエラーが発生しました",
-		}
-		return c.JSON(http.StatusInternalServerError, res)
+		return err
 	}
 
 	res := make([]api.GroupMemberResponse, 0, len(output.Members))
@@ -431,6 +300,7 @@ func (h groupHandler) GetMembers(c echo.Context, id string) error {
 			Name:   member.Name(),
 			Avatar: member.Avatar(),
 			Email:  member.Email(),
+			Guest:  member.IsGuest(),
 		})
 	}
 
@@ -444,18 +314,12 @@ func (h groupHandler) RemoveMember(c echo.Context, id string, userId string) err
 
 	groupID, err := ulid.Parse(id)
 	if err != nil {
-		res := &api.ErrorResponse{
-			Message: "IDの形式が正しくありません",
-		}
-		return c.JSON(http.StatusBadRequest, res)
+		return echo.NewHTTPError(http.StatusBadRequest, "IDの形式が正しくありません")
 	}
 
 	userID, ok := c.Get("uid").(string)
 	if !ok {
-		res := &api.ErrorResponse{
-			Message: "認証情報が取得できませんでした",
-		}
-		return c.JSON(http.StatusUnauthorized, res)
+		return echo.NewHTTPError(http.StatusUnauthorized, "認証情報が取得できませんでした")
 	}
 
 	input := GroupRemoveMemberInput{
@@ -465,26 +329,7 @@ func (h groupHandler) RemoveMember(c echo.Context, id string, userId string) err
 	}
 
 	if err := h.u.RemoveMember(ctx, input); err != nil {
-		
-		if errors.Is(err, &domain.NotFoundError{}) {
-			res := &api.ErrorResponse{
-				Message: "メンバーが見つかりません",
-			}
-			return c.JSON(http.StatusNotFound, res)
-		}
-		
-		if errors.Is(err, &domain.ForbiddenError{}) {
-			res := &api.ErrorResponse{
-				Message: err.Error(),
-			}
-			return c.JSON(http.StatusForbidden, res)
-		}
-		span.SetStatus(codes.Error, err.Error())
-		span.RecordError(err)
-		res := &api.ErrorResponse{
-			Message: "サーバーエラーが発生しました",
-		}
-		return c.JSON(http.StatusInternalServerError, res)
+		return err
 	}
 
 	return c.NoContent(http.StatusNoContent)
@@ -492,8 +337,9 @@ func (h groupHandler) RemoveMember(c echo.Context, id string, userId string) err
 
 // GroupCreateInput グループ作成の入力パラメータ
 type GroupCreateInput struct {
-	CreatedBy string
-	Name      string
+	CreatedBy        string
+	Name             string
+	PaymentTermsDays *int32
 }
 
 // GroupCreateOutput グループ作成の出力
@@ -524,9 +370,13 @@ type GroupGetOutput struct {
 
 // GroupUpdateInput グループ更新の入力パラメータ
 type GroupUpdateInput struct {
-	UserID  string
-	GroupID ulid.ULID
-	Name    string
+	UserID           string
+	GroupID          ulid.ULID
+	Name             string
+	PaymentTermsDays *int32
+	// ClearPaymentTermsDays 既定の支払い期限をなくす
+	ClearPaymentTermsDays bool
+	Version               *int32
 }
 
 // GroupUpdateOutput グループ更新の出力
@@ -547,6 +397,97 @@ type GroupAddMemberInput struct {
 	MemberID string
 }
 
+// Stream グループの変更をServer-Sent Eventsで配信する (メンバーのみ購読可能)
+func (h groupHandler) Stream(c echo.Context, id string) error {
+	ctx, span := tracer.Start(c.Request().Context(), "group.Stream")
+	defer span.End()
+
+	groupID, err := ulid.Parse(id)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "IDの形式が正しくありません")
+	}
+
+	userID, ok := c.Get("uid").(string)
+	if !ok {
+		return echo.NewHTTPError(http.StatusUnauthorized, "認証情報が取得できませんでした")
+	}
+
+	input := GroupSubscribeInput{
+		UserID:  userID,
+		GroupID: groupID,
+	}
+
+	output, err := h.u.Subscribe(ctx, input)
+	if err != nil {
+		return err
+	}
+	defer output.Cancel()
+
+	// 配信中はサーバー全体の書き込みタイムアウトを適用しない
+	res := c.Response()
+	if err := http.NewResponseController(res.Writer).SetWriteDeadline(time.Time{}); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		return err
+	}
+
+	res.Header().Set(echo.HeaderContentType, "text/event-stream")
+	res.Header().Set(echo.HeaderCacheControl, "no-cache")
+	// リバースプロキシでバッファリングさせない
+	res.Header().Set("X-Accel-Buffering", "no")
+	res.WriteHeader(http.StatusOK)
+	res.Flush()
+
+	heartbeat := time.NewTicker(streamHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	// 書き込みに失敗した場合はクライアントが切断しているため、エラーを返さずに終了する
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-heartbeat.C:
+			// ロードバランサーのアイドルタイムアウトで切断されないようにコメント行を送る
+			if _, err := fmt.Fprint(res, ": ping\n\n"); err != nil {
+				return nil
+			}
+			res.Flush()
+		case update, ok := <-output.Updates:
+			// ブローカーの停止または配信の遅延により購読が終了した
+			if !ok {
+				return nil
+			}
+			if err := writeGroupUpdateEvent(res, update); err != nil {
+				return nil
+			}
+			res.Flush()
+
+			// グループから削除された場合は以降の変更を配信しない
+			if update.Type() == domain.GroupUpdateMemberRemoved && update.ResourceID() == userID {
+				return nil
+			}
+		}
+	}
+}
+
+// streamHeartbeatInterval 変更がない場合に接続を維持するためのコメント行を送る間隔
+const streamHeartbeatInterval = 15 * time.Second
+
+// writeGroupUpdateEvent 変更をSSEのイベントとして書き込む
+func writeGroupUpdateEvent(w io.Writer, update *domain.GroupUpdate) error {
+	data, err := json.Marshal(api.GroupUpdateEvent{
+		Id:         update.ID().String(),
+		Type:       api.GroupUpdateEventType(update.Type()),
+		GroupId:    update.GroupID().String(),
+		ResourceId: update.ResourceID(),
+		OccurredAt: update.OccurredAt(),
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(w, "id: %s\nevent: %s\ndata: %s\n\n", update.ID(), update.Type(), data)
+	return err
+}
+
 // GroupListMembersInput メンバー一覧取得の入力パラメータ
 type GroupListMembersInput struct {
 	UserID  string
@@ -564,3 +505,17 @@ type GroupRemoveMemberInput struct {
 	GroupID  ulid.ULID
 	MemberID string
 }
+
+// GroupSubscribeInput グループの変更の購読の入力パラメータ
+type GroupSubscribeInput struct {
+	UserID  string
+	GroupID ulid.ULID
+}
+
+// GroupSubscribeOutput グループの変更の購読の出力
+type GroupSubscribeOutput struct {
+	// Updates 変更を受け取るチャネル (購読が終了すると閉じられる)
+	Updates <-chan *domain.GroupUpdate
+	// Cancel 購読を終了する
+	Cancel func()
+}
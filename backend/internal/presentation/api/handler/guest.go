@@ -0,0 +1,185 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/haebeal/datti/internal/domain"
+	"github.com/haebeal/datti/internal/presentation/api"
+	"github.com/labstack/echo/v4"
+	"github.com/oklog/ulid/v2"
+)
+
+// GuestUseCase ゲストメンバーに関するユースケースのインターフェース
+type GuestUseCase interface {
+	Create(context.Context, GuestCreateInput) (*GuestCreateOutput, error)
+	List(context.Context, GuestListInput) (*GuestListOutput, error)
+	Claim(context.Context, GuestClaimInput) (*GuestClaimOutput, error)
+}
+
+type guestHandler struct {
+	u GuestUseCase
+}
+
+// NewGuestHandler guestHandlerのファクトリ関数
+func NewGuestHandler(u GuestUseCase) guestHandler {
+	return guestHandler{
+		u: u,
+	}
+}
+
+// Create グループにゲストを追加する
+func (h guestHandler) Create(c echo.Context, id string) error {
+	ctx, span := tracer.Start(c.Request().Context(), "guest.Create")
+	defer span.End()
+
+	groupID, err := ulid.Parse(id)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "IDの形式が正しくありません")
+	}
+
+	userID, ok := c.Get("uid").(string)
+	if !ok {
+		return echo.NewHTTPError(http.StatusUnauthorized, "認証情報が取得できませんでした")
+	}
+
+	var req api.GuestCreateRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "リクエストの形式が正しくありません")
+	}
+
+	input := GuestCreateInput{
+		UserID:  userID,
+		GroupID: groupID,
+		Name:    req.Name,
+	}
+	if req.Email != nil {
+		input.Email = *req.Email
+	}
+
+	output, err := h.u.Create(ctx, input)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusCreated, newGuestResponse(output.Guest))
+}
+
+// List グループのゲスト一覧を取得する
+func (h guestHandler) List(c echo.Context, id string) error {
+	ctx, span := tracer.Start(c.Request().Context(), "guest.List")
+	defer span.End()
+
+	groupID, err := ulid.Parse(id)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "IDの形式が正しくありません")
+	}
+
+	userID, ok := c.Get("uid").(string)
+	if !ok {
+		return echo.NewHTTPError(http.StatusUnauthorized, "認証情報が取得できませんでした")
+	}
+
+	input := GuestListInput{
+		UserID:  userID,
+		GroupID: groupID,
+	}
+
+	output, err := h.u.List(ctx, input)
+	if err != nil {
+		return err
+	}
+
+	res := make([]api.GuestResponse, 0, len(output.Guests))
+	for _, guest := range output.Guests {
+		res = append(res, newGuestResponse(guest))
+	}
+
+	return c.JSON(http.StatusOK, res)
+}
+
+// Claim 招待トークンで指定したゲストを認証ユーザーに引き継ぐ
+func (h guestHandler) Claim(c echo.Context) error {
+	ctx, span := tracer.Start(c.Request().Context(), "guest.Claim")
+	defer span.End()
+
+	userID, ok := c.Get("uid").(string)
+	if !ok {
+		return echo.NewHTTPError(http.StatusUnauthorized, "認証情報が取得できませんでした")
+	}
+
+	var req api.GuestClaimRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "リクエストの形式が正しくありません")
+	}
+
+	input := GuestClaimInput{
+		UserID:      userID,
+		InviteToken: req.InviteToken,
+	}
+
+	output, err := h.u.Claim(ctx, input)
+	if err != nil {
+		return err
+	}
+
+	res := &api.GroupGetResponse{
+		Id:               output.Group.ID().String(),
+		Name:             output.Group.Name(),
+		PaymentTermsDays: output.Group.PaymentTermsDays(),
+		CreatedBy:        output.Group.CreatedBy(),
+		CreatedAt:        output.Group.CreatedAt(),
+		UpdatedAt:        output.Group.UpdatedAt(),
+		Version:          output.Group.Version(),
+	}
+
+	return c.JSON(http.StatusOK, res)
+}
+
+func newGuestResponse(g *domain.Guest) api.GuestResponse {
+	res := api.GuestResponse{
+		Id:          g.ID(),
+		GroupId:     g.GroupID().String(),
+		Name:        g.Name(),
+		InviteToken: g.InviteToken(),
+	}
+	if email := g.Email(); email != "" {
+		res.Email = &email
+	}
+	return res
+}
+
+// GuestCreateInput ゲスト作成の入力パラメータ
+type GuestCreateInput struct {
+	UserID  string
+	GroupID ulid.ULID
+	Name    string
+	Email   string
+}
+
+// GuestCreateOutput ゲスト作成の出力
+type GuestCreateOutput struct {
+	Guest *domain.Guest
+}
+
+// GuestListInput ゲスト一覧取得の入力パラメータ
+type GuestListInput struct {
+	UserID  string
+	GroupID ulid.ULID
+}
+
+// GuestListOutput ゲスト一覧取得の出力
+type GuestListOutput struct {
+	Guests []*domain.Guest
+}
+
+// GuestClaimInput ゲスト引き継ぎの入力パラメータ
+type GuestClaimInput struct {
+	UserID      string
+	InviteToken string
+}
+
+// GuestClaimOutput ゲスト引き継ぎの出力
+type GuestClaimOutput struct {
+	Group *domain.Group
+}
@@ -1,18 +1,35 @@
 package handler
 
 import (
+	"context"
 	"net/http"
+	"sort"
+	"sync/atomic"
 	"time"
 
 	"github.com/haebeal/datti/internal/presentation/api"
-	"github.com/haebeal/datti/internal/presentation/api/server"
 	"github.com/labstack/echo/v4"
 )
 
-type HealthHandler struct{}
+// readyCheckTimeout 依存サービスごとの疎通確認のタイムアウト
+const readyCheckTimeout = 3 * time.Second
 
-func NewHealthHandler() server.HealthHandler {
-	return &HealthHandler{}
+// ReadinessChecker 依存サービスへの疎通確認を行うインターフェース
+type ReadinessChecker interface {
+	Ping(ctx context.Context) error
+}
+
+type HealthHandler struct {
+	checkers map[string]ReadinessChecker
+	draining atomic.Bool
+}
+
+// NewHealthHandler HealthHandlerのファクトリ関数
+// checkersのキーはレスポンスに含める確認対象の名前
+func NewHealthHandler(checkers map[string]ReadinessChecker) *HealthHandler {
+	return &HealthHandler{
+		checkers: checkers,
+	}
 }
 
 func (h *HealthHandler) Check(c echo.Context) error {
@@ -20,9 +37,61 @@ func (h *HealthHandler) Check(c echo.Context) error {
 	defer span.End()
 
 	res := &api.HealthCheckResponse{
+		Status:    api.HealthCheckResponseStatusOk,
+		Timestamp: time.Now(),
+	}
+
+	return c.JSON(http.StatusOK, res)
+}
+
+// Ready 依存サービスへの疎通を確認し、リクエストを受け付けられる状態かを返す
+func (h *HealthHandler) Ready(c echo.Context) error {
+	ctx, span := tracer.Start(c.Request().Context(), "health.Ready")
+	defer span.End()
+
+	names := make([]string, 0, len(h.checkers))
+	for name := range h.checkers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	res := &api.HealthReadyResponse{
 		Status:    api.Ok,
 		Timestamp: time.Now(),
+		Checks:    make([]api.HealthReadyCheck, 0, len(names)),
+	}
+
+	for _, name := range names {
+		check := api.HealthReadyCheck{
+			Name:   name,
+			Status: api.HealthReadyCheckStatusOk,
+		}
+
+		checkCtx, cancel := context.WithTimeout(ctx, readyCheckTimeout)
+		err := h.checkers[name].Ping(checkCtx)
+		cancel()
+		if err != nil {
+			message := err.Error()
+			check.Status = api.HealthReadyCheckStatusError
+			check.Message = &message
+			res.Status = api.Unavailable
+		}
+
+		res.Checks = append(res.Checks, check)
+	}
+
+	// 終了処理中はロードバランサーの振り分け対象から外す
+	if h.draining.Load() {
+		res.Status = api.Unavailable
 	}
 
+	if res.Status != api.Ok {
+		return c.JSON(http.StatusServiceUnavailable, res)
+	}
 	return c.JSON(http.StatusOK, res)
 }
+
+// Drain 終了処理の開始を通知する (以降の/readyは503を返す)
+func (h *HealthHandler) Drain() {
+	h.draining.Store(true)
+}
@@ -2,15 +2,15 @@ package handler
 
 import (
 	"context"
-	"errors"
+	"maps"
 	"net/http"
+	"slices"
 	"time"
 
 	"github.com/haebeal/datti/internal/domain"
 	"github.com/haebeal/datti/internal/presentation/api"
 	"github.com/labstack/echo/v4"
 	"github.com/oklog/ulid/v2"
-	"go.opentelemetry.io/otel/codes"
 )
 
 // LendingUseCase 立て替えに関するユースケースのインターフェース
@@ -20,6 +20,8 @@ type LendingUseCase interface {
 	GetByQuery(context.Context, GetAllInput) (*GetAllOutput, error)
 	Update(context.Context, UpdateInput) (*UpdateOutput, error)
 	Delete(context.Context, DeleteInput) error
+	Search(context.Context, SearchInput) (*SearchOutput, error)
+	List(context.Context, ListInput) (*ListOutput, error)
 }
 
 type lendingHandler struct {
@@ -33,27 +35,34 @@ func NewLendingHandler(u LendingUseCase) lendingHandler {
 	}
 }
 
-// Create 立て替えを新規作成する
+// Create グループ内の立て替えを新規作成する
 func (h lendingHandler) Create(c echo.Context, id string) error {
 	ctx, span := tracer.Start(c.Request().Context(), "lending.Create")
 	defer span.End()
 
 	groupID, err := ulid.Parse(id)
 	if err != nil {
-		res := &api.ErrorResponse{
-			Message: "IDの形式が正しくありません",
-		}
-		return c.JSON(http.StatusBadRequest, res)
+		return echo.NewHTTPError(http.StatusBadRequest, "IDの形式が正しくありません")
 	}
 
+	return h.create(ctx, c, &groupID)
+}
+
+// CreatePersonal 個人間の立て替えを新規作成する
+func (h lendingHandler) CreatePersonal(c echo.Context) error {
+	ctx, span := tracer.Start(c.Request().Context(), "lending.CreatePersonal")
+	defer span.End()
+
+	return h.create(ctx, c, nil)
+}
+
+// create 立て替えを作成する (groupIDがnilの場合は個人間の立て替え)
+func (h lendingHandler) create(ctx context.Context, c echo.Context, groupID *ulid.ULID) error {
 	var req api.LendingCreateRequest
 
-	err = c.Bind(&req)
+	err := c.Bind(&req)
 	if err != nil {
-		res := &api.ErrorResponse{
-			Message: "リクエストの形式が正しくありません",
-		}
-		return c.JSON(http.StatusBadRequest, res)
+		return echo.NewHTTPError(http.StatusBadRequest, "リクエストの形式が正しくありません")
 	}
 
 	var debtParams []DebtParam
@@ -66,50 +75,41 @@ func (h lendingHandler) Create(c echo.Context, id string) error {
 
 	userID, ok := c.Get("uid").(string)
 	if !ok {
-		res := &api.ErrorResponse{
-			Message: "認証情報が取得できませんでした",
+		return echo.NewHTTPError(http.StatusUnauthorized, "認証情報が取得できませんでした")
+	}
+
+	var payerShare *int64
+	if req.PayerShare != nil {
+		share := int64(*req.PayerShare)
+		payerShare = &share
+	}
+
+	var payerParams []PayerParam
+	if req.Payers != nil {
+		for _, p := range *req.Payers {
+			payerParams = append(payerParams, PayerParam{
+				UserID: p.UserId,
+				Amount: int64(p.Amount),
+				Share:  int64(p.Share),
+			})
 		}
-		return c.JSON(http.StatusUnauthorized, res)
 	}
 
 	input := CreateInput{
-		GroupID:   groupID,
-		UserID:    userID,
-		Name:      req.Name,
-		Amount:    int64(req.Amount),
-		Debts:     debtParams,
-		EventDate: req.EventDate,
+		GroupID:    groupID,
+		UserID:     userID,
+		Name:       req.Name,
+		Amount:     int64(req.Amount),
+		PayerShare: payerShare,
+		Payers:     payerParams,
+		Debts:      debtParams,
+		EventDate:  req.EventDate,
+		DueDate:    req.DueDate,
 	}
 
 	output, err := h.u.Create(ctx, input)
 	if err != nil {
-		
-		if errors.Is(err, &domain.NotFoundError{}) {
-			res := &api.ErrorResponse{
-				Message: "立て替えが見つかりません",
-			}
-			return c.JSON(http.StatusNotFound, res)
-		}
-		
-		if errors.Is(err, &domain.ForbiddenError{}) {
-			res := &api.ErrorResponse{
-				Message: err.Error(),
-			}
-			return c.JSON(http.StatusForbidden, res)
-		}
-		
-		if errors.Is(err, &domain.ValidationError{}) {
-			res := &api.ErrorResponse{
-				Message: err.Error(),
-			}
-			return c.JSON(http.StatusBadRequest, res)
-		}
-		span.SetStatus(codes.Error, err.Error())
-		span.RecordError(err)
-		res := &api.ErrorResponse{
-			Message: "サーバーエラーが発生しました",
-		}
-		return c.JSON(http.StatusInternalServerError, res)
+		return err
 	}
 
 	var debts []api.LendingDebtParmam
@@ -121,45 +121,54 @@ func (h lendingHandler) Create(c echo.Context, id string) error {
 	}
 
 	res := &api.LendingCreateResponse{
-		Id:        output.Event.ID().String(),
-		Name:      output.Event.Name(),
-		Amount:    uint64(output.Event.Amount()),
-		EventDate: output.Event.EventDate(),
-		Debts:     debts,
-		CreatedAt: output.Event.CreatedAt(),
-		UpdatedAt: output.Event.UpdatedAt(),
-	}
-
+		Id:         output.Event.ID().String(),
+		Name:       output.Event.Name(),
+		Amount:     uint64(output.Event.Amount()),
+		PayerShare: uint64(output.Event.PayerShare()),
+		Payers:     toPayerParams(output.Event),
+		EventDate:  output.Event.EventDate(),
+		DueDate:    output.Event.DueDate(),
+		Debts:      debts,
+		CreatedAt:  output.Event.CreatedAt(),
+		UpdatedAt:  output.Event.UpdatedAt(),
+		Version:    output.Event.Version(),
+	}
+
+	setETag(c, output.Event.Version())
 	return c.JSON(http.StatusCreated, res)
 }
 
-// Get 指定したIDの立て替え情報を取得する
+// Get グループ内の指定したIDの立て替え情報を取得する
 func (h lendingHandler) Get(c echo.Context, id string, lendingId string) error {
 	ctx, span := tracer.Start(c.Request().Context(), "lending.Get")
 	defer span.End()
 
 	groupID, err := ulid.Parse(id)
 	if err != nil {
-		res := &api.ErrorResponse{
-			Message: "IDの形式が正しくありません",
-		}
-		return c.JSON(http.StatusBadRequest, res)
+		return echo.NewHTTPError(http.StatusBadRequest, "IDの形式が正しくありません")
 	}
 
+	return h.get(ctx, c, &groupID, lendingId)
+}
+
+// GetPersonal 指定したIDの個人間の立て替え情報を取得する
+func (h lendingHandler) GetPersonal(c echo.Context, lendingId string) error {
+	ctx, span := tracer.Start(c.Request().Context(), "lending.GetPersonal")
+	defer span.End()
+
+	return h.get(ctx, c, nil, lendingId)
+}
+
+// get 立て替えを取得する (groupIDがnilの場合は個人間の立て替え)
+func (h lendingHandler) get(ctx context.Context, c echo.Context, groupID *ulid.ULID, lendingId string) error {
 	eventID, err := ulid.Parse(lendingId)
 	if err != nil {
-		res := &api.ErrorResponse{
-			Message: "IDの形式が正しくありません",
-		}
-		return c.JSON(http.StatusBadRequest, res)
+		return echo.NewHTTPError(http.StatusBadRequest, "IDの形式が正しくありません")
 	}
 
 	userID, ok := c.Get("uid").(string)
 	if !ok {
-		res := &api.ErrorResponse{
-			Message: "認証情報が取得できませんでした",
-		}
-		return c.JSON(http.StatusUnauthorized, res)
+		return echo.NewHTTPError(http.StatusUnauthorized, "認証情報が取得できませんでした")
 	}
 
 	input := GetInput{
@@ -170,26 +179,7 @@ func (h lendingHandler) Get(c echo.Context, id string, lendingId string) error {
 
 	output, err := h.u.Get(ctx, input)
 	if err != nil {
-		
-		if errors.Is(err, &domain.NotFoundError{}) {
-			res := &api.ErrorResponse{
-				Message: "立て替えが見つかりません",
-			}
-			return c.JSON(http.StatusNotFound, res)
-		}
-		
-		if errors.Is(err, &domain.ForbiddenError{}) {
-			res := &api.ErrorResponse{
-				Message: err.Error(),
-			}
-			return c.JSON(http.StatusForbidden, res)
-		}
-		span.SetStatus(codes.Error, err.Error())
-		span.RecordError(err)
-		res := &api.ErrorResponse{
-			Message: "サーバーエラーが発生しました",
-		}
-		return c.JSON(http.StatusInternalServerError, res)
+		return err
 	}
 
 	var debts []api.LendingDebtParmam
@@ -201,16 +191,21 @@ func (h lendingHandler) Get(c echo.Context, id string, lendingId string) error {
 	}
 
 	res := &api.LendingGetResponse{
-		Id:        output.Lending.ID().String(),
-		Name:      output.Lending.Name(),
-		Amount:    uint64(output.Lending.Amount()),
-		EventDate: output.Lending.EventDate(),
-		Debts:     debts,
-		CreatedBy: output.Lending.Payer().ID(),
-		CreatedAt: output.Lending.CreatedAt(),
-		UpdatedAt: output.Lending.UpdatedAt(),
-	}
-
+		Id:         output.Lending.ID().String(),
+		Name:       output.Lending.Name(),
+		Amount:     uint64(output.Lending.Amount()),
+		PayerShare: uint64(output.Lending.PayerShare()),
+		Payers:     toPayerParams(output.Lending),
+		EventDate:  output.Lending.EventDate(),
+		DueDate:    output.Lending.DueDate(),
+		Debts:      debts,
+		CreatedBy:  output.Lending.Payer().ID(),
+		CreatedAt:  output.Lending.CreatedAt(),
+		UpdatedAt:  output.Lending.UpdatedAt(),
+		Version:    output.Lending.Version(),
+	}
+
+	setETag(c, output.Lending.Version())
 	return c.JSON(http.StatusOK, res)
 }
 
@@ -221,18 +216,12 @@ func (h lendingHandler) GetByQuery(c echo.Context, id string, params api.Lending
 
 	groupID, err := ulid.Parse(id)
 	if err != nil {
-		res := &api.ErrorResponse{
-			Message: "IDの形式が正しくありません",
-		}
-		return c.JSON(http.StatusBadRequest, res)
+		return echo.NewHTTPError(http.StatusBadRequest, "IDの形式が正しくありません")
 	}
 
 	userID, ok := c.Get("uid").(string)
 	if !ok {
-		res := &api.ErrorResponse{
-			Message: "認証情報が取得できませんでした",
-		}
-		return c.JSON(http.StatusUnauthorized, res)
+		return echo.NewHTTPError(http.StatusUnauthorized, "認証情報が取得できませんでした")
 	}
 
 	// Set default limit
@@ -250,26 +239,7 @@ func (h lendingHandler) GetByQuery(c echo.Context, id string, params api.Lending
 
 	output, err := h.u.GetByQuery(ctx, input)
 	if err != nil {
-		
-		if errors.Is(err, &domain.NotFoundError{}) {
-			res := &api.ErrorResponse{
-				Message: "グループが見つかりません",
-			}
-			return c.JSON(http.StatusNotFound, res)
-		}
-		
-		if errors.Is(err, &domain.ForbiddenError{}) {
-			res := &api.ErrorResponse{
-				Message: err.Error(),
-			}
-			return c.JSON(http.StatusForbidden, res)
-		}
-		span.SetStatus(codes.Error, err.Error())
-		span.RecordError(err)
-		res := &api.ErrorResponse{
-			Message: "サーバーエラーが発生しました",
-		}
-		return c.JSON(http.StatusInternalServerError, res)
+		return err
 	}
 
 	responseItems := make([]api.LendingGetAllResponse, 0)
@@ -283,14 +253,19 @@ func (h lendingHandler) GetByQuery(c echo.Context, id string, params api.Lending
 		}
 
 		responseItems = append(responseItems, api.LendingGetAllResponse{
-			Id:        l.Lending.ID().String(),
-			CreatedAt: l.Lending.CreatedAt(),
-			Debts:     debts,
-			Amount:    uint64(l.Lending.Amount()),
-			Name:      l.Lending.Name(),
-			EventDate: l.Lending.EventDate(),
-			CreatedBy: l.Lending.Payer().ID(),
-			UpdatedAt: l.Lending.UpdatedAt(),
+			Id:           l.Lending.ID().String(),
+			CreatedAt:    l.Lending.CreatedAt(),
+			Debts:        debts,
+			Amount:       uint64(l.Lending.Amount()),
+			PayerShare:   uint64(l.Lending.PayerShare()),
+			Payers:       toPayerParams(l.Lending),
+			Name:         l.Lending.Name(),
+			EventDate:    l.Lending.EventDate(),
+			DueDate:      l.Lending.DueDate(),
+			CreatedBy:    l.Lending.Payer().ID(),
+			UpdatedAt:    l.Lending.UpdatedAt(),
+			Version:      l.Lending.Version(),
+			CommentCount: output.CommentCounts[l.Lending.ID()],
 		})
 	}
 
@@ -303,34 +278,38 @@ func (h lendingHandler) GetByQuery(c echo.Context, id string, params api.Lending
 	return c.JSON(http.StatusOK, res)
 }
 
-// Update 立て替え情報を更新する
-func (h lendingHandler) Update(c echo.Context, id string, lendingId string) error {
+// Update グループ内の立て替え情報を更新する
+func (h lendingHandler) Update(c echo.Context, id string, lendingId string, params api.LendingUpdateParams) error {
 	ctx, span := tracer.Start(c.Request().Context(), "lending.Update")
 	defer span.End()
 
 	groupID, err := ulid.Parse(id)
 	if err != nil {
-		res := &api.ErrorResponse{
-			Message: "IDの形式が正しくありません",
-		}
-		return c.JSON(http.StatusBadRequest, res)
+		return echo.NewHTTPError(http.StatusBadRequest, "IDの形式が正しくありません")
 	}
 
+	return h.update(ctx, c, &groupID, lendingId, params.IfMatch)
+}
+
+// UpdatePersonal 個人間の立て替え情報を更新する
+func (h lendingHandler) UpdatePersonal(c echo.Context, lendingId string, params api.LendingUpdatePersonalParams) error {
+	ctx, span := tracer.Start(c.Request().Context(), "lending.UpdatePersonal")
+	defer span.End()
+
+	return h.update(ctx, c, nil, lendingId, params.IfMatch)
+}
+
+// update 立て替えを更新する (groupIDがnilの場合は個人間の立て替え)
+func (h lendingHandler) update(ctx context.Context, c echo.Context, groupID *ulid.ULID, lendingId string, ifMatch *string) error {
 	var req api.LendingUpdateRequest
 
 	if err := c.Bind(&req); err != nil {
-		res := &api.ErrorResponse{
-			Message: "リクエストの形式が正しくありません",
-		}
-		return c.JSON(http.StatusBadRequest, res)
+		return echo.NewHTTPError(http.StatusBadRequest, "リクエストの形式が正しくありません")
 	}
 
 	eventID, err := ulid.Parse(lendingId)
 	if err != nil {
-		res := &api.ErrorResponse{
-			Message: "IDの形式が正しくありません",
-		}
-		return c.JSON(http.StatusBadRequest, res)
+		return echo.NewHTTPError(http.StatusBadRequest, "IDの形式が正しくありません")
 	}
 
 	var debtParams []DebtParam
@@ -343,51 +322,51 @@ func (h lendingHandler) Update(c echo.Context, id string, lendingId string) erro
 
 	userID, ok := c.Get("uid").(string)
 	if !ok {
-		res := &api.ErrorResponse{
-			Message: "認証情報が取得できませんでした",
+		return echo.NewHTTPError(http.StatusUnauthorized, "認証情報が取得できませんでした")
+	}
+
+	var payerShare *int64
+	if req.PayerShare != nil {
+		share := int64(*req.PayerShare)
+		payerShare = &share
+	}
+
+	var payerParams []PayerParam
+	if req.Payers != nil {
+		for _, p := range *req.Payers {
+			payerParams = append(payerParams, PayerParam{
+				UserID: p.UserId,
+				Amount: int64(p.Amount),
+				Share:  int64(p.Share),
+			})
 		}
-		return c.JSON(http.StatusUnauthorized, res)
+	}
+
+	version, err := parseVersion(ifMatch, req.Version)
+	if err != nil {
+		return err
 	}
 
 	input := UpdateInput{
-		GroupID:   groupID,
-		UserID:    userID,
-		EventID:   eventID,
-		Name:      req.Name,
-		Amount:    int64(req.Amount),
-		Debts:     debtParams,
-		EventDate: req.EventDate,
+		GroupID:    groupID,
+		UserID:     userID,
+		EventID:    eventID,
+		Name:       req.Name,
+		Amount:     int64(req.Amount),
+		PayerShare: payerShare,
+		Payers:     payerParams,
+		Debts:      debtParams,
+		EventDate:  req.EventDate,
+		DueDate:    req.DueDate,
+		Version:    version,
+	}
+	if req.ClearDueDate != nil {
+		input.ClearDueDate = *req.ClearDueDate
 	}
 
 	output, err := h.u.Update(ctx, input)
 	if err != nil {
-		
-		if errors.Is(err, &domain.NotFoundError{}) {
-			res := &api.ErrorResponse{
-				Message: "立て替えが見つかりません",
-			}
-			return c.JSON(http.StatusNotFound, res)
-		}
-		
-		if errors.Is(err, &domain.ForbiddenError{}) {
-			res := &api.ErrorResponse{
-				Message: err.Error(),
-			}
-			return c.JSON(http.StatusForbidden, res)
-		}
-		
-		if errors.Is(err, &domain.ValidationError{}) {
-			res := &api.ErrorResponse{
-				Message: err.Error(),
-			}
-			return c.JSON(http.StatusBadRequest, res)
-		}
-		span.SetStatus(codes.Error, err.Error())
-		span.RecordError(err)
-		res := &api.ErrorResponse{
-			Message: "サーバーエラーが発生しました",
-		}
-		return c.JSON(http.StatusInternalServerError, res)
+		return err
 	}
 
 	var debts []api.LendingDebtParmam
@@ -399,45 +378,54 @@ func (h lendingHandler) Update(c echo.Context, id string, lendingId string) erro
 	}
 
 	res := &api.LendingUpdateResponse{
-		Id:        output.Lending.ID().String(),
-		Name:      output.Lending.Name(),
-		Amount:    uint64(output.Lending.Amount()),
-		EventDate: output.Lending.EventDate(),
-		Debts:     debts,
-		CreatedAt: output.Lending.CreatedAt(),
-		UpdatedAt: output.Lending.UpdatedAt(),
-	}
-
+		Id:         output.Lending.ID().String(),
+		Name:       output.Lending.Name(),
+		Amount:     uint64(output.Lending.Amount()),
+		PayerShare: uint64(output.Lending.PayerShare()),
+		Payers:     toPayerParams(output.Lending),
+		EventDate:  output.Lending.EventDate(),
+		DueDate:    output.Lending.DueDate(),
+		Debts:      debts,
+		CreatedAt:  output.Lending.CreatedAt(),
+		UpdatedAt:  output.Lending.UpdatedAt(),
+		Version:    output.Lending.Version(),
+	}
+
+	setETag(c, output.Lending.Version())
 	return c.JSON(http.StatusOK, res)
 }
 
-// Delete 立て替えを削除する
+// Delete グループ内の立て替えを削除する
 func (h lendingHandler) Delete(c echo.Context, id string, lendingId string) error {
 	ctx, span := tracer.Start(c.Request().Context(), "lending.Delete")
 	defer span.End()
 
 	groupID, err := ulid.Parse(id)
 	if err != nil {
-		res := &api.ErrorResponse{
-			Message: "IDの形式が正しくありません",
-		}
-		return c.JSON(http.StatusBadRequest, res)
+		return echo.NewHTTPError(http.StatusBadRequest, "IDの形式が正しくありません")
 	}
 
+	return h.delete(ctx, c, &groupID, lendingId)
+}
+
+// DeletePersonal 個人間の立て替えを削除する
+func (h lendingHandler) DeletePersonal(c echo.Context, lendingId string) error {
+	ctx, span := tracer.Start(c.Request().Context(), "lending.DeletePersonal")
+	defer span.End()
+
+	return h.delete(ctx, c, nil, lendingId)
+}
+
+// delete 立て替えを削除する (groupIDがnilの場合は個人間の立て替え)
+func (h lendingHandler) delete(ctx context.Context, c echo.Context, groupID *ulid.ULID, lendingId string) error {
 	eventID, err := ulid.Parse(lendingId)
 	if err != nil {
-		res := &api.ErrorResponse{
-			Message: "IDの形式が正しくありません",
-		}
-		return c.JSON(http.StatusBadRequest, res)
+		return echo.NewHTTPError(http.StatusBadRequest, "IDの形式が正しくありません")
 	}
 
 	userID, ok := c.Get("uid").(string)
 	if !ok {
-		res := &api.ErrorResponse{
-			Message: "認証情報が取得できませんでした",
-		}
-		return c.JSON(http.StatusUnauthorized, res)
+		return echo.NewHTTPError(http.StatusUnauthorized, "認証情報が取得できませんでした")
 	}
 
 	input := DeleteInput{
@@ -446,41 +434,123 @@ func (h lendingHandler) Delete(c echo.Context, id string, lendingId string) erro
 		EventID: eventID,
 	}
 
-	err = h.u.Delete(ctx, input)
+	if err := h.u.Delete(ctx, input); err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// Search 所属する全グループを横断して立て替えを検索する
+func (h lendingHandler) Search(c echo.Context, params api.LendingSearchParams) error {
+	ctx, span := tracer.Start(c.Request().Context(), "lending.Search")
+	defer span.End()
+
+	userID, ok := c.Get("uid").(string)
+	if !ok {
+		return echo.NewHTTPError(http.StatusUnauthorized, "認証情報が取得できませんでした")
+	}
+
+	limit, err := parseLimit(params.Limit)
 	if err != nil {
-		
-		if errors.Is(err, &domain.NotFoundError{}) {
-			res := &api.ErrorResponse{
-				Message: "立て替えが見つかりません",
-			}
-			return c.JSON(http.StatusNotFound, res)
-		}
-		
-		if errors.Is(err, &domain.ForbiddenError{}) {
-			res := &api.ErrorResponse{
-				Message: err.Error(),
-			}
-			return c.JSON(http.StatusForbidden, res)
-		}
-		span.SetStatus(codes.Error, err.Error())
-		span.RecordError(err)
-		res := &api.ErrorResponse{
-			Message: "サーバーエラーが発生しました",
+		return err
+	}
+
+	input := SearchInput{
+		UserID: userID,
+		Query:  params.Q,
+		Limit:  limit,
+	}
+
+	output, err := h.u.Search(ctx, input)
+	if err != nil {
+		return err
+	}
+
+	res := make([]api.LendingSearchResponse, 0, len(output.Results))
+	for _, r := range output.Results {
+		var debts []api.LendingDebtParmam
+		for _, d := range r.Debtors {
+			debts = append(debts, api.LendingDebtParmam{
+				UserId: d.ID(),
+				Amount: uint64(d.Amount()),
+			})
 		}
-		return c.JSON(http.StatusInternalServerError, res)
+
+		res = append(res, api.LendingSearchResponse{
+			Id:         r.Lending.ID().String(),
+			GroupId:    r.Group.ID().String(),
+			GroupName:  r.Group.Name(),
+			Name:       r.Lending.Name(),
+			Amount:     uint64(r.Lending.Amount()),
+			PayerShare: uint64(r.Lending.PayerShare()),
+			Payers:     toPayerParams(r.Lending),
+			EventDate:  r.Lending.EventDate(),
+			DueDate:    r.Lending.DueDate(),
+			Debts:      debts,
+			CreatedBy:  r.Lending.Payer().ID(),
+			CreatedAt:  r.Lending.CreatedAt(),
+			UpdatedAt:  r.Lending.UpdatedAt(),
+			Version:    r.Lending.Version(),
+		})
 	}
 
-	return c.NoContent(http.StatusNoContent)
+	return c.JSON(http.StatusOK, res)
+}
+
+// List グループと個人間の立て替えを横断して、ユーザーが関与する立て替えを新しい順に取得する
+func (h lendingHandler) List(c echo.Context, params api.LendingListParams) error {
+	ctx, span := tracer.Start(c.Request().Context(), "lending.List")
+	defer span.End()
+
+	userID, ok := c.Get("uid").(string)
+	if !ok {
+		return echo.NewHTTPError(http.StatusUnauthorized, "認証情報が取得できませんでした")
+	}
+
+	limit, err := parseLimit(params.Limit)
+	if err != nil {
+		return err
+	}
+
+	input := ListInput{
+		UserID: userID,
+		Limit:  limit,
+		Cursor: params.Cursor,
+	}
+
+	output, err := h.u.List(ctx, input)
+	if err != nil {
+		return err
+	}
+
+	responseItems := make([]api.LendingListResponse, 0, len(output.Results))
+	for _, r := range output.Results {
+		res := newLendingListResponse(r)
+		res.CommentCount = output.CommentCounts[r.Lending.ID()]
+		responseItems = append(responseItems, res)
+	}
+
+	res := &api.LendingListPaginatedResponse{
+		Lendings:   responseItems,
+		NextCursor: output.NextCursor,
+		HasMore:    output.HasMore,
+	}
+
+	return c.JSON(http.StatusOK, res)
 }
 
 // CreateInput 立て替え作成の入力パラメータ
 type CreateInput struct {
-	GroupID   ulid.ULID
-	UserID    string
-	Name      string
-	Amount    int64
-	Debts     []DebtParam
-	EventDate time.Time
+	GroupID    *ulid.ULID
+	UserID     string
+	Name       string
+	Amount     int64
+	PayerShare *int64
+	Payers     []PayerParam
+	Debts      []DebtParam
+	EventDate  time.Time
+	DueDate    *time.Time
 }
 
 // DebtParam 債務者情報のパラメータ
@@ -489,6 +559,13 @@ type DebtParam struct {
 	Amount int64
 }
 
+// PayerParam 支払い者情報のパラメータ
+type PayerParam struct {
+	UserID string
+	Amount int64
+	Share  int64
+}
+
 // CreateOutput 立て替え作成の出力
 type CreateOutput struct {
 	Event   *domain.Lending
@@ -497,7 +574,7 @@ type CreateOutput struct {
 
 // GetInput 立て替え取得の入力パラメータ
 type GetInput struct {
-	GroupID ulid.ULID
+	GroupID *ulid.ULID
 	UserID  string
 	EventID ulid.ULID
 }
@@ -522,19 +599,44 @@ type GetAllOutput struct {
 		Lending *domain.Lending
 		Debtors []*domain.Debtor
 	}
-	NextCursor *string
-	HasMore    bool
+	// CommentCounts 立て替えごとのコメント数 (コメントがない立て替えは含まない)
+	CommentCounts map[ulid.ULID]int32
+	NextCursor    *string
+	HasMore       bool
+}
+
+// ListInput 立て替え横断一覧取得の入力パラメータ
+type ListInput struct {
+	UserID string
+	Limit  int32
+	Cursor *string
+}
+
+// ListOutput 立て替え横断一覧取得の出力
+type ListOutput struct {
+	Results []*domain.LendingSearchResult
+	// CommentCounts 立て替えごとのコメント数 (コメントがない立て替えは含まない)
+	CommentCounts map[ulid.ULID]int32
+	NextCursor    *string
+	HasMore       bool
 }
 
 // UpdateInput 立て替え更新の入力パラメータ
 type UpdateInput struct {
-	GroupID   ulid.ULID
-	UserID    string
-	EventID   ulid.ULID
-	Name      string
-	Amount    int64
-	Debts     []DebtParam
-	EventDate time.Time
+	GroupID    *ulid.ULID
+	UserID     string
+	EventID    ulid.ULID
+	Name       string
+	Amount     int64
+	PayerShare *int64
+	Payers     []PayerParam
+	Debts      []DebtParam
+	EventDate  time.Time
+	Version    *int32
+	// DueDate 新しい返済期限 (nilの場合は変更しない)
+	DueDate *time.Time
+	// ClearDueDate 返済期限をなくす
+	ClearDueDate bool
 }
 
 // UpdateOutput 立て替え更新の出力
@@ -545,7 +647,70 @@ type UpdateOutput struct {
 
 // DeleteInput 立て替え削除の入力パラメータ
 type DeleteInput struct {
-	GroupID ulid.ULID
+	GroupID *ulid.ULID
 	UserID  string
 	EventID ulid.ULID
 }
+
+// SearchInput 立て替え検索の入力パラメータ
+type SearchInput struct {
+	UserID string
+	Query  string
+	Limit  int32
+}
+
+// SearchOutput 立て替え検索の出力 (関連度順)
+type SearchOutput struct {
+	Results []struct {
+		Group   *domain.Group
+		Lending *domain.Lending
+		Debtors []*domain.Debtor
+	}
+}
+
+// toPayerParams 支払い者一覧をユーザーID順のレスポンスに変換する
+func toPayerParams(l *domain.Lending) []api.LendingPayerParam {
+	payers := make([]api.LendingPayerParam, 0, len(l.Payers()))
+	for _, id := range slices.Sorted(maps.Keys(l.Payers())) {
+		p := l.Payers()[id]
+		payers = append(payers, api.LendingPayerParam{
+			UserId: p.ID(),
+			Amount: uint64(p.Amount()),
+			Share:  uint64(p.Share()),
+		})
+	}
+	return payers
+}
+
+// newLendingListResponse グループまたは個人間の立て替えをレスポンスに変換する
+func newLendingListResponse(r *domain.LendingSearchResult) api.LendingListResponse {
+	debts := make([]api.LendingDebtParmam, 0, len(r.Lending.Debtors()))
+	for _, id := range slices.Sorted(maps.Keys(r.Lending.Debtors())) {
+		debts = append(debts, api.LendingDebtParmam{
+			UserId: id,
+			Amount: uint64(r.Lending.Debtors()[id].Amount()),
+		})
+	}
+
+	res := api.LendingListResponse{
+		Id:         r.Lending.ID().String(),
+		Name:       r.Lending.Name(),
+		Amount:     uint64(r.Lending.Amount()),
+		PayerShare: uint64(r.Lending.PayerShare()),
+		Payers:     toPayerParams(r.Lending),
+		EventDate:  r.Lending.EventDate(),
+		DueDate:    r.Lending.DueDate(),
+		Debts:      debts,
+		CreatedBy:  r.Lending.Payer().ID(),
+		CreatedAt:  r.Lending.CreatedAt(),
+		UpdatedAt:  r.Lending.UpdatedAt(),
+		Version:    r.Lending.Version(),
+	}
+	if r.Group != nil {
+		groupID := r.Group.ID().String()
+		groupName := r.Group.Name()
+		res.GroupId = &groupID
+		res.GroupName = &groupName
+	}
+	return res
+}
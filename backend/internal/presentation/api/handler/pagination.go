@@ -0,0 +1,26 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	// defaultLimit 取得件数の指定がない場合の件数
+	defaultLimit int32 = 20
+	// maxLimit 一度に取得できる最大件数
+	maxLimit int32 = 100
+)
+
+// parseLimit 取得件数を検証する
+// 指定がない場合はデフォルトの件数を返し、1以上100以下でない場合は400を返す
+func parseLimit(limit *int32) (int32, error) {
+	if limit == nil {
+		return defaultLimit, nil
+	}
+	if *limit < 1 || *limit > maxLimit {
+		return 0, echo.NewHTTPError(http.StatusBadRequest, "limitは1以上100以下で指定してください")
+	}
+	return *limit, nil
+}
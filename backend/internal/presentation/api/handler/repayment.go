@@ -2,13 +2,11 @@ package handler
 
 import (
 	"context"
-	"errors"
 	"net/http"
 
 	"github.com/haebeal/datti/internal/domain"
 	"github.com/haebeal/datti/internal/presentation/api"
 	"github.com/labstack/echo/v4"
-	"go.opentelemetry.io/otel/codes"
 )
 
 // RepaymentUseCase 返済に関するユースケースのインターフェース
@@ -40,18 +38,12 @@ func (h repaymentHandler) Create(c echo.Context) error {
 
 	err := c.Bind(&req)
 	if err != nil {
-		res := &api.ErrorResponse{
-			Message: "リクエストの形式が正しくありません",
-		}
-		return c.JSON(http.StatusBadRequest, res)
+		return echo.NewHTTPError(http.StatusBadRequest, "リクエストの形式が正しくありません")
 	}
 
 	payerID, ok := c.Get("uid").(string)
 	if !ok {
-		res := &api.ErrorResponse{
-			Message: "認証情報が取得できませんでした",
-		}
-		return c.JSON(http.StatusUnauthorized, res)
+		return echo.NewHTTPError(http.StatusUnauthorized, "認証情報が取得できませんでした")
 	}
 
 	input := RepaymentCreateInput{
@@ -62,33 +54,7 @@ func (h repaymentHandler) Create(c echo.Context) error {
 
 	output, err := h.u.Create(ctx, input)
 	if err != nil {
-		
-		if errors.Is(err, &domain.NotFoundError{}) {
-			res := &api.ErrorResponse{
-				Message: "ユーザーが見つかりません",
-			}
-			return c.JSON(http.StatusNotFound, res)
-		}
-		
-		if errors.Is(err, &domain.ForbiddenError{}) {
-			res := &api.ErrorResponse{
-				Message: err.Error(),
-			}
-			return c.JSON(http.StatusForbidden, res)
-		}
-		
-		if errors.Is(err, &domain.ValidationError{}) {
-			res := &api.ErrorResponse{
-				Message: err.Error(),
-			}
-			return c.JSON(http.StatusBadRequest, res)
-		}
-		span.SetStatus(codes.Error, err.Error())
-		span.RecordError(err)
-		res := &api.ErrorResponse{
-			Message: "サーバーエラーが発生しました",
-		}
-		return c.JSON(http.StatusInternalServerError, res)
+		return err
 	}
 
 	res := &api.RepaymentCreateResponse{
@@ -98,8 +64,10 @@ func (h repaymentHandler) Create(c echo.Context) error {
 		Amount:    uint64(output.Repayment.Amount()),
 		CreatedAt: output.Repayment.CreatedAt(),
 		UpdatedAt: output.Repayment.UpdatedAt(),
+		Version:   output.Repayment.Version(),
 	}
 
+	setETag(c, output.Repayment.Version())
 	return c.JSON(http.StatusCreated, res)
 }
 
@@ -110,10 +78,7 @@ func (h repaymentHandler) GetByQuery(c echo.Context, params api.RepaymentGetAllP
 
 	userID, ok := c.Get("uid").(string)
 	if !ok {
-		res := &api.ErrorResponse{
-			Message: "認証情報が取得できませんでした",
-		}
-		return c.JSON(http.StatusUnauthorized, res)
+		return echo.NewHTTPError(http.StatusUnauthorized, "認証情報が取得できませんでした")
 	}
 
 	// Default limit
@@ -130,12 +95,7 @@ func (h repaymentHandler) GetByQuery(c echo.Context, params api.RepaymentGetAllP
 
 	output, err := h.u.GetByQuery(ctx, input)
 	if err != nil {
-		span.SetStatus(codes.Error, err.Error())
-		span.RecordError(err)
-		res := &api.ErrorResponse{
-			Message: "サーバーエラーが発生しました",
-		}
-		return c.JSON(http.StatusInternalServerError, res)
+		return err
 	}
 
 	responseItems := make([]api.RepaymentGetAllResponse, 0, len(output.Repayments))
@@ -147,6 +107,7 @@ func (h repaymentHandler) GetByQuery(c echo.Context, params api.RepaymentGetAllP
 			Amount:    uint64(r.Amount()),
 			CreatedAt: r.CreatedAt(),
 			UpdatedAt: r.UpdatedAt(),
+			Version:   r.Version(),
 		})
 	}
 
@@ -170,19 +131,7 @@ func (h repaymentHandler) Get(c echo.Context, id string) error {
 
 	output, err := h.u.Get(ctx, input)
 	if err != nil {
-		
-		if errors.Is(err, &domain.NotFoundError{}) {
-			res := &api.ErrorResponse{
-				Message: "返済が見つかりません",
-			}
-			return c.JSON(http.StatusNotFound, res)
-		}
-		span.SetStatus(codes.Error, err.Error())
-		span.RecordError(err)
-		res := &api.ErrorResponse{
-			Message: "サーバーエラーが発生しました",
-		}
-		return c.JSON(http.StatusInternalServerError, res)
+		return err
 	}
 
 	res := &api.RepaymentGetResponse{
@@ -192,13 +141,15 @@ func (h repaymentHandler) Get(c echo.Context, id string) error {
 		Amount:    uint64(output.Repayment.Amount()),
 		CreatedAt: output.Repayment.CreatedAt(),
 		UpdatedAt: output.Repayment.UpdatedAt(),
+		Version:   output.Repayment.Version(),
 	}
 
+	setETag(c, output.Repayment.Version())
 	return c.JSON(http.StatusOK, res)
 }
 
 // Update 返済情報を更新する
-func (h repaymentHandler) Update(c echo.Context, id string) error {
+func (h repaymentHandler) Update(c echo.Context, id string, params api.RepaymentUpdateParams) error {
 	ctx, span := tracer.Start(c.Request().Context(), "repayment.Update")
 	defer span.End()
 
@@ -206,39 +157,23 @@ func (h repaymentHandler) Update(c echo.Context, id string) error {
 
 	err := c.Bind(&req)
 	if err != nil {
-		res := &api.ErrorResponse{
-			Message: "リクエストの形式が正しくありません",
-		}
-		return c.JSON(http.StatusBadRequest, res)
+		return echo.NewHTTPError(http.StatusBadRequest, "リクエストの形式が正しくありません")
+	}
+
+	version, err := parseVersion(params.IfMatch, req.Version)
+	if err != nil {
+		return err
 	}
 
 	input := RepaymentUpdateInput{
-		ID:     id,
-		Amount: int64(req.Amount),
+		ID:      id,
+		Amount:  int64(req.Amount),
+		Version: version,
 	}
 
 	output, err := h.u.Update(ctx, input)
 	if err != nil {
-		
-		if errors.Is(err, &domain.NotFoundError{}) {
-			res := &api.ErrorResponse{
-				Message: "返済が見つかりません",
-			}
-			return c.JSON(http.StatusNotFound, res)
-		}
-		
-		if errors.Is(err, &domain.ForbiddenError{}) {
-			res := &api.ErrorResponse{
-				Message: err.Error(),
-			}
-			return c.JSON(http.StatusForbidden, res)
-		}
-		span.SetStatus(codes.Error, err.Error())
-		span.RecordError(err)
-		res := &api.ErrorResponse{
-			Message: "サーバーエラーが発生しました",
-		}
-		return c.JSON(http.StatusInternalServerError, res)
+		return err
 	}
 
 	res := &api.RepaymentUpdateResponse{
@@ -248,8 +183,10 @@ func (h repaymentHandler) Update(c echo.Context, id string) error {
 		Amount:    uint64(output.Repayment.Amount()),
 		CreatedAt: output.Repayment.CreatedAt(),
 		UpdatedAt: output.Repayment.UpdatedAt(),
+		Version:   output.Repayment.Version(),
 	}
 
+	setETag(c, output.Repayment.Version())
 	return c.JSON(http.StatusOK, res)
 }
 
@@ -264,26 +201,7 @@ func (h repaymentHandler) Delete(c echo.Context, id string) error {
 
 	err := h.u.Delete(ctx, input)
 	if err != nil {
-		
-		if errors.Is(err, &domain.NotFoundError{}) {
-			res := &api.ErrorResponse{
-				Message: "返済が見つかりません",
-			}
-			return c.JSON(http.StatusNotFound, res)
-		}
-		
-		if errors.Is(err, &domain.ForbiddenError{}) {
-			res := &api.ErrorResponse{
-				Message: err.Error(),
-			}
-			return c.JSON(http.StatusForbidden, res)
-		}
-		span.SetStatus(codes.Error, err.Error())
-		span.RecordError(err)
-		res := &api.ErrorResponse{
-			Message: "サーバーエラーが発生しました",
-		}
-		return c.JSON(http.StatusInternalServerError, res)
+		return err
 	}
 
 	return c.NoContent(http.StatusNoContent)
@@ -327,8 +245,9 @@ type RepaymentGetOutput struct {
 
 // RepaymentUpdateInput 返済更新の入力パラメータ
 type RepaymentUpdateInput struct {
-	ID     string
-	Amount int64
+	ID      string
+	Amount  int64
+	Version *int32
 }
 
 // RepaymentUpdateOutput 返済更新の出力
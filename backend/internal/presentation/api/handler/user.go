@@ -1,15 +1,20 @@
 package handler
 
 import (
+	"archive/zip"
+	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/haebeal/datti/internal/domain"
 	"github.com/haebeal/datti/internal/presentation/api"
 	"github.com/labstack/echo/v4"
-	"go.opentelemetry.io/otel/codes"
 )
 
 // UserUseCase ユーザーに関するユースケースのインターフェース
@@ -18,6 +23,10 @@ type UserUseCase interface {
 	Get(context.Context, UserGetInput) (*UserGetOutput, error)
 	GetMe(context.Context, UserGetMeInput) (*UserGetMeOutput, error)
 	UpdateMe(context.Context, UserUpdateMeInput) (*UserUpdateMeOutput, error)
+	DeleteMe(context.Context, UserDeleteMeInput) error
+	ExportMe(context.Context, UserExportMeInput) (*UserExportMeOutput, error)
+	GetPrivacy(context.Context, UserGetPrivacyInput) (*UserGetPrivacyOutput, error)
+	UpdatePrivacy(context.Context, UserUpdatePrivacyInput) (*UserUpdatePrivacyOutput, error)
 }
 
 type userHandler struct {
@@ -31,16 +40,15 @@ func NewUserHandler(u UserUseCase) userHandler {
 	}
 }
 
-// Search ユーザーを名前またはメールアドレスで検索する
+// Search 連絡先のユーザーを名前またはメールアドレスで検索する
+// メールアドレスを完全に指定した場合は連絡先以外のユーザーも検索する
 func (h userHandler) Search(c echo.Context, params api.UserSearchParams) error {
 	ctx, span := tracer.Start(c.Request().Context(), "user.Search")
 	defer span.End()
 
-	if _, ok := c.Get("uid").(string); !ok {
-		res := &api.ErrorResponse{
-			Message: "認証情報が取得できませんでした",
-		}
-		return c.JSON(http.StatusUnauthorized, res)
+	uid, ok := c.Get("uid").(string)
+	if !ok {
+		return echo.NewHTTPError(http.StatusUnauthorized, "認証情報が取得できませんでした")
 	}
 
 	name := ""
@@ -53,10 +61,7 @@ func (h userHandler) Search(c echo.Context, params api.UserSearchParams) error {
 	}
 
 	if name == "" && email == "" {
-		res := &api.ErrorResponse{
-			Message: "名前またはメールアドレスを指定してください",
-		}
-		return c.JSON(http.StatusBadRequest, res)
+		return echo.NewHTTPError(http.StatusBadRequest, "名前またはメールアドレスを指定してください")
 	}
 
 	var limit int32
@@ -65,6 +70,7 @@ func (h userHandler) Search(c echo.Context, params api.UserSearchParams) error {
 	}
 
 	input := UserSearchInput{
+		UID:   uid,
 		Name:  name,
 		Email: email,
 		Limit: limit,
@@ -72,12 +78,7 @@ func (h userHandler) Search(c echo.Context, params api.UserSearchParams) error {
 
 	output, err := h.u.Search(ctx, input)
 	if err != nil {
-		span.SetStatus(codes.Error, err.Error())
-		span.RecordError(err)
-		res := &api.ErrorResponse{
-			Message: "サーバーエラーが発生しました",
-		}
-		return c.JSON(http.StatusInternalServerError, res)
+		return err
 	}
 
 	res := make([]api.UserSearchResponse, 0, len(output.Users))
@@ -95,6 +96,7 @@ func (h userHandler) Search(c echo.Context, params api.UserSearchParams) error {
 
 // UserSearchInput ユーザー検索の入力パラメータ
 type UserSearchInput struct {
+	UID   string
 	Name  string
 	Email string
 	Limit int32
@@ -110,38 +112,29 @@ func (h userHandler) Get(c echo.Context, id string) error {
 	ctx, span := tracer.Start(c.Request().Context(), "user.Get")
 	defer span.End()
 
-	if _, ok := c.Get("uid").(string); !ok {
-		res := &api.ErrorResponse{
-			Message: "認証情報が取得できませんでした",
-		}
-		return c.JSON(http.StatusUnauthorized, res)
+	uid, ok := c.Get("uid").(string)
+	if !ok {
+		return echo.NewHTTPError(http.StatusUnauthorized, "認証情報が取得できませんでした")
 	}
 
 	input := UserGetInput{
-		ID: id,
+		UID: uid,
+		ID:  id,
 	}
 
 	output, err := h.u.Get(ctx, input)
 	if err != nil {
-		if errors.Is(err, &domain.NotFoundError{}) {
-			res := &api.ErrorResponse{
-				Message: "ユーザーが見つかりません",
-			}
-			return c.JSON(http.StatusNotFound, res)
-		}
-		span.SetStatus(codes.Error, err.Error())
-		span.RecordError(err)
-		res := &api.ErrorResponse{
-			Message: "サーバーエラーが発生しました",
-		}
-		return c.JSON(http.StatusInternalServerError, res)
+		return err
 	}
 
-	res := api.UserGetResponse{
+	res := api.UserProfileResponse{
 		Id:     output.User.ID(),
 		Name:   output.User.Name(),
 		Avatar: output.User.Avatar(),
-		Email:  output.User.Email(),
+	}
+	if output.EmailVisible {
+		email := output.User.Email()
+		res.Email = &email
 	}
 
 	return c.JSON(http.StatusOK, res)
@@ -154,10 +147,7 @@ func (h userHandler) GetMe(c echo.Context) error {
 
 	uid, ok := c.Get("uid").(string)
 	if !ok {
-		res := &api.ErrorResponse{
-			Message: "認証情報が取得できませんでした",
-		}
-		return c.JSON(http.StatusUnauthorized, res)
+		return echo.NewHTTPError(http.StatusUnauthorized, "認証情報が取得できませんでした")
 	}
 
 	input := UserGetMeInput{
@@ -167,17 +157,9 @@ func (h userHandler) GetMe(c echo.Context) error {
 	output, err := h.u.GetMe(ctx, input)
 	if err != nil {
 		if errors.Is(err, &domain.NotFoundError{}) {
-			res := &api.ErrorResponse{
-				Message: "ユーザーが見つかりません",
-			}
-			return c.JSON(http.StatusUnauthorized, res)
-		}
-		span.SetStatus(codes.Error, err.Error())
-		span.RecordError(err)
-		res := &api.ErrorResponse{
-			Message: "サーバーエラーが発生しました",
+			return echo.NewHTTPError(http.StatusUnauthorized, "ユーザーが見つかりません").SetInternal(err)
 		}
-		return c.JSON(http.StatusInternalServerError, res)
+		return err
 	}
 
 	res := api.UserGetResponse{
@@ -197,18 +179,12 @@ func (h userHandler) UpdateMe(c echo.Context) error {
 
 	uid, ok := c.Get("uid").(string)
 	if !ok {
-		res := &api.ErrorResponse{
-			Message: "認証情報が取得できませんでした",
-		}
-		return c.JSON(http.StatusUnauthorized, res)
+		return echo.NewHTTPError(http.StatusUnauthorized, "認証情報が取得できませんでした")
 	}
 
 	var req api.UserUpdateRequest
 	if err := c.Bind(&req); err != nil {
-		res := &api.ErrorResponse{
-			Message: "リクエストの形式が正しくありません",
-		}
-		return c.JSON(http.StatusBadRequest, res)
+		return echo.NewHTTPError(http.StatusBadRequest, "リクエストの形式が正しくありません")
 	}
 
 	input := UserUpdateMeInput{
@@ -219,18 +195,7 @@ func (h userHandler) UpdateMe(c echo.Context) error {
 
 	output, err := h.u.UpdateMe(ctx, input)
 	if err != nil {
-		if errors.Is(err, &domain.NotFoundError{}) {
-			res := &api.ErrorResponse{
-				Message: "ユーザーが見つかりません",
-			}
-			return c.JSON(http.StatusNotFound, res)
-		}
-		span.SetStatus(codes.Error, err.Error())
-		span.RecordError(err)
-		res := &api.ErrorResponse{
-			Message: "サーバーエラーが発生しました",
-		}
-		return c.JSON(http.StatusInternalServerError, res)
+		return err
 	}
 
 	res := api.UserGetResponse{
@@ -245,12 +210,15 @@ func (h userHandler) UpdateMe(c echo.Context) error {
 
 // UserGetInput ユーザー取得の入力パラメータ
 type UserGetInput struct {
-	ID string
+	UID string
+	ID  string
 }
 
 // UserGetOutput ユーザー取得の出力
 type UserGetOutput struct {
 	User *domain.User
+	// EmailVisible メールアドレスを公開するかどうか (自分自身または連絡先の場合)
+	EmailVisible bool
 }
 
 // UserGetMeInput 自身の情報取得の入力パラメータ
@@ -274,3 +242,226 @@ type UserUpdateMeInput struct {
 type UserUpdateMeOutput struct {
 	User *domain.User
 }
+
+// DeleteMe 認証ユーザー自身を退会させる
+func (h userHandler) DeleteMe(c echo.Context) error {
+	ctx, span := tracer.Start(c.Request().Context(), "user.DeleteMe")
+	defer span.End()
+
+	uid, ok := c.Get("uid").(string)
+	if !ok {
+		return echo.NewHTTPError(http.StatusUnauthorized, "認証情報が取得できませんでした")
+	}
+
+	input := UserDeleteMeInput{
+		UID: uid,
+	}
+
+	err := h.u.DeleteMe(ctx, input)
+	if err != nil {
+		if errors.Is(err, &domain.NotFoundError{}) {
+			return echo.NewHTTPError(http.StatusUnauthorized, "ユーザーが見つかりません").SetInternal(err)
+		}
+		return err
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// ExportMe 認証ユーザー自身に関するデータをZIPアーカイブで返す
+func (h userHandler) ExportMe(c echo.Context) error {
+	ctx, span := tracer.Start(c.Request().Context(), "user.ExportMe")
+	defer span.End()
+
+	uid, ok := c.Get("uid").(string)
+	if !ok {
+		return echo.NewHTTPError(http.StatusUnauthorized, "認証情報が取得できませんでした")
+	}
+
+	input := UserExportMeInput{
+		UID: uid,
+	}
+
+	output, err := h.u.ExportMe(ctx, input)
+	if err != nil {
+		if errors.Is(err, &domain.NotFoundError{}) {
+			return echo.NewHTTPError(http.StatusUnauthorized, "ユーザーが見つかりません").SetInternal(err)
+		}
+		return err
+	}
+
+	// 書き込み途中のエラーをエラーレスポンスとして返せるようにバッファに書き出す
+	var buf bytes.Buffer
+	if err := WriteUserExportArchive(&buf, output); err != nil {
+		return err
+	}
+
+	filename := fmt.Sprintf("datti-export-%s.zip", time.Now().Format("20060102"))
+	c.Response().Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", filename))
+	return c.Blob(http.StatusOK, "application/zip", buf.Bytes())
+}
+
+// WriteUserExportArchive エクスポートしたデータをZIPアーカイブとして書き出す
+// 各ファイルはAPIのレスポンスと同じ形式のJSONとする
+func WriteUserExportArchive(w io.Writer, output *UserExportMeOutput) error {
+	groups := make([]api.GroupGetResponse, 0, len(output.Groups))
+	for _, g := range output.Groups {
+		groups = append(groups, api.GroupGetResponse{
+			Id:               g.ID().String(),
+			Name:             g.Name(),
+			PaymentTermsDays: g.PaymentTermsDays(),
+			CreatedBy:        g.CreatedBy(),
+			CreatedAt:        g.CreatedAt(),
+			UpdatedAt:        g.UpdatedAt(),
+			Version:          g.Version(),
+		})
+	}
+
+	lendings := make([]api.LendingListResponse, 0, len(output.Lendings))
+	for _, r := range output.Lendings {
+		lendings = append(lendings, newLendingListResponse(r))
+	}
+
+	repayments := make([]api.RepaymentGetResponse, 0, len(output.Repayments))
+	for _, r := range output.Repayments {
+		repayments = append(repayments, api.RepaymentGetResponse{
+			Id:        r.ID().String(),
+			PayerId:   r.PayerID(),
+			DebtorId:  r.DebtorID(),
+			Amount:    uint64(r.Amount()),
+			CreatedAt: r.CreatedAt(),
+			UpdatedAt: r.UpdatedAt(),
+			Version:   r.Version(),
+		})
+	}
+
+	credits := make([]api.Credit, 0, len(output.Credits))
+	for _, credit := range output.Credits {
+		credits = append(credits, api.Credit{
+			UserId:        credit.UserID(),
+			Amount:        credit.Amount(),
+			OverdueAmount: credit.Overdue(),
+		})
+	}
+
+	files := []struct {
+		name string
+		body any
+	}{
+		{"user.json", api.UserGetResponse{
+			Id:     output.User.ID(),
+			Name:   output.User.Name(),
+			Avatar: output.User.Avatar(),
+			Email:  output.User.Email(),
+		}},
+		{"groups.json", groups},
+		{"lendings.json", lendings},
+		{"repayments.json", repayments},
+		{"credits.json", credits},
+	}
+
+	zw := zip.NewWriter(w)
+	for _, file := range files {
+		fw, err := zw.Create(file.name)
+		if err != nil {
+			return err
+		}
+		enc := json.NewEncoder(fw)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(file.body); err != nil {
+			return err
+		}
+	}
+
+	return zw.Close()
+}
+
+// UserDeleteMeInput 退会の入力パラメータ
+type UserDeleteMeInput struct {
+	UID string
+}
+
+// UserExportMeInput 自身のデータのエクスポートの入力パラメータ
+type UserExportMeInput struct {
+	UID string
+}
+
+// UserExportMeOutput 自身のデータのエクスポートの出力
+type UserExportMeOutput struct {
+	User       *domain.User
+	Groups     []*domain.Group
+	Lendings   []*domain.LendingSearchResult
+	Repayments []*domain.Repayment
+	Credits    []*domain.Credit
+}
+
+// GetPrivacy 認証ユーザーのプライバシー設定を取得する
+func (h userHandler) GetPrivacy(c echo.Context) error {
+	ctx, span := tracer.Start(c.Request().Context(), "user.GetPrivacy")
+	defer span.End()
+
+	uid, ok := c.Get("uid").(string)
+	if !ok {
+		return echo.NewHTTPError(http.StatusUnauthorized, "認証情報が取得できませんでした")
+	}
+
+	output, err := h.u.GetPrivacy(ctx, UserGetPrivacyInput{UID: uid})
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, api.UserPrivacy{
+		DiscoverableByEmail: output.Privacy.DiscoverableByEmail(),
+	})
+}
+
+// UpdatePrivacy 認証ユーザーのプライバシー設定を更新する
+func (h userHandler) UpdatePrivacy(c echo.Context) error {
+	ctx, span := tracer.Start(c.Request().Context(), "user.UpdatePrivacy")
+	defer span.End()
+
+	uid, ok := c.Get("uid").(string)
+	if !ok {
+		return echo.NewHTTPError(http.StatusUnauthorized, "認証情報が取得できませんでした")
+	}
+
+	var req api.UserPrivacy
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "リクエストの形式が正しくありません")
+	}
+
+	input := UserUpdatePrivacyInput{
+		UID:                 uid,
+		DiscoverableByEmail: req.DiscoverableByEmail,
+	}
+
+	output, err := h.u.UpdatePrivacy(ctx, input)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, api.UserPrivacy{
+		DiscoverableByEmail: output.Privacy.DiscoverableByEmail(),
+	})
+}
+
+// UserGetPrivacyInput プライバシー設定取得の入力パラメータ
+type UserGetPrivacyInput struct {
+	UID string
+}
+
+// UserGetPrivacyOutput プライバシー設定取得の出力
+type UserGetPrivacyOutput struct {
+	Privacy *domain.UserPrivacy
+}
+
+// UserUpdatePrivacyInput プライバシー設定更新の入力パラメータ
+type UserUpdatePrivacyInput struct {
+	UID                 string
+	DiscoverableByEmail bool
+}
+
+// UserUpdatePrivacyOutput プライバシー設定更新の出力
+type UserUpdatePrivacyOutput struct {
+	Privacy *domain.UserPrivacy
+}
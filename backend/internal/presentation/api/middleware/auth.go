@@ -1,15 +1,22 @@
 package middleware
 
 import (
-	"log"
+	"context"
+	"errors"
+	"log/slog"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/service/cognitoidentityprovider"
-	"github.com/haebeal/datti/internal/presentation/api"
+	"github.com/aws/aws-sdk-go-v2/service/cognitoidentityprovider/types"
+	"github.com/haebeal/datti/internal/logger"
 	"github.com/labstack/echo/v4"
 )
 
+// readinessCheckToken 疎通確認で送信するアクセストークン
+const readinessCheckToken = "readiness-check"
+
 type AuthMiddlewareConfig struct {
 	CognitoClient *cognitoidentityprovider.Client
 	SkipPaths     []string
@@ -29,45 +36,73 @@ func AuthMiddleware(cfg AuthMiddlewareConfig) echo.MiddlewareFunc {
 
 			authHeader := c.Request().Header.Get("Authorization")
 			if authHeader == "" {
-				return c.JSON(http.StatusUnauthorized, api.ErrorResponse{
-					Message: "Authorization header is required",
-				})
+				return echo.NewHTTPError(http.StatusUnauthorized, "Authorization header is required")
 			}
 
 			parts := strings.SplitN(authHeader, " ", 2)
 			if len(parts) != 2 || strings.ToLower(parts[0]) != "bearer" {
-				return c.JSON(http.StatusUnauthorized, api.ErrorResponse{
-					Message: "Invalid authorization header format",
-				})
+				return echo.NewHTTPError(http.StatusUnauthorized, "Invalid authorization header format")
 			}
 			accessToken := parts[1]
 
+			start := time.Now()
 			user, err := cfg.CognitoClient.GetUser(c.Request().Context(), &cognitoidentityprovider.GetUserInput{
 				AccessToken: &accessToken,
 			})
+			recordAuthVerifyDuration(c.Request().Context(), "cognito", time.Since(start), err)
 			if err != nil {
-				log.Printf("Cognito GetUser error: %v", err)
-				return c.JSON(http.StatusUnauthorized, api.ErrorResponse{
-					Message: "アクセストークンの検証に失敗しました",
-				})
+				logger.FromContext(c.Request().Context()).WarnContext(c.Request().Context(), "Cognito GetUser error", slog.Any("error", err))
+				return echo.NewHTTPError(http.StatusUnauthorized, "アクセストークンの検証に失敗しました").SetInternal(err)
 			}
 
-			var uid string
+			var uid, email string
+			var emailVerified bool
 			for _, attr := range user.UserAttributes {
-				if *attr.Name == "sub" {
+				switch *attr.Name {
+				case "sub":
 					uid = *attr.Value
-					break
+				case "email":
+					email = *attr.Value
+				case "email_verified":
+					emailVerified = *attr.Value == "true"
 				}
 			}
 			if uid == "" {
-				return c.JSON(http.StatusUnauthorized, api.ErrorResponse{
-					Message: "ユーザーIDの取得に失敗しました",
-				})
+				return echo.NewHTTPError(http.StatusUnauthorized, "ユーザーIDの取得に失敗しました")
 			}
 
 			c.Set("uid", uid)
+			// 確認済みのメールアドレスのみをゲストの引き継ぎなどの本人確認に使用する
+			if emailVerified && email != "" {
+				c.Set("verifiedEmail", email)
+			}
+
+			// 以降のログに認証済みのユーザーIDを含める
+			ctx := c.Request().Context()
+			ctx = logger.WithContext(ctx, logger.FromContext(ctx).With(slog.String("uid", uid)))
+			c.SetRequest(c.Request().WithContext(ctx))
 
 			return next(c)
 		}
 	}
 }
+
+// CognitoReadinessChecker Cognitoへの疎通確認を行う
+type CognitoReadinessChecker struct {
+	Client *cognitoidentityprovider.Client
+}
+
+// Ping 無効なアクセストークンで問い合わせ、Cognitoが応答するかを確認する
+func (r CognitoReadinessChecker) Ping(ctx context.Context) error {
+	token := readinessCheckToken
+	_, err := r.Client.GetUser(ctx, &cognitoidentityprovider.GetUserInput{
+		AccessToken: &token,
+	})
+
+	// トークンが無効であるというエラーはCognitoが応答していることを示す
+	var notAuthorized *types.NotAuthorizedException
+	if err == nil || errors.As(err, &notAuthorized) {
+		return nil
+	}
+	return err
+}
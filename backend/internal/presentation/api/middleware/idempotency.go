@@ -0,0 +1,123 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/haebeal/datti/internal/domain"
+	"github.com/haebeal/datti/internal/logger"
+	"github.com/labstack/echo/v4"
+)
+
+// IdempotencyKeyHeader 冪等性キーを指定するリクエストヘッダー
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// IdempotentReplayedHeader 保存済みのレスポンスを再送したことを示すレスポンスヘッダー
+const IdempotentReplayedHeader = "Idempotent-Replayed"
+
+type IdempotencyMiddlewareConfig struct {
+	Repository domain.IdempotencyKeyRepository
+	// TTL キーの有効期限 (期限切れのキーは再利用できる)
+	TTL time.Duration
+}
+
+// IdempotencyMiddleware Idempotency-Keyヘッダー付きのPOSTリクエストを冪等にするミドルウェア
+// 認証ミドルウェアの後に登録する必要がある
+func IdempotencyMiddleware(cfg IdempotencyMiddlewareConfig) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+			keyHeader := req.Header.Get(IdempotencyKeyHeader)
+			if req.Method != http.MethodPost || keyHeader == "" {
+				return next(c)
+			}
+
+			uid, ok := c.Get("uid").(string)
+			if !ok {
+				return next(c)
+			}
+
+			body, err := io.ReadAll(req.Body)
+			if err != nil {
+				return echo.NewHTTPError(http.StatusBadRequest, "リクエストの形式が正しくありません")
+			}
+			req.Body = io.NopCloser(bytes.NewReader(body))
+
+			key, err := domain.CreateIdempotencyKey(uid, keyHeader, req.Method, req.URL.Path, hashRequest(req.Method, req.URL.Path, body))
+			if err != nil {
+				return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+			}
+
+			ctx := req.Context()
+			existing, err := cfg.Repository.Reserve(ctx, key, time.Now().Add(-cfg.TTL))
+			if err != nil {
+				logger.FromContext(ctx).ErrorContext(ctx, "Idempotency key reserve error", slog.Any("error", err))
+				return echo.NewHTTPError(http.StatusInternalServerError, "サーバーエラーが発生しました")
+			}
+
+			if existing != nil {
+				if !existing.Matches(key.Method(), key.Path(), key.RequestHash()) {
+					return echo.NewHTTPError(http.StatusUnprocessableEntity, "Idempotency-Keyが異なるリクエストで使用されています")
+				}
+				if !existing.Completed() {
+					return echo.NewHTTPError(http.StatusConflict, "同じIdempotency-Keyのリクエストを処理中です")
+				}
+
+				c.Response().Header().Set(IdempotentReplayedHeader, "true")
+				return c.Blob(existing.StatusCode(), existing.ContentType(), existing.ResponseBody())
+			}
+
+			recorder := &responseRecorder{ResponseWriter: c.Response().Writer}
+			c.Response().Writer = recorder
+
+			err = next(c)
+
+			// サーバーエラーやハンドラーが返したエラーは再試行できるようにキーを削除する
+			status := c.Response().Status
+			if err != nil || !c.Response().Committed || status >= http.StatusInternalServerError {
+				if deleteErr := cfg.Repository.Delete(ctx, key); deleteErr != nil {
+					logger.FromContext(ctx).ErrorContext(ctx, "Idempotency key delete error", slog.Any("error", deleteErr))
+				}
+				return err
+			}
+
+			completed, completeErr := key.Complete(status, c.Response().Header().Get(echo.HeaderContentType), recorder.body.Bytes())
+			if completeErr == nil {
+				completeErr = cfg.Repository.Complete(ctx, completed)
+			}
+			if completeErr != nil {
+				logger.FromContext(ctx).ErrorContext(ctx, "Idempotency key complete error", slog.Any("error", errors.Join(completeErr, cfg.Repository.Delete(ctx, key))))
+			}
+
+			return nil
+		}
+	}
+}
+
+// hashRequest メソッド、パス、ボディからリクエスト内容のハッシュを計算する
+func hashRequest(method string, path string, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(method))
+	h.Write([]byte{'\n'})
+	h.Write([]byte(path))
+	h.Write([]byte{'\n'})
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// responseRecorder レスポンスボディを記録するResponseWriter
+type responseRecorder struct {
+	http.ResponseWriter
+	body bytes.Buffer
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
@@ -0,0 +1,65 @@
+package middleware
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/haebeal/datti/internal/logger"
+	"github.com/labstack/echo/v4"
+)
+
+type RequestLoggerMiddlewareConfig struct {
+	Logger *slog.Logger
+}
+
+// RequestLoggerMiddleware リクエストごとのアクセスログを出力するミドルウェア
+// リクエストのcontextにロガーを格納するため、otelechoのミドルウェアの後に登録する必要がある
+func RequestLoggerMiddleware(cfg RequestLoggerMiddlewareConfig) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			start := time.Now()
+			req := c.Request()
+
+			l := cfg.Logger.With(
+				slog.String("method", req.Method),
+				slog.String("route", c.Path()),
+			)
+			ctx := logger.WithContext(req.Context(), l)
+			c.SetRequest(req.WithContext(ctx))
+
+			// ステータスコードを確定させるためにここでエラーレスポンスを書き込む
+			// エラーは上位のミドルウェアのために返す (書き込み済みのレスポンスはErrorHandlerが再度書き込まない)
+			err := next(c)
+			if err != nil {
+				c.Error(err)
+			}
+
+			status := c.Response().Status
+			attrs := []slog.Attr{
+				slog.String("path", req.URL.Path),
+				slog.Int("status", status),
+				slog.Int64("latency_ms", time.Since(start).Milliseconds()),
+				slog.Int64("bytes_out", c.Response().Size),
+				slog.String("remote_ip", c.RealIP()),
+			}
+			if uid, ok := c.Get("uid").(string); ok {
+				attrs = append(attrs, slog.String("uid", uid))
+			}
+			if err != nil {
+				attrs = append(attrs, slog.Any("error", err))
+			}
+
+			level := slog.LevelInfo
+			switch {
+			case status >= http.StatusInternalServerError:
+				level = slog.LevelError
+			case status >= http.StatusBadRequest:
+				level = slog.LevelWarn
+			}
+			l.LogAttrs(ctx, level, "request", attrs...)
+
+			return err
+		}
+	}
+}
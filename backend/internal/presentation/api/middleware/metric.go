@@ -0,0 +1,30 @@
+package middleware
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+var meter metric.Meter = otel.Meter("github.com/haebeal/datti/internal/presentation/api/middleware")
+
+var authVerifyDuration, _ = meter.Float64Histogram(
+	"datti.auth.verify.duration",
+	metric.WithDescription("認証プロバイダーでのアクセストークンの検証に要した時間"),
+	metric.WithUnit("s"),
+)
+
+// recordAuthVerifyDuration アクセストークンの検証時間を記録する
+func recordAuthVerifyDuration(ctx context.Context, provider string, d time.Duration, err error) {
+	result := "success"
+	if err != nil {
+		result = "failure"
+	}
+	authVerifyDuration.Record(ctx, d.Seconds(), metric.WithAttributes(
+		attribute.String("auth.provider", provider),
+		attribute.String("result", result),
+	))
+}
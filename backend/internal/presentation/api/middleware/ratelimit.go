@@ -0,0 +1,185 @@
+package middleware
+
+import (
+	"context"
+	"log/slog"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/haebeal/datti/internal/logger"
+	"github.com/labstack/echo/v4"
+)
+
+// RateLimitStore トークンバケットの状態を保持するストア
+// 複数インスタンスで制限を共有する場合は共有ストアの実装に差し替える
+type RateLimitStore interface {
+	// Take keyのバケットからトークンを1つ取り出す
+	// 取り出せなかった場合はallowed=falseと次のトークンが補充されるまでの時間を返す
+	Take(ctx context.Context, key string, rate float64, burst int) (allowed bool, retryAfter time.Duration, err error)
+}
+
+type RateLimitMiddlewareConfig struct {
+	Store RateLimitStore
+	// Rate 1秒あたりに補充されるトークン数
+	Rate float64
+	// Burst バケットの容量 (連続して受け付けるリクエスト数)
+	Burst int
+	// KeyFunc 制限単位のキーを返す (falseの場合は制限しない)
+	KeyFunc func(c echo.Context) (string, bool)
+}
+
+// RateLimitMiddleware トークンバケット方式のレート制限ミドルウェア
+func RateLimitMiddleware(cfg RateLimitMiddlewareConfig) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			key, ok := cfg.KeyFunc(c)
+			if !ok {
+				return next(c)
+			}
+
+			allowed, retryAfter, err := cfg.Store.Take(c.Request().Context(), key, cfg.Rate, cfg.Burst)
+			if err != nil {
+				// ストアの障害時はリクエストを制限しない
+				logger.FromContext(c.Request().Context()).WarnContext(c.Request().Context(), "Rate limit store error", slog.Any("error", err))
+				return next(c)
+			}
+			if !allowed {
+				seconds := int(math.Ceil(retryAfter.Seconds()))
+				c.Response().Header().Set(echo.HeaderRetryAfter, strconv.Itoa(max(seconds, 1)))
+				return echo.NewHTTPError(http.StatusTooManyRequests, "リクエストが多すぎます。しばらくしてから再度お試しください")
+			}
+
+			return next(c)
+		}
+	}
+}
+
+// RateLimitKeyByUserID 認証済みユーザーのIDを制限単位とする
+func RateLimitKeyByUserID(c echo.Context) (string, bool) {
+	uid, ok := c.Get("uid").(string)
+	if !ok || uid == "" {
+		return "", false
+	}
+	return "uid:" + uid, true
+}
+
+// RateLimitKeyByIP クライアントのIPアドレスを制限単位とする
+// IPアドレスはEchoのIPExtractor (NewIPExtractor) で取得する
+func RateLimitKeyByIP(c echo.Context) (string, bool) {
+	return "ip:" + c.RealIP(), true
+}
+
+// RateLimitKeyByIPForPaths 指定したパスで始まるリクエストのみクライアントのIPアドレスを制限単位とする
+// 認証済みのルートはサーバーサイドからのリクエストが同じIPアドレスを共有するため、ユーザー単位で制限する
+func RateLimitKeyByIPForPaths(paths []string) func(c echo.Context) (string, bool) {
+	return func(c echo.Context) (string, bool) {
+		path := c.Request().URL.Path
+		for _, p := range paths {
+			if strings.HasPrefix(path, p) {
+				return RateLimitKeyByIP(c)
+			}
+		}
+		return "", false
+	}
+}
+
+// NewIPExtractor 信頼するプロキシを経由したリクエストのみX-Forwarded-ForからクライアントのIPアドレスを取得する
+// trustedProxiesはCIDR形式で、指定しない場合はヘッダーを無視して接続元のIPアドレスを使用する
+func NewIPExtractor(trustedProxies []string) (echo.IPExtractor, error) {
+	if len(trustedProxies) == 0 {
+		return echo.ExtractIPDirect(), nil
+	}
+
+	// 既定で信頼するループバックやプライベートネットワークも明示的に指定したもののみ信頼する
+	options := []echo.TrustOption{
+		echo.TrustLoopback(false),
+		echo.TrustLinkLocal(false),
+		echo.TrustPrivateNet(false),
+	}
+	for _, proxy := range trustedProxies {
+		_, ipNet, err := net.ParseCIDR(proxy)
+		if err != nil {
+			return nil, err
+		}
+		options = append(options, echo.TrustIPRange(ipNet))
+	}
+
+	return echo.ExtractIPFromXFFHeader(options...), nil
+}
+
+// memoryRateLimitSweepInterval 使われていないバケットを削除する間隔
+const memoryRateLimitSweepInterval = time.Minute
+
+// MemoryRateLimitStore プロセス内でバケットを保持するRateLimitStoreの実装
+type MemoryRateLimitStore struct {
+	mu        sync.Mutex
+	buckets   map[string]*tokenBucket
+	lastSweep time.Time
+}
+
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+	rate   float64
+	burst  int
+}
+
+// NewMemoryRateLimitStore MemoryRateLimitStoreのファクトリ関数
+func NewMemoryRateLimitStore() *MemoryRateLimitStore {
+	return &MemoryRateLimitStore{
+		buckets:   make(map[string]*tokenBucket),
+		lastSweep: time.Now(),
+	}
+}
+
+// Take keyのバケットからトークンを1つ取り出す
+func (s *MemoryRateLimitStore) Take(_ context.Context, key string, rate float64, burst int) (bool, time.Duration, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	s.sweep(now)
+
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &tokenBucket{tokens: float64(burst), last: now, rate: rate, burst: burst}
+		s.buckets[key] = b
+	}
+
+	// 経過時間に応じてトークンを補充
+	elapsed := now.Sub(b.last).Seconds()
+	b.tokens = math.Min(float64(burst), b.tokens+elapsed*rate)
+	b.last = now
+	b.rate = rate
+	b.burst = burst
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0, nil
+	}
+
+	if rate <= 0 {
+		return false, memoryRateLimitSweepInterval, nil
+	}
+	wait := time.Duration((1 - b.tokens) / rate * float64(time.Second))
+	return false, wait, nil
+}
+
+// sweep 満タンまで補充されたバケットを削除する
+func (s *MemoryRateLimitStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < memoryRateLimitSweepInterval {
+		return
+	}
+	s.lastSweep = now
+
+	for key, b := range s.buckets {
+		if b.tokens+now.Sub(b.last).Seconds()*b.rate >= float64(b.burst) {
+			delete(s.buckets, key)
+		}
+	}
+}
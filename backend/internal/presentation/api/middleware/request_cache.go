@@ -0,0 +1,24 @@
+package middleware
+
+import (
+	"context"
+
+	"github.com/labstack/echo/v4"
+)
+
+type RequestCacheMiddlewareConfig struct {
+	// WithCache リクエスト単位のキャッシュをcontextに格納する関数
+	WithCache func(ctx context.Context) context.Context
+}
+
+// RequestCacheMiddleware リクエストの処理中だけ有効なキャッシュをcontextに格納するミドルウェア
+// キャッシュはリクエストの終了とともに破棄される
+func RequestCacheMiddleware(cfg RequestCacheMiddlewareConfig) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+			c.SetRequest(req.WithContext(cfg.WithCache(req.Context())))
+			return next(c)
+		}
+	}
+}
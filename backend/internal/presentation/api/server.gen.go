@@ -36,7 +36,13 @@ type ServerInterface interface {
 	GroupGet(ctx echo.Context, id string) error
 	// グループの更新
 	// (PUT /groups/{id})
-	GroupUpdate(ctx echo.Context, id string) error
+	GroupUpdate(ctx echo.Context, id string, params GroupUpdateParams) error
+	// ゲストメンバー一覧の取得
+	// (GET /groups/{id}/guests)
+	GuestList(ctx echo.Context, id string) error
+	// ゲストメンバーの追加
+	// (POST /groups/{id}/guests)
+	GuestCreate(ctx echo.Context, id string) error
 	// グループ内の立て替え一覧取得
 	// (GET /groups/{id}/lendings)
 	LendingGetAll(ctx echo.Context, id string, params LendingGetAllParams) error
@@ -51,7 +57,19 @@ type ServerInterface interface {
 	LendingGet(ctx echo.Context, id string, lendingId string) error
 	// グループ内の立て替え更新
 	// (PUT /groups/{id}/lendings/{lendingId})
-	LendingUpdate(ctx echo.Context, id string, lendingId string) error
+	LendingUpdate(ctx echo.Context, id string, lendingId string, params LendingUpdateParams) error
+	// グループ内の立て替えのコメント一覧取得
+	// (GET /groups/{id}/lendings/{lendingId}/comments)
+	CommentList(ctx echo.Context, id string, lendingId string, params CommentListParams) error
+	// グループ内の立て替えへのコメント投稿
+	// (POST /groups/{id}/lendings/{lendingId}/comments)
+	CommentCreate(ctx echo.Context, id string, lendingId string) error
+	// グループ内の立て替えのコメント削除
+	// (DELETE /groups/{id}/lendings/{lendingId}/comments/{commentId})
+	CommentDelete(ctx echo.Context, id string, lendingId string, commentId string) error
+	// グループ内の立て替えのコメント編集
+	// (PUT /groups/{id}/lendings/{lendingId}/comments/{commentId})
+	CommentUpdate(ctx echo.Context, id string, lendingId string, commentId string) error
 	// グループメンバー一覧の取得
 	// (GET /groups/{id}/members)
 	GroupGetMembers(ctx echo.Context, id string) error
@@ -61,9 +79,45 @@ type ServerInterface interface {
 	// グループメンバーの削除
 	// (DELETE /groups/{id}/members/{userId})
 	GroupRemoveMember(ctx echo.Context, id string, userId string) error
+	// グループの変更の購読
+	// (GET /groups/{id}/stream)
+	GroupStream(ctx echo.Context, id string) error
+	// ゲストの引き継ぎ
+	// (POST /guests/claim)
+	GuestClaim(ctx echo.Context) error
 	// ヘルスチェック
 	// (GET /health)
 	HealthCheck(ctx echo.Context) error
+	// 立て替え一覧取得
+	// (GET /lendings)
+	LendingList(ctx echo.Context, params LendingListParams) error
+	// 個人間の立て替え作成
+	// (POST /lendings)
+	LendingCreatePersonal(ctx echo.Context) error
+	// 個人間の立て替え削除
+	// (DELETE /lendings/{lendingId})
+	LendingDeletePersonal(ctx echo.Context, lendingId string) error
+	// 個人間の立て替え取得
+	// (GET /lendings/{lendingId})
+	LendingGetPersonal(ctx echo.Context, lendingId string) error
+	// 個人間の立て替え更新
+	// (PUT /lendings/{lendingId})
+	LendingUpdatePersonal(ctx echo.Context, lendingId string, params LendingUpdatePersonalParams) error
+	// 個人間の立て替えのコメント一覧取得
+	// (GET /lendings/{lendingId}/comments)
+	CommentListPersonal(ctx echo.Context, lendingId string, params CommentListPersonalParams) error
+	// 個人間の立て替えへのコメント投稿
+	// (POST /lendings/{lendingId}/comments)
+	CommentCreatePersonal(ctx echo.Context, lendingId string) error
+	// 個人間の立て替えのコメント削除
+	// (DELETE /lendings/{lendingId}/comments/{commentId})
+	CommentDeletePersonal(ctx echo.Context, lendingId string, commentId string) error
+	// 個人間の立て替えのコメント編集
+	// (PUT /lendings/{lendingId}/comments/{commentId})
+	CommentUpdatePersonal(ctx echo.Context, lendingId string, commentId string) error
+	// レディネスチェック
+	// (GET /ready)
+	HealthReady(ctx echo.Context) error
 	// 全ての返済の取得
 	// (GET /repayments)
 	RepaymentGetAll(ctx echo.Context, params RepaymentGetAllParams) error
@@ -78,16 +132,40 @@ type ServerInterface interface {
 	RepaymentGet(ctx echo.Context, id string) error
 	// 返済の更新
 	// (PUT /repayments/{id})
-	RepaymentUpdate(ctx echo.Context, id string) error
+	RepaymentUpdate(ctx echo.Context, id string, params RepaymentUpdateParams) error
+	// 所属グループ横断の立て替え検索
+	// (GET /search)
+	LendingSearch(ctx echo.Context, params LendingSearchParams) error
 	// ユーザー検索
 	// (GET /users)
 	UserSearch(ctx echo.Context, params UserSearchParams) error
+	// 退会
+	// (DELETE /users/me)
+	UserDeleteMe(ctx echo.Context) error
 	// 自身のユーザー情報取得
 	// (GET /users/me)
 	UserGetMe(ctx echo.Context) error
 	// 自身のユーザー情報更新
 	// (PUT /users/me)
 	UserUpdateMe(ctx echo.Context) error
+	// 連絡先一覧の取得
+	// (GET /users/me/contacts)
+	ContactList(ctx echo.Context) error
+	// 連絡先の追加
+	// (POST /users/me/contacts)
+	ContactAdd(ctx echo.Context) error
+	// 連絡先の削除
+	// (DELETE /users/me/contacts/{userId})
+	ContactRemove(ctx echo.Context, userId string) error
+	// 自身のデータのエクスポート
+	// (GET /users/me/export)
+	UserExportMe(ctx echo.Context) error
+	// プライバシー設定の取得
+	// (GET /users/me/privacy)
+	UserGetPrivacy(ctx echo.Context) error
+	// プライバシー設定の更新
+	// (PUT /users/me/privacy)
+	UserUpdatePrivacy(ctx echo.Context) error
 	// ユーザー情報取得
 	// (GET /users/{id})
 	UserGet(ctx echo.Context, id string) error
@@ -128,6 +206,13 @@ func (w *ServerInterfaceWrapper) CreditsList(ctx echo.Context) error {
 
 	// Parameter object where we will unmarshal all parameters from the context
 	var params CreditsListParams
+	// ------------- Optional query parameter "status" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "status", ctx.QueryParams(), &params.Status)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter status: %s", err))
+	}
+
 	// ------------- Optional query parameter "order_by" -------------
 
 	err = runtime.BindQueryParameter("form", true, false, "order_by", ctx.QueryParams(), &params.OrderBy)
@@ -211,8 +296,64 @@ func (w *ServerInterfaceWrapper) GroupUpdate(ctx echo.Context) error {
 
 	ctx.Set(BearerAuthScopes, []string{})
 
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GroupUpdateParams
+
+	headers := ctx.Request().Header
+	// ------------- Optional header parameter "If-Match" -------------
+	if valueList, found := headers[http.CanonicalHeaderKey("If-Match")]; found {
+		var IfMatch string
+		n := len(valueList)
+		if n != 1 {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Expected one value for If-Match, got %d", n))
+		}
+
+		err = runtime.BindStyledParameterWithOptions("simple", "If-Match", valueList[0], &IfMatch, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationHeader, Explode: false, Required: false})
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter If-Match: %s", err))
+		}
+
+		params.IfMatch = &IfMatch
+	}
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.GroupUpdate(ctx, id, params)
+	return err
+}
+
+// GuestList converts echo context to params.
+func (w *ServerInterfaceWrapper) GuestList(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "id" -------------
+	var id string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", ctx.Param("id"), &id, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter id: %s", err))
+	}
+
+	ctx.Set(BearerAuthScopes, []string{})
+
 	// Invoke the callback with all the unmarshaled arguments
-	err = w.Handler.GroupUpdate(ctx, id)
+	err = w.Handler.GuestList(ctx, id)
+	return err
+}
+
+// GuestCreate converts echo context to params.
+func (w *ServerInterfaceWrapper) GuestCreate(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "id" -------------
+	var id string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", ctx.Param("id"), &id, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter id: %s", err))
+	}
+
+	ctx.Set(BearerAuthScopes, []string{})
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.GuestCreate(ctx, id)
 	return err
 }
 
@@ -341,8 +482,164 @@ func (w *ServerInterfaceWrapper) LendingUpdate(ctx echo.Context) error {
 
 	ctx.Set(BearerAuthScopes, []string{})
 
+	// Parameter object where we will unmarshal all parameters from the context
+	var params LendingUpdateParams
+
+	headers := ctx.Request().Header
+	// ------------- Optional header parameter "If-Match" -------------
+	if valueList, found := headers[http.CanonicalHeaderKey("If-Match")]; found {
+		var IfMatch string
+		n := len(valueList)
+		if n != 1 {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Expected one value for If-Match, got %d", n))
+		}
+
+		err = runtime.BindStyledParameterWithOptions("simple", "If-Match", valueList[0], &IfMatch, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationHeader, Explode: false, Required: false})
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter If-Match: %s", err))
+		}
+
+		params.IfMatch = &IfMatch
+	}
+
 	// Invoke the callback with all the unmarshaled arguments
-	err = w.Handler.LendingUpdate(ctx, id, lendingId)
+	err = w.Handler.LendingUpdate(ctx, id, lendingId, params)
+	return err
+}
+
+// CommentList converts echo context to params.
+func (w *ServerInterfaceWrapper) CommentList(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "id" -------------
+	var id string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", ctx.Param("id"), &id, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter id: %s", err))
+	}
+
+	// ------------- Path parameter "lendingId" -------------
+	var lendingId string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "lendingId", ctx.Param("lendingId"), &lendingId, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter lendingId: %s", err))
+	}
+
+	ctx.Set(BearerAuthScopes, []string{})
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params CommentListParams
+	// ------------- Optional query parameter "limit" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "limit", ctx.QueryParams(), &params.Limit)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter limit: %s", err))
+	}
+
+	// ------------- Optional query parameter "cursor" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "cursor", ctx.QueryParams(), &params.Cursor)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter cursor: %s", err))
+	}
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.CommentList(ctx, id, lendingId, params)
+	return err
+}
+
+// CommentCreate converts echo context to params.
+func (w *ServerInterfaceWrapper) CommentCreate(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "id" -------------
+	var id string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", ctx.Param("id"), &id, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter id: %s", err))
+	}
+
+	// ------------- Path parameter "lendingId" -------------
+	var lendingId string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "lendingId", ctx.Param("lendingId"), &lendingId, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter lendingId: %s", err))
+	}
+
+	ctx.Set(BearerAuthScopes, []string{})
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.CommentCreate(ctx, id, lendingId)
+	return err
+}
+
+// CommentDelete converts echo context to params.
+func (w *ServerInterfaceWrapper) CommentDelete(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "id" -------------
+	var id string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", ctx.Param("id"), &id, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter id: %s", err))
+	}
+
+	// ------------- Path parameter "lendingId" -------------
+	var lendingId string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "lendingId", ctx.Param("lendingId"), &lendingId, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter lendingId: %s", err))
+	}
+
+	// ------------- Path parameter "commentId" -------------
+	var commentId string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "commentId", ctx.Param("commentId"), &commentId, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter commentId: %s", err))
+	}
+
+	ctx.Set(BearerAuthScopes, []string{})
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.CommentDelete(ctx, id, lendingId, commentId)
+	return err
+}
+
+// CommentUpdate converts echo context to params.
+func (w *ServerInterfaceWrapper) CommentUpdate(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "id" -------------
+	var id string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", ctx.Param("id"), &id, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter id: %s", err))
+	}
+
+	// ------------- Path parameter "lendingId" -------------
+	var lendingId string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "lendingId", ctx.Param("lendingId"), &lendingId, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter lendingId: %s", err))
+	}
+
+	// ------------- Path parameter "commentId" -------------
+	var commentId string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "commentId", ctx.Param("commentId"), &commentId, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter commentId: %s", err))
+	}
+
+	ctx.Set(BearerAuthScopes, []string{})
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.CommentUpdate(ctx, id, lendingId, commentId)
 	return err
 }
 
@@ -408,6 +705,35 @@ func (w *ServerInterfaceWrapper) GroupRemoveMember(ctx echo.Context) error {
 	return err
 }
 
+// GroupStream converts echo context to params.
+func (w *ServerInterfaceWrapper) GroupStream(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "id" -------------
+	var id string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", ctx.Param("id"), &id, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter id: %s", err))
+	}
+
+	ctx.Set(BearerAuthScopes, []string{})
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.GroupStream(ctx, id)
+	return err
+}
+
+// GuestClaim converts echo context to params.
+func (w *ServerInterfaceWrapper) GuestClaim(ctx echo.Context) error {
+	var err error
+
+	ctx.Set(BearerAuthScopes, []string{})
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.GuestClaim(ctx)
+	return err
+}
+
 // HealthCheck converts echo context to params.
 func (w *ServerInterfaceWrapper) HealthCheck(ctx echo.Context) error {
 	var err error
@@ -419,6 +745,233 @@ func (w *ServerInterfaceWrapper) HealthCheck(ctx echo.Context) error {
 	return err
 }
 
+// LendingList converts echo context to params.
+func (w *ServerInterfaceWrapper) LendingList(ctx echo.Context) error {
+	var err error
+
+	ctx.Set(BearerAuthScopes, []string{})
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params LendingListParams
+	// ------------- Optional query parameter "limit" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "limit", ctx.QueryParams(), &params.Limit)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter limit: %s", err))
+	}
+
+	// ------------- Optional query parameter "cursor" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "cursor", ctx.QueryParams(), &params.Cursor)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter cursor: %s", err))
+	}
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.LendingList(ctx, params)
+	return err
+}
+
+// LendingCreatePersonal converts echo context to params.
+func (w *ServerInterfaceWrapper) LendingCreatePersonal(ctx echo.Context) error {
+	var err error
+
+	ctx.Set(BearerAuthScopes, []string{})
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.LendingCreatePersonal(ctx)
+	return err
+}
+
+// LendingDeletePersonal converts echo context to params.
+func (w *ServerInterfaceWrapper) LendingDeletePersonal(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "lendingId" -------------
+	var lendingId string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "lendingId", ctx.Param("lendingId"), &lendingId, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter lendingId: %s", err))
+	}
+
+	ctx.Set(BearerAuthScopes, []string{})
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.LendingDeletePersonal(ctx, lendingId)
+	return err
+}
+
+// LendingGetPersonal converts echo context to params.
+func (w *ServerInterfaceWrapper) LendingGetPersonal(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "lendingId" -------------
+	var lendingId string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "lendingId", ctx.Param("lendingId"), &lendingId, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter lendingId: %s", err))
+	}
+
+	ctx.Set(BearerAuthScopes, []string{})
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.LendingGetPersonal(ctx, lendingId)
+	return err
+}
+
+// LendingUpdatePersonal converts echo context to params.
+func (w *ServerInterfaceWrapper) LendingUpdatePersonal(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "lendingId" -------------
+	var lendingId string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "lendingId", ctx.Param("lendingId"), &lendingId, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter lendingId: %s", err))
+	}
+
+	ctx.Set(BearerAuthScopes, []string{})
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params LendingUpdatePersonalParams
+
+	headers := ctx.Request().Header
+	// ------------- Optional header parameter "If-Match" -------------
+	if valueList, found := headers[http.CanonicalHeaderKey("If-Match")]; found {
+		var IfMatch string
+		n := len(valueList)
+		if n != 1 {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Expected one value for If-Match, got %d", n))
+		}
+
+		err = runtime.BindStyledParameterWithOptions("simple", "If-Match", valueList[0], &IfMatch, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationHeader, Explode: false, Required: false})
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter If-Match: %s", err))
+		}
+
+		params.IfMatch = &IfMatch
+	}
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.LendingUpdatePersonal(ctx, lendingId, params)
+	return err
+}
+
+// CommentListPersonal converts echo context to params.
+func (w *ServerInterfaceWrapper) CommentListPersonal(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "lendingId" -------------
+	var lendingId string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "lendingId", ctx.Param("lendingId"), &lendingId, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter lendingId: %s", err))
+	}
+
+	ctx.Set(BearerAuthScopes, []string{})
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params CommentListPersonalParams
+	// ------------- Optional query parameter "limit" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "limit", ctx.QueryParams(), &params.Limit)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter limit: %s", err))
+	}
+
+	// ------------- Optional query parameter "cursor" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "cursor", ctx.QueryParams(), &params.Cursor)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter cursor: %s", err))
+	}
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.CommentListPersonal(ctx, lendingId, params)
+	return err
+}
+
+// CommentCreatePersonal converts echo context to params.
+func (w *ServerInterfaceWrapper) CommentCreatePersonal(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "lendingId" -------------
+	var lendingId string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "lendingId", ctx.Param("lendingId"), &lendingId, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter lendingId: %s", err))
+	}
+
+	ctx.Set(BearerAuthScopes, []string{})
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.CommentCreatePersonal(ctx, lendingId)
+	return err
+}
+
+// CommentDeletePersonal converts echo context to params.
+func (w *ServerInterfaceWrapper) CommentDeletePersonal(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "lendingId" -------------
+	var lendingId string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "lendingId", ctx.Param("lendingId"), &lendingId, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter lendingId: %s", err))
+	}
+
+	// ------------- Path parameter "commentId" -------------
+	var commentId string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "commentId", ctx.Param("commentId"), &commentId, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter commentId: %s", err))
+	}
+
+	ctx.Set(BearerAuthScopes, []string{})
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.CommentDeletePersonal(ctx, lendingId, commentId)
+	return err
+}
+
+// CommentUpdatePersonal converts echo context to params.
+func (w *ServerInterfaceWrapper) CommentUpdatePersonal(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "lendingId" -------------
+	var lendingId string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "lendingId", ctx.Param("lendingId"), &lendingId, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter lendingId: %s", err))
+	}
+
+	// ------------- Path parameter "commentId" -------------
+	var commentId string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "commentId", ctx.Param("commentId"), &commentId, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter commentId: %s", err))
+	}
+
+	ctx.Set(BearerAuthScopes, []string{})
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.CommentUpdatePersonal(ctx, lendingId, commentId)
+	return err
+}
+
+// HealthReady converts echo context to params.
+func (w *ServerInterfaceWrapper) HealthReady(ctx echo.Context) error {
+	var err error
+
+	ctx.Set(BearerAuthScopes, []string{})
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.HealthReady(ctx)
+	return err
+}
+
 // RepaymentGetAll converts echo context to params.
 func (w *ServerInterfaceWrapper) RepaymentGetAll(ctx echo.Context) error {
 	var err error
@@ -506,8 +1059,55 @@ func (w *ServerInterfaceWrapper) RepaymentUpdate(ctx echo.Context) error {
 
 	ctx.Set(BearerAuthScopes, []string{})
 
+	// Parameter object where we will unmarshal all parameters from the context
+	var params RepaymentUpdateParams
+
+	headers := ctx.Request().Header
+	// ------------- Optional header parameter "If-Match" -------------
+	if valueList, found := headers[http.CanonicalHeaderKey("If-Match")]; found {
+		var IfMatch string
+		n := len(valueList)
+		if n != 1 {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Expected one value for If-Match, got %d", n))
+		}
+
+		err = runtime.BindStyledParameterWithOptions("simple", "If-Match", valueList[0], &IfMatch, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationHeader, Explode: false, Required: false})
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter If-Match: %s", err))
+		}
+
+		params.IfMatch = &IfMatch
+	}
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.RepaymentUpdate(ctx, id, params)
+	return err
+}
+
+// LendingSearch converts echo context to params.
+func (w *ServerInterfaceWrapper) LendingSearch(ctx echo.Context) error {
+	var err error
+
+	ctx.Set(BearerAuthScopes, []string{})
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params LendingSearchParams
+	// ------------- Required query parameter "q" -------------
+
+	err = runtime.BindQueryParameter("form", true, true, "q", ctx.QueryParams(), &params.Q)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter q: %s", err))
+	}
+
+	// ------------- Optional query parameter "limit" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "limit", ctx.QueryParams(), &params.Limit)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter limit: %s", err))
+	}
+
 	// Invoke the callback with all the unmarshaled arguments
-	err = w.Handler.RepaymentUpdate(ctx, id)
+	err = w.Handler.LendingSearch(ctx, params)
 	return err
 }
 
@@ -545,6 +1145,17 @@ func (w *ServerInterfaceWrapper) UserSearch(ctx echo.Context) error {
 	return err
 }
 
+// UserDeleteMe converts echo context to params.
+func (w *ServerInterfaceWrapper) UserDeleteMe(ctx echo.Context) error {
+	var err error
+
+	ctx.Set(BearerAuthScopes, []string{})
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.UserDeleteMe(ctx)
+	return err
+}
+
 // UserGetMe converts echo context to params.
 func (w *ServerInterfaceWrapper) UserGetMe(ctx echo.Context) error {
 	var err error
@@ -567,6 +1178,79 @@ func (w *ServerInterfaceWrapper) UserUpdateMe(ctx echo.Context) error {
 	return err
 }
 
+// ContactList converts echo context to params.
+func (w *ServerInterfaceWrapper) ContactList(ctx echo.Context) error {
+	var err error
+
+	ctx.Set(BearerAuthScopes, []string{})
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.ContactList(ctx)
+	return err
+}
+
+// ContactAdd converts echo context to params.
+func (w *ServerInterfaceWrapper) ContactAdd(ctx echo.Context) error {
+	var err error
+
+	ctx.Set(BearerAuthScopes, []string{})
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.ContactAdd(ctx)
+	return err
+}
+
+// ContactRemove converts echo context to params.
+func (w *ServerInterfaceWrapper) ContactRemove(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "userId" -------------
+	var userId string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "userId", ctx.Param("userId"), &userId, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter userId: %s", err))
+	}
+
+	ctx.Set(BearerAuthScopes, []string{})
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.ContactRemove(ctx, userId)
+	return err
+}
+
+// UserExportMe converts echo context to params.
+func (w *ServerInterfaceWrapper) UserExportMe(ctx echo.Context) error {
+	var err error
+
+	ctx.Set(BearerAuthScopes, []string{})
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.UserExportMe(ctx)
+	return err
+}
+
+// UserGetPrivacy converts echo context to params.
+func (w *ServerInterfaceWrapper) UserGetPrivacy(ctx echo.Context) error {
+	var err error
+
+	ctx.Set(BearerAuthScopes, []string{})
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.UserGetPrivacy(ctx)
+	return err
+}
+
+// UserUpdatePrivacy converts echo context to params.
+func (w *ServerInterfaceWrapper) UserUpdatePrivacy(ctx echo.Context) error {
+	var err error
+
+	ctx.Set(BearerAuthScopes, []string{})
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.UserUpdatePrivacy(ctx)
+	return err
+}
+
 // UserGet converts echo context to params.
 func (w *ServerInterfaceWrapper) UserGet(ctx echo.Context) error {
 	var err error
@@ -621,23 +1305,49 @@ func RegisterHandlersWithBaseURL(router EchoRouter, si ServerInterface, baseURL
 	router.DELETE(baseURL+"/groups/:id", wrapper.GroupDelete)
 	router.GET(baseURL+"/groups/:id", wrapper.GroupGet)
 	router.PUT(baseURL+"/groups/:id", wrapper.GroupUpdate)
+	router.GET(baseURL+"/groups/:id/guests", wrapper.GuestList)
+	router.POST(baseURL+"/groups/:id/guests", wrapper.GuestCreate)
 	router.GET(baseURL+"/groups/:id/lendings", wrapper.LendingGetAll)
 	router.POST(baseURL+"/groups/:id/lendings", wrapper.LendingCreate)
 	router.DELETE(baseURL+"/groups/:id/lendings/:lendingId", wrapper.LendingDelete)
 	router.GET(baseURL+"/groups/:id/lendings/:lendingId", wrapper.LendingGet)
 	router.PUT(baseURL+"/groups/:id/lendings/:lendingId", wrapper.LendingUpdate)
+	router.GET(baseURL+"/groups/:id/lendings/:lendingId/comments", wrapper.CommentList)
+	router.POST(baseURL+"/groups/:id/lendings/:lendingId/comments", wrapper.CommentCreate)
+	router.DELETE(baseURL+"/groups/:id/lendings/:lendingId/comments/:commentId", wrapper.CommentDelete)
+	router.PUT(baseURL+"/groups/:id/lendings/:lendingId/comments/:commentId", wrapper.CommentUpdate)
 	router.GET(baseURL+"/groups/:id/members", wrapper.GroupGetMembers)
 	router.POST(baseURL+"/groups/:id/members", wrapper.GroupAddMember)
 	router.DELETE(baseURL+"/groups/:id/members/:userId", wrapper.GroupRemoveMember)
+	router.GET(baseURL+"/groups/:id/stream", wrapper.GroupStream)
+	router.POST(baseURL+"/guests/claim", wrapper.GuestClaim)
 	router.GET(baseURL+"/health", wrapper.HealthCheck)
+	router.GET(baseURL+"/lendings", wrapper.LendingList)
+	router.POST(baseURL+"/lendings", wrapper.LendingCreatePersonal)
+	router.DELETE(baseURL+"/lendings/:lendingId", wrapper.LendingDeletePersonal)
+	router.GET(baseURL+"/lendings/:lendingId", wrapper.LendingGetPersonal)
+	router.PUT(baseURL+"/lendings/:lendingId", wrapper.LendingUpdatePersonal)
+	router.GET(baseURL+"/lendings/:lendingId/comments", wrapper.CommentListPersonal)
+	router.POST(baseURL+"/lendings/:lendingId/comments", wrapper.CommentCreatePersonal)
+	router.DELETE(baseURL+"/lendings/:lendingId/comments/:commentId", wrapper.CommentDeletePersonal)
+	router.PUT(baseURL+"/lendings/:lendingId/comments/:commentId", wrapper.CommentUpdatePersonal)
+	router.GET(baseURL+"/ready", wrapper.HealthReady)
 	router.GET(baseURL+"/repayments", wrapper.RepaymentGetAll)
 	router.POST(baseURL+"/repayments", wrapper.RepaymentCreate)
 	router.DELETE(baseURL+"/repayments/:id", wrapper.RepaymentDelete)
 	router.GET(baseURL+"/repayments/:id", wrapper.RepaymentGet)
 	router.PUT(baseURL+"/repayments/:id", wrapper.RepaymentUpdate)
+	router.GET(baseURL+"/search", wrapper.LendingSearch)
 	router.GET(baseURL+"/users", wrapper.UserSearch)
+	router.DELETE(baseURL+"/users/me", wrapper.UserDeleteMe)
 	router.GET(baseURL+"/users/me", wrapper.UserGetMe)
 	router.PUT(baseURL+"/users/me", wrapper.UserUpdateMe)
+	router.GET(baseURL+"/users/me/contacts", wrapper.ContactList)
+	router.POST(baseURL+"/users/me/contacts", wrapper.ContactAdd)
+	router.DELETE(baseURL+"/users/me/contacts/:userId", wrapper.ContactRemove)
+	router.GET(baseURL+"/users/me/export", wrapper.UserExportMe)
+	router.GET(baseURL+"/users/me/privacy", wrapper.UserGetPrivacy)
+	router.PUT(baseURL+"/users/me/privacy", wrapper.UserUpdatePrivacy)
 	router.GET(baseURL+"/users/:id", wrapper.UserGet)
 
 }
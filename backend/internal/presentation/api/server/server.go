@@ -9,8 +9,25 @@ type LendingHandler interface {
 	Create(c echo.Context, id string) error
 	Get(c echo.Context, id string, lendingId string) error
 	GetByQuery(c echo.Context, id string, params api.LendingGetAllParams) error
-	Update(c echo.Context, id string, lendingId string) error
+	Update(c echo.Context, id string, lendingId string, params api.LendingUpdateParams) error
 	Delete(c echo.Context, id string, lendingId string) error
+	Search(c echo.Context, params api.LendingSearchParams) error
+	List(c echo.Context, params api.LendingListParams) error
+	CreatePersonal(c echo.Context) error
+	GetPersonal(c echo.Context, lendingId string) error
+	UpdatePersonal(c echo.Context, lendingId string, params api.LendingUpdatePersonalParams) error
+	DeletePersonal(c echo.Context, lendingId string) error
+}
+
+type CommentHandler interface {
+	List(c echo.Context, id string, lendingId string, params api.CommentListParams) error
+	Create(c echo.Context, id string, lendingId string) error
+	Update(c echo.Context, id string, lendingId string, commentId string) error
+	Delete(c echo.Context, id string, lendingId string, commentId string) error
+	ListPersonal(c echo.Context, lendingId string, params api.CommentListPersonalParams) error
+	CreatePersonal(c echo.Context, lendingId string) error
+	UpdatePersonal(c echo.Context, lendingId string, commentId string) error
+	DeletePersonal(c echo.Context, lendingId string, commentId string) error
 }
 
 type CreditHandler interface {
@@ -19,13 +36,14 @@ type CreditHandler interface {
 
 type HealthHandler interface {
 	Check(c echo.Context) error
+	Ready(c echo.Context) error
 }
 
 type RepaymentHandler interface {
 	Create(c echo.Context) error
 	GetByQuery(c echo.Context, params api.RepaymentGetAllParams) error
 	Get(c echo.Context, id string) error
-	Update(c echo.Context, id string) error
+	Update(c echo.Context, id string, params api.RepaymentUpdateParams) error
 	Delete(c echo.Context, id string) error
 }
 
@@ -33,11 +51,18 @@ type GroupHandler interface {
 	Create(c echo.Context) error
 	GetAll(c echo.Context) error
 	Get(c echo.Context, id string) error
-	Update(c echo.Context, id string) error
+	Update(c echo.Context, id string, params api.GroupUpdateParams) error
 	Delete(c echo.Context, id string) error
 	AddMember(c echo.Context, id string) error
 	RemoveMember(c echo.Context, id string, userId string) error
 	GetMembers(c echo.Context, id string) error
+	Stream(c echo.Context, id string) error
+}
+
+type GuestHandler interface {
+	List(c echo.Context, id string) error
+	Create(c echo.Context, id string) error
+	Claim(c echo.Context) error
 }
 
 type UserHandler interface {
@@ -45,6 +70,16 @@ type UserHandler interface {
 	Get(c echo.Context, id string) error
 	GetMe(c echo.Context) error
 	UpdateMe(c echo.Context) error
+	DeleteMe(c echo.Context) error
+	ExportMe(c echo.Context) error
+	GetPrivacy(c echo.Context) error
+	UpdatePrivacy(c echo.Context) error
+}
+
+type ContactHandler interface {
+	List(c echo.Context) error
+	Add(c echo.Context) error
+	Remove(c echo.Context, userId string) error
 }
 
 type AuthHandler interface {
@@ -53,24 +88,30 @@ type AuthHandler interface {
 }
 
 type Server struct {
-	lh LendingHandler
-	ch CreditHandler
-	hh HealthHandler
-	rh RepaymentHandler
-	gh GroupHandler
-	uh UserHandler
-	ah AuthHandler
-}
-
-func NewServer(lh LendingHandler, ch CreditHandler, hh HealthHandler, rh RepaymentHandler, gh GroupHandler, uh UserHandler, ah AuthHandler) api.ServerInterface {
+	lh  LendingHandler
+	cmh CommentHandler
+	ch  CreditHandler
+	hh  HealthHandler
+	rh  RepaymentHandler
+	gh  GroupHandler
+	gsh GuestHandler
+	uh  UserHandler
+	cth ContactHandler
+	ah  AuthHandler
+}
+
+func NewServer(lh LendingHandler, cmh CommentHandler, ch CreditHandler, hh HealthHandler, rh RepaymentHandler, gh GroupHandler, gsh GuestHandler, uh UserHandler, cth ContactHandler, ah AuthHandler) api.ServerInterface {
 	return &Server{
-		lh: lh,
-		ch: ch,
-		hh: hh,
-		rh: rh,
-		gh: gh,
-		uh: uh,
-		ah: ah,
+		lh:  lh,
+		cmh: cmh,
+		ch:  ch,
+		hh:  hh,
+		rh:  rh,
+		gh:  gh,
+		gsh: gsh,
+		uh:  uh,
+		cth: cth,
+		ah:  ah,
 	}
 }
 
@@ -87,14 +128,70 @@ func (s *Server) LendingGetAll(ctx echo.Context, id string, params api.LendingGe
 	return s.lh.GetByQuery(ctx, id, params)
 }
 
-func (s *Server) LendingUpdate(ctx echo.Context, id string, lendingId string) error {
-	return s.lh.Update(ctx, id, lendingId)
+func (s *Server) LendingUpdate(ctx echo.Context, id string, lendingId string, params api.LendingUpdateParams) error {
+	return s.lh.Update(ctx, id, lendingId, params)
 }
 
 func (s *Server) LendingDelete(ctx echo.Context, id string, lendingId string) error {
 	return s.lh.Delete(ctx, id, lendingId)
 }
 
+func (s *Server) LendingSearch(ctx echo.Context, params api.LendingSearchParams) error {
+	return s.lh.Search(ctx, params)
+}
+
+func (s *Server) LendingList(ctx echo.Context, params api.LendingListParams) error {
+	return s.lh.List(ctx, params)
+}
+
+func (s *Server) LendingCreatePersonal(ctx echo.Context) error {
+	return s.lh.CreatePersonal(ctx)
+}
+
+func (s *Server) LendingGetPersonal(ctx echo.Context, lendingId string) error {
+	return s.lh.GetPersonal(ctx, lendingId)
+}
+
+func (s *Server) LendingUpdatePersonal(ctx echo.Context, lendingId string, params api.LendingUpdatePersonalParams) error {
+	return s.lh.UpdatePersonal(ctx, lendingId, params)
+}
+
+func (s *Server) LendingDeletePersonal(ctx echo.Context, lendingId string) error {
+	return s.lh.DeletePersonal(ctx, lendingId)
+}
+
+func (s *Server) CommentList(ctx echo.Context, id string, lendingId string, params api.CommentListParams) error {
+	return s.cmh.List(ctx, id, lendingId, params)
+}
+
+func (s *Server) CommentCreate(ctx echo.Context, id string, lendingId string) error {
+	return s.cmh.Create(ctx, id, lendingId)
+}
+
+func (s *Server) CommentUpdate(ctx echo.Context, id string, lendingId string, commentId string) error {
+	return s.cmh.Update(ctx, id, lendingId, commentId)
+}
+
+func (s *Server) CommentDelete(ctx echo.Context, id string, lendingId string, commentId string) error {
+	return s.cmh.Delete(ctx, id, lendingId, commentId)
+}
+
+func (s *Server) CommentListPersonal(ctx echo.Context, lendingId string, params api.CommentListPersonalParams) error {
+	return s.cmh.ListPersonal(ctx, lendingId, params)
+}
+
+func (s *Server) CommentCreatePersonal(ctx echo.Context, lendingId string) error {
+	return s.cmh.CreatePersonal(ctx, lendingId)
+}
+
+func (s *Server) CommentUpdatePersonal(ctx echo.Context, lendingId string, commentId string) error {
+	return s.cmh.UpdatePersonal(ctx, lendingId, commentId)
+}
+
+func (s *Server) CommentDeletePersonal(ctx echo.Context, lendingId string, commentId string) error {
+	return s.cmh.DeletePersonal(ctx, lendingId, commentId)
+}
+
 func (s *Server) CreditsList(ctx echo.Context, params api.CreditsListParams) error {
 	return s.ch.List(ctx, params)
 }
@@ -103,6 +200,10 @@ func (s *Server) HealthCheck(ctx echo.Context) error {
 	return s.hh.Check(ctx)
 }
 
+func (s *Server) HealthReady(ctx echo.Context) error {
+	return s.hh.Ready(ctx)
+}
+
 func (s *Server) RepaymentCreate(ctx echo.Context) error {
 	return s.rh.Create(ctx)
 }
@@ -115,8 +216,8 @@ func (s *Server) RepaymentGet(ctx echo.Context, id string) error {
 	return s.rh.Get(ctx, id)
 }
 
-func (s *Server) RepaymentUpdate(ctx echo.Context, id string) error {
-	return s.rh.Update(ctx, id)
+func (s *Server) RepaymentUpdate(ctx echo.Context, id string, params api.RepaymentUpdateParams) error {
+	return s.rh.Update(ctx, id, params)
 }
 
 func (s *Server) RepaymentDelete(ctx echo.Context, id string) error {
@@ -135,8 +236,8 @@ func (s *Server) GroupGet(ctx echo.Context, id string) error {
 	return s.gh.Get(ctx, id)
 }
 
-func (s *Server) GroupUpdate(ctx echo.Context, id string) error {
-	return s.gh.Update(ctx, id)
+func (s *Server) GroupUpdate(ctx echo.Context, id string, params api.GroupUpdateParams) error {
+	return s.gh.Update(ctx, id, params)
 }
 
 func (s *Server) GroupDelete(ctx echo.Context, id string) error {
@@ -151,10 +252,26 @@ func (s *Server) GroupGetMembers(ctx echo.Context, id string) error {
 	return s.gh.GetMembers(ctx, id)
 }
 
+func (s *Server) GroupStream(ctx echo.Context, id string) error {
+	return s.gh.Stream(ctx, id)
+}
+
 func (s *Server) GroupRemoveMember(ctx echo.Context, id string, userId string) error {
 	return s.gh.RemoveMember(ctx, id, userId)
 }
 
+func (s *Server) GuestList(ctx echo.Context, id string) error {
+	return s.gsh.List(ctx, id)
+}
+
+func (s *Server) GuestCreate(ctx echo.Context, id string) error {
+	return s.gsh.Create(ctx, id)
+}
+
+func (s *Server) GuestClaim(ctx echo.Context) error {
+	return s.gsh.Claim(ctx)
+}
+
 func (s *Server) UserSearch(ctx echo.Context, params api.UserSearchParams) error {
 	return s.uh.Search(ctx, params)
 }
@@ -171,6 +288,34 @@ func (s *Server) UserUpdateMe(ctx echo.Context) error {
 	return s.uh.UpdateMe(ctx)
 }
 
+func (s *Server) UserDeleteMe(ctx echo.Context) error {
+	return s.uh.DeleteMe(ctx)
+}
+
+func (s *Server) UserExportMe(ctx echo.Context) error {
+	return s.uh.ExportMe(ctx)
+}
+
+func (s *Server) UserGetPrivacy(ctx echo.Context) error {
+	return s.uh.GetPrivacy(ctx)
+}
+
+func (s *Server) UserUpdatePrivacy(ctx echo.Context) error {
+	return s.uh.UpdatePrivacy(ctx)
+}
+
+func (s *Server) ContactList(ctx echo.Context) error {
+	return s.cth.List(ctx)
+}
+
+func (s *Server) ContactAdd(ctx echo.Context) error {
+	return s.cth.Add(ctx)
+}
+
+func (s *Server) ContactRemove(ctx echo.Context, userId string) error {
+	return s.cth.Remove(ctx, userId)
+}
+
 func (s *Server) AuthLogin(ctx echo.Context) error {
 	return s.ah.Login(ctx)
 }
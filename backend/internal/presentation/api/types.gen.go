@@ -11,9 +11,38 @@ const (
 	BearerAuthScopes = "BearerAuth.Scopes"
 )
 
+// Defines values for GroupUpdateEventType.
+const (
+	LendingCreated   GroupUpdateEventType = "lending.created"
+	LendingDeleted   GroupUpdateEventType = "lending.deleted"
+	LendingUpdated   GroupUpdateEventType = "lending.updated"
+	MemberAdded      GroupUpdateEventType = "member.added"
+	MemberRemoved    GroupUpdateEventType = "member.removed"
+	RepaymentCreated GroupUpdateEventType = "repayment.created"
+	RepaymentDeleted GroupUpdateEventType = "repayment.deleted"
+	RepaymentUpdated GroupUpdateEventType = "repayment.updated"
+)
+
 // Defines values for HealthCheckResponseStatus.
 const (
-	Ok HealthCheckResponseStatus = "ok"
+	HealthCheckResponseStatusOk HealthCheckResponseStatus = "ok"
+)
+
+// Defines values for HealthReadyCheckStatus.
+const (
+	HealthReadyCheckStatusError HealthReadyCheckStatus = "error"
+	HealthReadyCheckStatusOk    HealthReadyCheckStatus = "ok"
+)
+
+// Defines values for HealthReadyResponseStatus.
+const (
+	Ok          HealthReadyResponseStatus = "ok"
+	Unavailable HealthReadyResponseStatus = "unavailable"
+)
+
+// Defines values for CreditsListParamsStatus.
+const (
+	Overdue CreditsListParamsStatus = "overdue"
 )
 
 // Defines values for CreditsListParamsOrderBy.
@@ -37,14 +66,99 @@ type AuthSignupResponse struct {
 	Name   string `json:"name"`
 }
 
+// CommentCreateRequest defines model for Comment.CreateRequest.
+type CommentCreateRequest struct {
+	// Body コメント本文（1〜1000文字）
+	Body string `json:"body"`
+}
+
+// CommentPaginatedResponse defines model for Comment.PaginatedResponse.
+type CommentPaginatedResponse struct {
+	Comments []CommentResponse `json:"comments"`
+
+	// HasMore 次ページが存在するかどうか
+	HasMore bool `json:"hasMore"`
+
+	// NextCursor 次ページ用カーソル（次ページがない場合はnull）
+	NextCursor *string `json:"nextCursor"`
+}
+
+// CommentResponse defines model for Comment.Response.
+type CommentResponse struct {
+	Body      string    `json:"body"`
+	CreatedAt time.Time `json:"createdAt"`
+	Id        string    `json:"id"`
+	LendingId string    `json:"lendingId"`
+	UpdatedAt time.Time `json:"updatedAt"`
+
+	// UserAvatar 投稿者のアバター
+	UserAvatar string `json:"userAvatar"`
+
+	// UserId 投稿者のユーザーID
+	UserId string `json:"userId"`
+
+	// UserName 投稿者の名前
+	UserName string `json:"userName"`
+}
+
+// CommentUpdateRequest defines model for Comment.UpdateRequest.
+type CommentUpdateRequest struct {
+	// Body コメント本文（1〜1000文字）
+	Body string `json:"body"`
+}
+
+// ContactAddRequest defines model for Contact.AddRequest.
+type ContactAddRequest struct {
+	UserId string `json:"userId"`
+}
+
+// ContactResponse defines model for Contact.Response.
+type ContactResponse struct {
+	// Added 明示的に追加した連絡先かどうか (falseの場合は同じグループのメンバー)
+	Added  bool   `json:"added"`
+	Avatar string `json:"avatar"`
+	Email  string `json:"email"`
+	Id     string `json:"id"`
+	Name   string `json:"name"`
+}
+
 // Credit defines model for Credit.
 type Credit struct {
-	Amount int64  `json:"amount"`
-	UserId string `json:"userId"`
+	Amount int64 `json:"amount"`
+
+	// OverdueAmount 残高のうち返済期限を過ぎた金額（正=相手が延滞している、負=自分が延滞している）
+	OverdueAmount int64  `json:"overdueAmount"`
+	UserId        string `json:"userId"`
 }
 
-// ErrorResponse defines model for ErrorResponse.
+// ErrorResponse RFC 7807 Problem Details（Content-Type: application/problem+json）
 type ErrorResponse struct {
+	// Code エラーの種類を表す機械可読なコード（bad_request, validation_error, unauthorized, forbidden, not_found, conflict, precondition_failed, precondition_required, unprocessable_entity, too_many_requests, internal_error）
+	Code string `json:"code"`
+
+	// Detail エラーの詳細
+	Detail string `json:"detail"`
+
+	// Errors 入力値エラーの項目ごとの詳細
+	Errors *[]ErrorResponseFieldError `json:"errors,omitempty"`
+
+	// Instance エラーが発生したリクエストのパス
+	Instance *string `json:"instance,omitempty"`
+
+	// Message detailと同じ値（互換性のため）
+	Message string `json:"message"`
+	Status  int32  `json:"status"`
+
+	// Title HTTPステータスの説明
+	Title string `json:"title"`
+
+	// Type 問題の種類を表すURI（現在は常にabout:blank）
+	Type string `json:"type"`
+}
+
+// ErrorResponseFieldError defines model for ErrorResponse.FieldError.
+type ErrorResponseFieldError struct {
+	Field   string `json:"field"`
 	Message string `json:"message"`
 }
 
@@ -56,6 +170,9 @@ type GroupAddMemberRequest struct {
 // GroupCreateRequest defines model for Group.CreateRequest.
 type GroupCreateRequest struct {
 	Name string `json:"name"`
+
+	// PaymentTermsDays 立て替えの既定の支払い期限（立て替えの日付からの日数。返済期限を指定しない立て替えに適用する。省略時は期限を設けない）
+	PaymentTermsDays *int32 `json:"paymentTermsDays,omitempty"`
 }
 
 // GroupCreateResponse defines model for Group.CreateResponse.
@@ -64,7 +181,13 @@ type GroupCreateResponse struct {
 	CreatedBy string    `json:"createdBy"`
 	Id        string    `json:"id"`
 	Name      string    `json:"name"`
-	UpdatedAt time.Time `json:"updatedAt"`
+
+	// PaymentTermsDays 立て替えの既定の支払い期限（立て替えの日付からの日数。期限を設けない場合は省略）
+	PaymentTermsDays *int32    `json:"paymentTermsDays,omitempty"`
+	UpdatedAt        time.Time `json:"updatedAt"`
+
+	// Version 楽観的排他制御のためのバージョン（ETagと同じ値）
+	Version int32 `json:"version"`
 }
 
 // GroupGetAllResponse defines model for Group.GetAllResponse.
@@ -73,7 +196,13 @@ type GroupGetAllResponse struct {
 	CreatedBy string    `json:"createdBy"`
 	Id        string    `json:"id"`
 	Name      string    `json:"name"`
-	UpdatedAt time.Time `json:"updatedAt"`
+
+	// PaymentTermsDays 立て替えの既定の支払い期限（立て替えの日付からの日数。期限を設けない場合は省略）
+	PaymentTermsDays *int32    `json:"paymentTermsDays,omitempty"`
+	UpdatedAt        time.Time `json:"updatedAt"`
+
+	// Version 楽観的排他制御のためのバージョン（ETagと同じ値）
+	Version int32 `json:"version"`
 }
 
 // GroupGetResponse defines model for Group.GetResponse.
@@ -82,20 +211,55 @@ type GroupGetResponse struct {
 	CreatedBy string    `json:"createdBy"`
 	Id        string    `json:"id"`
 	Name      string    `json:"name"`
-	UpdatedAt time.Time `json:"updatedAt"`
+
+	// PaymentTermsDays 立て替えの既定の支払い期限（立て替えの日付からの日数。期限を設けない場合は省略）
+	PaymentTermsDays *int32    `json:"paymentTermsDays,omitempty"`
+	UpdatedAt        time.Time `json:"updatedAt"`
+
+	// Version 楽観的排他制御のためのバージョン（ETagと同じ値）
+	Version int32 `json:"version"`
 }
 
 // GroupMemberResponse defines model for Group.MemberResponse.
 type GroupMemberResponse struct {
 	Avatar string `json:"avatar"`
 	Email  string `json:"email"`
-	Id     string `json:"id"`
-	Name   string `json:"name"`
+
+	// Guest アカウントを持たないゲストメンバーかどうか
+	Guest bool   `json:"guest"`
+	Id    string `json:"id"`
+	Name  string `json:"name"`
 }
 
+// GroupUpdateEvent defines model for Group.UpdateEvent.
+type GroupUpdateEvent struct {
+	GroupId string `json:"groupId"`
+
+	// Id 変更ID（SSEのイベントIDと同じ値）
+	Id         string    `json:"id"`
+	OccurredAt time.Time `json:"occurredAt"`
+
+	// ResourceId 変更された立て替え・返済のID、またはメンバーのユーザーID
+	ResourceId string `json:"resourceId"`
+
+	// Type 変更の種類（SSEのイベント名と同じ値）
+	Type GroupUpdateEventType `json:"type"`
+}
+
+// GroupUpdateEventType 変更の種類（SSEのイベント名と同じ値）
+type GroupUpdateEventType string
+
 // GroupUpdateRequest defines model for Group.UpdateRequest.
 type GroupUpdateRequest struct {
-	Name string `json:"name"`
+	// ClearPaymentTermsDays trueの場合は既定の支払い期限をなくす（paymentTermsDaysと同時には指定できない）
+	ClearPaymentTermsDays *bool  `json:"clearPaymentTermsDays,omitempty"`
+	Name                  string `json:"name"`
+
+	// PaymentTermsDays 立て替えの既定の支払い期限（立て替えの日付からの日数。返済期限を指定しない立て替えに適用する。省略時は現在の支払い期限を変更しない）
+	PaymentTermsDays *int32 `json:"paymentTermsDays,omitempty"`
+
+	// Version 更新元のバージョン（If-Matchヘッダーを指定しない場合は必須で、どちらもない場合は428を返す）
+	Version *int32 `json:"version,omitempty"`
 }
 
 // GroupUpdateResponse defines model for Group.UpdateResponse.
@@ -104,7 +268,34 @@ type GroupUpdateResponse struct {
 	CreatedBy string    `json:"createdBy"`
 	Id        string    `json:"id"`
 	Name      string    `json:"name"`
-	UpdatedAt time.Time `json:"updatedAt"`
+
+	// PaymentTermsDays 立て替えの既定の支払い期限（立て替えの日付からの日数。期限を設けない場合は省略）
+	PaymentTermsDays *int32    `json:"paymentTermsDays,omitempty"`
+	UpdatedAt        time.Time `json:"updatedAt"`
+
+	// Version 楽観的排他制御のためのバージョン（ETagと同じ値）
+	Version int32 `json:"version"`
+}
+
+// GuestClaimRequest defines model for Guest.ClaimRequest.
+type GuestClaimRequest struct {
+	InviteToken string `json:"inviteToken"`
+}
+
+// GuestCreateRequest defines model for Guest.CreateRequest.
+type GuestCreateRequest struct {
+	// Email 指定した場合、同じメールアドレスで新規登録したユーザーに自動的に統合される
+	Email *string `json:"email,omitempty"`
+	Name  string  `json:"name"`
+}
+
+// GuestResponse defines model for Guest.Response.
+type GuestResponse struct {
+	Email       *string `json:"email,omitempty"`
+	GroupId     string  `json:"groupId"`
+	Id          string  `json:"id"`
+	InviteToken string  `json:"inviteToken"`
+	Name        string  `json:"name"`
 }
 
 // HealthCheckResponse defines model for Health.CheckResponse.
@@ -116,12 +307,44 @@ type HealthCheckResponse struct {
 // HealthCheckResponseStatus defines model for HealthCheckResponse.Status.
 type HealthCheckResponseStatus string
 
+// HealthReadyCheck defines model for Health.ReadyCheck.
+type HealthReadyCheck struct {
+	// Message 失敗した場合のエラー内容
+	Message *string `json:"message,omitempty"`
+
+	// Name 確認対象（postgres, auth）
+	Name   string                 `json:"name"`
+	Status HealthReadyCheckStatus `json:"status"`
+}
+
+// HealthReadyCheckStatus defines model for HealthReadyCheck.Status.
+type HealthReadyCheckStatus string
+
+// HealthReadyResponse defines model for Health.ReadyResponse.
+type HealthReadyResponse struct {
+	Checks    []HealthReadyCheck        `json:"checks"`
+	Status    HealthReadyResponseStatus `json:"status"`
+	Timestamp time.Time                 `json:"timestamp"`
+}
+
+// HealthReadyResponseStatus defines model for HealthReadyResponse.Status.
+type HealthReadyResponseStatus string
+
 // LendingCreateRequest defines model for Lending.CreateRequest.
 type LendingCreateRequest struct {
-	Amount    uint64              `json:"amount"`
-	Debts     []LendingDebtParmam `json:"debts"`
-	EventDate time.Time           `json:"eventDate"`
-	Name      string              `json:"name"`
+	Amount uint64              `json:"amount"`
+	Debts  []LendingDebtParmam `json:"debts"`
+
+	// DueDate 返済期限（立て替えの日付以降。省略時はグループの既定の支払い期限を適用し、グループにもない場合は期限を設けない）
+	DueDate   *time.Time `json:"dueDate,omitempty"`
+	EventDate time.Time  `json:"eventDate"`
+	Name      string     `json:"name"`
+
+	// PayerShare 支払い者自身の負担額（payers省略時のみ有効。省略時は金額から債務の合計を差し引いた額）
+	PayerShare *uint64 `json:"payerShare,omitempty"`
+
+	// Payers 支払い者一覧（省略時は作成者が全額を立て替えたものとみなす）
+	Payers *[]LendingPayerParam `json:"payers,omitempty"`
 }
 
 // LendingCreateResponse defines model for Lending.CreateResponse.
@@ -129,10 +352,20 @@ type LendingCreateResponse struct {
 	Amount    uint64              `json:"amount"`
 	CreatedAt time.Time           `json:"createdAt"`
 	Debts     []LendingDebtParmam `json:"debts"`
-	EventDate time.Time           `json:"eventDate"`
-	Id        string              `json:"id"`
-	Name      string              `json:"name"`
-	UpdatedAt time.Time           `json:"updatedAt"`
+
+	// DueDate 返済期限（期限を設けない場合は省略）
+	DueDate   *time.Time `json:"dueDate,omitempty"`
+	EventDate time.Time  `json:"eventDate"`
+	Id        string     `json:"id"`
+	Name      string     `json:"name"`
+
+	// PayerShare 支払い者自身の負担額（金額 = 支払い者の負担額 + 債務の合計）
+	PayerShare uint64              `json:"payerShare"`
+	Payers     []LendingPayerParam `json:"payers"`
+	UpdatedAt  time.Time           `json:"updatedAt"`
+
+	// Version 楽観的排他制御のためのバージョン（ETagと同じ値）
+	Version int32 `json:"version"`
 }
 
 // LendingDebtParmam defines model for Lending.DebtParmam.
@@ -143,16 +376,29 @@ type LendingDebtParmam struct {
 
 // LendingGetAllResponse defines model for Lending.GetAllResponse.
 type LendingGetAllResponse struct {
-	Amount    uint64    `json:"amount"`
-	CreatedAt time.Time `json:"createdAt"`
+	Amount uint64 `json:"amount"`
+
+	// CommentCount コメント数
+	CommentCount int32     `json:"commentCount"`
+	CreatedAt    time.Time `json:"createdAt"`
 
 	// CreatedBy イベント作成者のユーザーID（Firebase UID）
 	CreatedBy string              `json:"createdBy"`
 	Debts     []LendingDebtParmam `json:"debts"`
-	EventDate time.Time           `json:"eventDate"`
-	Id        string              `json:"id"`
-	Name      string              `json:"name"`
-	UpdatedAt time.Time           `json:"updatedAt"`
+
+	// DueDate 返済期限（期限を設けない場合は省略）
+	DueDate   *time.Time `json:"dueDate,omitempty"`
+	EventDate time.Time  `json:"eventDate"`
+	Id        string     `json:"id"`
+	Name      string     `json:"name"`
+
+	// PayerShare 支払い者自身の負担額（金額 = 支払い者の負担額 + 債務の合計）
+	PayerShare uint64              `json:"payerShare"`
+	Payers     []LendingPayerParam `json:"payers"`
+	UpdatedAt  time.Time           `json:"updatedAt"`
+
+	// Version 楽観的排他制御のためのバージョン（ETagと同じ値）
+	Version int32 `json:"version"`
 }
 
 // LendingGetResponse defines model for Lending.GetResponse.
@@ -163,10 +409,63 @@ type LendingGetResponse struct {
 	// CreatedBy イベント作成者のユーザーID（Firebase UID）
 	CreatedBy string              `json:"createdBy"`
 	Debts     []LendingDebtParmam `json:"debts"`
-	EventDate time.Time           `json:"eventDate"`
-	Id        string              `json:"id"`
-	Name      string              `json:"name"`
-	UpdatedAt time.Time           `json:"updatedAt"`
+
+	// DueDate 返済期限（期限を設けない場合は省略）
+	DueDate   *time.Time `json:"dueDate,omitempty"`
+	EventDate time.Time  `json:"eventDate"`
+	Id        string     `json:"id"`
+	Name      string     `json:"name"`
+
+	// PayerShare 支払い者自身の負担額（金額 = 支払い者の負担額 + 債務の合計）
+	PayerShare uint64              `json:"payerShare"`
+	Payers     []LendingPayerParam `json:"payers"`
+	UpdatedAt  time.Time           `json:"updatedAt"`
+
+	// Version 楽観的排他制御のためのバージョン（ETagと同じ値）
+	Version int32 `json:"version"`
+}
+
+// LendingListPaginatedResponse defines model for Lending.ListPaginatedResponse.
+type LendingListPaginatedResponse struct {
+	// HasMore 次ページが存在するかどうか
+	HasMore  bool                  `json:"hasMore"`
+	Lendings []LendingListResponse `json:"lendings"`
+
+	// NextCursor 次ページ用カーソル（次ページがない場合はnull）
+	NextCursor *string `json:"nextCursor"`
+}
+
+// LendingListResponse defines model for Lending.ListResponse.
+type LendingListResponse struct {
+	Amount uint64 `json:"amount"`
+
+	// CommentCount コメント数
+	CommentCount int32     `json:"commentCount"`
+	CreatedAt    time.Time `json:"createdAt"`
+
+	// CreatedBy イベント作成者のユーザーID（Firebase UID）
+	CreatedBy string              `json:"createdBy"`
+	Debts     []LendingDebtParmam `json:"debts"`
+
+	// DueDate 返済期限（期限を設けない場合は省略）
+	DueDate   *time.Time `json:"dueDate,omitempty"`
+	EventDate time.Time  `json:"eventDate"`
+
+	// GroupId 立て替えが属するグループのID（個人間の立て替えの場合は省略）
+	GroupId *string `json:"groupId,omitempty"`
+
+	// GroupName 立て替えが属するグループの名前（個人間の立て替えの場合は省略）
+	GroupName *string `json:"groupName,omitempty"`
+	Id        string  `json:"id"`
+	Name      string  `json:"name"`
+
+	// PayerShare 支払い者自身の負担額（金額 = 支払い者の負担額 + 債務の合計）
+	PayerShare uint64              `json:"payerShare"`
+	Payers     []LendingPayerParam `json:"payers"`
+	UpdatedAt  time.Time           `json:"updatedAt"`
+
+	// Version 楽観的排他制御のためのバージョン（ETagと同じ値）
+	Version int32 `json:"version"`
 }
 
 // LendingPaginatedResponse defines model for Lending.PaginatedResponse.
@@ -179,12 +478,63 @@ type LendingPaginatedResponse struct {
 	NextCursor *string `json:"nextCursor"`
 }
 
+// LendingPayerParam defines model for Lending.PayerParam.
+type LendingPayerParam struct {
+	// Amount 立て替えた金額
+	Amount uint64 `json:"amount"`
+
+	// Share 支払い者自身の負担額
+	Share  uint64 `json:"share"`
+	UserId string `json:"userId"`
+}
+
+// LendingSearchResponse defines model for Lending.SearchResponse.
+type LendingSearchResponse struct {
+	Amount    uint64    `json:"amount"`
+	CreatedAt time.Time `json:"createdAt"`
+
+	// CreatedBy イベント作成者のユーザーID（Firebase UID）
+	CreatedBy string              `json:"createdBy"`
+	Debts     []LendingDebtParmam `json:"debts"`
+
+	// DueDate 返済期限（期限を設けない場合は省略）
+	DueDate   *time.Time `json:"dueDate,omitempty"`
+	EventDate time.Time  `json:"eventDate"`
+	GroupId   string     `json:"groupId"`
+	GroupName string     `json:"groupName"`
+	Id        string     `json:"id"`
+	Name      string     `json:"name"`
+
+	// PayerShare 支払い者自身の負担額（金額 = 支払い者の負担額 + 債務の合計）
+	PayerShare uint64              `json:"payerShare"`
+	Payers     []LendingPayerParam `json:"payers"`
+	UpdatedAt  time.Time           `json:"updatedAt"`
+
+	// Version 楽観的排他制御のためのバージョン（ETagと同じ値）
+	Version int32 `json:"version"`
+}
+
 // LendingUpdateRequest defines model for Lending.UpdateRequest.
 type LendingUpdateRequest struct {
-	Amount    uint64              `json:"amount"`
-	Debts     []LendingDebtParmam `json:"debts"`
-	EventDate time.Time           `json:"eventDate"`
-	Name      string              `json:"name"`
+	Amount uint64 `json:"amount"`
+
+	// ClearDueDate trueの場合は返済期限をなくす（dueDateと同時には指定できない）
+	ClearDueDate *bool               `json:"clearDueDate,omitempty"`
+	Debts        []LendingDebtParmam `json:"debts"`
+
+	// DueDate 返済期限（立て替えの日付以降。省略時は現在の返済期限を変更しない）
+	DueDate   *time.Time `json:"dueDate,omitempty"`
+	EventDate time.Time  `json:"eventDate"`
+	Name      string     `json:"name"`
+
+	// PayerShare 支払い者自身の負担額（payers省略時のみ有効。省略時は金額から債務の合計を差し引いた額）
+	PayerShare *uint64 `json:"payerShare,omitempty"`
+
+	// Payers 支払い者一覧（省略時は作成者が全額を立て替えたものとみなす）
+	Payers *[]LendingPayerParam `json:"payers,omitempty"`
+
+	// Version 更新元のバージョン（If-Matchヘッダーを指定しない場合は必須で、どちらもない場合は428を返す）
+	Version *int32 `json:"version,omitempty"`
 }
 
 // LendingUpdateResponse defines model for Lending.UpdateResponse.
@@ -192,10 +542,20 @@ type LendingUpdateResponse struct {
 	Amount    uint64              `json:"amount"`
 	CreatedAt time.Time           `json:"createdAt"`
 	Debts     []LendingDebtParmam `json:"debts"`
-	EventDate time.Time           `json:"eventDate"`
-	Id        string              `json:"id"`
-	Name      string              `json:"name"`
-	UpdatedAt time.Time           `json:"updatedAt"`
+
+	// DueDate 返済期限（期限を設けない場合は省略）
+	DueDate   *time.Time `json:"dueDate,omitempty"`
+	EventDate time.Time  `json:"eventDate"`
+	Id        string     `json:"id"`
+	Name      string     `json:"name"`
+
+	// PayerShare 支払い者自身の負担額（金額 = 支払い者の負担額 + 債務の合計）
+	PayerShare uint64              `json:"payerShare"`
+	Payers     []LendingPayerParam `json:"payers"`
+	UpdatedAt  time.Time           `json:"updatedAt"`
+
+	// Version 楽観的排他制御のためのバージョン（ETagと同じ値）
+	Version int32 `json:"version"`
 }
 
 // RepaymentCreateRequest defines model for Repayment.CreateRequest.
@@ -212,6 +572,9 @@ type RepaymentCreateResponse struct {
 	Id        string    `json:"id"`
 	PayerId   string    `json:"payerId"`
 	UpdatedAt time.Time `json:"updatedAt"`
+
+	// Version 楽観的排他制御のためのバージョン（ETagと同じ値）
+	Version int32 `json:"version"`
 }
 
 // RepaymentGetAllResponse defines model for Repayment.GetAllResponse.
@@ -222,6 +585,9 @@ type RepaymentGetAllResponse struct {
 	Id        string    `json:"id"`
 	PayerId   string    `json:"payerId"`
 	UpdatedAt time.Time `json:"updatedAt"`
+
+	// Version 楽観的排他制御のためのバージョン（ETagと同じ値）
+	Version int32 `json:"version"`
 }
 
 // RepaymentGetResponse defines model for Repayment.GetResponse.
@@ -232,6 +598,9 @@ type RepaymentGetResponse struct {
 	Id        string    `json:"id"`
 	PayerId   string    `json:"payerId"`
 	UpdatedAt time.Time `json:"updatedAt"`
+
+	// Version 楽観的排他制御のためのバージョン（ETagと同じ値）
+	Version int32 `json:"version"`
 }
 
 // RepaymentPaginatedResponse defines model for Repayment.PaginatedResponse.
@@ -247,6 +616,9 @@ type RepaymentPaginatedResponse struct {
 // RepaymentUpdateRequest defines model for Repayment.UpdateRequest.
 type RepaymentUpdateRequest struct {
 	Amount uint64 `json:"amount"`
+
+	// Version 更新元のバージョン（If-Matchヘッダーを指定しない場合は必須で、どちらもない場合は428を返す）
+	Version *int32 `json:"version,omitempty"`
 }
 
 // RepaymentUpdateResponse defines model for Repayment.UpdateResponse.
@@ -257,6 +629,9 @@ type RepaymentUpdateResponse struct {
 	Id        string    `json:"id"`
 	PayerId   string    `json:"payerId"`
 	UpdatedAt time.Time `json:"updatedAt"`
+
+	// Version 楽観的排他制御のためのバージョン（ETagと同じ値）
+	Version int32 `json:"version"`
 }
 
 // UserGetResponse defines model for User.GetResponse.
@@ -267,6 +642,22 @@ type UserGetResponse struct {
 	Name   string `json:"name"`
 }
 
+// UserPrivacy defines model for User.Privacy.
+type UserPrivacy struct {
+	// DiscoverableByEmail 連絡先ではないユーザーからのメールアドレスの完全一致による検索を許可するかどうか
+	DiscoverableByEmail bool `json:"discoverableByEmail"`
+}
+
+// UserProfileResponse defines model for User.ProfileResponse.
+type UserProfileResponse struct {
+	Avatar string `json:"avatar"`
+
+	// Email 自分自身と連絡先のユーザーの場合のみ返す
+	Email *string `json:"email,omitempty"`
+	Id    string  `json:"id"`
+	Name  string  `json:"name"`
+}
+
 // UserSearchResponse defines model for User.SearchResponse.
 type UserSearchResponse struct {
 	Avatar string `json:"avatar"`
@@ -283,13 +674,25 @@ type UserUpdateRequest struct {
 
 // CreditsListParams defines parameters for CreditsList.
 type CreditsListParams struct {
+	// Status overdueを指定すると返済期限を過ぎた金額があるユーザーのみを返す
+	Status *CreditsListParamsStatus `form:"status,omitempty" json:"status,omitempty"`
+
 	// OrderBy ソート順を指定（asc: 金額昇順、desc: 金額降順）
 	OrderBy *CreditsListParamsOrderBy `form:"order_by,omitempty" json:"order_by,omitempty"`
 }
 
+// CreditsListParamsStatus defines parameters for CreditsList.
+type CreditsListParamsStatus string
+
 // CreditsListParamsOrderBy defines parameters for CreditsList.
 type CreditsListParamsOrderBy string
 
+// GroupUpdateParams defines parameters for GroupUpdate.
+type GroupUpdateParams struct {
+	// IfMatch 更新元のETag（バージョンが一致しない場合は412を返す。指定しない場合はリクエストボディのversionが必須）
+	IfMatch *string `json:"If-Match,omitempty"`
+}
+
 // LendingGetAllParams defines parameters for LendingGetAll.
 type LendingGetAllParams struct {
 	// Limit 取得件数（デフォルト: 20、最大: 100）
@@ -299,6 +702,45 @@ type LendingGetAllParams struct {
 	Cursor *string `form:"cursor,omitempty" json:"cursor,omitempty"`
 }
 
+// LendingUpdateParams defines parameters for LendingUpdate.
+type LendingUpdateParams struct {
+	// IfMatch 更新元のETag（バージョンが一致しない場合は412を返す。指定しない場合はリクエストボディのversionが必須）
+	IfMatch *string `json:"If-Match,omitempty"`
+}
+
+// CommentListParams defines parameters for CommentList.
+type CommentListParams struct {
+	// Limit 取得件数（デフォルト: 20、最大: 100）
+	Limit *int32 `form:"limit,omitempty" json:"limit,omitempty"`
+
+	// Cursor 次ページ用カーソル（前回レスポンスの最後のID）
+	Cursor *string `form:"cursor,omitempty" json:"cursor,omitempty"`
+}
+
+// LendingListParams defines parameters for LendingList.
+type LendingListParams struct {
+	// Limit 取得件数（デフォルト: 20、最大: 100）
+	Limit *int32 `form:"limit,omitempty" json:"limit,omitempty"`
+
+	// Cursor 次ページ用カーソル（前回レスポンスの最後のID）
+	Cursor *string `form:"cursor,omitempty" json:"cursor,omitempty"`
+}
+
+// LendingUpdatePersonalParams defines parameters for LendingUpdatePersonal.
+type LendingUpdatePersonalParams struct {
+	// IfMatch 更新元のETag（バージョンが一致しない場合は412を返す。指定しない場合はリクエストボディのversionが必須）
+	IfMatch *string `json:"If-Match,omitempty"`
+}
+
+// CommentListPersonalParams defines parameters for CommentListPersonal.
+type CommentListPersonalParams struct {
+	// Limit 取得件数（デフォルト: 20、最大: 100）
+	Limit *int32 `form:"limit,omitempty" json:"limit,omitempty"`
+
+	// Cursor 次ページ用カーソル（前回レスポンスの最後のID）
+	Cursor *string `form:"cursor,omitempty" json:"cursor,omitempty"`
+}
+
 // RepaymentGetAllParams defines parameters for RepaymentGetAll.
 type RepaymentGetAllParams struct {
 	// Limit 取得件数（デフォルト: 20、最大: 100）
@@ -308,6 +750,21 @@ type RepaymentGetAllParams struct {
 	Cursor *string `form:"cursor,omitempty" json:"cursor,omitempty"`
 }
 
+// RepaymentUpdateParams defines parameters for RepaymentUpdate.
+type RepaymentUpdateParams struct {
+	// IfMatch 更新元のETag（バージョンが一致しない場合は412を返す。指定しない場合はリクエストボディのversionが必須）
+	IfMatch *string `json:"If-Match,omitempty"`
+}
+
+// LendingSearchParams defines parameters for LendingSearch.
+type LendingSearchParams struct {
+	// Q 検索キーワード（立て替え名の部分一致・類似度で検索）
+	Q string `form:"q" json:"q"`
+
+	// Limit 取得件数（デフォルト: 20、最大: 100）
+	Limit *int32 `form:"limit,omitempty" json:"limit,omitempty"`
+}
+
 // UserSearchParams defines parameters for UserSearch.
 type UserSearchParams struct {
 	Name  *string `form:"name,omitempty" json:"name,omitempty"`
@@ -324,15 +781,39 @@ type GroupCreateJSONRequestBody = GroupCreateRequest
 // GroupUpdateJSONRequestBody defines body for GroupUpdate for application/json ContentType.
 type GroupUpdateJSONRequestBody = GroupUpdateRequest
 
+// GuestCreateJSONRequestBody defines body for GuestCreate for application/json ContentType.
+type GuestCreateJSONRequestBody = GuestCreateRequest
+
 // LendingCreateJSONRequestBody defines body for LendingCreate for application/json ContentType.
 type LendingCreateJSONRequestBody = LendingCreateRequest
 
 // LendingUpdateJSONRequestBody defines body for LendingUpdate for application/json ContentType.
 type LendingUpdateJSONRequestBody = LendingUpdateRequest
 
+// CommentCreateJSONRequestBody defines body for CommentCreate for application/json ContentType.
+type CommentCreateJSONRequestBody = CommentCreateRequest
+
+// CommentUpdateJSONRequestBody defines body for CommentUpdate for application/json ContentType.
+type CommentUpdateJSONRequestBody = CommentUpdateRequest
+
 // GroupAddMemberJSONRequestBody defines body for GroupAddMember for application/json ContentType.
 type GroupAddMemberJSONRequestBody = GroupAddMemberRequest
 
+// GuestClaimJSONRequestBody defines body for GuestClaim for application/json ContentType.
+type GuestClaimJSONRequestBody = GuestClaimRequest
+
+// LendingCreatePersonalJSONRequestBody defines body for LendingCreatePersonal for application/json ContentType.
+type LendingCreatePersonalJSONRequestBody = LendingCreateRequest
+
+// LendingUpdatePersonalJSONRequestBody defines body for LendingUpdatePersonal for application/json ContentType.
+type LendingUpdatePersonalJSONRequestBody = LendingUpdateRequest
+
+// CommentCreatePersonalJSONRequestBody defines body for CommentCreatePersonal for application/json ContentType.
+type CommentCreatePersonalJSONRequestBody = CommentCreateRequest
+
+// CommentUpdatePersonalJSONRequestBody defines body for CommentUpdatePersonal for application/json ContentType.
+type CommentUpdatePersonalJSONRequestBody = CommentUpdateRequest
+
 // RepaymentCreateJSONRequestBody defines body for RepaymentCreate for application/json ContentType.
 type RepaymentCreateJSONRequestBody = RepaymentCreateRequest
 
@@ -341,3 +822,9 @@ type RepaymentUpdateJSONRequestBody = RepaymentUpdateRequest
 
 // UserUpdateMeJSONRequestBody defines body for UserUpdateMe for application/json ContentType.
 type UserUpdateMeJSONRequestBody = UserUpdateRequest
+
+// ContactAddJSONRequestBody defines body for ContactAdd for application/json ContentType.
+type ContactAddJSONRequestBody = ContactAddRequest
+
+// UserUpdatePrivacyJSONRequestBody defines body for UserUpdatePrivacy for application/json ContentType.
+type UserUpdatePrivacyJSONRequestBody = UserPrivacy
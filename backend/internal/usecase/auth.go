@@ -3,21 +3,27 @@ package usecase
 import (
 	"context"
 	"errors"
+	"log/slog"
 
 	"github.com/haebeal/datti/internal/domain"
+	"github.com/haebeal/datti/internal/logger"
 	"github.com/haebeal/datti/internal/presentation/api/handler"
 	"go.opentelemetry.io/otel/codes"
 )
 
 // AuthUseCaseImpl 認証に関するユースケースの実装
 type AuthUseCaseImpl struct {
-	ur domain.UserRepository
+	ur  domain.UserRepository
+	gsr domain.GuestRepository
+	b   domain.GroupUpdateBroker
 }
 
 // NewAuthUseCase AuthUseCaseImplのファクトリ関数
-func NewAuthUseCase(ur domain.UserRepository) AuthUseCaseImpl {
+func NewAuthUseCase(ur domain.UserRepository, gsr domain.GuestRepository, b domain.GroupUpdateBroker) AuthUseCaseImpl {
 	return AuthUseCaseImpl{
-		ur: ur,
+		ur:  ur,
+		gsr: gsr,
+		b:   b,
 	}
 }
 
@@ -42,7 +48,9 @@ func (a AuthUseCaseImpl) Login(ctx context.Context, input handler.AuthLoginInput
 }
 
 // Signup ユーザー登録を行う
-// 既存ユーザーとの重複チェック、Firebase→Cognito移行時のID更新を含む
+// 既存ユーザーとの重複チェック、Firebase→Cognito移行時のID更新、
+// メールアドレスが一致するゲストの引き継ぎを含む
+// ID移行とゲストの引き継ぎはCognitoで確認済みのメールアドレスでのみ行う
 func (a AuthUseCaseImpl) Signup(ctx context.Context, input handler.AuthSignupInput) (*handler.AuthSignupOutput, error) {
 	ctx, span := tracer.Start(ctx, "auth.Signup")
 	defer span.End()
@@ -59,8 +67,13 @@ func (a AuthUseCaseImpl) Signup(ctx context.Context, input handler.AuthSignupInp
 		return nil, err
 	}
 
+	// 確認済みのメールアドレスでない場合は既存ユーザーやゲストを引き継がずに新規作成する
+	if input.VerifiedEmail == "" {
+		return a.createUser(ctx, input)
+	}
+
 	// メールアドレスでユーザーの存在確認（Firebase → Cognito移行用）
-	existingUser, err := a.ur.FindByEmail(ctx, input.Email)
+	existingUser, err := a.ur.FindByEmail(ctx, input.VerifiedEmail)
 	if err == nil {
 		// 同じメールアドレスのユーザーが見つかった場合、IDを移行
 		if err := a.ur.UpdateID(ctx, existingUser.ID(), input.UID); err != nil {
@@ -68,6 +81,7 @@ func (a AuthUseCaseImpl) Signup(ctx context.Context, input handler.AuthSignupInp
 			span.RecordError(err)
 			return nil, err
 		}
+		logger.FromContext(ctx).InfoContext(ctx, "ユーザーIDを移行しました", slog.String("from", existingUser.ID()), slog.String("to", input.UID))
 
 		// 新しいIDで移行済みユーザーを返す
 		migratedUser, err := domain.NewUser(ctx, input.UID, existingUser.Name(), existingUser.Avatar(), existingUser.Email())
@@ -76,6 +90,11 @@ func (a AuthUseCaseImpl) Signup(ctx context.Context, input handler.AuthSignupInp
 			span.RecordError(err)
 			return nil, err
 		}
+		if err := a.claimGuestsByEmail(ctx, migratedUser, input.VerifiedEmail); err != nil {
+			span.SetStatus(codes.Error, err.Error())
+			span.RecordError(err)
+			return nil, err
+		}
 		return &handler.AuthSignupOutput{
 			User: migratedUser,
 		}, nil
@@ -86,7 +105,25 @@ func (a AuthUseCaseImpl) Signup(ctx context.Context, input handler.AuthSignupInp
 		return nil, err
 	}
 
-	// 新規ユーザーを作成
+	output, err := a.createUser(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := a.claimGuestsByEmail(ctx, output.User, input.VerifiedEmail); err != nil {
+		span.SetStatus(codes.Error, err.Error())
+		span.RecordError(err)
+		return nil, err
+	}
+
+	return output, nil
+}
+
+// createUser 新規ユーザーを作成する
+func (a AuthUseCaseImpl) createUser(ctx context.Context, input handler.AuthSignupInput) (*handler.AuthSignupOutput, error) {
+	ctx, span := tracer.Start(ctx, "auth.createUser")
+	defer span.End()
+
 	user, err := domain.NewUser(ctx, input.UID, input.Name, input.Avatar, input.Email)
 	if err != nil {
 		span.SetStatus(codes.Error, err.Error())
@@ -104,3 +141,20 @@ func (a AuthUseCaseImpl) Signup(ctx context.Context, input handler.AuthSignupInp
 		User: user,
 	}, nil
 }
+
+// claimGuestsByEmail 確認済みのメールアドレスが一致するゲストの履歴をユーザーに引き継ぐ
+// 未確認のメールアドレスでは他人のゲストを引き継げてしまうため、招待トークンによる引き継ぎを使用する
+func (a AuthUseCaseImpl) claimGuestsByEmail(ctx context.Context, user *domain.User, verifiedEmail string) error {
+	guests, err := a.gsr.FindByEmail(ctx, verifiedEmail)
+	if err != nil {
+		return err
+	}
+
+	for _, guest := range guests {
+		if err := claimGuest(ctx, a.gsr, a.b, guest, user); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,171 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/haebeal/datti/internal/domain"
+	"github.com/haebeal/datti/internal/presentation/api/handler"
+	"github.com/oklog/ulid/v2"
+	"go.opentelemetry.io/otel/codes"
+)
+
+// CommentUseCaseImpl 立て替えのコメントに関するユースケースの実装
+// コメントの閲覧と投稿は、立て替えの取得と同じく支払い者と債務者のみ可能
+type CommentUseCaseImpl struct {
+	ur  domain.UserRepository
+	gr  domain.GroupRepository
+	lr  domain.LendingRepository
+	cmr domain.CommentRepository
+}
+
+// NewCommentUseCase CommentUseCaseImplのファクトリ関数
+func NewCommentUseCase(ur domain.UserRepository, gr domain.GroupRepository, lr domain.LendingRepository, cmr domain.CommentRepository) CommentUseCaseImpl {
+	return CommentUseCaseImpl{
+		ur:  ur,
+		gr:  gr,
+		lr:  lr,
+		cmr: cmr,
+	}
+}
+
+// Create 立て替えにコメントを投稿する
+func (u CommentUseCaseImpl) Create(ctx context.Context, input handler.CommentCreateInput) (output *handler.CommentCreateOutput, err error) {
+	ctx, span := tracer.Start(ctx, "usecase.Comment.Create")
+	defer func() {
+		if err != nil {
+			span.SetStatus(codes.Error, err.Error())
+			span.RecordError(err)
+		}
+		span.End()
+	}()
+
+	lending, err := findVisibleLending(ctx, u.gr, u.lr, input.GroupID, input.UserID, input.LendingID)
+	if err != nil {
+		return nil, err
+	}
+
+	author, err := u.ur.FindByID(ctx, input.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	comment, err := domain.CreateComment(ctx, lending.ID(), author, input.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := u.cmr.Create(ctx, comment); err != nil {
+		return nil, err
+	}
+
+	return &handler.CommentCreateOutput{
+		Comment: comment,
+	}, nil
+}
+
+// List 立て替えのコメント一覧を古い順に取得する
+func (u CommentUseCaseImpl) List(ctx context.Context, input handler.CommentListInput) (output *handler.CommentListOutput, err error) {
+	ctx, span := tracer.Start(ctx, "usecase.Comment.List")
+	defer func() {
+		if err != nil {
+			span.SetStatus(codes.Error, err.Error())
+			span.RecordError(err)
+		}
+		span.End()
+	}()
+
+	lending, err := findVisibleLending(ctx, u.gr, u.lr, input.GroupID, input.UserID, input.LendingID)
+	if err != nil {
+		return nil, err
+	}
+
+	comments, err := u.cmr.FindByLendingID(ctx, lending.ID(), input.Cursor, input.Limit)
+	if err != nil {
+		return nil, err
+	}
+
+	result := handler.CommentListOutput{
+		Comments: comments,
+	}
+
+	// ページネーション情報の設定
+	if len(comments) > 0 && int32(len(comments)) >= input.Limit {
+		lastID := comments[len(comments)-1].ID().String()
+		result.NextCursor = &lastID
+		result.HasMore = true
+	}
+
+	return &result, nil
+}
+
+// Update 自身が投稿したコメントを編集する
+func (u CommentUseCaseImpl) Update(ctx context.Context, input handler.CommentUpdateInput) (output *handler.CommentUpdateOutput, err error) {
+	ctx, span := tracer.Start(ctx, "usecase.Comment.Update")
+	defer func() {
+		if err != nil {
+			span.SetStatus(codes.Error, err.Error())
+			span.RecordError(err)
+		}
+		span.End()
+	}()
+
+	comment, err := u.findOwnComment(ctx, input.GroupID, input.UserID, input.LendingID, input.CommentID)
+	if err != nil {
+		return nil, err
+	}
+
+	updated, err := comment.Edit(ctx, input.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := u.cmr.Update(ctx, updated); err != nil {
+		return nil, err
+	}
+
+	return &handler.CommentUpdateOutput{
+		Comment: updated,
+	}, nil
+}
+
+// Delete 自身が投稿したコメントを削除する
+func (u CommentUseCaseImpl) Delete(ctx context.Context, input handler.CommentDeleteInput) (err error) {
+	ctx, span := tracer.Start(ctx, "usecase.Comment.Delete")
+	defer func() {
+		if err != nil {
+			span.SetStatus(codes.Error, err.Error())
+			span.RecordError(err)
+		}
+		span.End()
+	}()
+
+	comment, err := u.findOwnComment(ctx, input.GroupID, input.UserID, input.LendingID, input.CommentID)
+	if err != nil {
+		return err
+	}
+
+	return u.cmr.Delete(ctx, comment.ID())
+}
+
+// findOwnComment 閲覧できる立て替えに対して自身が投稿したコメントを取得する
+func (u CommentUseCaseImpl) findOwnComment(ctx context.Context, groupID *ulid.ULID, userID string, lendingID ulid.ULID, commentID ulid.ULID) (*domain.Comment, error) {
+	if _, err := findVisibleLending(ctx, u.gr, u.lr, groupID, userID, lendingID); err != nil {
+		return nil, err
+	}
+
+	comment, err := u.cmr.FindByID(ctx, commentID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 別の立て替えのコメントは存在しないものとして扱う
+	if comment.LendingID() != lendingID {
+		return nil, domain.NewNotFoundError("comment", commentID.String())
+	}
+
+	if comment.Author().ID() != userID {
+		return nil, domain.NewForbiddenError("自身が投稿したコメントのみ変更できます")
+	}
+
+	return comment, nil
+}
@@ -0,0 +1,121 @@
+package usecase
+
+import (
+	"context"
+	"slices"
+
+	"github.com/haebeal/datti/internal/domain"
+	"github.com/haebeal/datti/internal/presentation/api/handler"
+	"go.opentelemetry.io/otel/codes"
+)
+
+// ContactUseCaseImpl 連絡先に関するユースケースの実装
+type ContactUseCaseImpl struct {
+	ur  domain.UserRepository
+	ctr domain.ContactRepository
+}
+
+// NewContactUseCase ContactUseCaseImplのファクトリ関数
+func NewContactUseCase(ur domain.UserRepository, ctr domain.ContactRepository) ContactUseCaseImpl {
+	return ContactUseCaseImpl{
+		ur:  ur,
+		ctr: ctr,
+	}
+}
+
+// List 自身の連絡先一覧を取得する
+func (u ContactUseCaseImpl) List(ctx context.Context, input handler.ContactListInput) (output *handler.ContactListOutput, err error) {
+	ctx, span := tracer.Start(ctx, "usecase.Contact.List")
+	defer func() {
+		if err != nil {
+			span.SetStatus(codes.Error, err.Error())
+			span.RecordError(err)
+		}
+		span.End()
+	}()
+
+	contacts, err := u.ctr.FindByUserID(ctx, input.UID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &handler.ContactListOutput{
+		Contacts: contacts,
+	}, nil
+}
+
+// Add ユーザーを連絡先に追加する
+// 同じグループのメンバー以外は、メールアドレスでの検索を許可しているユーザーのみ追加できる
+func (u ContactUseCaseImpl) Add(ctx context.Context, input handler.ContactAddInput) (output *handler.ContactAddOutput, err error) {
+	ctx, span := tracer.Start(ctx, "usecase.Contact.Add")
+	defer func() {
+		if err != nil {
+			span.SetStatus(codes.Error, err.Error())
+			span.RecordError(err)
+		}
+		span.End()
+	}()
+
+	if input.ContactID == input.UID {
+		return nil, domain.NewValidationError("userId", "自分自身は連絡先に追加できません")
+	}
+
+	contacts, err := u.ctr.FindByUserID(ctx, input.UID)
+	if err != nil {
+		return nil, err
+	}
+
+	i := slices.IndexFunc(contacts, func(c *domain.Contact) bool {
+		return c.User().ID() == input.ContactID
+	})
+	if i >= 0 && contacts[i].Added() {
+		return nil, domain.NewConflictError("contact", "既に連絡先に追加されています")
+	}
+
+	user, err := u.ur.FindByID(ctx, input.ContactID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 検索を許可していないユーザーの存在は明かさない
+	if user.IsGuest() {
+		return nil, domain.NewNotFoundError("user", input.ContactID)
+	}
+	if i < 0 {
+		privacy, err := u.ur.FindPrivacy(ctx, user.ID())
+		if err != nil {
+			return nil, err
+		}
+		if !privacy.DiscoverableByEmail() {
+			return nil, domain.NewNotFoundError("user", input.ContactID)
+		}
+	}
+
+	err = u.ctr.Add(ctx, input.UID, user)
+	if err != nil {
+		return nil, err
+	}
+
+	contact, err := domain.NewContact(user, true)
+	if err != nil {
+		return nil, err
+	}
+
+	return &handler.ContactAddOutput{
+		Contact: contact,
+	}, nil
+}
+
+// Remove 明示的に追加した連絡先を削除する
+func (u ContactUseCaseImpl) Remove(ctx context.Context, input handler.ContactRemoveInput) (err error) {
+	ctx, span := tracer.Start(ctx, "usecase.Contact.Remove")
+	defer func() {
+		if err != nil {
+			span.SetStatus(codes.Error, err.Error())
+			span.RecordError(err)
+		}
+		span.End()
+	}()
+
+	return u.ctr.Remove(ctx, input.UID, input.ContactID)
+}
@@ -2,6 +2,7 @@ package usecase
 
 import (
 	"context"
+	"slices"
 
 	"github.com/haebeal/datti/internal/domain"
 	"github.com/haebeal/datti/internal/presentation/api/handler"
@@ -36,6 +37,12 @@ func (u CreditUseCaseImpl) List(ctx context.Context, input handler.CreditListInp
 		return nil, err
 	}
 
+	if input.OverdueOnly {
+		credits = slices.DeleteFunc(credits, func(c *domain.Credit) bool {
+			return !c.IsOverdue()
+		})
+	}
+
 	return &handler.CreditListOutput{
 		Credits: credits,
 	}, nil
@@ -13,13 +13,15 @@ import (
 type GroupUseCaseImpl struct {
 	ur domain.UserRepository
 	gr domain.GroupRepository
+	b  domain.GroupUpdateBroker
 }
 
 // NewGroupUseCase GroupUseCaseImplのファクトリ関数
-func NewGroupUseCase(ur domain.UserRepository, gr domain.GroupRepository) GroupUseCaseImpl {
+func NewGroupUseCase(ur domain.UserRepository, gr domain.GroupRepository, b domain.GroupUpdateBroker) GroupUseCaseImpl {
 	return GroupUseCaseImpl{
 		ur: ur,
 		gr: gr,
+		b:  b,
 	}
 }
 
@@ -34,7 +36,7 @@ func (u GroupUseCaseImpl) Create(ctx context.Context, input handler.GroupCreateI
 		span.End()
 	}()
 
-	group, err := domain.CreateGroup(ctx, input.Name, input.CreatedBy)
+	group, err := domain.CreateGroup(ctx, input.Name, input.CreatedBy, input.PaymentTermsDays)
 	if err != nil {
 		return nil, err
 	}
@@ -96,17 +98,10 @@ func (u GroupUseCaseImpl) Get(ctx context.Context, input handler.GroupGetInput)
 		return nil, err
 	}
 
-	members, err := u.gr.FindMembersByID(ctx, input.GroupID)
-	if err != nil {
+	if err := verifyGroupMember(ctx, u.gr, input.GroupID, input.UserID); err != nil {
 		return nil, err
 	}
 
-	if !slices.ContainsFunc(members, func(m *domain.User) bool {
-		return m.ID() == input.UserID
-	}) {
-		return nil, domain.NewForbiddenError("グループのメンバーではありません")
-	}
-
 	return &handler.GroupGetOutput{
 		Group: group,
 	}, nil
@@ -132,7 +127,12 @@ func (u GroupUseCaseImpl) Update(ctx context.Context, input handler.GroupUpdateI
 		return nil, domain.NewForbiddenError("グループの更新権限がありません")
 	}
 
-	updatedGroup, err := group.Update(ctx, input.Name)
+	// クライアントが参照したバージョンから更新されていないことを確認
+	if err := domain.VerifyVersion("group", group.Version(), input.Version); err != nil {
+		return nil, err
+	}
+
+	updatedGroup, err := group.Update(ctx, input.Name, input.PaymentTermsDays, input.ClearPaymentTermsDays)
 	if err != nil {
 		return nil, err
 	}
@@ -167,14 +167,12 @@ func (u GroupUseCaseImpl) AddMember(ctx context.Context, input handler.GroupAddM
 		return domain.NewForbiddenError("メンバーの追加権限がありません")
 	}
 
-	members, err := u.gr.FindMembersByID(ctx, input.GroupID)
+	isMember, err := u.gr.IsMember(ctx, input.GroupID, input.MemberID)
 	if err != nil {
 		return err
 	}
 
-	if slices.ContainsFunc(members, func(m *domain.User) bool {
-		return m.ID() == input.MemberID
-	}) {
+	if isMember {
 		return domain.NewConflictError("member", "既にメンバーに追加されています")
 	}
 
@@ -183,10 +181,16 @@ func (u GroupUseCaseImpl) AddMember(ctx context.Context, input handler.GroupAddM
 		return err
 	}
 
+	// ゲストは作成されたグループにのみ所属できる
+	if member.IsGuest() {
+		return domain.NewValidationError("memberId", "ゲストは他のグループに追加できません")
+	}
+
 	err = u.gr.AddMember(ctx, group, member)
 	if err != nil {
 		return err
 	}
+	publishGroupUpdate(ctx, u.b, group.ID(), domain.GroupUpdateMemberAdded, member.ID())
 
 	return nil
 }
@@ -282,6 +286,38 @@ func (u GroupUseCaseImpl) RemoveMember(ctx context.Context, input handler.GroupR
 	if err != nil {
 		return err
 	}
+	publishGroupUpdate(ctx, u.b, group.ID(), domain.GroupUpdateMemberRemoved, member.ID())
 
 	return nil
 }
+
+// Subscribe グループの変更を購読する (メンバーのみ購読可能)
+func (u GroupUseCaseImpl) Subscribe(ctx context.Context, input handler.GroupSubscribeInput) (output *handler.GroupSubscribeOutput, err error) {
+	ctx, span := tracer.Start(ctx, "usecase.Group.Subscribe")
+	defer func() {
+		if err != nil {
+			span.SetStatus(codes.Error, err.Error())
+			span.RecordError(err)
+		}
+		span.End()
+	}()
+
+	group, err := u.gr.FindByID(ctx, input.GroupID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := verifyGroupMember(ctx, u.gr, group.ID(), input.UserID); err != nil {
+		return nil, err
+	}
+
+	updates, cancel, err := u.b.Subscribe(ctx, group.ID())
+	if err != nil {
+		return nil, err
+	}
+
+	return &handler.GroupSubscribeOutput{
+		Updates: updates,
+		Cancel:  cancel,
+	}, nil
+}
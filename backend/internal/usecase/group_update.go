@@ -0,0 +1,68 @@
+package usecase
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/haebeal/datti/internal/domain"
+	"github.com/haebeal/datti/internal/logger"
+	"github.com/oklog/ulid/v2"
+)
+
+// publishGroupUpdate グループの変更をメンバーに通知する
+// 通知は画面の自動更新のためのものなので、失敗しても変更自体は成功として扱う
+func publishGroupUpdate(ctx context.Context, b domain.GroupUpdateBroker, groupID ulid.ULID, updateType domain.GroupUpdateType, resourceID string) {
+	update, err := domain.CreateGroupUpdate(groupID, updateType, resourceID)
+	if err == nil {
+		err = b.Publish(ctx, update)
+	}
+	if err != nil {
+		logger.FromContext(ctx).WarnContext(ctx, "グループの変更の通知に失敗しました",
+			slog.String("groupId", groupID.String()),
+			slog.String("type", string(updateType)),
+			slog.Any("error", err),
+		)
+	}
+}
+
+// publishRepaymentUpdate 返済の変更を支払い者と受取人が共に所属する全グループに通知する
+// 返済はグループに属さないため、両者の貸し借りが表示されるグループを対象とする
+func publishRepaymentUpdate(ctx context.Context, b domain.GroupUpdateBroker, gr domain.GroupRepository, r *domain.Repayment, updateType domain.GroupUpdateType) {
+	groups, err := findSharedGroups(ctx, gr, r.PayerID(), r.DebtorID())
+	if err != nil {
+		logger.FromContext(ctx).WarnContext(ctx, "返済の変更の通知先を取得できませんでした",
+			slog.String("repaymentId", r.ID().String()),
+			slog.Any("error", err),
+		)
+		return
+	}
+
+	for _, g := range groups {
+		publishGroupUpdate(ctx, b, g.ID(), updateType, r.ID().String())
+	}
+}
+
+// findSharedGroups 2人のユーザーが共に所属するグループ一覧を取得する
+func findSharedGroups(ctx context.Context, gr domain.GroupRepository, userID string, otherUserID string) ([]*domain.Group, error) {
+	groups, err := gr.FindByMemberUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	otherGroups, err := gr.FindByMemberUserID(ctx, otherUserID)
+	if err != nil {
+		return nil, err
+	}
+
+	otherGroupIDs := make(map[ulid.ULID]struct{}, len(otherGroups))
+	for _, g := range otherGroups {
+		otherGroupIDs[g.ID()] = struct{}{}
+	}
+
+	shared := make([]*domain.Group, 0, len(groups))
+	for _, g := range groups {
+		if _, ok := otherGroupIDs[g.ID()]; ok {
+			shared = append(shared, g)
+		}
+	}
+	return shared, nil
+}
@@ -0,0 +1,152 @@
+package usecase
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/haebeal/datti/internal/domain"
+	"github.com/haebeal/datti/internal/logger"
+	"github.com/haebeal/datti/internal/presentation/api/handler"
+	"go.opentelemetry.io/otel/codes"
+)
+
+// GuestUseCaseImpl ゲストメンバーに関するユースケースの実装
+type GuestUseCaseImpl struct {
+	ur  domain.UserRepository
+	gr  domain.GroupRepository
+	gsr domain.GuestRepository
+	b   domain.GroupUpdateBroker
+}
+
+// NewGuestUseCase GuestUseCaseImplのファクトリ関数
+func NewGuestUseCase(ur domain.UserRepository, gr domain.GroupRepository, gsr domain.GuestRepository, b domain.GroupUpdateBroker) GuestUseCaseImpl {
+	return GuestUseCaseImpl{
+		ur:  ur,
+		gr:  gr,
+		gsr: gsr,
+		b:   b,
+	}
+}
+
+// Create グループにゲストを追加する (メンバーのみ実行可能)
+func (u GuestUseCaseImpl) Create(ctx context.Context, input handler.GuestCreateInput) (output *handler.GuestCreateOutput, err error) {
+	ctx, span := tracer.Start(ctx, "usecase.Guest.Create")
+	defer func() {
+		if err != nil {
+			span.SetStatus(codes.Error, err.Error())
+			span.RecordError(err)
+		}
+		span.End()
+	}()
+
+	group, err := u.gr.FindByID(ctx, input.GroupID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := verifyGroupMember(ctx, u.gr, input.GroupID, input.UserID); err != nil {
+		return nil, err
+	}
+
+	guest, err := domain.CreateGuest(ctx, group.ID(), input.Name, input.Email)
+	if err != nil {
+		return nil, err
+	}
+
+	err = u.gsr.Create(ctx, guest)
+	if err != nil {
+		return nil, err
+	}
+	publishGroupUpdate(ctx, u.b, group.ID(), domain.GroupUpdateMemberAdded, guest.ID())
+
+	return &handler.GuestCreateOutput{
+		Guest: guest,
+	}, nil
+}
+
+// List グループのゲスト一覧を取得する (メンバーのみアクセス可能)
+func (u GuestUseCaseImpl) List(ctx context.Context, input handler.GuestListInput) (output *handler.GuestListOutput, err error) {
+	ctx, span := tracer.Start(ctx, "usecase.Guest.List")
+	defer func() {
+		if err != nil {
+			span.SetStatus(codes.Error, err.Error())
+			span.RecordError(err)
+		}
+		span.End()
+	}()
+
+	if err := verifyGroupMember(ctx, u.gr, input.GroupID, input.UserID); err != nil {
+		return nil, err
+	}
+
+	guests, err := u.gsr.FindByGroupID(ctx, input.GroupID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &handler.GuestListOutput{
+		Guests: guests,
+	}, nil
+}
+
+// Claim 招待トークンで指定したゲストの履歴をログインユーザーに引き継ぐ
+func (u GuestUseCaseImpl) Claim(ctx context.Context, input handler.GuestClaimInput) (output *handler.GuestClaimOutput, err error) {
+	ctx, span := tracer.Start(ctx, "usecase.Guest.Claim")
+	defer func() {
+		if err != nil {
+			span.SetStatus(codes.Error, err.Error())
+			span.RecordError(err)
+		}
+		span.End()
+	}()
+
+	if input.InviteToken == "" {
+		return nil, domain.NewValidationError("inviteToken", "招待トークンは必須です")
+	}
+
+	guest, err := u.gsr.FindByInviteToken(ctx, input.InviteToken)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := u.ur.FindByID(ctx, input.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := claimGuest(ctx, u.gsr, u.b, guest, user); err != nil {
+		return nil, err
+	}
+
+	group, err := u.gr.FindByID(ctx, guest.GroupID())
+	if err != nil {
+		return nil, err
+	}
+
+	return &handler.GuestClaimOutput{
+		Group: group,
+	}, nil
+}
+
+// claimGuest ゲストの履歴をユーザーに引き継ぐ
+// 新規登録時のメールアドレスによる引き継ぎと招待トークンによる引き継ぎで共通して使用する
+func claimGuest(ctx context.Context, gsr domain.GuestRepository, b domain.GroupUpdateBroker, guest *domain.Guest, user *domain.User) error {
+	if user.IsGuest() {
+		return domain.NewValidationError("user", "ゲストは他のゲストを引き継げません")
+	}
+
+	if err := gsr.Claim(ctx, guest, user); err != nil {
+		return err
+	}
+
+	logger.FromContext(ctx).InfoContext(ctx, "ゲストを引き継ぎました",
+		slog.String("guestId", guest.ID()),
+		slog.String("userId", user.ID()),
+		slog.String("groupId", guest.GroupID().String()),
+	)
+	// ゲストはユーザーに置き換えられる
+	publishGroupUpdate(ctx, b, guest.GroupID(), domain.GroupUpdateMemberRemoved, guest.ID())
+	publishGroupUpdate(ctx, b, guest.GroupID(), domain.GroupUpdateMemberAdded, user.ID())
+
+	return nil
+}
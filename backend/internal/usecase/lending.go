@@ -2,30 +2,42 @@ package usecase
 
 import (
 	"context"
+	"fmt"
 	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
 
 	"github.com/haebeal/datti/internal/domain"
 	"github.com/haebeal/datti/internal/presentation/api/handler"
+	"github.com/oklog/ulid/v2"
 	"go.opentelemetry.io/otel/codes"
 )
 
 // LendingUseCaseImpl 立て替えに関するユースケースの実装
 type LendingUseCaseImpl struct {
-	ur domain.UserRepository
-	gr domain.GroupRepository
-	lr domain.LendingRepository
+	ur  domain.UserRepository
+	gr  domain.GroupRepository
+	lr  domain.LendingRepository
+	ctr domain.ContactRepository
+	cmr domain.CommentRepository
+	b   domain.GroupUpdateBroker
 }
 
 // NewLendingUseCase LendingUseCaseImplのファクトリ関数
-func NewLendingUseCase(ur domain.UserRepository, gr domain.GroupRepository, lr domain.LendingRepository) LendingUseCaseImpl {
+func NewLendingUseCase(ur domain.UserRepository, gr domain.GroupRepository, lr domain.LendingRepository, ctr domain.ContactRepository, cmr domain.CommentRepository, b domain.GroupUpdateBroker) LendingUseCaseImpl {
 	return LendingUseCaseImpl{
-		ur: ur,
-		gr: gr,
-		lr: lr,
+		ur:  ur,
+		gr:  gr,
+		lr:  lr,
+		ctr: ctr,
+		cmr: cmr,
+		b:   b,
 	}
 }
 
 // Create 立て替えを作成する
+// GroupIDがnilの場合はグループに属さない個人間の立て替えとして作成する
 func (u LendingUseCaseImpl) Create(ctx context.Context, i handler.CreateInput) (output *handler.CreateOutput, err error) {
 	ctx, span := tracer.Start(ctx, "usecase.Lending.Create")
 	defer func() {
@@ -37,44 +49,61 @@ func (u LendingUseCaseImpl) Create(ctx context.Context, i handler.CreateInput) (
 	}()
 
 	// グループの取得とメンバーシップ確認
-	group, err := u.gr.FindByID(ctx, i.GroupID)
-	if err != nil {
-		return nil, err
+	var group *domain.Group
+	if i.GroupID != nil {
+		group, err = u.gr.FindByID(ctx, *i.GroupID)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := verifyGroupMember(ctx, u.gr, *i.GroupID, i.UserID); err != nil {
+			return nil, err
+		}
 	}
 
-	members, err := u.gr.FindMembersByID(ctx, i.GroupID)
+	// グループの立て替えはメンバーとのみ、個人間の立て替えは連絡先のユーザーとのみ作成できる
+	// 存在しないユーザーもメンバーではないため、ユーザーの取得より先に確認して同じ検証エラーにする
+	requested := requestedUserIDs(i.UserID, i.Payers, i.Debts)
+	if group != nil {
+		err = verifyGroupMembers(ctx, u.gr, group.ID(), requested)
+	} else {
+		err = u.verifyContacts(ctx, i.UserID, requested)
+	}
 	if err != nil {
 		return nil, err
 	}
-	if !slices.ContainsFunc(members, func(m *domain.User) bool {
-		return m.ID() == i.UserID
-	}) {
-		return nil, domain.NewForbiddenError("グループのメンバーではありません")
-	}
 
-	// 支払い者の作成
-	paidUser, err := u.ur.FindByID(ctx, i.UserID)
+	// 支払い者と債務者のユーザーをまとめて取得
+	users, err := findUsers(ctx, u.ur, requested)
 	if err != nil {
 		return nil, err
 	}
-	payer, err := domain.NewPayer(paidUser.ID(), paidUser.Name(), paidUser.Avatar(), paidUser.Email())
+
+	// 支払い者の作成
+	payers, err := buildPayers(i.UserID, i.Amount, i.PayerShare, i.Payers, i.Debts, users)
 	if err != nil {
 		return nil, err
 	}
 
 	// Lending集約を作成
-	lending, err := domain.CreateLending(ctx, i.Name, i.Amount, i.EventDate, payer)
+	lending, err := domain.CreateLending(ctx, i.Name, i.Amount, i.EventDate, resolveDueDate(group, i.EventDate, i.DueDate), payers[i.UserID])
 	if err != nil {
 		return nil, err
 	}
 
-	// 債務者を追加（AddDebtorでバリデーション）
-	for _, d := range i.Debts {
-		user, err := u.ur.FindByID(ctx, d.UserID)
-		if err != nil {
+	// 作成者以外の支払い者を追加
+	for id, p := range payers {
+		if id == i.UserID {
+			continue
+		}
+		if err := lending.AddPayer(p); err != nil {
 			return nil, err
 		}
+	}
 
+	// 債務者を追加（AddDebtorでバリデーション）
+	for _, d := range i.Debts {
+		user := users[d.UserID]
 		debtor, err := domain.NewDebtor(user.ID(), user.Name(), user.Avatar(), user.Email(), d.Amount)
 		if err != nil {
 			return nil, err
@@ -90,10 +119,19 @@ func (u LendingUseCaseImpl) Create(ctx context.Context, i handler.CreateInput) (
 		return nil, domain.NewValidationError("debts", "債務者は1人以上必要です")
 	}
 
+	// 金額の内訳を検証
+	if err := lending.ValidateBreakdown(); err != nil {
+		return nil, err
+	}
+
 	// リポジトリに保存
 	if err := u.lr.Create(ctx, group, lending); err != nil {
 		return nil, err
 	}
+	lendingsCreated.Add(ctx, 1)
+	if group != nil {
+		publishGroupUpdate(ctx, u.b, group.ID(), domain.GroupUpdateLendingCreated, lending.ID().String())
+	}
 
 	// ハンドラー互換のため配列に変換
 	debtorList := make([]*domain.Debtor, 0, len(lending.Debtors()))
@@ -107,6 +145,58 @@ func (u LendingUseCaseImpl) Create(ctx context.Context, i handler.CreateInput) (
 	}, nil
 }
 
+// buildPayers 入力から支払い者一覧を作成する
+// 支払い者が指定されていない場合は作成者が全額を立て替えたものとみなし、
+// 作成者の負担額はpayerShare (未指定の場合は金額と債務の合計の差額) とする
+// usersにはfindUsersで取得した支払い者のユーザーが含まれている必要がある
+func buildPayers(creatorID string, amount int64, payerShare *int64, params []handler.PayerParam, debts []handler.DebtParam, users map[string]*domain.User) (map[string]*domain.Payer, error) {
+	if len(params) == 0 {
+		share := amount
+		for _, d := range debts {
+			share -= d.Amount
+		}
+		if payerShare != nil {
+			share = *payerShare
+		}
+		params = []handler.PayerParam{{UserID: creatorID, Amount: amount, Share: share}}
+	} else {
+		// 立て替え額が0の支払い者は債務者と区別できないため指定できない
+		for _, param := range params {
+			if param.Amount <= 0 {
+				return nil, domain.NewValidationError("payers", "支払い者の立て替え額は1以上である必要があります")
+			}
+		}
+	}
+
+	debtorIDs := make(map[string]struct{}, len(debts))
+	for _, d := range debts {
+		debtorIDs[d.UserID] = struct{}{}
+	}
+
+	payers := make(map[string]*domain.Payer, len(params))
+	for _, param := range params {
+		if _, exists := payers[param.UserID]; exists {
+			return nil, domain.NewValidationError("payers", "同じ支払い者が複数指定されています")
+		}
+		if _, exists := debtorIDs[param.UserID]; exists {
+			return nil, domain.NewValidationError("payers", "債務者を支払い者にすることはできません")
+		}
+
+		user := users[param.UserID]
+		payer, err := domain.NewPayer(user.ID(), user.Name(), user.Avatar(), user.Email(), param.Amount, param.Share)
+		if err != nil {
+			return nil, err
+		}
+		payers[payer.ID()] = payer
+	}
+
+	if _, exists := payers[creatorID]; !exists {
+		return nil, domain.NewValidationError("payers", "作成者は支払い者に含まれる必要があります")
+	}
+
+	return payers, nil
+}
+
 // Get 立て替えを取得する
 func (u LendingUseCaseImpl) Get(ctx context.Context, i handler.GetInput) (output *handler.GetOutput, err error) {
 	ctx, span := tracer.Start(ctx, "usecase.Lending.Get")
@@ -118,29 +208,10 @@ func (u LendingUseCaseImpl) Get(ctx context.Context, i handler.GetInput) (output
 		span.End()
 	}()
 
-	// メンバーシップ確認
-	members, err := u.gr.FindMembersByID(ctx, i.GroupID)
+	lending, err := findVisibleLending(ctx, u.gr, u.lr, i.GroupID, i.UserID, i.EventID)
 	if err != nil {
 		return nil, err
 	}
-	if !slices.ContainsFunc(members, func(m *domain.User) bool {
-		return m.ID() == i.UserID
-	}) {
-		return nil, domain.NewForbiddenError("グループのメンバーではありません")
-	}
-
-	// Lending取得
-	lending, err := u.lr.FindByID(ctx, i.EventID)
-	if err != nil {
-		return nil, err
-	}
-
-	// アクセス権限確認: 支払い者または債務者のみ
-	isPayer := lending.Payer().ID() == i.UserID
-	_, isDebtor := lending.Debtors()[i.UserID]
-	if !isPayer && !isDebtor {
-		return nil, domain.NewNotFoundError("lending", i.EventID.String())
-	}
 
 	// ハンドラー互換のため配列に変換
 	debtorList := make([]*domain.Debtor, 0, len(lending.Debtors()))
@@ -171,15 +242,9 @@ func (u LendingUseCaseImpl) GetByQuery(ctx context.Context, i handler.GetAllInpu
 		return nil, err
 	}
 
-	members, err := u.gr.FindMembersByID(ctx, i.GroupID)
-	if err != nil {
+	if err := verifyGroupMember(ctx, u.gr, i.GroupID, i.UserID); err != nil {
 		return nil, err
 	}
-	if !slices.ContainsFunc(members, func(m *domain.User) bool {
-		return m.ID() == i.UserID
-	}) {
-		return nil, domain.NewForbiddenError("グループのメンバーではありません")
-	}
 
 	// Lending一覧取得
 	limit := i.Limit
@@ -195,6 +260,16 @@ func (u LendingUseCaseImpl) GetByQuery(ctx context.Context, i handler.GetAllInpu
 		}, 0, len(lendings)),
 	}
 
+	// コメント数の取得
+	ids := make([]ulid.ULID, 0, len(lendings))
+	for _, l := range lendings {
+		ids = append(ids, l.ID())
+	}
+	result.CommentCounts, err = u.cmr.CountByLendingIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
 	for _, l := range lendings {
 		// Debtorsをmapから配列に変換
 		debtorList := make([]*domain.Debtor, 0, len(l.Debtors()))
@@ -232,33 +307,62 @@ func (u LendingUseCaseImpl) Update(ctx context.Context, i handler.UpdateInput) (
 		span.End()
 	}()
 
-	// メンバーシップ確認
-	members, err := u.gr.FindMembersByID(ctx, i.GroupID)
-	if err != nil {
-		return nil, err
-	}
-	if !slices.ContainsFunc(members, func(m *domain.User) bool {
-		return m.ID() == i.UserID
-	}) {
-		return nil, domain.NewForbiddenError("グループのメンバーではありません")
-	}
-
-	// Lending取得
-	lending, err := u.lr.FindByID(ctx, i.EventID)
+	lending, err := findLending(ctx, u.gr, u.lr, i.GroupID, i.UserID, i.EventID)
 	if err != nil {
 		return nil, err
 	}
+	// 更新前から関与しているユーザーはメンバーや連絡先の確認を省略する
+	existingIDs := participantIDs(lending)
 
 	// 支払い者のみ更新可能
 	if lending.Payer().ID() != i.UserID {
 		return nil, domain.NewForbiddenError("支払い者のみ更新できます")
 	}
 
+	// クライアントが参照したバージョンから更新されていないことを確認
+	if err := domain.VerifyVersion("lending", lending.Version(), i.Version); err != nil {
+		return nil, err
+	}
+
 	// 債務者がいない場合はエラー
 	if len(i.Debts) == 0 {
 		return nil, domain.NewValidationError("debts", "債務者は1人以上必要です")
 	}
 
+	// 脱退したメンバーや連絡先から外したユーザーを含む立て替えも編集できるよう、
+	// 新しく追加するユーザーのみグループのメンバー (個人間の立て替えは連絡先) であることを確認する
+	// 存在しないユーザーもメンバーではないため、ユーザーの取得より先に確認して同じ検証エラーにする
+	requested := requestedUserIDs(lending.Payer().ID(), i.Payers, i.Debts)
+	var addedIDs []string
+	for _, id := range requested {
+		if !slices.Contains(existingIDs, id) {
+			addedIDs = append(addedIDs, id)
+		}
+	}
+	if i.GroupID != nil {
+		err = verifyGroupMembers(ctx, u.gr, *i.GroupID, addedIDs)
+	} else {
+		err = u.verifyContacts(ctx, i.UserID, addedIDs)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// 支払い者と債務者のユーザーをまとめて取得
+	users, err := findUsers(ctx, u.ur, requested)
+	if err != nil {
+		return nil, err
+	}
+
+	// 支払い者を置き換える
+	payers, err := buildPayers(lending.Payer().ID(), i.Amount, i.PayerShare, i.Payers, i.Debts, users)
+	if err != nil {
+		return nil, err
+	}
+	if err := lending.SetPayers(payers); err != nil {
+		return nil, err
+	}
+
 	// 入力の債務者IDセットを作成
 	inputDebtorIDs := make(map[string]handler.DebtParam)
 	for _, d := range i.Debts {
@@ -292,11 +396,7 @@ func (u LendingUseCaseImpl) Update(ctx context.Context, i handler.UpdateInput) (
 				return nil, domain.NewValidationError("debts", "自分自身に立て替えを作成することはできません")
 			}
 
-			user, err := u.ur.FindByID(ctx, d.UserID)
-			if err != nil {
-				return nil, err
-			}
-
+			user := users[d.UserID]
 			debtor, err := domain.NewDebtor(user.ID(), user.Name(), user.Avatar(), user.Email(), d.Amount)
 			if err != nil {
 				return nil, err
@@ -308,8 +408,19 @@ func (u LendingUseCaseImpl) Update(ctx context.Context, i handler.UpdateInput) (
 		}
 	}
 
+	// 返済期限を省略した場合は変更せず、ClearDueDateを指定した場合は期限をなくす
+	dueDate := lending.DueDate()
+	switch {
+	case i.ClearDueDate && i.DueDate != nil:
+		return nil, domain.NewValidationError("dueDate", "返済期限とclearDueDateは同時に指定できません")
+	case i.ClearDueDate:
+		dueDate = nil
+	case i.DueDate != nil:
+		dueDate = i.DueDate
+	}
+
 	// 基本情報を更新
-	updatedLending, err := lending.Update(ctx, i.Name, i.Amount, i.EventDate)
+	updatedLending, err := lending.Update(ctx, i.Name, i.Amount, i.EventDate, dueDate)
 	if err != nil {
 		return nil, err
 	}
@@ -318,6 +429,9 @@ func (u LendingUseCaseImpl) Update(ctx context.Context, i handler.UpdateInput) (
 	if err := u.lr.Update(ctx, updatedLending); err != nil {
 		return nil, err
 	}
+	if i.GroupID != nil {
+		publishGroupUpdate(ctx, u.b, *i.GroupID, domain.GroupUpdateLendingUpdated, updatedLending.ID().String())
+	}
 
 	// ハンドラー互換のため配列に変換
 	debtorList := make([]*domain.Debtor, 0, len(updatedLending.Debtors()))
@@ -342,28 +456,270 @@ func (u LendingUseCaseImpl) Delete(ctx context.Context, i handler.DeleteInput) (
 		span.End()
 	}()
 
-	// メンバーシップ確認
-	members, err := u.gr.FindMembersByID(ctx, i.GroupID)
+	lending, err := findLending(ctx, u.gr, u.lr, i.GroupID, i.UserID, i.EventID)
 	if err != nil {
 		return err
 	}
-	if !slices.ContainsFunc(members, func(m *domain.User) bool {
-		return m.ID() == i.UserID
-	}) {
+
+	// 支払い者のみ削除可能
+	if lending.Payer().ID() != i.UserID {
+		return domain.NewForbiddenError("支払い者のみ削除できます")
+	}
+
+	// リポジトリから削除
+	if err := u.lr.Delete(ctx, i.EventID); err != nil {
+		return err
+	}
+	if i.GroupID != nil {
+		publishGroupUpdate(ctx, u.b, *i.GroupID, domain.GroupUpdateLendingDeleted, i.EventID.String())
+	}
+
+	return nil
+}
+
+// Search 所属する全グループから立て替えを検索する
+func (u LendingUseCaseImpl) Search(ctx context.Context, i handler.SearchInput) (output *handler.SearchOutput, err error) {
+	ctx, span := tracer.Start(ctx, "usecase.Lending.Search")
+	defer func() {
+		if err != nil {
+			span.SetStatus(codes.Error, err.Error())
+			span.RecordError(err)
+		}
+		span.End()
+	}()
+
+	query := strings.TrimSpace(i.Query)
+	if utf8.RuneCountInString(query) < 1 {
+		return nil, domain.NewValidationError("q", "検索キーワードは1文字以上である必要があります")
+	}
+
+	// 所属グループの取得 (メンバーシップはグループ一覧取得と同じ条件)
+	groups, err := u.gr.FindByMemberUserID(ctx, i.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	results, err := u.lr.Search(ctx, groups, i.UserID, query, i.Limit)
+	if err != nil {
+		return nil, err
+	}
+
+	result := handler.SearchOutput{
+		Results: make([]struct {
+			Group   *domain.Group
+			Lending *domain.Lending
+			Debtors []*domain.Debtor
+		}, 0, len(results)),
+	}
+	for _, r := range results {
+		// Debtorsをmapから配列に変換
+		debtorList := make([]*domain.Debtor, 0, len(r.Lending.Debtors()))
+		for _, d := range r.Lending.Debtors() {
+			debtorList = append(debtorList, d)
+		}
+
+		result.Results = append(result.Results, struct {
+			Group   *domain.Group
+			Lending *domain.Lending
+			Debtors []*domain.Debtor
+		}{
+			Group:   r.Group,
+			Lending: r.Lending,
+			Debtors: debtorList,
+		})
+	}
+
+	return &result, nil
+}
+
+// List グループと個人間の立て替えを横断して、ユーザーが関与する立て替えを新しい順に取得する
+func (u LendingUseCaseImpl) List(ctx context.Context, i handler.ListInput) (output *handler.ListOutput, err error) {
+	ctx, span := tracer.Start(ctx, "usecase.Lending.List")
+	defer func() {
+		if err != nil {
+			span.SetStatus(codes.Error, err.Error())
+			span.RecordError(err)
+		}
+		span.End()
+	}()
+
+	results, err := u.lr.FindByUserID(ctx, i.UserID, i.Cursor, i.Limit)
+	if err != nil {
+		return nil, err
+	}
+
+	result := handler.ListOutput{
+		Results: results,
+	}
+
+	// コメント数の取得
+	ids := make([]ulid.ULID, 0, len(results))
+	for _, r := range results {
+		ids = append(ids, r.Lending.ID())
+	}
+	result.CommentCounts, err = u.cmr.CountByLendingIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	// ページネーション情報の設定
+	if len(results) > 0 && int32(len(results)) >= i.Limit {
+		lastID := results[len(results)-1].Lending.ID().String()
+		result.NextCursor = &lastID
+		result.HasMore = true
+	}
+
+	return &result, nil
+}
+
+// findLending 立て替えを取得する
+// groupIDがnilの場合は個人間の立て替えを取得し、それ以外の場合はグループのメンバーであることを確認する
+func findLending(ctx context.Context, gr domain.GroupRepository, lr domain.LendingRepository, groupID *ulid.ULID, userID string, eventID ulid.ULID) (*domain.Lending, error) {
+	if groupID == nil {
+		return lr.FindPersonalByID(ctx, eventID)
+	}
+
+	if err := verifyGroupMember(ctx, gr, *groupID, userID); err != nil {
+		return nil, err
+	}
+
+	return lr.FindByID(ctx, eventID)
+}
+
+// findVisibleLending 支払い者または債務者として閲覧できる立て替えを取得する
+// 関与していない立て替えは存在を明かさないためNotFoundErrorとする
+func findVisibleLending(ctx context.Context, gr domain.GroupRepository, lr domain.LendingRepository, groupID *ulid.ULID, userID string, eventID ulid.ULID) (*domain.Lending, error) {
+	lending, err := findLending(ctx, gr, lr, groupID, userID, eventID)
+	if err != nil {
+		return nil, err
+	}
+
+	_, isPayer := lending.Payers()[userID]
+	_, isDebtor := lending.Debtors()[userID]
+	if !isPayer && !isDebtor {
+		return nil, domain.NewNotFoundError("lending", eventID.String())
+	}
+
+	return lending, nil
+}
+
+// verifyGroupMember ユーザーがグループのメンバーであることを確認する
+func verifyGroupMember(ctx context.Context, gr domain.GroupRepository, groupID ulid.ULID, userID string) error {
+	isMember, err := gr.IsMember(ctx, groupID, userID)
+	if err != nil {
+		return err
+	}
+
+	if !isMember {
 		return domain.NewForbiddenError("グループのメンバーではありません")
 	}
 
-	// Lending取得
-	lending, err := u.lr.FindByID(ctx, i.EventID)
+	return nil
+}
+
+// verifyGroupMembers ユーザーがすべてグループのメンバーであることを1回の問い合わせで確認する
+// メンバーではないユーザーが含まれる場合は、そのユーザーIDの一覧をエラーに含める
+func verifyGroupMembers(ctx context.Context, gr domain.GroupRepository, groupID ulid.ULID, ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	memberIDs, err := gr.FindMemberIDs(ctx, groupID, ids)
 	if err != nil {
 		return err
 	}
 
-	// 支払い者のみ削除可能
-	if lending.Payer().ID() != i.UserID {
-		return domain.NewForbiddenError("支払い者のみ削除できます")
+	var nonMemberIDs []string
+	for _, id := range ids {
+		if !slices.Contains(memberIDs, id) {
+			nonMemberIDs = append(nonMemberIDs, id)
+		}
+	}
+	if len(nonMemberIDs) > 0 {
+		slices.Sort(nonMemberIDs)
+		return domain.NewValidationError("debts", fmt.Sprintf("グループのメンバーではないユーザーは指定できません: %s", strings.Join(slices.Compact(nonMemberIDs), ", ")))
 	}
 
-	// リポジトリから削除
-	return u.lr.Delete(ctx, i.EventID)
+	return nil
+}
+
+// resolveDueDate 立て替えの返済期限を決める
+// 指定されていない場合はグループの既定の支払い期限を適用し、個人間の立て替えや既定の期限がないグループでは期限を設けない
+func resolveDueDate(group *domain.Group, eventDate time.Time, dueDate *time.Time) *time.Time {
+	if dueDate != nil || group == nil {
+		return dueDate
+	}
+	return group.DefaultDueDate(eventDate)
+}
+
+// findUsers IDで複数のユーザーをまとめて取得する
+// 存在しないユーザーが含まれる場合はNotFoundErrorを返す
+func findUsers(ctx context.Context, ur domain.UserRepository, ids []string) (map[string]*domain.User, error) {
+	found, err := ur.FindByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	users := make(map[string]*domain.User, len(found))
+	for _, user := range found {
+		users[user.ID()] = user
+	}
+	for _, id := range ids {
+		if _, ok := users[id]; !ok {
+			return nil, domain.NewNotFoundError("user", id)
+		}
+	}
+
+	return users, nil
+}
+
+// requestedUserIDs 立て替えの入力で指定された支払い者と債務者のユーザーID一覧 (重複を除く)
+// 支払い者が指定されていない場合は作成者を支払い者とみなす
+func requestedUserIDs(creatorID string, payers []handler.PayerParam, debts []handler.DebtParam) []string {
+	ids := []string{creatorID}
+	for _, p := range payers {
+		ids = append(ids, p.UserID)
+	}
+	for _, d := range debts {
+		ids = append(ids, d.UserID)
+	}
+	slices.Sort(ids)
+	return slices.Compact(ids)
+}
+
+// verifyContacts userID以外のユーザーがすべてuserIDの連絡先であることを確認する
+func (u LendingUseCaseImpl) verifyContacts(ctx context.Context, userID string, ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	contacts, err := u.ctr.FindByUserID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	for _, id := range ids {
+		if id == userID {
+			continue
+		}
+		if !slices.ContainsFunc(contacts, func(c *domain.Contact) bool {
+			return c.User().ID() == id
+		}) {
+			return domain.NewValidationError("debts", "連絡先ではないユーザーは指定できません")
+		}
+	}
+
+	return nil
+}
+
+// participantIDs 立て替えに関与する支払い者と債務者のユーザーID一覧
+func participantIDs(l *domain.Lending) []string {
+	ids := make([]string, 0, len(l.Payers())+len(l.Debtors()))
+	for id := range l.Payers() {
+		ids = append(ids, id)
+	}
+	for id := range l.Debtors() {
+		ids = append(ids, id)
+	}
+	return ids
 }
@@ -0,0 +1,43 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/haebeal/datti/internal/domain"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/metric"
+)
+
+var meter metric.Meter = otel.Meter("github.com/haebeal/datti/internal/usecase")
+
+var (
+	lendingsCreated, _ = meter.Int64Counter(
+		"datti.lendings.created",
+		metric.WithDescription("作成された立て替えの件数"),
+		metric.WithUnit("{lending}"),
+	)
+	repaymentsCreated, _ = meter.Int64Counter(
+		"datti.repayments.created",
+		metric.WithDescription("作成された返済の件数"),
+		metric.WithUnit("{repayment}"),
+	)
+)
+
+// RegisterCreditMetrics 未精算の金額の合計を計測するゲージを登録する
+// 値は計測のたびにリポジトリから集計する
+func RegisterCreditMetrics(cr domain.CreditRepository) error {
+	_, err := meter.Int64ObservableGauge(
+		"datti.credits.outstanding",
+		metric.WithDescription("全ユーザー間の未精算の金額の合計"),
+		metric.WithUnit("{JPY}"),
+		metric.WithInt64Callback(func(ctx context.Context, o metric.Int64Observer) error {
+			amount, err := cr.TotalOutstanding(ctx)
+			if err != nil {
+				return err
+			}
+			o.Observe(amount)
+			return nil
+		}),
+	)
+	return err
+}
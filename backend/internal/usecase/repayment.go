@@ -13,13 +13,17 @@ import (
 type RepaymentUseCaseImpl struct {
 	rr domain.RepaymentRepository
 	cr domain.CreditRepository
+	gr domain.GroupRepository
+	b  domain.GroupUpdateBroker
 }
 
 // NewRepaymentUseCase RepaymentUseCaseImplのファクトリ関数
-func NewRepaymentUseCase(rr domain.RepaymentRepository, cr domain.CreditRepository) RepaymentUseCaseImpl {
+func NewRepaymentUseCase(rr domain.RepaymentRepository, cr domain.CreditRepository, gr domain.GroupRepository, b domain.GroupUpdateBroker) RepaymentUseCaseImpl {
 	return RepaymentUseCaseImpl{
 		rr: rr,
 		cr: cr,
+		gr: gr,
+		b:  b,
 	}
 }
 
@@ -50,6 +54,8 @@ func (u RepaymentUseCaseImpl) Create(ctx context.Context, i handler.RepaymentCre
 	if err := u.rr.Create(ctx, repayment); err != nil {
 		return nil, err
 	}
+	repaymentsCreated.Add(ctx, 1)
+	publishRepaymentUpdate(ctx, u.b, u.gr, repayment, domain.GroupUpdateRepaymentCreated)
 
 	return &handler.RepaymentCreateOutput{
 		Repayment: repayment,
@@ -134,6 +140,11 @@ func (u RepaymentUseCaseImpl) Update(ctx context.Context, i handler.RepaymentUpd
 		return nil, err
 	}
 
+	// クライアントが参照したバージョンから更新されていないことを確認
+	if err := domain.VerifyVersion("repayment", repayment.Version(), i.Version); err != nil {
+		return nil, err
+	}
+
 	updatedRepayment, err := repayment.Update(ctx, i.Amount)
 	if err != nil {
 		return nil, err
@@ -142,6 +153,7 @@ func (u RepaymentUseCaseImpl) Update(ctx context.Context, i handler.RepaymentUpd
 	if err := u.rr.Update(ctx, updatedRepayment); err != nil {
 		return nil, err
 	}
+	publishRepaymentUpdate(ctx, u.b, u.gr, updatedRepayment, domain.GroupUpdateRepaymentUpdated)
 
 	return &handler.RepaymentUpdateOutput{
 		Repayment: updatedRepayment,
@@ -164,5 +176,16 @@ func (u RepaymentUseCaseImpl) Delete(ctx context.Context, i handler.RepaymentDel
 		return err
 	}
 
-	return u.rr.Delete(ctx, id)
+	// 削除後は通知先を特定できないため先に取得する
+	repayment, err := u.rr.FindByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if err := u.rr.Delete(ctx, id); err != nil {
+		return err
+	}
+	publishRepaymentUpdate(ctx, u.b, u.gr, repayment, domain.GroupUpdateRepaymentDeleted)
+
+	return nil
 }
@@ -2,21 +2,39 @@ package usecase
 
 import (
 	"context"
+	"errors"
+	"log/slog"
+	"net/mail"
+	"slices"
 
 	"github.com/haebeal/datti/internal/domain"
+	"github.com/haebeal/datti/internal/logger"
 	"github.com/haebeal/datti/internal/presentation/api/handler"
 	"go.opentelemetry.io/otel/codes"
 )
 
+// exportPageSize データのエクスポート時に1回のクエリで取得する件数
+const exportPageSize int32 = 100
+
 // UserUseCaseImpl ユーザーに関するユースケースの実装
 type UserUseCaseImpl struct {
-	ur domain.UserRepository
+	ur  domain.UserRepository
+	gr  domain.GroupRepository
+	lr  domain.LendingRepository
+	rr  domain.RepaymentRepository
+	cr  domain.CreditRepository
+	ctr domain.ContactRepository
 }
 
 // NewUserUseCase UserUseCaseImplのファクトリ関数
-func NewUserUseCase(ur domain.UserRepository) UserUseCaseImpl {
+func NewUserUseCase(ur domain.UserRepository, gr domain.GroupRepository, lr domain.LendingRepository, rr domain.RepaymentRepository, cr domain.CreditRepository, ctr domain.ContactRepository) UserUseCaseImpl {
 	return UserUseCaseImpl{
-		ur: ur,
+		ur:  ur,
+		gr:  gr,
+		lr:  lr,
+		rr:  rr,
+		cr:  cr,
+		ctr: ctr,
 	}
 }
 
@@ -32,7 +50,8 @@ func (u UserUseCaseImpl) Search(ctx context.Context, input handler.UserSearchInp
 	}()
 
 	query := domain.UserSearchQuery{
-		Limit: 20, // デフォルト値
+		UserID: input.UID,
+		Limit:  20, // デフォルト値
 	}
 	if input.Name != "" {
 		query.Name = &input.Name
@@ -49,11 +68,30 @@ func (u UserUseCaseImpl) Search(ctx context.Context, input handler.UserSearchInp
 		return nil, err
 	}
 
+	// メールアドレスを完全に指定した場合のみ連絡先以外のユーザーを検索し、部分一致による列挙を防ぐ
+	if isEmailAddress(input.Email) {
+		user, err := u.ur.FindDiscoverableByEmail(ctx, input.Email)
+		if err != nil && !errors.Is(err, &domain.NotFoundError{}) {
+			return nil, err
+		}
+		if err == nil && user.ID() != input.UID && !slices.ContainsFunc(users, func(c *domain.User) bool {
+			return c.ID() == user.ID()
+		}) {
+			users = append(users, user)
+		}
+	}
+
 	return &handler.UserSearchOutput{
 		Users: users,
 	}, nil
 }
 
+// isEmailAddress 表示名などを含まないメールアドレスかどうか
+func isEmailAddress(s string) bool {
+	addr, err := mail.ParseAddress(s)
+	return err == nil && addr.Address == s
+}
+
 // Get ユーザーを取得する
 func (u UserUseCaseImpl) Get(ctx context.Context, input handler.UserGetInput) (output *handler.UserGetOutput, err error) {
 	ctx, span := tracer.Start(ctx, "usecase.User.Get")
@@ -70,8 +108,21 @@ func (u UserUseCaseImpl) Get(ctx context.Context, input handler.UserGetInput) (o
 		return nil, err
 	}
 
+	// メールアドレスは検索と同じく自分自身と連絡先のユーザーにのみ公開する
+	emailVisible := user.ID() == input.UID
+	if !emailVisible {
+		contacts, err := u.ctr.FindByUserID(ctx, input.UID)
+		if err != nil {
+			return nil, err
+		}
+		emailVisible = slices.ContainsFunc(contacts, func(c *domain.Contact) bool {
+			return c.User().ID() == user.ID()
+		})
+	}
+
 	return &handler.UserGetOutput{
-		User: user,
+		User:         user,
+		EmailVisible: emailVisible,
 	}, nil
 }
 
@@ -126,3 +177,152 @@ func (u UserUseCaseImpl) UpdateMe(ctx context.Context, input handler.UserUpdateM
 		User: updatedUser,
 	}, nil
 }
+
+// DeleteMe 退会する
+// 未精算の貸し借りがある場合は退会できない
+// 他のメンバーとの立て替えや返済の記録を保つため、ユーザーは削除せずに匿名化する
+func (u UserUseCaseImpl) DeleteMe(ctx context.Context, input handler.UserDeleteMeInput) (err error) {
+	ctx, span := tracer.Start(ctx, "usecase.User.DeleteMe")
+	defer func() {
+		if err != nil {
+			span.SetStatus(codes.Error, err.Error())
+			span.RecordError(err)
+		}
+		span.End()
+	}()
+
+	user, err := u.ur.FindByID(ctx, input.UID)
+	if err != nil {
+		return err
+	}
+
+	anonymized, err := user.Anonymize(ctx)
+	if err != nil {
+		return err
+	}
+
+	// 未精算の貸し借りの確認と作成したグループの引き継ぎも匿名化と同時に行う
+	err = u.ur.Anonymize(ctx, user, anonymized)
+	if err != nil {
+		return err
+	}
+	logger.FromContext(ctx).InfoContext(ctx, "ユーザーを匿名化しました", slog.String("anonymized_id", anonymized.ID()))
+
+	return nil
+}
+
+// ExportMe 自分に関するデータを全て取得する
+func (u UserUseCaseImpl) ExportMe(ctx context.Context, input handler.UserExportMeInput) (output *handler.UserExportMeOutput, err error) {
+	ctx, span := tracer.Start(ctx, "usecase.User.ExportMe")
+	defer func() {
+		if err != nil {
+			span.SetStatus(codes.Error, err.Error())
+			span.RecordError(err)
+		}
+		span.End()
+	}()
+
+	user, err := u.ur.FindByID(ctx, input.UID)
+	if err != nil {
+		return nil, err
+	}
+
+	groups, err := u.gr.FindByMemberUserID(ctx, user.ID())
+	if err != nil {
+		return nil, err
+	}
+
+	output = &handler.UserExportMeOutput{
+		User:       user,
+		Groups:     groups,
+		Lendings:   []*domain.LendingSearchResult{},
+		Repayments: []*domain.Repayment{},
+	}
+
+	// 脱退したグループや個人間の立て替えも含める
+	limit := exportPageSize
+	var cursor *string
+	for {
+		lendings, err := u.lr.FindByUserID(ctx, user.ID(), cursor, limit)
+		if err != nil {
+			return nil, err
+		}
+		output.Lendings = append(output.Lendings, lendings...)
+		if int32(len(lendings)) < limit {
+			break
+		}
+		next := lendings[len(lendings)-1].Lending.ID().String()
+		cursor = &next
+	}
+
+	// 支払った返済と受け取った返済の両方を含める
+	for _, find := range []func(context.Context, string, *string, *int32) ([]*domain.Repayment, error){
+		u.rr.FindByPayerID,
+		u.rr.FindByDebtorID,
+	} {
+		limit := exportPageSize
+		var cursor *string
+		for {
+			repayments, err := find(ctx, user.ID(), cursor, &limit)
+			if err != nil {
+				return nil, err
+			}
+			output.Repayments = append(output.Repayments, repayments...)
+			if int32(len(repayments)) < limit {
+				break
+			}
+			next := repayments[len(repayments)-1].ID().String()
+			cursor = &next
+		}
+	}
+
+	output.Credits, err = u.cr.FindByUserID(ctx, user.ID())
+	if err != nil {
+		return nil, err
+	}
+
+	return output, nil
+}
+
+// GetPrivacy 自身のプライバシー設定を取得する
+func (u UserUseCaseImpl) GetPrivacy(ctx context.Context, input handler.UserGetPrivacyInput) (output *handler.UserGetPrivacyOutput, err error) {
+	ctx, span := tracer.Start(ctx, "usecase.User.GetPrivacy")
+	defer func() {
+		if err != nil {
+			span.SetStatus(codes.Error, err.Error())
+			span.RecordError(err)
+		}
+		span.End()
+	}()
+
+	privacy, err := u.ur.FindPrivacy(ctx, input.UID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &handler.UserGetPrivacyOutput{
+		Privacy: privacy,
+	}, nil
+}
+
+// UpdatePrivacy 自身のプライバシー設定を更新する
+func (u UserUseCaseImpl) UpdatePrivacy(ctx context.Context, input handler.UserUpdatePrivacyInput) (output *handler.UserUpdatePrivacyOutput, err error) {
+	ctx, span := tracer.Start(ctx, "usecase.User.UpdatePrivacy")
+	defer func() {
+		if err != nil {
+			span.SetStatus(codes.Error, err.Error())
+			span.RecordError(err)
+		}
+		span.End()
+	}()
+
+	privacy := domain.NewUserPrivacy(input.DiscoverableByEmail)
+	err = u.ur.UpdatePrivacy(ctx, input.UID, privacy)
+	if err != nil {
+		return nil, err
+	}
+
+	return &handler.UserUpdatePrivacyOutput{
+		Privacy: privacy,
+	}, nil
+}
@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS event_payments;
+DROP TABLE IF EXISTS payments;
+DROP TABLE IF EXISTS events;
+DROP TABLE IF EXISTS group_members;
+DROP TABLE IF EXISTS groups;
+DROP TABLE IF EXISTS users;
@@ -1,4 +1,8 @@
-CREATE TABLE users (
+-- 初期スキーマ
+-- マイグレーション導入前の schema.sql と同じ内容で、適用済みのデータベースでも実行できるようにIF NOT EXISTSを付ける
+-- 以降に追加したテーブルやカラムは後続のマイグレーションで追加する
+
+CREATE TABLE IF NOT EXISTS users (
   id TEXT PRIMARY KEY,
   name TEXT NOT NULL,
   avatar TEXT NOT NULL,
@@ -7,7 +11,7 @@ CREATE TABLE users (
   updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT current_timestamp
 );
 
-CREATE TABLE groups (
+CREATE TABLE IF NOT EXISTS groups (
   id TEXT PRIMARY KEY,
   name TEXT NOT NULL,
   created_by TEXT NOT NULL REFERENCES users(id) ON UPDATE CASCADE,
@@ -15,19 +19,19 @@ CREATE TABLE groups (
   updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT current_timestamp
 );
 
-CREATE INDEX idx_groups_created_by ON groups(created_by);
+CREATE INDEX IF NOT EXISTS idx_groups_created_by ON groups(created_by);
 
-CREATE TABLE group_members (
+CREATE TABLE IF NOT EXISTS group_members (
   group_id TEXT NOT NULL REFERENCES groups(id) ON DELETE CASCADE ON UPDATE CASCADE,
   user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE ON UPDATE CASCADE,
   created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT current_timestamp,
   PRIMARY KEY (group_id, user_id)
 );
 
-CREATE INDEX idx_group_members_group_id ON group_members(group_id);
-CREATE INDEX idx_group_members_user_id ON group_members(user_id);
+CREATE INDEX IF NOT EXISTS idx_group_members_group_id ON group_members(group_id);
+CREATE INDEX IF NOT EXISTS idx_group_members_user_id ON group_members(user_id);
 
-CREATE TABLE events (
+CREATE TABLE IF NOT EXISTS events (
   id TEXT PRIMARY KEY,
   group_id TEXT NOT NULL REFERENCES groups(id) ON DELETE CASCADE,
   name TEXT NOT NULL,
@@ -37,9 +41,9 @@ CREATE TABLE events (
   updated_at TIMESTAMP WITH TIME ZONE NOT NULL
 );
 
-CREATE INDEX idx_events_group_id ON events(group_id);
+CREATE INDEX IF NOT EXISTS idx_events_group_id ON events(group_id);
 
-CREATE TABLE payments (
+CREATE TABLE IF NOT EXISTS payments (
   id TEXT PRIMARY KEY,
   payer_id TEXT NOT NULL REFERENCES users(id) ON UPDATE CASCADE,
   debtor_id TEXT NOT NULL REFERENCES users(id) ON UPDATE CASCADE,
@@ -48,7 +52,7 @@ CREATE TABLE payments (
   updated_at TIMESTAMP WITH TIME ZONE NOT NULL
 );
 
-CREATE TABLE event_payments (
+CREATE TABLE IF NOT EXISTS event_payments (
   event_id TEXT NOT NULL REFERENCES events(id) ON DELETE CASCADE,
   payment_id TEXT NOT NULL REFERENCES payments(id) ON DELETE CASCADE,
   PRIMARY KEY (event_id, payment_id)
@@ -0,0 +1,2 @@
+-- 退会したユーザーは立て替えや返済の記録を保つため、匿名化した上で残す
+ALTER TABLE users ADD COLUMN deleted_at TIMESTAMP WITH TIME ZONE;
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_users_guest_group_id;
+ALTER TABLE users DROP COLUMN invite_token;
+ALTER TABLE users DROP COLUMN guest_group_id;
@@ -0,0 +1,8 @@
+-- アカウントを持たないゲストメンバー
+-- ゲストはusersの行として作成し、作成したグループにのみ所属できる
+-- 立て替えの支払い者や債務者として扱えるように、登録ユーザーと同じテーブルに保存する
+ALTER TABLE users ADD COLUMN guest_group_id TEXT REFERENCES groups(id) ON DELETE CASCADE ON UPDATE CASCADE;
+-- ゲストを登録ユーザーに統合するための招待トークン
+ALTER TABLE users ADD COLUMN invite_token TEXT UNIQUE;
+
+CREATE INDEX IF NOT EXISTS idx_users_guest_group_id ON users(guest_group_id);
@@ -0,0 +1,3 @@
+ALTER TABLE users DROP COLUMN discoverable_by_email;
+
+DROP TABLE IF EXISTS contacts;
@@ -0,0 +1,13 @@
+-- 明示的に追加した連絡先
+-- 同じグループのメンバーは追加しなくても連絡先として扱う
+CREATE TABLE IF NOT EXISTS contacts (
+  user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE ON UPDATE CASCADE,
+  contact_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE ON UPDATE CASCADE,
+  created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT current_timestamp,
+  PRIMARY KEY (user_id, contact_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_contacts_contact_id ON contacts(contact_id);
+
+-- 連絡先ではないユーザーからメールアドレスの完全一致で検索できるかどうか
+ALTER TABLE users ADD COLUMN discoverable_by_email BOOLEAN NOT NULL DEFAULT true;
@@ -0,0 +1,10 @@
+DELETE FROM payments
+WHERE id IN (
+  SELECT ep.payment_id
+  FROM event_payments ep
+  INNER JOIN events e ON ep.event_id = e.id
+  WHERE e.group_id IS NULL
+);
+DELETE FROM events WHERE group_id IS NULL;
+
+ALTER TABLE events ALTER COLUMN group_id SET NOT NULL;
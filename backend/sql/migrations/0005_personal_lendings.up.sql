@@ -0,0 +1,2 @@
+-- グループに属さない個人間の立て替えは group_id を NULL とする
+ALTER TABLE events ALTER COLUMN group_id DROP NOT NULL;
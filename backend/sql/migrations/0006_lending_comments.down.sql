@@ -0,0 +1 @@
+DROP TABLE IF EXISTS lending_comments;
@@ -0,0 +1,13 @@
+-- 立て替えに対するコメント
+-- 立て替えの削除時にコメントも削除する
+CREATE TABLE IF NOT EXISTS lending_comments (
+  id TEXT PRIMARY KEY,
+  event_id TEXT NOT NULL REFERENCES events(id) ON DELETE CASCADE,
+  user_id TEXT NOT NULL REFERENCES users(id) ON UPDATE CASCADE,
+  body TEXT NOT NULL,
+  created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+  updated_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_lending_comments_event_id ON lending_comments(event_id, id);
+CREATE INDEX IF NOT EXISTS idx_lending_comments_user_id ON lending_comments(user_id);
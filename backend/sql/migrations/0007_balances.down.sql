@@ -0,0 +1,4 @@
+DROP TRIGGER IF EXISTS payments_update_balances ON payments;
+DROP FUNCTION IF EXISTS payments_update_balances();
+DROP FUNCTION IF EXISTS apply_payment_to_balances(TEXT, TEXT, BIGINT);
+DROP TABLE IF EXISTS balances;
@@ -0,0 +1,56 @@
+-- ユーザーの組ごとの残高
+-- amount は user_id から見た other_user_id との残高 (正=貸している、負=借りている) で、
+-- 同じ組の2行は常に符号を反転した値になる
+-- ユーザーIDの変更は payments の更新として反映されるため、users は参照しない
+CREATE TABLE IF NOT EXISTS balances (
+  user_id TEXT NOT NULL,
+  other_user_id TEXT NOT NULL,
+  amount BIGINT NOT NULL,
+  PRIMARY KEY (user_id, other_user_id)
+);
+
+-- 支払いの変更をその支払いを書き込むトランザクション内で残高に反映する
+CREATE OR REPLACE FUNCTION apply_payment_to_balances(payer TEXT, debtor TEXT, delta BIGINT) RETURNS void AS $$
+BEGIN
+  -- 支払い者自身の負担分は残高に影響しない
+  IF payer = debtor OR delta = 0 THEN
+    RETURN;
+  END IF;
+
+  -- 同じ組を更新するトランザクション同士のデッドロックを避けるため、ユーザーIDの順に更新する
+  INSERT INTO balances (user_id, other_user_id, amount)
+  SELECT v.user_id, v.other_user_id, v.amount
+  FROM (VALUES (payer, debtor, delta), (debtor, payer, -delta)) AS v(user_id, other_user_id, amount)
+  ORDER BY v.user_id
+  ON CONFLICT (user_id, other_user_id) DO UPDATE SET amount = balances.amount + EXCLUDED.amount;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION payments_update_balances() RETURNS trigger AS $$
+BEGIN
+  IF TG_OP IN ('UPDATE', 'DELETE') THEN
+    PERFORM apply_payment_to_balances(OLD.payer_id, OLD.debtor_id, -OLD.amount::bigint);
+  END IF;
+  IF TG_OP IN ('INSERT', 'UPDATE') THEN
+    PERFORM apply_payment_to_balances(NEW.payer_id, NEW.debtor_id, NEW.amount::bigint);
+  END IF;
+  RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS payments_update_balances ON payments;
+CREATE TRIGGER payments_update_balances
+AFTER INSERT OR DELETE OR UPDATE OF payer_id, debtor_id, amount ON payments
+FOR EACH ROW EXECUTE FUNCTION payments_update_balances();
+
+-- 既存の支払いから残高を作成する
+LOCK TABLE payments IN SHARE MODE;
+DELETE FROM balances;
+INSERT INTO balances (user_id, other_user_id, amount)
+SELECT payer_id, debtor_id, SUM(amount)
+FROM (
+  SELECT payer_id, debtor_id, amount FROM payments WHERE payer_id != debtor_id
+  UNION ALL
+  SELECT debtor_id, payer_id, -amount FROM payments WHERE payer_id != debtor_id
+) AS p
+GROUP BY payer_id, debtor_id;
@@ -0,0 +1,2 @@
+ALTER TABLE groups DROP COLUMN IF EXISTS payment_terms_days;
+ALTER TABLE events DROP COLUMN IF EXISTS due_date;
@@ -0,0 +1,7 @@
+-- 立て替えの返済期限
+-- due_date が NULL の立て替えは期限を設けず、延滞として扱わない
+ALTER TABLE events ADD COLUMN IF NOT EXISTS due_date TIMESTAMP WITH TIME ZONE;
+
+-- グループの立て替えの既定の支払い期限 (立て替えの日付からの日数)
+-- 返済期限を指定せずに作成・更新した立て替えに適用する
+ALTER TABLE groups ADD COLUMN IF NOT EXISTS payment_terms_days INT;
@@ -0,0 +1,4 @@
+-- 立て替えの名前の部分一致・類似度検索
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE INDEX IF NOT EXISTS idx_events_name_trgm ON events USING gin (name gin_trgm_ops);
@@ -0,0 +1,3 @@
+-- 立て替えの作成者
+-- 複数の支払い者がいる立て替えで作成者を特定するために記録する (既存の行はNULLで、最初の支払い者を作成者とみなす)
+ALTER TABLE events ADD COLUMN IF NOT EXISTS created_by TEXT REFERENCES users(id) ON UPDATE CASCADE;
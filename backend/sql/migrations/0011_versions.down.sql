@@ -0,0 +1,3 @@
+ALTER TABLE payments DROP COLUMN IF EXISTS version;
+ALTER TABLE events DROP COLUMN IF EXISTS version;
+ALTER TABLE groups DROP COLUMN IF EXISTS version;
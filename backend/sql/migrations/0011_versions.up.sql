@@ -0,0 +1,4 @@
+-- 楽観的排他制御のためのバージョン (更新のたびに1ずつ増える)
+ALTER TABLE groups ADD COLUMN IF NOT EXISTS version INT NOT NULL DEFAULT 1;
+ALTER TABLE events ADD COLUMN IF NOT EXISTS version INT NOT NULL DEFAULT 1;
+ALTER TABLE payments ADD COLUMN IF NOT EXISTS version INT NOT NULL DEFAULT 1;
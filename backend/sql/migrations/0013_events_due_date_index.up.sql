@@ -0,0 +1,2 @@
+-- 延滞額の集計は返済期限のある立て替えのみを対象にする
+CREATE INDEX IF NOT EXISTS idx_events_due_date ON events(due_date) WHERE due_date IS NOT NULL;
@@ -0,0 +1,2 @@
+-- 補正したデータは正しい状態のため元に戻さない
+SELECT 1;
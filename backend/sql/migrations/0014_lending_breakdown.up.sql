@@ -0,0 +1,43 @@
+-- 支払い者自身の負担額を記録する前の立て替えを、金額と支払いの合計が一致するように補正する
+-- 支払い者自身の負担分 (payer_id = debtor_id) と金額は残高に影響しないため、補正しても残高は変わらない
+
+-- 作成者が記録されていない立て替えは最初の支払い者を作成者とする
+UPDATE events e
+SET created_by = (
+  SELECT p.payer_id
+  FROM payments p
+  INNER JOIN event_payments ep ON p.id = ep.payment_id
+  WHERE ep.event_id = e.id
+  ORDER BY p.id
+  LIMIT 1
+)
+WHERE e.created_by IS NULL;
+
+-- 支払いの合計が金額に満たない立て替えは、差額を作成者自身の負担分として支払いに追加する
+WITH shortfalls AS (
+  SELECT gen_random_uuid()::text AS payment_id, e.id AS event_id, e.created_by, e.amount - SUM(p.amount) AS amount, e.created_at, e.updated_at
+  FROM events e
+  INNER JOIN event_payments ep ON e.id = ep.event_id
+  INNER JOIN payments p ON ep.payment_id = p.id
+  WHERE e.created_by IS NOT NULL
+  GROUP BY e.id
+  HAVING e.amount > SUM(p.amount)
+), inserted AS (
+  INSERT INTO payments (id, payer_id, debtor_id, amount, created_at, updated_at)
+  SELECT payment_id, created_by, created_by, amount, created_at, updated_at
+  FROM shortfalls
+)
+INSERT INTO event_payments (event_id, payment_id)
+SELECT event_id, payment_id
+FROM shortfalls;
+
+-- 債務の合計が金額を超えている立て替えは、金額を支払いの合計に合わせる
+UPDATE events e
+SET amount = t.total
+FROM (
+  SELECT ep.event_id, SUM(p.amount) AS total
+  FROM event_payments ep
+  INNER JOIN payments p ON ep.payment_id = p.id
+  GROUP BY ep.event_id
+) AS t
+WHERE e.id = t.event_id AND e.amount < t.total;
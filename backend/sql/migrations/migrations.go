@@ -0,0 +1,10 @@
+// Package migrations バイナリに埋め込むデータベースのマイグレーション
+//
+// ファイル名は {バージョン}_{名前}.up.sql / {バージョン}_{名前}.down.sql の形式で、
+// バージョンの昇順に適用する
+package migrations
+
+import "embed"
+
+//go:embed *.sql
+var FS embed.FS
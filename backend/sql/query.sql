@@ -1,23 +1,153 @@
 -- name: FindAllUsers :many
 SELECT id, name, avatar, email, created_at, updated_at FROM users;
 
--- name: FindUsersBySearch :many
-SELECT id, name, avatar, email, created_at, updated_at
-FROM users
-WHERE (sqlc.narg('name')::text IS NOT NULL AND name ILIKE '%' || sqlc.narg('name') || '%')
-   OR (sqlc.narg('email')::text IS NOT NULL AND email ILIKE '%' || sqlc.narg('email') || '%')
-ORDER BY name ASC
+-- name: FindContactsBySearch :many
+SELECT u.id, u.name, u.avatar, u.email
+FROM users u
+WHERE u.deleted_at IS NULL
+  AND u.guest_group_id IS NULL
+  AND u.id <> sqlc.arg('user_id')
+  AND (
+    EXISTS (
+      SELECT 1 FROM contacts c
+      WHERE c.user_id = sqlc.arg('user_id') AND c.contact_id = u.id
+    )
+    OR EXISTS (
+      SELECT 1 FROM group_members gm1
+      INNER JOIN group_members gm2 ON gm1.group_id = gm2.group_id
+      WHERE gm1.user_id = sqlc.arg('user_id') AND gm2.user_id = u.id
+    )
+  )
+  AND (
+    (sqlc.narg('name')::text IS NOT NULL AND u.name ILIKE '%' || sqlc.narg('name') || '%')
+    OR (sqlc.narg('email')::text IS NOT NULL AND u.email ILIKE '%' || sqlc.narg('email') || '%')
+  )
+ORDER BY u.name ASC
 LIMIT sqlc.arg('limit');
 
+-- name: FindDiscoverableUserByEmail :one
+SELECT id, name, avatar, email
+FROM users
+WHERE lower(email) = lower(sqlc.arg('email'))
+  AND deleted_at IS NULL
+  AND guest_group_id IS NULL
+  AND discoverable_by_email
+LIMIT 1;
+
+-- name: FindContactsByUserID :many
+SELECT u.id, u.name, u.avatar, u.email,
+  EXISTS (
+    SELECT 1 FROM contacts c
+    WHERE c.user_id = sqlc.arg('user_id') AND c.contact_id = u.id
+  ) AS added
+FROM users u
+WHERE u.deleted_at IS NULL
+  AND u.guest_group_id IS NULL
+  AND u.id <> sqlc.arg('user_id')
+  AND (
+    EXISTS (
+      SELECT 1 FROM contacts c
+      WHERE c.user_id = sqlc.arg('user_id') AND c.contact_id = u.id
+    )
+    OR EXISTS (
+      SELECT 1 FROM group_members gm1
+      INNER JOIN group_members gm2 ON gm1.group_id = gm2.group_id
+      WHERE gm1.user_id = sqlc.arg('user_id') AND gm2.user_id = u.id
+    )
+  )
+ORDER BY u.name ASC;
+
+-- name: AddContact :exec
+INSERT INTO contacts (user_id, contact_id, created_at)
+VALUES ($1, $2, current_timestamp);
+
+-- name: DeleteContact :execrows
+DELETE FROM contacts WHERE user_id = $1 AND contact_id = $2;
+
+-- name: MoveContacts :exec
+INSERT INTO contacts (user_id, contact_id, created_at)
+SELECT sqlc.arg('to_id'), c.contact_id, c.created_at
+FROM contacts c
+WHERE c.user_id = sqlc.arg('from_id') AND c.contact_id <> sqlc.arg('to_id')
+ON CONFLICT DO NOTHING;
+
+-- name: MoveContactsContactID :exec
+INSERT INTO contacts (user_id, contact_id, created_at)
+SELECT c.user_id, sqlc.arg('to_id'), c.created_at
+FROM contacts c
+WHERE c.contact_id = sqlc.arg('from_id') AND c.user_id <> sqlc.arg('to_id')
+ON CONFLICT DO NOTHING;
+
+-- name: DeleteContactsByUserID :exec
+DELETE FROM contacts WHERE user_id = $1 OR contact_id = $1;
+
+-- name: FindUserPrivacyByID :one
+SELECT discoverable_by_email FROM users WHERE id = $1 LIMIT 1;
+
+-- name: UpdateUserPrivacy :exec
+UPDATE users SET discoverable_by_email = $2, updated_at = current_timestamp WHERE id = $1;
+
 -- name: FindUserByID :one
-SELECT id, name, avatar, email, created_at, updated_at FROM users WHERE id = $1 LIMIT 1;
+SELECT id, name, avatar, email, guest_group_id, created_at, updated_at FROM users WHERE id = $1 LIMIT 1;
+
+-- name: FindUsersByIDs :many
+SELECT id, name, avatar, email, guest_group_id FROM users
+WHERE id = ANY(sqlc.arg('ids')::text[])
+ORDER BY id;
 
 -- name: FindUserByEmail :one
-SELECT id, name, avatar, email, created_at, updated_at FROM users WHERE email = $1 LIMIT 1;
+SELECT id, name, avatar, email, created_at, updated_at FROM users WHERE email = $1 AND guest_group_id IS NULL LIMIT 1;
+
+-- name: CreateGuest :exec
+INSERT INTO users (id, name, avatar, email, guest_group_id, invite_token, created_at, updated_at)
+VALUES ($1, $2, '', $3, $4, $5, current_timestamp, current_timestamp);
+
+-- name: FindGuestsByGroupID :many
+SELECT id, name, email, guest_group_id::text AS group_id, invite_token::text AS invite_token
+FROM users
+WHERE guest_group_id = $1
+ORDER BY created_at ASC;
+
+-- name: FindGuestByInviteToken :one
+SELECT id, name, email, guest_group_id::text AS group_id, invite_token::text AS invite_token
+FROM users
+WHERE invite_token = $1 AND guest_group_id IS NOT NULL
+LIMIT 1;
+
+-- name: FindGuestsByEmail :many
+SELECT id, name, email, guest_group_id::text AS group_id, invite_token::text AS invite_token
+FROM users
+WHERE email = $1 AND guest_group_id IS NOT NULL
+ORDER BY created_at ASC;
+
+-- name: DeleteGuestIfUnreferenced :exec
+DELETE FROM users u
+WHERE u.id = $1
+  AND u.guest_group_id IS NOT NULL
+  AND NOT EXISTS (
+    SELECT 1 FROM payments p WHERE p.payer_id = u.id OR p.debtor_id = u.id
+  );
 
 -- name: UpdateUserID :exec
 UPDATE users SET id = $2, updated_at = current_timestamp WHERE id = $1;
 
+-- name: AnonymizeUser :exec
+UPDATE users
+SET id = sqlc.arg('new_id'),
+    name = sqlc.arg('name'),
+    avatar = sqlc.arg('avatar'),
+    email = sqlc.arg('email'),
+    deleted_at = current_timestamp,
+    updated_at = current_timestamp
+WHERE id = sqlc.arg('id');
+
+-- name: LockUser :exec
+-- 退会の確認中に支払いが追加されないようにする
+SELECT id FROM users WHERE id = $1 FOR UPDATE;
+
+-- name: DeleteUser :exec
+DELETE FROM users WHERE id = $1;
+
 -- name: CreateUser :exec
 INSERT INTO users (id, name, avatar, email, created_at, updated_at)
 VALUES ($1, $2, $3, $4, current_timestamp, current_timestamp);
@@ -28,16 +158,20 @@ SET name = $2, avatar = $3, updated_at = current_timestamp
 WHERE id = $1;
 
 -- name: CreateEvent :exec
-INSERT INTO events (id, group_id, name, amount, event_date, created_at, updated_at)
-VALUES ($1, $2, $3, $4, $5, $6, $7);
+INSERT INTO events (id, group_id, name, amount, event_date, created_at, updated_at, created_by, due_date)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9);
 
 -- name: FindAllEvents :many
-SELECT id, group_id, name, amount, event_date, created_at, updated_at FROM events;
+SELECT id, group_id, name, amount, event_date, created_at, updated_at, due_date, created_by, version FROM events;
 
 -- name: FindEventById :one
-SELECT id, group_id, name, amount, event_date, created_at, updated_at
+SELECT id, group_id, name, amount, event_date, created_at, updated_at, due_date, created_by, version
 FROM events WHERE id = $1 LIMIT 1;
 
+-- name: FindEventsByIDs :many
+SELECT id, group_id, name, amount, event_date, created_at, updated_at, due_date, created_by, version
+FROM events WHERE id = ANY(sqlc.arg('ids')::text[]);
+
 -- name: FindAllLendingsByGroupIDAndUserIDWithCursor :many
 SELECT DISTINCT ON (e.id)
   e.id,
@@ -51,12 +185,49 @@ SELECT DISTINCT ON (e.id)
 FROM events e
 INNER JOIN event_payments ep ON e.id = ep.event_id
 INNER JOIN payments p ON ep.payment_id = p.id
-WHERE e.group_id = sqlc.arg('group_id')
+WHERE e.group_id = sqlc.arg('group_id')::text
   AND (p.payer_id = sqlc.arg('user_id') OR p.debtor_id = sqlc.arg('user_id'))
   AND (sqlc.narg('cursor')::text IS NULL OR e.id < sqlc.narg('cursor'))
 ORDER BY e.id DESC
 LIMIT sqlc.arg('limit');
 
+-- name: FindLendingsByUserIDWithCursor :many
+SELECT e.id, e.group_id
+FROM events e
+WHERE EXISTS (
+    SELECT 1
+    FROM event_payments ep
+    INNER JOIN payments p ON ep.payment_id = p.id
+    WHERE ep.event_id = e.id
+      AND (p.payer_id = sqlc.arg('user_id') OR p.debtor_id = sqlc.arg('user_id'))
+  )
+  AND (sqlc.narg('cursor')::text IS NULL OR e.id < sqlc.narg('cursor'))
+ORDER BY e.id DESC
+LIMIT sqlc.arg('limit');
+
+-- name: SearchLendingsByGroupIDsAndUserID :many
+SELECT
+  e.id,
+  e.group_id,
+  e.name,
+  e.amount,
+  e.event_date,
+  e.created_at,
+  e.updated_at,
+  GREATEST(similarity(e.name, sqlc.arg('query')::text), word_similarity(sqlc.arg('query')::text, e.name))::real AS rank
+FROM events e
+WHERE e.group_id = ANY(sqlc.arg('group_ids')::text[])
+  AND EXISTS (
+    SELECT 1
+    FROM event_payments ep
+    INNER JOIN payments p ON ep.payment_id = p.id
+    WHERE ep.event_id = e.id
+      AND (p.payer_id = sqlc.arg('user_id') OR p.debtor_id = sqlc.arg('user_id'))
+  )
+  AND (e.name ILIKE '%' || sqlc.arg('pattern')::text || '%' ESCAPE '\' OR sqlc.arg('query')::text <% e.name)
+ORDER BY rank DESC, e.event_date DESC, e.id DESC
+LIMIT sqlc.arg('limit');
+
 -- name: FindEventByGroupIDAndDebtorIDAndEventID :one
 SELECT
   e.id AS event_id,
@@ -69,23 +240,38 @@ SELECT
 FROM events e
 INNER JOIN event_payments ep ON e.id = ep.event_id
 INNER JOIN payments p ON ep.payment_id = p.id
-WHERE e.group_id = $1 AND p.debtor_id = $2 AND e.id = $3
+WHERE e.group_id = sqlc.arg('group_id')::text AND p.debtor_id = sqlc.arg('debtor_id') AND e.id = sqlc.arg('id')
 LIMIT 1;
 
--- name: CreatePayment :exec
-INSERT INTO payments (id, payer_id, debtor_id, amount, created_at, updated_at)
-VALUES ($1, $2, $3, $4, current_timestamp, current_timestamp);
-
--- name: CreateEventPayment :exec
+-- name: CreateEventPayments :exec
+-- 支払いの作成とイベントへの紐付けを1回のクエリで行う
+WITH inserted AS (
+  INSERT INTO payments (id, payer_id, debtor_id, amount, created_at, updated_at)
+  SELECT
+    unnest(sqlc.arg('ids')::text[]),
+    unnest(sqlc.arg('payer_ids')::text[]),
+    unnest(sqlc.arg('debtor_ids')::text[]),
+    unnest(sqlc.arg('amounts')::int[]),
+    current_timestamp,
+    current_timestamp
+  RETURNING id
+)
 INSERT INTO event_payments (event_id, payment_id)
-VALUES ($1, $2);
+SELECT sqlc.arg('event_id')::text, id FROM inserted;
 
 -- name: FindPaymentsByEventId :many
-SELECT p.id, p.payer_id, p.debtor_id, p.amount, p.created_at, p.updated_at
+SELECT p.id, p.payer_id, p.debtor_id, p.amount, p.created_at, p.updated_at, p.version
 FROM payments p
 INNER JOIN event_payments ep ON p.id = ep.payment_id
 WHERE ep.event_id = $1;
 
+-- name: FindPaymentsByEventIDs :many
+SELECT ep.event_id, p.payer_id, p.debtor_id, p.amount
+FROM payments p
+INNER JOIN event_payments ep ON p.id = ep.payment_id
+WHERE ep.event_id = ANY(sqlc.arg('event_ids')::text[])
+ORDER BY ep.event_id, p.id;
+
 -- name: FindDebtorsByEventIDs :many
 SELECT ep.event_id, u.id, u.name, u.avatar, u.email, p.amount
 FROM payments p
@@ -94,7 +280,7 @@ INNER JOIN users u ON p.debtor_id = u.id
 WHERE ep.event_id = ANY(sqlc.arg('event_ids')::text[]);
 
 -- name: FindPaymentByDebtorId :one
-SELECT p.id, p.payer_id, p.debtor_id, p.amount, p.created_at, p.updated_at
+SELECT p.id, p.payer_id, p.debtor_id, p.amount, p.created_at, p.updated_at, p.version
 FROM payments p
 INNER JOIN event_payments ep ON p.id = ep.payment_id
 WHERE ep.event_id = $1 AND p.debtor_id = $2 LIMIT 1;
@@ -111,39 +297,136 @@ DELETE FROM payments WHERE id = $1;
 -- name: DeleteEventPayment :exec
 DELETE FROM event_payments WHERE event_id = $1 AND payment_id = $2;
 
--- name: UpdateEvent :exec
+-- name: UpdateEvent :execrows
 UPDATE events
 SET name = $2,
     amount = $3,
     event_date = $4,
-    updated_at = $5
-WHERE id = $1;
+    updated_at = $5,
+    version = $6,
+    due_date = $7
+WHERE id = $1 AND version = $6 - 1;
 
 -- name: DeleteEvent :exec
 DELETE FROM events WHERE id = $1;
 
--- name: ListLendingCreditAmountsByUserID :many
-SELECT debtor_id AS user_id, SUM(amount)::bigint AS amount
-FROM payments
-WHERE payer_id = $1
-  AND payer_id != debtor_id
-GROUP BY debtor_id
-ORDER BY debtor_id;
-
--- name: ListBorrowingCreditAmountsByUserID :many
-SELECT payer_id AS user_id, SUM(amount)::bigint AS amount
-FROM payments
-WHERE debtor_id = $1
-  AND payer_id != debtor_id
-GROUP BY payer_id
-ORDER BY payer_id;
+-- name: CreateLendingComment :exec
+INSERT INTO lending_comments (id, event_id, user_id, body, created_at, updated_at)
+VALUES ($1, $2, $3, $4, $5, $6);
+
+-- name: FindLendingCommentByID :one
+SELECT c.id, c.event_id, c.body, c.created_at, c.updated_at, u.id AS user_id, u.name AS user_name, u.avatar AS user_avatar, u.email AS user_email
+FROM lending_comments c
+INNER JOIN users u ON c.user_id = u.id
+WHERE c.id = $1
+LIMIT 1;
+
+-- name: FindLendingCommentsByEventIDWithCursor :many
+SELECT c.id, c.event_id, c.body, c.created_at, c.updated_at, u.id AS user_id, u.name AS user_name, u.avatar AS user_avatar, u.email AS user_email
+FROM lending_comments c
+INNER JOIN users u ON c.user_id = u.id
+WHERE c.event_id = sqlc.arg('event_id')
+  AND (sqlc.narg('cursor')::text IS NULL OR c.id > sqlc.narg('cursor'))
+ORDER BY c.id ASC
+LIMIT sqlc.arg('limit');
+
+-- name: CountLendingCommentsByEventIDs :many
+SELECT event_id, COUNT(*)::int AS count
+FROM lending_comments
+WHERE event_id = ANY(sqlc.arg('event_ids')::text[])
+GROUP BY event_id;
+
+-- name: UpdateLendingComment :execrows
+UPDATE lending_comments SET body = $2, updated_at = $3 WHERE id = $1;
+
+-- name: DeleteLendingComment :execrows
+DELETE FROM lending_comments WHERE id = $1;
+
+-- name: ListBalancesByUserID :many
+SELECT other_user_id AS user_id, amount
+FROM balances
+WHERE user_id = $1
+  AND amount != 0
+ORDER BY other_user_id;
+
+-- name: ExistsBalanceByUserID :one
+SELECT EXISTS (
+  SELECT 1 FROM balances
+  WHERE user_id = $1 AND amount != 0
+);
+
+-- name: FindBalance :one
+SELECT amount
+FROM balances
+WHERE user_id = $1 AND other_user_id = $2;
+
+-- name: ListDatedDebtsByUserID :many
+-- 返済期限のある立て替えによる相手ユーザーごとの貸し/借りの合計と、そのうち返済期限前のもの
+-- 返済期限のない立て替えは延滞の判定に使わないため集計しない
+-- other_user_idを指定した場合はそのユーザーとの分のみ集計する
+SELECT
+  (CASE WHEN p.payer_id = sqlc.arg('user_id') THEN p.debtor_id ELSE p.payer_id END)::text AS user_id,
+  COALESCE(SUM(p.amount) FILTER (WHERE p.payer_id = sqlc.arg('user_id')), 0)::bigint AS lent,
+  COALESCE(SUM(p.amount) FILTER (WHERE p.debtor_id = sqlc.arg('user_id')), 0)::bigint AS borrowed,
+  COALESCE(SUM(p.amount) FILTER (WHERE p.payer_id = sqlc.arg('user_id') AND e.due_date >= current_timestamp), 0)::bigint AS lent_not_due,
+  COALESCE(SUM(p.amount) FILTER (WHERE p.debtor_id = sqlc.arg('user_id') AND e.due_date >= current_timestamp), 0)::bigint AS borrowed_not_due
+FROM events e
+INNER JOIN event_payments ep ON e.id = ep.event_id
+INNER JOIN payments p ON ep.payment_id = p.id
+WHERE e.due_date IS NOT NULL
+  AND (p.payer_id = sqlc.arg('user_id') OR p.debtor_id = sqlc.arg('user_id'))
+  AND p.payer_id <> p.debtor_id
+  AND (sqlc.narg('other_user_id')::text IS NULL OR p.payer_id = sqlc.narg('other_user_id') OR p.debtor_id = sqlc.narg('other_user_id'))
+GROUP BY 1;
+
+-- name: SumOutstandingCreditAmount :one
+SELECT COALESCE(SUM(amount), 0)::bigint AS amount
+FROM balances
+WHERE amount > 0;
+
+-- name: ListBalanceMismatches :many
+-- 支払いから集計した残高と残高テーブルの値が異なるユーザーの組
+SELECT
+  COALESCE(p.user_id, b.user_id)::text AS user_id,
+  COALESCE(p.other_user_id, b.other_user_id)::text AS other_user_id,
+  COALESCE(p.amount, 0)::bigint AS expected,
+  COALESCE(b.amount, 0)::bigint AS actual
+FROM (
+  SELECT payer_id AS user_id, debtor_id AS other_user_id, SUM(amount) AS amount
+  FROM (
+    SELECT payer_id, debtor_id, amount FROM payments WHERE payer_id != debtor_id
+    UNION ALL
+    SELECT debtor_id, payer_id, -amount FROM payments WHERE payer_id != debtor_id
+  ) AS directed
+  GROUP BY payer_id, debtor_id
+) AS p
+FULL OUTER JOIN balances b ON p.user_id = b.user_id AND p.other_user_id = b.other_user_id
+WHERE COALESCE(p.amount, 0) != COALESCE(b.amount, 0)
+ORDER BY 1, 2;
+
+-- name: LockPaymentsForBalanceRebuild :exec
+-- 作り直している間に支払いが変更されないようにする
+LOCK TABLE payments IN SHARE MODE;
+
+-- name: DeleteAllBalances :exec
+DELETE FROM balances;
+
+-- name: RebuildBalances :execrows
+INSERT INTO balances (user_id, other_user_id, amount)
+SELECT payer_id, debtor_id, SUM(amount)
+FROM (
+  SELECT payer_id, debtor_id, amount FROM payments WHERE payer_id != debtor_id
+  UNION ALL
+  SELECT debtor_id, payer_id, -amount FROM payments WHERE payer_id != debtor_id
+) AS directed
+GROUP BY payer_id, debtor_id;
 
 -- name: CreateRepayment :exec
 INSERT INTO payments (id, payer_id, debtor_id, amount, created_at, updated_at)
 VALUES ($1, $2, $3, $4, $5, $6);
 
 -- name: FindRepaymentsByPayerIDWithCursor :many
-SELECT p.id, p.payer_id, p.debtor_id, p.amount, p.created_at, p.updated_at
+SELECT p.id, p.payer_id, p.debtor_id, p.amount, p.created_at, p.updated_at, p.version
 FROM payments p
 LEFT JOIN event_payments ep ON p.id = ep.payment_id
 WHERE p.payer_id = sqlc.arg('payer_id')
@@ -152,24 +435,34 @@ WHERE p.payer_id = sqlc.arg('payer_id')
 ORDER BY p.id DESC
 LIMIT sqlc.arg('limit');
 
+-- name: FindRepaymentsByDebtorIDWithCursor :many
+SELECT p.id, p.payer_id, p.debtor_id, p.amount, p.created_at, p.updated_at, p.version
+FROM payments p
+LEFT JOIN event_payments ep ON p.id = ep.payment_id
+WHERE p.debtor_id = sqlc.arg('debtor_id')
+  AND ep.event_id IS NULL
+  AND (sqlc.narg('cursor')::text IS NULL OR p.id < sqlc.narg('cursor'))
+ORDER BY p.id DESC
+LIMIT sqlc.arg('limit');
+
 -- name: FindRepaymentByID :one
-SELECT p.id, p.payer_id, p.debtor_id, p.amount, p.created_at, p.updated_at
+SELECT p.id, p.payer_id, p.debtor_id, p.amount, p.created_at, p.updated_at, p.version
 FROM payments p
 LEFT JOIN event_payments ep ON p.id = ep.payment_id
 WHERE p.id = $1 AND ep.event_id IS NULL
 LIMIT 1;
 
--- name: UpdateRepayment :exec
+-- name: UpdateRepayment :execrows
 UPDATE payments
-SET amount = $2, updated_at = $3
-WHERE id = $1;
+SET amount = $2, updated_at = $3, version = $4
+WHERE id = $1 AND version = $4 - 1;
 
 -- name: DeleteRepayment :exec
 DELETE FROM payments WHERE id = $1;
 
 -- name: CreateGroup :exec
-INSERT INTO groups (id, name, created_by, created_at, updated_at)
-VALUES ($1, $2, $3, $4, $5);
+INSERT INTO groups (id, name, created_by, created_at, updated_at, payment_terms_days)
+VALUES ($1, $2, $3, $4, $5, $6);
 
 -- name: AddGroupMember :exec
 INSERT INTO group_members (group_id, user_id, created_at)
@@ -180,25 +473,39 @@ DELETE FROM group_members
 WHERE group_id = $1 AND user_id = $2;
 
 -- name: FindGroupByID :one
-SELECT id, name, created_by, created_at, updated_at
+SELECT id, name, created_by, created_at, updated_at, payment_terms_days, version
 FROM groups WHERE id = $1 LIMIT 1;
 
 -- name: FindGroupMembersByGroupID :many
 SELECT user_id FROM group_members
 WHERE group_id = $1 ORDER BY created_at ASC;
 
+-- name: ExistsGroupMember :one
+SELECT EXISTS (
+  SELECT 1 FROM group_members
+  WHERE group_id = $1 AND user_id = $2
+);
+
+-- name: FindGroupMemberIDsIn :many
+SELECT user_id FROM group_members
+WHERE group_id = sqlc.arg('group_id') AND user_id = ANY(sqlc.arg('user_ids')::text[])
+ORDER BY user_id;
+
 -- name: FindGroupMemberUsersByGroupID :many
-SELECT u.id, u.name, u.avatar, u.email
+SELECT u.id, u.name, u.avatar, u.email, u.guest_group_id
 FROM users u
 INNER JOIN group_members gm ON u.id = gm.user_id
 WHERE gm.group_id = $1
 ORDER BY gm.created_at ASC;
 
--- name: UpdateGroup :exec
+-- name: UpdateGroup :execrows
 UPDATE groups
 SET name = $2,
-    updated_at = $3
-WHERE id = $1;
+    created_by = $3,
+    updated_at = $4,
+    version = $5,
+    payment_terms_days = $6
+WHERE id = $1 AND version = $5 - 1;
 
 -- name: DeleteGroup :exec
 DELETE FROM groups
@@ -210,23 +517,99 @@ WHERE id IN (
   SELECT ep.payment_id
   FROM event_payments ep
   INNER JOIN events e ON ep.event_id = e.id
-  WHERE e.group_id = $1
-);
-
--- name: DeletePaymentsByGroupIDAndUserID :exec
-DELETE FROM payments
-WHERE id IN (
-  SELECT ep.payment_id
-  FROM event_payments ep
-  INNER JOIN events e ON ep.event_id = e.id
-  INNER JOIN payments p ON ep.payment_id = p.id
-  WHERE e.group_id = $1
-    AND (p.payer_id = $2 OR p.debtor_id = $2)
+  WHERE e.group_id = $1::text
 );
 
 -- name: FindGroupsByMemberUserID :many
-SELECT g.id, g.name, g.created_by, g.created_at, g.updated_at
+SELECT g.id, g.name, g.created_by, g.created_at, g.updated_at, g.payment_terms_days, g.version
 FROM groups g
 INNER JOIN group_members gm ON g.id = gm.group_id
 WHERE gm.user_id = $1
 ORDER BY g.created_at DESC;
+
+-- name: CreateIdempotencyKey :execrows
+INSERT INTO idempotency_keys (user_id, key, method, path, request_hash, created_at)
+VALUES ($1, $2, $3, $4, $5, $6)
+ON CONFLICT (user_id, key) DO NOTHING;
+
+-- name: FindIdempotencyKey :one
+SELECT user_id, key, method, path, request_hash, status_code, content_type, response_body, created_at
+FROM idempotency_keys
+WHERE user_id = $1 AND key = $2
+LIMIT 1;
+
+-- name: UpdateIdempotencyKeyResponse :exec
+UPDATE idempotency_keys
+SET status_code = $3, content_type = $4, response_body = $5
+WHERE user_id = $1 AND key = $2;
+
+-- name: DeleteIdempotencyKey :exec
+DELETE FROM idempotency_keys
+WHERE user_id = $1 AND key = $2;
+
+-- name: DeleteExpiredIdempotencyKey :exec
+DELETE FROM idempotency_keys
+WHERE user_id = $1 AND key = $2 AND created_at < $3;
+
+-- name: ListUnbalancedEvents :many
+SELECT e.id, e.group_id, e.name, e.amount, COALESCE(SUM(p.amount), 0)::bigint AS paid
+FROM events e
+LEFT JOIN event_payments ep ON e.id = ep.event_id
+LEFT JOIN payments p ON ep.payment_id = p.id
+GROUP BY e.id
+HAVING COALESCE(SUM(p.amount), 0) != e.amount
+ORDER BY e.id;
+
+-- name: MoveGroupMembers :exec
+INSERT INTO group_members (group_id, user_id, created_at)
+SELECT gm.group_id, sqlc.arg('to_id'), gm.created_at
+FROM group_members gm
+WHERE gm.user_id = sqlc.arg('from_id')
+ON CONFLICT (group_id, user_id) DO NOTHING;
+
+-- name: FindGroupSuccessorsByCreatedBy :many
+-- 作成者の次に古くから所属しているメンバー (他にメンバーがいない場合は空文字)
+SELECT g.id, COALESCE(s.user_id, '') AS successor_id
+FROM groups g
+LEFT JOIN LATERAL (
+  SELECT gm.user_id FROM group_members gm
+  WHERE gm.group_id = g.id AND gm.user_id != g.created_by
+  ORDER BY gm.created_at ASC
+  LIMIT 1
+) s ON true
+WHERE g.created_by = $1
+ORDER BY g.id;
+
+-- name: TransferGroupOwnership :exec
+UPDATE groups
+SET created_by = sqlc.arg('created_by'),
+    updated_at = current_timestamp,
+    version = version + 1
+WHERE id = sqlc.arg('id');
+
+-- name: DeleteGroupMembersByUserID :exec
+DELETE FROM group_members WHERE user_id = $1;
+
+-- name: MoveGroupsCreatedBy :exec
+UPDATE groups SET created_by = sqlc.arg('to_id') WHERE created_by = sqlc.arg('from_id');
+
+-- name: MoveEventsCreatedBy :exec
+UPDATE events SET created_by = sqlc.arg('to_id') WHERE created_by = sqlc.arg('from_id');
+
+-- name: MovePaymentsPayerID :exec
+UPDATE payments SET payer_id = sqlc.arg('to_id'), updated_at = current_timestamp WHERE payer_id = sqlc.arg('from_id');
+
+-- name: MovePaymentsDebtorID :exec
+UPDATE payments SET debtor_id = sqlc.arg('to_id'), updated_at = current_timestamp WHERE debtor_id = sqlc.arg('from_id');
+
+-- name: DeleteSelfRepaymentsByUserID :exec
+DELETE FROM payments p
+WHERE p.payer_id = $1
+  AND p.debtor_id = $1
+  AND NOT EXISTS (SELECT 1 FROM event_payments ep WHERE ep.payment_id = p.id);
+
+-- name: MoveLendingCommentsUserID :exec
+UPDATE lending_comments SET user_id = sqlc.arg('to_id') WHERE user_id = sqlc.arg('from_id');
+
+-- name: DeleteIdempotencyKeysByUserID :exec
+DELETE FROM idempotency_keys WHERE user_id = $1;
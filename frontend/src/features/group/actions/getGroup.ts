@@ -3,14 +3,18 @@
 import { getAuthToken } from "@/libs/auth/getAuthToken";
 import { createApiClient } from "@/libs/api/client";
 import type { Result } from "@/utils/types";
-import type { Group } from "../types";
+import type { EditableGroup } from "../types";
 
-export async function getGroup(id: string): Promise<Result<Group>> {
+export async function getGroup(id: string): Promise<Result<EditableGroup>> {
   const token = await getAuthToken();
   const client = createApiClient(token);
 
   // Fetch group data
-  const { data: response, error } = await client.GET("/groups/{id}", {
+  const {
+    data: response,
+    error,
+    response: httpResponse,
+  } = await client.GET("/groups/{id}", {
     params: { path: { id } },
   });
 
@@ -39,12 +43,13 @@ export async function getGroup(id: string): Promise<Result<Group>> {
   }
 
   // Transform to frontend Group type
-  const group: Group = {
+  const group: EditableGroup = {
     id: response.id,
     name: response.name,
     creator,
     createdAt: response.createdAt,
     updatedAt: response.updatedAt,
+    etag: httpResponse.headers.get("ETag") ?? "",
   };
 
   return {
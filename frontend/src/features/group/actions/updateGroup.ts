@@ -14,13 +14,14 @@ export async function updateGroup(_: unknown, formData: FormData) {
     return submission.reply();
   }
 
-  const { id, name } = submission.value;
+  const { id, etag, name } = submission.value;
 
   const token = await getAuthToken();
   const client = createApiClient(token);
 
   const { error } = await client.PUT("/groups/{id}", {
     params: { path: { id } },
+    headers: { "If-Match": etag },
     body: { name },
   });
 
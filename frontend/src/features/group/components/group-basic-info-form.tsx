@@ -11,12 +11,12 @@ import {
   deleteGroup,
 } from "@/features/group/actions/deleteGroup";
 import { updateGroup } from "@/features/group/actions/updateGroup";
-import type { Group } from "@/features/group/types";
+import type { EditableGroup } from "@/features/group/types";
 import { cn } from "@/utils/cn";
 import { updateGroupSchema } from "../schema";
 
 type Props = {
-  group: Group;
+  group: EditableGroup;
   currentUserId: string;
 };
 
@@ -26,7 +26,7 @@ export function GroupBasicInfoForm({ group, currentUserId }: Props) {
     updateGroup,
     undefined,
   );
-  const [form, { id, name }] = useForm({
+  const [form, { id, etag, name }] = useForm({
     lastResult,
     defaultValue: group,
     onValidate({ formData }) {
@@ -56,6 +56,7 @@ export function GroupBasicInfoForm({ group, currentUserId }: Props) {
         <h2 className={cn("text-lg font-semibold")}>基本情報</h2>
 
         <input type="hidden" name={id.name} value={group.id} readOnly />
+        <input type="hidden" name={etag.name} value={group.etag} readOnly />
 
         <label htmlFor={name.id} className={cn("text-sm")}>
           グループ名
@@ -6,6 +6,7 @@ export const createGroupSchema = z.object({
 
 export const updateGroupSchema = z.object({
   id: z.string(),
+  etag: z.string(),
   name: z.string().min(1, "グループ名を入力してください"),
 });
 
@@ -26,6 +26,13 @@ export type Group = {
   updatedAt: string;
 };
 
+/**
+ * Group with the ETag sent as If-Match when updating it
+ */
+export type EditableGroup = Group & {
+  etag: string;
+};
+
 export type CreateGroupRequest = {
   name: string;
 };
@@ -3,16 +3,16 @@
 import { getAuthToken } from "@/libs/auth/getAuthToken";
 import { createApiClient } from "@/libs/api/client";
 import type { Result } from "@/utils/types";
-import type { Lending } from "../types";
+import type { EditableLending } from "../types";
 
 export async function getLending(
   groupId: string,
   id: string,
-): Promise<Result<Lending>> {
+): Promise<Result<EditableLending>> {
   const token = await getAuthToken();
   const client = createApiClient(token);
 
-  const { data, error } = await client.GET(
+  const { data, error, response } = await client.GET(
     "/groups/{id}/lendings/{lendingId}",
     {
       params: { path: { id: groupId, lendingId: id } },
@@ -29,7 +29,7 @@ export async function getLending(
 
   return {
     success: true,
-    result: data,
+    result: { ...data, etag: response.headers.get("ETag") ?? "" },
     error: null,
   };
 }
@@ -20,13 +20,14 @@ export async function updateLending(
     return submission.reply();
   }
 
-  const { id, name, amount, eventDate, debts } = submission.value;
+  const { id, etag, name, amount, eventDate, debts } = submission.value;
 
   const token = await getAuthToken();
   const client = createApiClient(token);
 
   const { error } = await client.PUT("/groups/{id}/lendings/{lendingId}", {
     params: { path: { id: groupId, lendingId: id } },
+    headers: { "If-Match": etag },
     body: {
       name,
       amount,
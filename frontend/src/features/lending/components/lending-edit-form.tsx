@@ -12,11 +12,11 @@ import { ErrorText } from "@/components/ui/error-text";
 import { updateLending } from "../actions/updateLending";
 import { updateLendingSchema } from "../schema";
 import type { GroupMember } from "@/features/group/types";
-import type { Lending } from "../types";
+import type { EditableLending } from "../types";
 
 type Props = {
   groupId: string;
-  lending: Lending;
+  lending: EditableLending;
   members: GroupMember[];
   currentUserId: string;
 };
@@ -111,6 +111,12 @@ export function LendingEditForm({
       <h2 className={cn("text-lg font-semibold")}>イベント情報</h2>
 
       <input type="hidden" name={fields.id.name} value={lending.id} readOnly />
+      <input
+        type="hidden"
+        name={fields.etag.name}
+        value={lending.etag}
+        readOnly
+      />
 
       <label htmlFor={fields.name.id} className={cn("text-sm")}>
         名前
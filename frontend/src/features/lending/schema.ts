@@ -14,6 +14,7 @@ export const createLendingSchema = z.object({
 
 export const updateLendingSchema = z.object({
   id: z.string(),
+  etag: z.string(),
   name: z.string().min(1, "名前を入力してください"),
   amount: z.coerce.number().min(1, "金額は1以上である必要があります"),
   eventDate: z.string().min(1, "日付を選択してください"),
@@ -13,6 +13,13 @@ export type Lending = {
   updatedAt: string;
 };
 
+/**
+ * Lending with the ETag sent as If-Match when updating it
+ */
+export type EditableLending = Lending & {
+  etag: string;
+};
+
 export type Debt = {
   userId: string;
   amount: number;
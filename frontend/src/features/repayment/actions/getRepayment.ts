@@ -3,14 +3,18 @@
 import { getAuthToken } from "@/libs/auth/getAuthToken";
 import { createApiClient } from "@/libs/api/client";
 import type { Result } from "@/utils/types";
-import type { Repayment } from "../types";
+import type { EditableRepayment } from "../types";
 
-export async function getRepayment(id: string): Promise<Result<Repayment>> {
+export async function getRepayment(id: string): Promise<Result<EditableRepayment>> {
   const token = await getAuthToken();
   const client = createApiClient(token);
 
   // Fetch repayment data
-  const { data: response, error } = await client.GET("/repayments/{id}", {
+  const {
+    data: response,
+    error,
+    response: httpResponse,
+  } = await client.GET("/repayments/{id}", {
     params: { path: { id } },
   });
 
@@ -37,13 +41,14 @@ export async function getRepayment(id: string): Promise<Result<Repayment>> {
   }
 
   // Transform to frontend Repayment type
-  const repayment: Repayment = {
+  const repayment: EditableRepayment = {
     id: response.id,
     payer: payerResult.data,
     debtor: debtorResult.data,
     amount: response.amount,
     createdAt: response.createdAt,
     updatedAt: response.updatedAt,
+    etag: httpResponse.headers.get("ETag") ?? "",
   };
 
   return {
@@ -20,13 +20,14 @@ export async function updateRepayment(
     return submission.reply();
   }
 
-  const { amount } = submission.value;
+  const { etag, amount } = submission.value;
 
   const token = await getAuthToken();
   const client = createApiClient(token);
 
   const { error } = await client.PUT("/repayments/{id}", {
     params: { path: { id } },
+    headers: { "If-Match": etag },
     body: { amount },
   });
 
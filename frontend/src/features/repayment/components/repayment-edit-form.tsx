@@ -9,10 +9,10 @@ import { Input } from "@/components/ui/input";
 import { ErrorText } from "@/components/ui/error-text";
 import { updateRepayment } from "../actions/updateRepayment";
 import { updateRepaymentSchema } from "../schema";
-import type { Repayment } from "../types";
+import type { EditableRepayment } from "../types";
 
 type Props = {
-  repayment: Repayment;
+  repayment: EditableRepayment;
 };
 
 export function RepaymentEditForm({ repayment }: Props) {
@@ -44,6 +44,13 @@ export function RepaymentEditForm({ repayment }: Props) {
     >
       <h2 className={cn("text-lg font-semibold")}>返済情報</h2>
 
+      <input
+        type="hidden"
+        name={fields.etag.name}
+        value={repayment.etag}
+        readOnly
+      />
+
       <div className={cn("flex justify-between items-start gap-6")}>
         <div className={cn("flex-1")}>
           <p className={cn("text-sm text-gray-600")}>返済者</p>
@@ -6,5 +6,6 @@ export const createRepaymentSchema = z.object({
 });
 
 export const updateRepaymentSchema = z.object({
+  etag: z.string(),
   amount: z.coerce.number().min(1, "金額は1以上である必要があります"),
 });
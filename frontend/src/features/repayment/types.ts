@@ -46,6 +46,13 @@ export type Repayment = {
   updatedAt: string;
 };
 
+/**
+ * Repayment with the ETag sent as If-Match when updating it
+ */
+export type EditableRepayment = Repayment & {
+  etag: string;
+};
+
 export type CreateRepaymentRequest = {
   debtorId: string;
   amount: number;